@@ -23,7 +23,12 @@ func main() {
 	cryptor := crypto.NewCryptor(cfg.Password, cfg.Login)
 	serverPassword := cryptor.GenerateServerPassword()
 
-	cli := client.NewGRPCClient(cfg.ServerAddress, cfg.Login, serverPassword)
+	tlsConfig, err := cfg.TLS.ClientConfig()
+	if err != nil {
+		panic(err)
+	}
+
+	cli := client.NewGRPCClient(cfg.ServerAddress, cfg.Login, serverPassword, tlsConfig)
 
 	defer func() {
 		if err := cli.Close(); err != nil {
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"log"
+	"os"
 
 	"github.com/etoneja/go-keeper/internal/ctl"
 	"github.com/joho/godotenv"
@@ -10,13 +10,5 @@ import (
 func main() {
 	_ = godotenv.Load()
 
-	app, err := ctl.NewApp()
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer app.Close()
-
-	if err := app.Run(); err != nil {
-		log.Fatal(err)
-	}
+	os.Exit(ctl.NewApp().Run())
 }
@@ -6,6 +6,8 @@ func (a ActionType) String() string {
 	return string(a)
 }
 
+// NOTE: Delete actions move the secret to the trash, sync carries the move to
+// the other side like any other modification
 const (
 	ActionDeleteLocal  ActionType = "delete_local"
 	ActionCreateRemote ActionType = "create_remote"
@@ -17,6 +19,20 @@ const (
 	ActionReplaceRemote ActionType = "replace_remote"
 
 	ActionSkip ActionType = "ignore"
+
+	// Purge actions are taken without asking, see markPurged
+	ActionPurgeLocal  ActionType = "purge_local"
+	ActionPurgeRemote ActionType = "purge_remote"
+
+	// ActionMigrateRemoteHash is taken without asking, see migrateRemoteHash
+	ActionMigrateRemoteHash ActionType = "migrate_remote_hash"
+
+	// Attachment actions are taken without asking, see chooseAttachmentAction
+	ActionUploadAttachment       ActionType = "upload_attachment"
+	ActionDownloadAttachment     ActionType = "download_attachment"
+	ActionDeleteLocalAttachment  ActionType = "delete_local_attachment"
+	ActionDeleteRemoteAttachment ActionType = "delete_remote_attachment"
+	ActionDeleteAttachment       ActionType = "delete_attachment"
 )
 
 var LocalOnlyActions = []ActionType{
@@ -36,3 +52,19 @@ var ConflictCheckPairActions = []ActionType{
 	ActionReplaceRemote,
 	ActionSkip,
 }
+
+// NOTE: Secrets flagged as a possible rollback never offer to take the server copy
+var RollbackLocalOnlyActions = []ActionType{
+	ActionCreateRemote,
+	ActionSkip,
+}
+
+var RollbackRemoteOnlyActions = []ActionType{
+	ActionDeleteRemote,
+	ActionSkip,
+}
+
+var RollbackCheckPairActions = []ActionType{
+	ActionReplaceRemote,
+	ActionSkip,
+}
@@ -2,9 +2,11 @@ package ctl
 
 import (
 	"context"
-	"log"
+	"errors"
 
 	"github.com/etoneja/go-keeper/internal/ctl/config"
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
 	"github.com/spf13/cobra"
 )
 
@@ -14,33 +16,67 @@ const (
 	appContextKey contextKey = "app"
 )
 
+// annotationNoVault marks commands that run without a vault config, such as
+// config itself. Subcommands inherit it.
+const annotationNoVault = "no-vault"
+
 type App struct {
 	cfg     *config.Config
 	cmd     *cobra.Command
 	service *VaultService
 }
 
-func NewApp() (*App, error) {
-	cfg, err := config.LoadCfg()
-	if err != nil {
-		return nil, err
-	}
-
-	app := &App{cfg: cfg}
+// NewApp builds the command line. The config is loaded once the command and
+// its --profile are known, see initializeService.
+func NewApp() *App {
+	app := &App{}
 	app.setupCommands()
-	return app, nil
+	return app
 }
 
-func (a *App) Run() error {
-	return a.cmd.Execute()
+// Run executes the command line, closes the vault and returns the exit code,
+// see errs.ExitCode.
+func (a *App) Run() int {
+	cmd, err := a.cmd.ExecuteC()
+
+	var reported *reportedError
+	if err != nil && !errors.As(err, &reported) {
+		printMessage("%s %v", constants.EmojiError, err)
+		// NOTE: A command that cannot run only fails when cobra finds no
+		// such subcommand, which it reports untyped
+		if !cmd.Runnable() && errs.ExitCode(err) == errs.ExitFailure {
+			err = errs.NewUsageError(err)
+		}
+		if errs.IsUsage(err) {
+			printMessage("Run '%s --help' for usage", cmd.CommandPath())
+		}
+	}
+
+	if closeErr := a.Close(); closeErr != nil {
+		printMessage("%s %v", constants.EmojiError, closeErr)
+		if err == nil {
+			err = closeErr
+		}
+	}
+
+	return errs.ExitCode(err)
 }
 
 func (a *App) setupCommands() {
 	rootCmd := &cobra.Command{
-		Use:              "keeperctl",
-		Short:            "Zero-Knowledge secret manager",
-		PersistentPreRun: a.initializeService,
+		Use:               "keeperctl",
+		Short:             "Zero-Knowledge secret manager",
+		PersistentPreRunE: a.initializeService,
+		// NOTE: Failures are printed by withErrorHandling and Run
+		SilenceErrors: true,
+		SilenceUsage:  true,
 	}
+	rootCmd.PersistentFlags().StringP("output", "o", outputTable, "Output format: table, json or yaml")
+	rootCmd.PersistentFlags().String("profile", "", "Config file profile, defaults to GOKEEPER_PROFILE or current_profile")
+
+	rootCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return errs.NewUsageError(err)
+	})
 
 	ctx := context.WithValue(context.Background(), appContextKey, a)
 	rootCmd.SetContext(ctx)
@@ -49,15 +85,66 @@ func (a *App) setupCommands() {
 	a.cmd = rootCmd
 }
 
-func (a *App) initializeService(cmd *cobra.Command, args []string) {
+func (a *App) initializeService(cmd *cobra.Command, args []string) error {
+	// NOTE: Cobra checks these after this hook and reports them untyped
+	if err := cmd.ValidateRequiredFlags(); err != nil {
+		return errs.NewUsageError(err)
+	}
+	if err := cmd.ValidateFlagGroups(); err != nil {
+		return errs.NewUsageError(err)
+	}
+
+	if !needsVault(cmd) {
+		return a.validateOutputFormat(cmd)
+	}
+
+	if a.cfg == nil {
+		cfg, err := config.Load(getStringFlag(cmd, "profile"))
+		if err != nil {
+			return err
+		}
+		a.cfg = cfg
+	}
+
+	output := cmd.Flag("output")
+	if !output.Changed && a.cfg.Output != "" {
+		if err := output.Value.Set(a.cfg.Output); err != nil {
+			return err
+		}
+	}
+
+	if err := a.validateOutputFormat(cmd); err != nil {
+		return err
+	}
+
 	service := NewVaultService(a.cfg)
 	a.service = service
+	return nil
+}
+
+func (a *App) validateOutputFormat(cmd *cobra.Command) error {
+	if err := validateOutputFormat(getOutputFormat(cmd)); err != nil {
+		return errs.NewUsageError(err)
+	}
+	return nil
 }
 
-func (a *App) Close() {
-	if a.service != nil {
-		if err := a.service.Close(); err != nil {
-			log.Printf("Error closing service: %v", err)
+// needsVault reports whether cmd needs the vault config, see annotationNoVault.
+func needsVault(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if _, exists := c.Annotations[annotationNoVault]; exists {
+			return false
 		}
 	}
+	return true
+}
+
+func (a *App) Close() error {
+	if a.service == nil {
+		return nil
+	}
+
+	service := a.service
+	a.service = nil
+	return service.Close()
 }
@@ -0,0 +1,102 @@
+package ctl
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/clipboard"
+	"github.com/etoneja/go-keeper/internal/ctl/config"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApp_RunExitCodes(t *testing.T) {
+	cfg := &config.Config{
+		DBPath:   filepath.Join(t.TempDir(), "vault.db"),
+		Login:    "login",
+		Password: "password",
+	}
+	service := NewVaultService(cfg)
+	require.NoError(t, service.Initialize(context.Background()))
+	require.NoError(t, service.Close())
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "success", args: []string{"list"}, want: errs.ExitOK},
+		{name: "unknown flag", args: []string{"list", "--bogus"}, want: errs.ExitUsage},
+		{name: "unknown command", args: []string{"bogus"}, want: errs.ExitUsage},
+		{name: "wrong argument count", args: []string{"delete"}, want: errs.ExitUsage},
+		{name: "missing required flag", args: []string{"add", "text", "--name", "n"}, want: errs.ExitUsage},
+		{name: "unknown output format", args: []string{"list", "-o", "xml"}, want: errs.ExitUsage},
+		{name: "missing secret", args: []string{"delete", "00000000-0000-0000-0000-000000000000"}, want: errs.ExitNotFound},
+		{name: "invalid secret", args: []string{"add", "text", "--name", "n", "--content", ""}, want: errs.ExitValidation},
+		{name: "untyped failure", args: []string{"import", "--format", "csv", "--file", filepath.Join(t.TempDir(), "missing.csv")}, want: errs.ExitFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &App{cfg: cfg}
+			app.setupCommands()
+			app.cmd.SetArgs(tt.args)
+			app.cmd.SetOut(&bytes.Buffer{})
+
+			assert.Equal(t, tt.want, app.Run())
+		})
+	}
+}
+
+func TestApp_ClipboardReleasesVault(t *testing.T) {
+	ctx := context.Background()
+	copied := filepath.Join(t.TempDir(), "clipboard")
+	t.Setenv(clipboard.BackendEnv, "tee "+copied)
+
+	cfg := &config.Config{
+		DBPath:   filepath.Join(t.TempDir(), "vault.db"),
+		Login:    "login",
+		Password: "password",
+	}
+	service := NewVaultService(cfg)
+	require.NoError(t, service.Initialize(ctx))
+	secret := addTestTextSecret(t, service, "note", "hunter2")
+	require.NoError(t, service.Close())
+
+	app := &App{cfg: cfg}
+	app.setupCommands()
+	app.cmd.SetArgs([]string{"get", secret.UUID, "--field", "content", "--clipboard", "--clear-after", "2s"})
+	app.cmd.SetOut(&bytes.Buffer{})
+
+	done := make(chan int, 1)
+	go func() {
+		done <- app.Run()
+	}()
+
+	require.Eventually(t, func() bool {
+		content, err := os.ReadFile(copied)
+		return err == nil && string(content) == "hunter2"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// NOTE: The clear timer is pending, yet the vault opens without waiting
+	other := NewVaultService(cfg)
+	_, err := other.GetLocalSecret(ctx, secret.UUID)
+	require.NoError(t, err)
+	require.NoError(t, other.Close())
+
+	select {
+	case <-done:
+		t.Fatal("get returned before clearing the clipboard")
+	default:
+	}
+
+	assert.Equal(t, errs.ExitOK, <-done)
+	content, err := os.ReadFile(copied)
+	require.NoError(t, err)
+	assert.Empty(t, content)
+}
@@ -0,0 +1,198 @@
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/fsutil"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+const (
+	archiveVersion  = 1
+	archiveFileMode = 0600
+)
+
+// ErrArchiveExists is returned when writing would overwrite a file.
+var ErrArchiveExists = errs.NewConflictError(errors.New("backup file already exists"))
+
+// ArchivedSecret is a secret as stored in an archive. Hashes are keyed by the
+// master password, so they are left out and recomputed on restore.
+type ArchivedSecret struct {
+	UUID         string                `json:"uuid"`
+	Type         string                `json:"type"`
+	Name         string                `json:"name"`
+	LastModified time.Time             `json:"last_modified"`
+	Metadata     string                `json:"metadata"`
+	Tags         []string              `json:"tags,omitempty"`
+	Fields       []types.CustomField   `json:"fields,omitempty"`
+	Data         []byte                `json:"data"`
+	Attachments  []*ArchivedAttachment `json:"attachments,omitempty"`
+}
+
+// ArchivedAttachment is an attachment of an archived secret, without its hash.
+type ArchivedAttachment struct {
+	UUID         string    `json:"uuid"`
+	Name         string    `json:"name"`
+	MIMEType     string    `json:"mime_type"`
+	LastModified time.Time `json:"last_modified"`
+	Content      []byte    `json:"content"`
+}
+
+// Archive holds secrets independently of the vault format, to be encrypted
+// with a passphrase.
+type Archive struct {
+	Version   int               `json:"version"`
+	CreatedAt time.Time         `json:"created_at"`
+	Secrets   []*ArchivedSecret `json:"secrets"`
+}
+
+// NewArchive builds an archive of secrets, which must have their data loaded.
+func NewArchive(secrets []*types.LocalSecret) *Archive {
+	archive := &Archive{
+		Version:   archiveVersion,
+		CreatedAt: time.Now().UTC(),
+		Secrets:   make([]*ArchivedSecret, 0, len(secrets)),
+	}
+
+	for _, secret := range secrets {
+		archive.Secrets = append(archive.Secrets, &ArchivedSecret{
+			UUID:         secret.UUID,
+			Type:         secret.Type,
+			Name:         secret.Name,
+			LastModified: secret.LastModified,
+			Metadata:     secret.Metadata,
+			Tags:         secret.Tags,
+			Fields:       secret.Fields,
+			Data:         secret.Data,
+		})
+	}
+
+	return archive
+}
+
+// AddAttachments archives attachments, which must have their content
+// loaded, with their secrets. Attachments of secrets not in the archive are
+// left out.
+func (a *Archive) AddAttachments(attachments []*types.Attachment) {
+	secrets := make(map[string]*ArchivedSecret, len(a.Secrets))
+	for _, secret := range a.Secrets {
+		secrets[secret.UUID] = secret
+	}
+
+	for _, attachment := range attachments {
+		secret, exists := secrets[attachment.SecretUUID]
+		if !exists {
+			continue
+		}
+		secret.Attachments = append(secret.Attachments, &ArchivedAttachment{
+			UUID:         attachment.UUID,
+			Name:         attachment.Name,
+			MIMEType:     attachment.MIMEType,
+			LastModified: attachment.LastModified,
+			Content:      attachment.Content,
+		})
+	}
+}
+
+// LocalAttachments converts the attachments of the archived secret back,
+// with hashes computed by cryptor.
+func (s *ArchivedSecret) LocalAttachments(cryptor crypto.Cryptor) []*types.Attachment {
+	attachments := make([]*types.Attachment, 0, len(s.Attachments))
+	for _, archived := range s.Attachments {
+		attachment := &types.Attachment{
+			UUID:         archived.UUID,
+			SecretUUID:   s.UUID,
+			Name:         archived.Name,
+			MIMEType:     archived.MIMEType,
+			Size:         int64(len(archived.Content)),
+			LastModified: archived.LastModified,
+			Content:      archived.Content,
+		}
+		attachment.RefreshHash(cryptor)
+		attachments = append(attachments, attachment)
+	}
+	return attachments
+}
+
+// LocalSecret converts the archived secret back, with the hash computed by cryptor.
+func (s *ArchivedSecret) LocalSecret(cryptor crypto.Cryptor) *types.LocalSecret {
+	secret := &types.LocalSecret{
+		UUID:         s.UUID,
+		Type:         s.Type,
+		Name:         s.Name,
+		LastModified: s.LastModified,
+		Metadata:     s.Metadata,
+		Tags:         s.Tags,
+		Fields:       s.Fields,
+		Data:         s.Data,
+	}
+	secret.RefreshHash(cryptor)
+
+	return secret
+}
+
+// Write encrypts the archive with passphrase to a new file at path, readable
+// by its owner only.
+func Write(path string, passphrase string, archive *Archive) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%w: %s", ErrArchiveExists, path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to check file existence: %w", err)
+	}
+
+	plainData, err := json.Marshal(archive)
+	if err != nil {
+		return fmt.Errorf("failed to marshal archive: %w", err)
+	}
+
+	encrypted, err := crypto.EncryptWithPassphrase(plainData, passphrase)
+	if err != nil {
+		return err
+	}
+
+	return fsutil.WriteFileAtomic(path, encrypted, archiveFileMode)
+}
+
+// Read decrypts the archive at path and checks that every secret in it is
+// well formed.
+func Read(path string, passphrase string) (*Archive, error) {
+	encrypted, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	plainData, err := crypto.DecryptWithPassphrase(encrypted, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &Archive{}
+	if err := json.Unmarshal(plainData, archive); err != nil {
+		return nil, errs.NewCorruptError(fmt.Errorf("failed to parse backup: %w", err))
+	}
+
+	if archive.Version != archiveVersion {
+		return nil, fmt.Errorf("unsupported backup version %d", archive.Version)
+	}
+
+	seen := make(map[string]bool, len(archive.Secrets))
+	for _, secret := range archive.Secrets {
+		if secret.UUID == "" || seen[secret.UUID] {
+			return nil, errs.NewCorruptError(fmt.Errorf("backup holds a missing or duplicate secret id %q", secret.UUID))
+		}
+		seen[secret.UUID] = true
+
+		localSecret := &types.LocalSecret{Type: secret.Type, Data: secret.Data}
+		if _, err := localSecret.ParseData(); err != nil {
+			return nil, errs.NewCorruptError(fmt.Errorf("backup secret %s is malformed: %w", secret.UUID, err))
+		}
+	}
+
+	return archive, nil
+}
@@ -0,0 +1,76 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchive(t *testing.T) {
+	cryptor := crypto.NewCryptor("password", "login")
+
+	secret, err := types.NewSecretModel(
+		types.BaseSecret{Type: "binary", Name: "key", Metadata: "prod"},
+		types.FileData{FileName: "id_ed25519", FileSize: 3, Content: "AAEC"},
+		cryptor,
+	)
+	require.NoError(t, err)
+
+	t.Run("round trip", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "vault.gkbak")
+		require.NoError(t, Write(path, "backup passphrase", NewArchive([]*types.LocalSecret{secret})))
+
+		if runtime.GOOS != "windows" {
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		}
+
+		archive, err := Read(path, "backup passphrase")
+		require.NoError(t, err)
+		require.Len(t, archive.Secrets, 1)
+
+		restored := archive.Secrets[0].LocalSecret(cryptor)
+		assert.Equal(t, secret.UUID, restored.UUID)
+		assert.Equal(t, secret.Name, restored.Name)
+		assert.Equal(t, secret.Metadata, restored.Metadata)
+		assert.Equal(t, secret.Data, restored.Data)
+		assert.Equal(t, secret.Hash, restored.Hash)
+		assert.True(t, secret.LastModified.Equal(restored.LastModified))
+	})
+
+	t.Run("existing file is not overwritten", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "vault.gkbak")
+		require.NoError(t, os.WriteFile(path, []byte("keep"), 0600))
+
+		err := Write(path, "backup passphrase", NewArchive(nil))
+		assert.ErrorIs(t, err, ErrArchiveExists)
+	})
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "vault.gkbak")
+		require.NoError(t, Write(path, "backup passphrase", NewArchive(nil)))
+
+		_, err := Read(path, "password")
+		assert.ErrorIs(t, err, crypto.ErrWrongPassphrase)
+	})
+
+	t.Run("malformed secret is rejected", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "vault.gkbak")
+		archive := NewArchive(nil)
+		archive.Secrets = append(archive.Secrets, &ArchivedSecret{
+			UUID: "broken", Type: "text", LastModified: time.Now(), Data: []byte("{"),
+		})
+		require.NoError(t, Write(path, "backup passphrase", archive))
+
+		_, err := Read(path, "backup passphrase")
+		assert.ErrorContains(t, err, "backup secret broken is malformed")
+	})
+}
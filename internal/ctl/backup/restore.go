@@ -0,0 +1,34 @@
+package backup
+
+import "github.com/etoneja/go-keeper/internal/ctl/errs"
+
+// Restore modes.
+const (
+	// ModeMerge adds missing secrets and updates those the archive has newer.
+	ModeMerge = "merge"
+	// ModeReplace makes the vault hold exactly the archived secrets, the
+	// others are moved to the trash.
+	ModeReplace = "replace"
+	// ModeVerify only checks that the archive decrypts and is well formed.
+	ModeVerify = "verify"
+)
+
+// ValidateMode returns an error for unknown restore modes.
+func ValidateMode(mode string) error {
+	switch mode {
+	case ModeMerge, ModeReplace, ModeVerify:
+		return nil
+	default:
+		return errs.Validationf("unknown restore mode %q, expected %s, %s or %s", mode, ModeMerge, ModeReplace, ModeVerify)
+	}
+}
+
+// RestoreSummary counts what a restore did to the vault.
+type RestoreSummary struct {
+	Mode      string
+	Total     int
+	Added     int
+	Updated   int
+	Unchanged int
+	Deleted   int
+}
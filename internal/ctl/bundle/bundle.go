@@ -0,0 +1,391 @@
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/fsutil"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+// Bundle file layout:
+//
+//	magic "GKSB" | version (1 byte) | salt | sealed JSON contents
+//
+// The contents are sealed under the storage key derived from the salt, with
+// the header as associated data. Records inside keep the ciphertexts the
+// server would hold, so a bundle is a server snapshot on disk.
+const (
+	bundleMagic   = "GKSB"
+	bundleVersion = 1
+
+	bundleFileMode = 0600
+)
+
+var (
+	// ErrNotBundle is returned for files that are not sync bundles.
+	ErrNotBundle = errs.NewValidationError(errors.New("not a sync bundle"))
+	// ErrBundleExists is returned when export would overwrite a bundle.
+	ErrBundleExists = errs.NewConflictError(errors.New("bundle file already exists"))
+)
+
+type bundleRecord struct {
+	UUID         string    `json:"uuid"`
+	LastModified time.Time `json:"last_modified"`
+	Hash         string    `json:"hash"`
+	Data         []byte    `json:"data"`
+	DeletedAt    time.Time `json:"deleted_at,omitzero"`
+}
+
+type bundleAttachment struct {
+	UUID         string    `json:"uuid"`
+	SecretUUID   string    `json:"secret_uuid"`
+	LastModified time.Time `json:"last_modified"`
+	Hash         string    `json:"hash"`
+	Data         []byte    `json:"data"`
+	Header       []byte    `json:"header,omitempty"`
+	Chunks       [][]byte  `json:"chunks,omitempty"`
+}
+
+// remoteAttachment describes the attachment without its data.
+func (a *bundleAttachment) remoteAttachment() *types.RemoteAttachment {
+	attachment := &types.RemoteAttachment{
+		UUID:         a.UUID,
+		SecretUUID:   a.SecretUUID,
+		LastModified: a.LastModified,
+		Hash:         a.Hash,
+		Chunks:       len(a.Chunks),
+	}
+	for _, chunk := range a.Chunks {
+		attachment.Size += int64(len(chunk))
+	}
+	return attachment
+}
+
+type bundleContents struct {
+	ID          string             `json:"id"`
+	Records     []bundleRecord     `json:"records"`
+	Attachments []bundleAttachment `json:"attachments,omitempty"`
+}
+
+// Bundle is an encrypted file of remote secret records serving as a sync
+// target for devices that cannot reach the server. Changes are kept in
+// memory until Save.
+type Bundle struct {
+	path    string
+	cryptor crypto.Cryptor
+
+	mu          sync.Mutex
+	id          string
+	records     map[string]*types.RemoteSecret
+	attachments map[string]*bundleAttachment
+	modified    bool
+}
+
+// Create returns an empty bundle with a new ID, to be written to path.
+func Create(cryptor crypto.Cryptor, path string) (*Bundle, error) {
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrBundleExists, path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to check bundle file: %w", err)
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate bundle id: %w", err)
+	}
+
+	return &Bundle{
+		path:        path,
+		cryptor:     cryptor,
+		id:          hex.EncodeToString(id),
+		records:     make(map[string]*types.RemoteSecret),
+		attachments: make(map[string]*bundleAttachment),
+		modified:    true,
+	}, nil
+}
+
+// Open reads and decrypts the bundle at path.
+func Open(cryptor crypto.Cryptor, path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	headerSize := len(bundleMagic) + 1 + crypto.StorageSaltSize
+	if len(data) < headerSize || string(data[:len(bundleMagic)]) != bundleMagic {
+		return nil, fmt.Errorf("%w: %s", ErrNotBundle, path)
+	}
+	if version := data[len(bundleMagic)]; version != bundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d", version)
+	}
+
+	header := data[:headerSize]
+	sealer, err := cryptor.StorageSealer(header[len(bundleMagic)+1:])
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := sealer.Open(data[headerSize:], header)
+	if err != nil {
+		return nil, errs.NewAuthError(fmt.Errorf("failed to decrypt bundle, wrong password or corrupted bundle: %w", err))
+	}
+
+	var contents bundleContents
+	if err := json.Unmarshal(plain, &contents); err != nil {
+		return nil, errs.NewCorruptError(fmt.Errorf("failed to parse bundle: %w", err))
+	}
+
+	b := &Bundle{
+		path:        path,
+		cryptor:     cryptor,
+		id:          contents.ID,
+		records:     make(map[string]*types.RemoteSecret, len(contents.Records)),
+		attachments: make(map[string]*bundleAttachment, len(contents.Attachments)),
+	}
+	for _, record := range contents.Records {
+		b.records[record.UUID] = &types.RemoteSecret{
+			UUID:         record.UUID,
+			LastModified: record.LastModified,
+			Hash:         record.Hash,
+			Data:         record.Data,
+			DeletedAt:    record.DeletedAt,
+		}
+	}
+	for _, attachment := range contents.Attachments {
+		b.attachments[attachment.UUID] = &attachment
+	}
+
+	return b, nil
+}
+
+// ID identifies the bundle across the devices it is carried between.
+func (b *Bundle) ID() string {
+	return b.id
+}
+
+// Path returns the file the bundle is read from and saved to.
+func (b *Bundle) Path() string {
+	return b.path
+}
+
+// Modified reports whether the bundle has changes not yet saved.
+func (b *Bundle) Modified() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.modified
+}
+
+// Save writes the bundle atomically, readable by its owner only.
+func (b *Bundle) Save() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	contents := bundleContents{ID: b.id, Records: make([]bundleRecord, 0, len(b.records))}
+	for _, secret := range b.records {
+		contents.Records = append(contents.Records, bundleRecord{
+			UUID:         secret.UUID,
+			LastModified: secret.LastModified,
+			Hash:         secret.Hash,
+			Data:         secret.Data,
+			DeletedAt:    secret.DeletedAt,
+		})
+	}
+	sort.Slice(contents.Records, func(i, j int) bool {
+		return contents.Records[i].UUID < contents.Records[j].UUID
+	})
+	for _, attachment := range b.attachments {
+		contents.Attachments = append(contents.Attachments, *attachment)
+	}
+	sort.Slice(contents.Attachments, func(i, j int) bool {
+		return contents.Attachments[i].UUID < contents.Attachments[j].UUID
+	})
+
+	plain, err := json.Marshal(contents)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundle: %w", err)
+	}
+
+	salt, err := crypto.NewStorageSalt()
+	if err != nil {
+		return err
+	}
+
+	var header bytes.Buffer
+	header.WriteString(bundleMagic)
+	header.WriteByte(bundleVersion)
+	header.Write(salt)
+
+	sealer, err := b.cryptor.StorageSealer(salt)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := sealer.Seal(plain, header.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to encrypt bundle: %w", err)
+	}
+
+	if err := fsutil.WriteFileAtomic(b.path, append(header.Bytes(), sealed...), bundleFileMode); err != nil {
+		return err
+	}
+
+	b.modified = false
+
+	return nil
+}
+
+func (b *Bundle) TargetID() string {
+	return constants.BundleSyncTargetPrefix + b.id
+}
+
+func (b *Bundle) SetSecret(ctx context.Context, secret *types.RemoteSecret) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stored := *secret
+	stored.Data = append([]byte(nil), secret.Data...)
+	b.records[secret.UUID] = &stored
+	b.modified = true
+
+	return nil
+}
+
+func (b *Bundle) GetSecret(ctx context.Context, secretID string) (*types.RemoteSecret, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	secret, exists := b.records[secretID]
+	if !exists {
+		return nil, errs.NewSecretNotFoundError(secretID)
+	}
+
+	found := *secret
+	found.Data = append([]byte(nil), secret.Data...)
+
+	return &found, nil
+}
+
+func (b *Bundle) DeleteSecret(ctx context.Context, secretID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, exists := b.records[secretID]; !exists {
+		return errs.NewSecretNotFoundError(secretID)
+	}
+
+	delete(b.records, secretID)
+	b.modified = true
+
+	return nil
+}
+
+// ListSecrets returns the records without their data, like the server does.
+func (b *Bundle) ListSecrets(ctx context.Context) ([]*types.RemoteSecret, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	secrets := make([]*types.RemoteSecret, 0, len(b.records))
+	for _, secret := range b.records {
+		secrets = append(secrets, &types.RemoteSecret{
+			UUID:         secret.UUID,
+			LastModified: secret.LastModified,
+			Hash:         secret.Hash,
+			DeletedAt:    secret.DeletedAt,
+		})
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].UUID < secrets[j].UUID
+	})
+
+	return secrets, nil
+}
+
+// UploadAttachment reads all of the content at once, bundles have no
+// interrupted uploads to resume.
+func (b *Bundle) UploadAttachment(ctx context.Context, attachment *types.RemoteAttachment, content types.ChunkSource) error {
+	stored := &bundleAttachment{
+		UUID:         attachment.UUID,
+		SecretUUID:   attachment.SecretUUID,
+		LastModified: attachment.LastModified,
+		Hash:         attachment.Hash,
+		Data:         append([]byte(nil), attachment.Data...),
+		Header:       append([]byte(nil), attachment.Header...),
+		Chunks:       make([][]byte, attachment.Chunks),
+	}
+	for i := range stored.Chunks {
+		chunk, err := content.Chunk(i)
+		if err != nil {
+			return err
+		}
+		stored.Chunks[i] = chunk
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.attachments[attachment.UUID] = stored
+	b.modified = true
+
+	return nil
+}
+
+func (b *Bundle) DownloadAttachment(ctx context.Context, attachmentID string) (*types.RemoteAttachment, types.ChunkReader, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stored, exists := b.attachments[attachmentID]
+	if !exists {
+		return nil, nil, errs.NewAttachmentNotFoundError(attachmentID)
+	}
+
+	attachment := stored.remoteAttachment()
+	attachment.Data = append([]byte(nil), stored.Data...)
+	attachment.Header = append([]byte(nil), stored.Header...)
+
+	return attachment, types.NewChunkReader(slices.Clone(stored.Chunks)), nil
+}
+
+func (b *Bundle) DeleteAttachment(ctx context.Context, attachmentID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, exists := b.attachments[attachmentID]; !exists {
+		return errs.NewAttachmentNotFoundError(attachmentID)
+	}
+
+	delete(b.attachments, attachmentID)
+	b.modified = true
+
+	return nil
+}
+
+// ListAttachments returns the attachments without their data.
+func (b *Bundle) ListAttachments(ctx context.Context) ([]*types.RemoteAttachment, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	attachments := make([]*types.RemoteAttachment, 0, len(b.attachments))
+	for _, attachment := range b.attachments {
+		attachments = append(attachments, attachment.remoteAttachment())
+	}
+	sort.Slice(attachments, func(i, j int) bool {
+		return attachments[i].UUID < attachments[j].UUID
+	})
+
+	return attachments, nil
+}
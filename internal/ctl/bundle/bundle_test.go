@@ -0,0 +1,152 @@
+package bundle
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chunkSlice is a content source of chunks held in memory.
+type chunkSlice [][]byte
+
+func (c chunkSlice) Chunk(index int) ([]byte, error) {
+	return c[index], nil
+}
+
+func TestBundle(t *testing.T) {
+	ctx := context.Background()
+	cryptor := crypto.NewCryptor("password", "login")
+
+	secret := &types.RemoteSecret{
+		UUID:         "secret-1",
+		LastModified: time.Date(2025, 1, 2, 3, 4, 5, 6000, time.UTC),
+		Hash:         "hash-1",
+		Data:         []byte("sealed"),
+	}
+	attachment := &types.RemoteAttachment{
+		UUID:         "attachment-1",
+		SecretUUID:   secret.UUID,
+		LastModified: secret.LastModified,
+		Hash:         "hash-a",
+		Data:         []byte("sealed description"),
+		Header:       []byte("header"),
+		Chunks:       2,
+	}
+	chunks := chunkSlice{[]byte("sealed"), []byte("content")}
+
+	t.Run("round trip", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sync.gkb")
+
+		b, err := Create(cryptor, path)
+		require.NoError(t, err)
+		assert.Equal(t, constants.BundleSyncTargetPrefix+b.ID(), b.TargetID())
+		require.NoError(t, b.SetSecret(ctx, secret))
+		require.NoError(t, b.SetSecret(ctx, &types.RemoteSecret{UUID: "secret-2", Data: []byte("x")}))
+		require.NoError(t, b.DeleteSecret(ctx, "secret-2"))
+		require.NoError(t, b.UploadAttachment(ctx, attachment, chunks))
+		require.NoError(t, b.Save())
+		assert.False(t, b.Modified())
+
+		if runtime.GOOS != "windows" {
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		}
+
+		opened, err := Open(cryptor, path)
+		require.NoError(t, err)
+		assert.Equal(t, b.ID(), opened.ID())
+		assert.False(t, opened.Modified())
+
+		listed, err := opened.ListSecrets(ctx)
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		assert.Nil(t, listed[0].Data, "listing omits data like the server")
+
+		loaded, err := opened.GetSecret(ctx, secret.UUID)
+		require.NoError(t, err)
+		assert.Equal(t, secret.Data, loaded.Data)
+		assert.Equal(t, secret.Hash, loaded.Hash)
+		assert.True(t, secret.LastModified.Equal(loaded.LastModified))
+
+		_, err = opened.GetSecret(ctx, "secret-2")
+		assert.True(t, errs.IsNotFound(err))
+
+		attachments, err := opened.ListAttachments(ctx)
+		require.NoError(t, err)
+		require.Len(t, attachments, 1)
+		assert.Equal(t, attachment.SecretUUID, attachments[0].SecretUUID)
+		assert.Nil(t, attachments[0].Data)
+		assert.Equal(t, 2, attachments[0].Chunks)
+		assert.Equal(t, int64(13), attachments[0].Size)
+
+		loadedAttachment, content, err := opened.DownloadAttachment(ctx, attachment.UUID)
+		require.NoError(t, err)
+		assert.Equal(t, attachment.Data, loadedAttachment.Data)
+		assert.Equal(t, attachment.Header, loadedAttachment.Header)
+		for _, want := range chunks {
+			chunk, err := content.ReadChunk()
+			require.NoError(t, err)
+			assert.Equal(t, want, chunk)
+		}
+		_, err = content.ReadChunk()
+		assert.ErrorIs(t, err, io.EOF)
+		require.NoError(t, content.Close())
+
+		require.NoError(t, opened.DeleteAttachment(ctx, attachment.UUID))
+		assert.True(t, opened.Modified())
+		assert.True(t, errs.IsNotFound(opened.DeleteAttachment(ctx, attachment.UUID)))
+	})
+
+	t.Run("existing file is not overwritten", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sync.gkb")
+		require.NoError(t, os.WriteFile(path, []byte("keep"), 0600))
+
+		_, err := Create(cryptor, path)
+		assert.ErrorIs(t, err, ErrBundleExists)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sync.gkb")
+		b, err := Create(cryptor, path)
+		require.NoError(t, err)
+		require.NoError(t, b.Save())
+
+		_, err = Open(crypto.NewCryptor("wrong", "login"), path)
+		assert.ErrorContains(t, err, "failed to decrypt bundle")
+	})
+
+	t.Run("tampered header", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sync.gkb")
+		b, err := Create(cryptor, path)
+		require.NoError(t, err)
+		require.NoError(t, b.Save())
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		data[len(bundleMagic)+1] ^= 0xff
+		require.NoError(t, os.WriteFile(path, data, 0600))
+
+		_, err = Open(cryptor, path)
+		assert.ErrorContains(t, err, "failed to decrypt bundle")
+	})
+
+	t.Run("not a bundle", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "vault.db")
+		require.NoError(t, os.WriteFile(path, []byte("something else entirely"), 0600))
+
+		_, err := Open(cryptor, path)
+		assert.ErrorIs(t, err, ErrNotBundle)
+	})
+}
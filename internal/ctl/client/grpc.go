@@ -1,20 +1,29 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"errors"
+	"fmt"
+	"io"
 
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
 	"github.com/etoneja/go-keeper/internal/ctl/types"
 	"github.com/etoneja/go-keeper/internal/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 var (
-	ErrUnauthorized = errors.New("unauthorized")
-	ErrNotConnected = errors.New("not connected to server")
+	ErrUnauthorized = errs.NewAuthError(errors.New("unauthorized"))
+	ErrNotConnected = errs.NewNetworkError(errors.New("not connected to server"))
 )
 
 type Client struct {
@@ -23,6 +32,7 @@ type Client struct {
 	secretClient proto.SecretServiceClient
 
 	serverAddress string
+	tlsConfig     *tls.Config
 
 	login    string
 	password string
@@ -30,17 +40,24 @@ type Client struct {
 	token string
 }
 
-func NewGRPCClient(serverAddress string, login string, password string) *Client {
-
+// NewGRPCClient returns a client for the server, connecting over TLS unless
+// tlsConfig is nil.
+func NewGRPCClient(serverAddress string, login string, password string, tlsConfig *tls.Config) *Client {
 	return &Client{
 		serverAddress: serverAddress,
+		tlsConfig:     tlsConfig,
 		login:         login,
 		password:      password,
 	}
 }
 
 func (c *Client) Connect(ctx context.Context) error {
-	conn, err := grpc.NewClient(c.serverAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	transportCredentials := insecure.NewCredentials()
+	if c.tlsConfig != nil {
+		transportCredentials = credentials.NewTLS(c.tlsConfig)
+	}
+
+	conn, err := grpc.NewClient(c.serverAddress, grpc.WithTransportCredentials(transportCredentials))
 	if err != nil {
 		return err
 	}
@@ -65,8 +82,11 @@ func (c *Client) Login(ctx context.Context) error {
 	req.SetPassword(c.password)
 
 	resp, err := c.authClient.Login(ctx, req)
+	if status.Code(err) == codes.NotFound {
+		return errs.NewAuthError(err)
+	}
 	if err != nil {
-		return err
+		return rpcError(err)
 	}
 
 	c.token = resp.GetToken()
@@ -80,7 +100,7 @@ func (c *Client) Register(ctx context.Context) (string, error) {
 
 	resp, err := c.authClient.Register(ctx, req)
 	if err != nil {
-		return "", err
+		return "", rpcError(err)
 	}
 	return resp.GetUserId(), nil
 }
@@ -108,10 +128,10 @@ func (c *Client) withAuthRetry(ctx context.Context, fn func(context.Context) err
 		}
 
 		authCtx = c.createAuthContext(ctx)
-		return fn(authCtx)
+		return rpcError(fn(authCtx))
 	}
 
-	return err
+	return rpcError(err)
 }
 
 func (c *Client) createAuthContext(ctx context.Context) context.Context {
@@ -120,6 +140,10 @@ func (c *Client) createAuthContext(ctx context.Context) context.Context {
 	)
 }
 
+func (c *Client) TargetID() string {
+	return constants.ServerSyncTarget
+}
+
 // Secret methods with auto-auth
 func (c *Client) SetSecret(ctx context.Context, secret *types.RemoteSecret) error {
 	reqSecret := &proto.Secret{}
@@ -127,6 +151,9 @@ func (c *Client) SetSecret(ctx context.Context, secret *types.RemoteSecret) erro
 	reqSecret.SetLastModified(timestamppb.New(secret.LastModified))
 	reqSecret.SetHash(secret.Hash)
 	reqSecret.SetData(secret.Data)
+	if secret.InTrash() {
+		reqSecret.SetDeletedAt(timestamppb.New(secret.DeletedAt))
+	}
 
 	req := &proto.SetSecretRequest{}
 	req.SetSecret(reqSecret)
@@ -148,24 +175,24 @@ func (c *Client) GetSecret(ctx context.Context, secretID string) (*types.RemoteS
 		resp, err = c.secretClient.GetSecret(authCtx, req)
 		return err
 	})
+	if status.Code(err) == codes.NotFound {
+		return nil, errs.NewSecretNotFoundError(secretID)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	secretResp := resp.GetSecret()
-	secret := &types.RemoteSecret{
-		UUID:         secretResp.GetId(),
-		LastModified: secretResp.GetLastModified().AsTime(),
-		Hash:         secretResp.GetHash(),
-		Data:         secretResp.GetData(),
-	}
+	secret := newRemoteSecret(resp.GetSecret())
+	secret.Data = resp.GetSecret().GetData()
 
 	return secret, nil
 }
 
+// DeleteSecret erases the secret from the server, in the trash or not.
 func (c *Client) DeleteSecret(ctx context.Context, secretID string) error {
 	req := &proto.DeleteSecretRequest{}
 	req.SetSecretId(secretID)
+	req.SetPurge(true)
 
 	return c.withAuthRetry(ctx, func(authCtx context.Context) error {
 		_, err := c.secretClient.DeleteSecret(authCtx, req)
@@ -187,16 +214,257 @@ func (c *Client) ListSecrets(ctx context.Context) ([]*types.RemoteSecret, error)
 	secretsResp := resp.GetSecrets()
 	secrets := make([]*types.RemoteSecret, len(secretsResp))
 	for i, secretResp := range secretsResp {
-		secrets[i] = &types.RemoteSecret{
-			UUID:         secretResp.GetId(),
-			LastModified: secretResp.GetLastModified().AsTime(),
-			Hash:         secretResp.GetHash(),
-		}
+		secrets[i] = newRemoteSecret(secretResp)
 	}
 
 	return secrets, nil
 }
 
+// newRemoteSecret converts the secret without its data.
+func newRemoteSecret(secretResp *proto.Secret) *types.RemoteSecret {
+	secret := &types.RemoteSecret{
+		UUID:         secretResp.GetId(),
+		LastModified: secretResp.GetLastModified().AsTime(),
+		Hash:         secretResp.GetHash(),
+	}
+	if secretResp.HasDeletedAt() {
+		secret.DeletedAt = secretResp.GetDeletedAt().AsTime()
+	}
+
+	return secret
+}
+
+// UploadAttachment streams the attachment to the server chunk by chunk. An
+// upload of the same attachment cut off earlier resumes after the chunks the
+// server already has.
+func (c *Client) UploadAttachment(ctx context.Context, attachment *types.RemoteAttachment, content types.ChunkSource) error {
+	reqAttachment := newProtoAttachment(attachment)
+
+	firstChunk, err := c.resumableUpload(ctx, reqAttachment)
+	if err != nil {
+		return err
+	}
+
+	upload := &proto.AttachmentUpload{}
+	upload.SetAttachment(reqAttachment)
+	upload.SetFirstChunk(int32(firstChunk))
+
+	req := &proto.UploadAttachmentRequest{}
+	req.SetUpload(upload)
+
+	return c.withAuthRetry(ctx, func(authCtx context.Context) error {
+		stream, err := c.secretClient.UploadAttachment(authCtx)
+		if err != nil {
+			return err
+		}
+
+		// NOTE: Send fails with io.EOF once the server ends the call, CloseAndRecv has its status
+		if err := stream.Send(req); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		for index := firstChunk; index < attachment.Chunks; index++ {
+			data, err := content.Chunk(index)
+			if err != nil {
+				return err
+			}
+
+			chunk := &proto.AttachmentChunk{}
+			chunk.SetIndex(int32(index))
+			chunk.SetData(data)
+
+			chunkReq := &proto.UploadAttachmentRequest{}
+			chunkReq.SetChunk(chunk)
+			if err := stream.Send(chunkReq); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return err
+			}
+		}
+
+		_, err = stream.CloseAndRecv()
+		return err
+	})
+}
+
+// resumableUpload returns the chunk to upload the attachment from. The
+// server keeps an upload cut off earlier, which is resumed if it is of the
+// same content. Its sealed data is kept, sealing it again differs every time.
+func (c *Client) resumableUpload(ctx context.Context, attachment *proto.Attachment) (int, error) {
+	var resp *proto.GetAttachmentUploadResponse
+
+	req := &proto.GetAttachmentUploadRequest{}
+	req.SetAttachmentId(attachment.GetId())
+
+	err := c.withAuthRetry(ctx, func(authCtx context.Context) error {
+		var err error
+		resp, err = c.secretClient.GetAttachmentUpload(authCtx, req)
+		return err
+	})
+	if status.Code(err) == codes.NotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	upload := resp.GetAttachment()
+	if upload.GetSecretId() != attachment.GetSecretId() ||
+		upload.GetHash() != attachment.GetHash() ||
+		!upload.GetLastModified().AsTime().Equal(attachment.GetLastModified().AsTime()) ||
+		upload.GetChunks() != attachment.GetChunks() ||
+		upload.GetSize() != attachment.GetSize() ||
+		!bytes.Equal(upload.GetHeader(), attachment.GetHeader()) {
+		return 0, nil
+	}
+
+	attachment.SetData(upload.GetData())
+	return int(resp.GetReceivedChunks()), nil
+}
+
+// DownloadAttachment starts streaming the attachment from the server. The
+// returned reader reads its chunks and must be closed.
+func (c *Client) DownloadAttachment(ctx context.Context, attachmentID string) (*types.RemoteAttachment, types.ChunkReader, error) {
+	var (
+		stream proto.SecretService_DownloadAttachmentClient
+		resp   *proto.DownloadAttachmentResponse
+	)
+
+	req := &proto.DownloadAttachmentRequest{}
+	req.SetAttachmentId(attachmentID)
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	err := c.withAuthRetry(streamCtx, func(authCtx context.Context) error {
+		var err error
+		stream, err = c.secretClient.DownloadAttachment(authCtx, req)
+		if err != nil {
+			return err
+		}
+		resp, err = stream.Recv()
+		return err
+	})
+	if status.Code(err) == codes.NotFound {
+		cancel()
+		return nil, nil, errs.NewAttachmentNotFoundError(attachmentID)
+	}
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	if !resp.HasAttachment() {
+		cancel()
+		return nil, nil, fmt.Errorf("download of attachment %s did not start with the attachment", attachmentID)
+	}
+
+	attachment := newRemoteAttachment(resp.GetAttachment())
+	attachment.Data = resp.GetAttachment().GetData()
+	attachment.Header = resp.GetAttachment().GetHeader()
+
+	return attachment, &chunkStream{stream: stream, cancel: cancel}, nil
+}
+
+// chunkStream reads attachment chunks from a download stream.
+type chunkStream struct {
+	stream proto.SecretService_DownloadAttachmentClient
+	cancel context.CancelFunc
+}
+
+func (s *chunkStream) ReadChunk() ([]byte, error) {
+	resp, err := s.stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	if !resp.HasChunk() {
+		return nil, fmt.Errorf("expected an attachment chunk")
+	}
+	return resp.GetChunk().GetData(), nil
+}
+
+func (s *chunkStream) Close() error {
+	s.cancel()
+	return nil
+}
+
+func (c *Client) DeleteAttachment(ctx context.Context, attachmentID string) error {
+	req := &proto.DeleteAttachmentRequest{}
+	req.SetAttachmentId(attachmentID)
+
+	err := c.withAuthRetry(ctx, func(authCtx context.Context) error {
+		_, err := c.secretClient.DeleteAttachment(authCtx, req)
+		return err
+	})
+	if status.Code(err) == codes.NotFound {
+		return errs.NewAttachmentNotFoundError(attachmentID)
+	}
+	return err
+}
+
+func (c *Client) ListAttachments(ctx context.Context) ([]*types.RemoteAttachment, error) {
+	var resp *proto.ListAttachmentsResponse
+	err := c.withAuthRetry(ctx, func(authCtx context.Context) error {
+		var err error
+		resp, err = c.secretClient.ListAttachments(authCtx, &proto.ListAttachmentsRequest{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	attachmentsResp := resp.GetAttachments()
+	attachments := make([]*types.RemoteAttachment, len(attachmentsResp))
+	for i, attachmentResp := range attachmentsResp {
+		attachments[i] = newRemoteAttachment(attachmentResp)
+	}
+
+	return attachments, nil
+}
+
+func newProtoAttachment(attachment *types.RemoteAttachment) *proto.Attachment {
+	reqAttachment := &proto.Attachment{}
+	reqAttachment.SetId(attachment.UUID)
+	reqAttachment.SetSecretId(attachment.SecretUUID)
+	reqAttachment.SetLastModified(timestamppb.New(attachment.LastModified))
+	reqAttachment.SetHash(attachment.Hash)
+	reqAttachment.SetData(attachment.Data)
+	reqAttachment.SetHeader(attachment.Header)
+	reqAttachment.SetChunks(int32(attachment.Chunks))
+	reqAttachment.SetSize(attachment.Size)
+
+	return reqAttachment
+}
+
+func newRemoteAttachment(attachment *proto.Attachment) *types.RemoteAttachment {
+	return &types.RemoteAttachment{
+		UUID:         attachment.GetId(),
+		SecretUUID:   attachment.GetSecretId(),
+		LastModified: attachment.GetLastModified().AsTime(),
+		Hash:         attachment.GetHash(),
+		Chunks:       int(attachment.GetChunks()),
+		Size:         attachment.GetSize(),
+	}
+}
+
+// rpcError classifies a failed call by its gRPC status, for exit codes.
+func rpcError(err error) error {
+	switch status.Code(err) {
+	case codes.OK:
+		return err
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return errs.NewAuthError(err)
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return errs.NewNetworkError(err)
+	case codes.AlreadyExists, codes.Aborted, codes.FailedPrecondition:
+		return errs.NewConflictError(err)
+	case codes.InvalidArgument, codes.OutOfRange, codes.ResourceExhausted:
+		return errs.NewValidationError(err)
+	default:
+		return err
+	}
+}
+
 func isUnauthorizedError(err error) bool {
 	return err != nil && err.Error() == "rpc error: code = Unauthenticated desc = invalid token"
 }
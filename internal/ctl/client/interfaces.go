@@ -6,15 +6,36 @@ import (
 	"github.com/etoneja/go-keeper/internal/ctl/types"
 )
 
+// SyncTarget is a store of remote secrets the vault is synced with.
+type SyncTarget interface {
+	// TargetID names the target the vault keeps its seen sync manifest for.
+	TargetID() string
+
+	// SetSecret stores the secret, in the trash when DeletedAt is set.
+	SetSecret(ctx context.Context, secret *types.RemoteSecret) error
+	GetSecret(ctx context.Context, secretID string) (*types.RemoteSecret, error)
+	// DeleteSecret erases the secret for good, it is moved to the trash with SetSecret.
+	DeleteSecret(ctx context.Context, secretID string) error
+	// ListSecrets returns every secret, those in the trash too, without its data.
+	ListSecrets(ctx context.Context) ([]*types.RemoteSecret, error)
+
+	// UploadAttachment stores the attachment, reading its sealed content from
+	// content. An interrupted upload of the same attachment is resumed.
+	UploadAttachment(ctx context.Context, attachment *types.RemoteAttachment, content types.ChunkSource) error
+	// DownloadAttachment returns the attachment and a reader of its sealed
+	// content, which the caller must close.
+	DownloadAttachment(ctx context.Context, attachmentID string) (*types.RemoteAttachment, types.ChunkReader, error)
+	DeleteAttachment(ctx context.Context, attachmentID string) error
+	// ListAttachments returns every attachment without its data.
+	ListAttachments(ctx context.Context) ([]*types.RemoteAttachment, error)
+}
+
 type Clienter interface {
+	SyncTarget
+
 	Connect(ctx context.Context) error
 	Close() error
 
 	Login(ctx context.Context) error
 	Register(ctx context.Context) (string, error)
-
-	SetSecret(ctx context.Context, secret *types.RemoteSecret) error
-	GetSecret(ctx context.Context, secretID string) (*types.RemoteSecret, error)
-	DeleteSecret(ctx context.Context, secretID string) error
-	ListSecrets(ctx context.Context) ([]*types.RemoteSecret, error)
 }
@@ -2,9 +2,10 @@ package client
 
 import (
 	"fmt"
+	"os"
 	"time"
 )
 
 func Log(msg string) {
-	fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05.000"), msg)
+	fmt.Fprintf(os.Stderr, "[%s] %s\n", time.Now().Format("15:04:05.000"), msg)
 }
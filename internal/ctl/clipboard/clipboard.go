@@ -0,0 +1,144 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// BackendEnv selects the backend instead of detection: "osc52" or a copy
+// command such as "xclip -selection clipboard", which reads the value on stdin.
+const BackendEnv = "GOKEEPER_CLIPBOARD"
+
+// waitDelay bounds the wait for the output of a copy command that exited. xclip
+// and xsel leave a child serving the selection, which inherits stderr.
+const waitDelay = time.Second
+
+// Clipboard copies values for pasting elsewhere.
+type Clipboard interface {
+	Copy(value string) error
+	// Clear empties the clipboard.
+	Clear() error
+	// Name describes the backend in messages.
+	Name() string
+}
+
+// Command runs an external program that reads the value on stdin.
+type Command struct {
+	copyArgs  []string
+	clearArgs []string
+}
+
+// NewCommand returns a backend running copyArgs. Clearing runs clearArgs, or
+// copies an empty value when there are none.
+func NewCommand(copyArgs, clearArgs []string) *Command {
+	return &Command{copyArgs: copyArgs, clearArgs: clearArgs}
+}
+
+func (c *Command) Copy(value string) error {
+	return c.run(c.copyArgs, value)
+}
+
+func (c *Command) Clear() error {
+	if len(c.clearArgs) == 0 {
+		return c.run(c.copyArgs, "")
+	}
+	return c.run(c.clearArgs, "")
+}
+
+func (c *Command) Name() string {
+	return c.copyArgs[0]
+}
+
+func (c *Command) run(args []string, stdin string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = waitDelay
+
+	err := cmd.Run()
+	if err != nil && !errors.Is(err, exec.ErrWaitDelay) {
+		return fmt.Errorf("%s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// OSC52 asks the terminal to set its clipboard with an escape sequence, which
+// also works over SSH. Not every terminal supports it.
+type OSC52 struct {
+	w io.Writer
+}
+
+// NewOSC52 returns a backend writing the escape sequence to w, the terminal.
+func NewOSC52(w io.Writer) *OSC52 {
+	return &OSC52{w: w}
+}
+
+func (o *OSC52) Copy(value string) error {
+	return o.write(base64.StdEncoding.EncodeToString([]byte(value)))
+}
+
+func (o *OSC52) Clear() error {
+	// NOTE: Terminals clear the selection when the payload is not valid base64
+	return o.write("!")
+}
+
+func (o *OSC52) Name() string {
+	return "OSC52"
+}
+
+func (o *OSC52) write(payload string) error {
+	if _, err := fmt.Fprintf(o.w, "\x1b]52;c;%s\a", payload); err != nil {
+		return fmt.Errorf("failed to write to terminal: %w", err)
+	}
+	return nil
+}
+
+// Detect picks the backend named by BackendEnv, else the first clipboard
+// program found for the platform, else OSC52 on stderr.
+func Detect() Clipboard {
+	if backend := strings.TrimSpace(os.Getenv(BackendEnv)); backend != "" {
+		if backend == "osc52" {
+			return NewOSC52(os.Stderr)
+		}
+		return NewCommand(strings.Fields(backend), nil)
+	}
+
+	for _, candidate := range candidates() {
+		if _, err := exec.LookPath(candidate.copyArgs[0]); err == nil {
+			return candidate
+		}
+	}
+
+	return NewOSC52(os.Stderr)
+}
+
+func candidates() []*Command {
+	switch runtime.GOOS {
+	case "darwin":
+		return []*Command{NewCommand([]string{"pbcopy"}, nil)}
+	case "windows":
+		return []*Command{NewCommand([]string{"clip"}, nil)}
+	}
+
+	var found []*Command
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		found = append(found, NewCommand([]string{"wl-copy"}, []string{"wl-copy", "--clear"}))
+	}
+	if os.Getenv("DISPLAY") != "" {
+		found = append(found,
+			NewCommand([]string{"xclip", "-selection", "clipboard"}, nil),
+			NewCommand([]string{"xsel", "--clipboard", "--input"}, []string{"xsel", "--clipboard", "--delete"}),
+		)
+	}
+	return found
+}
@@ -0,0 +1,67 @@
+package clipboard
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOSC52(t *testing.T) {
+	var terminal bytes.Buffer
+	backend := NewOSC52(&terminal)
+
+	require.NoError(t, backend.Copy("hunter2"))
+	assert.Equal(t, "\x1b]52;c;aHVudGVyMg==\a", terminal.String())
+
+	terminal.Reset()
+	require.NoError(t, backend.Clear())
+	assert.Equal(t, "\x1b]52;c;!\a", terminal.String())
+}
+
+func TestCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+
+	path := filepath.Join(t.TempDir(), "clipboard")
+	backend := NewCommand([]string{"sh", "-c", "cat > " + path}, nil)
+
+	require.NoError(t, backend.Copy("hunter2"))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", string(content))
+
+	require.NoError(t, backend.Clear())
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Empty(t, content)
+
+	// NOTE: Like xclip, the command leaves a child holding its output open
+	forking := NewCommand([]string{"sh", "-c", "cat > " + path + "; sleep 30 &"}, nil)
+	started := time.Now()
+	require.NoError(t, forking.Copy("hunter2"))
+	assert.Less(t, time.Since(started), 10*time.Second)
+
+	failing := NewCommand([]string{"sh", "-c", "echo broken >&2; exit 1"}, nil)
+	err = failing.Copy("value")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "broken")
+}
+
+func TestDetect_Env(t *testing.T) {
+	t.Setenv(BackendEnv, "osc52")
+	assert.Equal(t, "OSC52", Detect().Name())
+
+	t.Setenv(BackendEnv, "my-copy --flag")
+	assert.Equal(t, "my-copy", Detect().Name())
+}
@@ -0,0 +1,41 @@
+package ctl
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var attachCmd = &cobra.Command{
+	Use:   "attach",
+	Short: "Manage file attachments of secrets",
+}
+
+var attachAddCmd = &cobra.Command{
+	Use:   "add <uuid|path> <file>",
+	Short: "Attach file to secret",
+	Args:  usageArgs(cobra.ExactArgs(2)),
+	RunE:  withErrorHandling(createAttachAddHandler()),
+}
+
+var attachListCmd = &cobra.Command{
+	Use:   "list <uuid|path>",
+	Short: "List attachments of secret",
+	Args:  usageArgs(cobra.ExactArgs(1)),
+	RunE:  withErrorHandling(createAttachListHandler()),
+}
+
+var attachGetCmd = &cobra.Command{
+	Use:   "get <uuid|path> <attachment>",
+	Short: "Save attachment of secret to file",
+	Long: `Save attachment of secret to file. The attachment is given by UUID or name.
+Without --out the file is written to the current directory under the attachment name,
+--out - writes the content to stdout.`,
+	Args: usageArgs(cobra.ExactArgs(2)),
+	RunE: withErrorHandling(createAttachGetHandler()),
+}
+
+var attachRemoveCmd = &cobra.Command{
+	Use:   "rm <uuid|path> <attachment>",
+	Short: "Remove attachment from secret",
+	Args:  usageArgs(cobra.ExactArgs(2)),
+	RunE:  withErrorHandling(createAttachRemoveHandler()),
+}
@@ -0,0 +1,22 @@
+package ctl
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up and restore all secrets",
+}
+
+var backupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create encrypted backup",
+	RunE:  withErrorHandling(createBackupCreateHandler()),
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore encrypted backup",
+	RunE:  withErrorHandling(createBackupRestoreHandler()),
+}
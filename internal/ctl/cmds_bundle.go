@@ -0,0 +1,22 @@
+package ctl
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Sync through encrypted bundle files",
+}
+
+var bundleExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export vault to a new bundle",
+	RunE:  withErrorHandling(createBundleExportHandler()),
+}
+
+var bundleImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Sync vault with a bundle",
+	RunE:  withErrorHandling(createBundleImportHandler()),
+}
@@ -0,0 +1,32 @@
+package ctl
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:         "config",
+	Short:       "Manage config file profiles",
+	Annotations: map[string]string{annotationNoVault: ""},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Get config value",
+	Args:  usageArgs(cobra.ExactArgs(1)),
+	RunE:  withErrorHandling(createConfigGetHandler()),
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set config value",
+	Long:  "Set a value in the selected profile, creating it when needed. An empty value unsets the key.",
+	Args:  usageArgs(cobra.ExactArgs(2)),
+	RunE:  withErrorHandling(createConfigSetHandler()),
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List config profiles",
+	RunE:  withErrorHandling(createConfigListHandler()),
+}
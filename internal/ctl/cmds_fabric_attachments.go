@@ -0,0 +1,110 @@
+package ctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/fsutil"
+	"github.com/spf13/cobra"
+)
+
+// attachStdout is the --out value of attach get writing to stdout.
+const attachStdout = "-"
+
+func createAttachAddHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		filePath := args[1]
+
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to get file info: %w", err)
+		}
+		if info.Size() > constants.MaxAttachmentSize {
+			return errs.Validationf("file too large: %d bytes (max: %d bytes)",
+				info.Size(), constants.MaxAttachmentSize)
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+
+		name := getStringFlag(cmd, "name")
+		if name == "" {
+			name = filepath.Base(filePath)
+		}
+
+		app := getAppFromCommand(cmd)
+		attachment, err := app.service.AttachFile(context.Background(), args[0], name, getStringFlag(cmd, "mime"), content)
+		if err != nil {
+			return err
+		}
+
+		printMessage("%s Attached %s (%d bytes) to secret %s", constants.EmojiSuccess, attachment.Name, attachment.Size, attachment.SecretUUID)
+		return writeOutput(cmd, newAttachmentOutput(attachment), nil)
+	}
+}
+
+func createAttachListHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		app := getAppFromCommand(cmd)
+		secret, attachments, err := app.service.ListAttachments(context.Background(), args[0])
+		if err != nil {
+			return err
+		}
+
+		return writeOutput(cmd, newAttachmentsOutput(attachments), func(w io.Writer) error {
+			return displayAttachments(w, secret, attachments)
+		})
+	}
+}
+
+func createAttachGetHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		app := getAppFromCommand(cmd)
+		attachment, err := app.service.GetAttachment(context.Background(), args[0], args[1])
+		if err != nil {
+			return err
+		}
+
+		outPath := getStringFlag(cmd, "out")
+		if outPath == attachStdout {
+			_, err := cmd.OutOrStdout().Write(attachment.Content)
+			return err
+		}
+		if outPath == "" {
+			outPath = attachment.Name
+		}
+
+		if _, err := os.Stat(outPath); err == nil {
+			return errs.NewConflictError(fmt.Errorf("file already exists: %s", outPath))
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to check file existence: %w", err)
+		}
+
+		if err := fsutil.WriteFileAtomic(outPath, attachment.Content, exportFileMode); err != nil {
+			return err
+		}
+
+		printMessage("%s Attachment %s saved to %s", constants.EmojiSuccess, attachment.Name, outPath)
+		return writeOutput(cmd, &attachmentWriteOutput{attachmentOutput: newAttachmentOutput(attachment), Path: outPath}, nil)
+	}
+}
+
+func createAttachRemoveHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		app := getAppFromCommand(cmd)
+		attachment, err := app.service.DeleteAttachment(context.Background(), args[0], args[1])
+		if err != nil {
+			return err
+		}
+
+		printMessage("%s Attachment %s removed", constants.EmojiSuccess, attachment.Name)
+		return writeOutput(cmd, &deleteOutput{UUID: attachment.UUID, Deleted: true}, nil)
+	}
+}
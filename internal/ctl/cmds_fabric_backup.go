@@ -0,0 +1,68 @@
+package ctl
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/etoneja/go-keeper/internal/ctl/backup"
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/spf13/cobra"
+)
+
+// readBackupPassphrase takes the passphrase from GOKEEPER_BACKUP_PASSPHRASE,
+// prompting for it when unset.
+func readBackupPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv("GOKEEPER_BACKUP_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+
+	return PromptForPassphrase("Backup passphrase", confirm)
+}
+
+func createBackupCreateHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		path := getStringFlag(cmd, "file")
+
+		passphrase, err := readBackupPassphrase(true)
+		if err != nil {
+			return err
+		}
+
+		app := getAppFromCommand(cmd)
+		count, err := app.service.CreateBackup(context.Background(), path, passphrase)
+		if err != nil {
+			return err
+		}
+
+		printMessage("%s Backed up %d secrets to %s", constants.EmojiSuccess, count, path)
+		return writeOutput(cmd, &fileWriteOutput{Path: path, Secrets: count}, nil)
+	}
+}
+
+func createBackupRestoreHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		path := getStringFlag(cmd, "file")
+		mode := getStringFlag(cmd, "mode")
+
+		err := backup.ValidateMode(mode)
+		if err != nil {
+			return err
+		}
+
+		passphrase, err := readBackupPassphrase(false)
+		if err != nil {
+			return err
+		}
+
+		app := getAppFromCommand(cmd)
+		summary, err := app.service.RestoreBackup(context.Background(), path, passphrase, mode)
+		if err != nil {
+			return err
+		}
+
+		return writeOutput(cmd, newRestoreOutput(path, summary), func(w io.Writer) error {
+			return displayRestoreSummary(w, path, summary)
+		})
+	}
+}
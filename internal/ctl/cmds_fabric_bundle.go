@@ -0,0 +1,51 @@
+package ctl
+
+import (
+	"context"
+	"io"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/spf13/cobra"
+)
+
+func createBundleExportHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		path := getStringFlag(cmd, "file")
+
+		app := getAppFromCommand(cmd)
+		count, err := app.service.ExportBundle(context.Background(), path)
+		if err != nil {
+			return err
+		}
+
+		printMessage("%s Exported %d secrets to bundle %s", constants.EmojiSuccess, count, path)
+		return writeOutput(cmd, &fileWriteOutput{Path: path, Secrets: count}, nil)
+	}
+}
+
+func createBundleImportHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		path := getStringFlag(cmd, "file")
+
+		app := getAppFromCommand(cmd)
+		report, updated, err := app.service.ImportBundle(context.Background(), path)
+		if report == nil {
+			return err
+		}
+
+		if err == nil && updated {
+			printMessage("%s Vault synced with bundle %s, the bundle was updated", constants.EmojiSuccess, path)
+		} else if err == nil {
+			printMessage("%s Vault synced with bundle %s", constants.EmojiSuccess, path)
+		}
+
+		output := &bundleImportOutput{Path: path, BundleUpdated: updated, Sync: newSyncOutput(report)}
+		outputErr := writeOutput(cmd, output, func(w io.Writer) error {
+			return displaySyncReport(w, report)
+		})
+		if err != nil {
+			return err
+		}
+		return outputErr
+	}
+}
@@ -0,0 +1,124 @@
+package ctl
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/etoneja/go-keeper/internal/ctl/config"
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/spf13/cobra"
+)
+
+func createConfigGetHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		key := args[0]
+
+		_, file, err := readConfigFile()
+		if err != nil {
+			return err
+		}
+
+		output := &configValueOutput{Key: key}
+		if key == config.CurrentProfileKey {
+			output.Value = file.CurrentProfile
+		} else {
+			output.Profile, err = selectedProfileName(cmd, file)
+			if err != nil {
+				return err
+			}
+
+			profile, err := file.Profile(output.Profile)
+			if err != nil {
+				return err
+			}
+
+			output.Value, err = profile.Get(key)
+			if err != nil {
+				return err
+			}
+		}
+
+		return writeOutput(cmd, output, func(w io.Writer) error {
+			_, err := fmt.Fprintln(w, output.Value)
+			return err
+		})
+	}
+}
+
+func createConfigSetHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		key, value := args[0], args[1]
+
+		path, file, err := readConfigFile()
+		if err != nil {
+			return err
+		}
+
+		output := &configValueOutput{Key: key, Value: value}
+		if key == config.CurrentProfileKey {
+			if _, err := file.Profile(value); err != nil {
+				return err
+			}
+			file.CurrentProfile = value
+		} else {
+			output.Profile, err = selectedProfileName(cmd, file)
+			if err != nil {
+				return err
+			}
+
+			if err := file.SetProfile(output.Profile).Set(key, value); err != nil {
+				return err
+			}
+		}
+
+		if err := file.Save(path); err != nil {
+			return err
+		}
+
+		if output.Profile == "" {
+			printMessage("%s Set %s in %s", constants.EmojiSuccess, key, path)
+		} else {
+			printMessage("%s Set %s of profile %s in %s", constants.EmojiSuccess, key, output.Profile, path)
+		}
+		return writeOutput(cmd, output, nil)
+	}
+}
+
+func createConfigListHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		path, file, err := readConfigFile()
+		if err != nil {
+			return err
+		}
+
+		profiles := newConfigProfilesOutput(file)
+		return writeOutput(cmd, profiles, func(w io.Writer) error {
+			return displayConfigProfiles(w, path, profiles)
+		})
+	}
+}
+
+func readConfigFile() (string, *config.File, error) {
+	path, err := config.Path()
+	if err != nil {
+		return "", nil, err
+	}
+
+	file, err := config.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return path, file, nil
+}
+
+// selectedProfileName returns the profile named by --profile,
+// GOKEEPER_PROFILE or current_profile.
+func selectedProfileName(cmd *cobra.Command, file *config.File) (string, error) {
+	name := file.ProfileName(getStringFlag(cmd, "profile"))
+	if name == "" {
+		return "", errs.Validationf("no profile selected, pass --profile or set %s", config.CurrentProfileKey)
+	}
+	return name, nil
+}
@@ -0,0 +1,67 @@
+package ctl
+
+import (
+	"context"
+	"io"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/spf13/cobra"
+)
+
+func createHistoryListHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		app := getAppFromCommand(cmd)
+		history, err := app.service.SecretHistory(context.Background(), args[0])
+		if err != nil {
+			return err
+		}
+
+		return writeOutput(cmd, newHistoryOutput(history), func(w io.Writer) error {
+			return displayHistory(w, history)
+		})
+	}
+}
+
+func createHistoryDiffHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		full, _ := cmd.Flags().GetBool("full")
+
+		app := getAppFromCommand(cmd)
+		diff, err := app.service.DiffSecretVersions(context.Background(), args[0], args[1], args[2], full)
+		if err != nil {
+			return err
+		}
+
+		return writeOutput(cmd, newVersionDiffOutput(diff), func(w io.Writer) error {
+			return displayVersionDiff(w, diff)
+		})
+	}
+}
+
+func createRestoreHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		version, _ := cmd.Flags().GetInt("version")
+
+		app := getAppFromCommand(cmd)
+		secret, err := app.service.RestoreSecretVersion(context.Background(), args[0], version)
+		if err != nil {
+			return err
+		}
+
+		printMessage("%s Secret %s restored from version %d", constants.EmojiSuccess, secret.UUID, version)
+		return writeOutput(cmd, newSecretSummaryOutput(secret), nil)
+	}
+}
+
+func createPurgeHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		app := getAppFromCommand(cmd)
+		secretID, err := app.service.PurgeSecret(context.Background(), args[0])
+		if err != nil {
+			return err
+		}
+
+		printMessage("%s Secret %s erased with its attachments and history", constants.EmojiSuccess, secretID)
+		return writeOutput(cmd, &purgeOutput{UUID: secretID, Purged: true}, nil)
+	}
+}
@@ -0,0 +1,89 @@
+package ctl
+
+import (
+	"context"
+	"io"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/interop"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/spf13/cobra"
+)
+
+func createImportHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		mapping, err := interop.ParseCSVMapping(getStringFlag(cmd, "map"))
+		if err != nil {
+			return err
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		allowDuplicates, _ := cmd.Flags().GetBool("allow-duplicates")
+		opts := interop.ImportOptions{
+			ParseOptions:    interop.ParseOptions{CSVMapping: mapping},
+			DryRun:          dryRun,
+			AllowDuplicates: allowDuplicates,
+		}
+
+		app := getAppFromCommand(cmd)
+		report, err := app.service.ImportSecrets(context.Background(),
+			getStringFlag(cmd, "file"), getStringFlag(cmd, "format"), opts)
+		if err != nil {
+			return err
+		}
+
+		return writeOutput(cmd, newImportOutput(report), func(w io.Writer) error {
+			return displayImportReport(w, report)
+		})
+	}
+}
+
+func createExportHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		unencrypted, _ := cmd.Flags().GetBool("unencrypted")
+		if !unencrypted {
+			return errs.Validationf("the export holds every selected secret in plain text, pass --unencrypted to confirm")
+		}
+
+		folder, err := types.NormalizeFolder(getStringFlag(cmd, "folder"))
+		if err != nil {
+			return err
+		}
+
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		tags, err = types.NormalizeTags(tags)
+		if err != nil {
+			return err
+		}
+
+		secretTypes, _ := cmd.Flags().GetStringSlice("type")
+		filter := interop.Filter{
+			Types:       secretTypes,
+			NamePattern: getStringFlag(cmd, "name"),
+			Folder:      folder,
+			Tags:        tags,
+		}
+
+		path := getStringFlag(cmd, "file")
+
+		app := getAppFromCommand(cmd)
+		summary, err := app.service.ExportSecrets(context.Background(), path, getStringFlag(cmd, "format"), filter)
+		if err != nil {
+			return err
+		}
+
+		printMessage("%s Exported %d secrets to %s", constants.EmojiSuccess, summary.Secrets, path)
+		if summary.FilesDir != "" {
+			printMessage("%d binary secrets were written to %s", summary.SideFiles, summary.FilesDir)
+		}
+
+		output := &fileWriteOutput{
+			Path:      path,
+			Secrets:   summary.Secrets,
+			FilesDir:  summary.FilesDir,
+			SideFiles: summary.SideFiles,
+		}
+		return writeOutput(cmd, output, nil)
+	}
+}
@@ -2,12 +2,14 @@ package ctl
 
 import (
 	"context"
+	"io"
+
 	"github.com/spf13/cobra"
 )
 
-func createVersionHandler() func(cmd *cobra.Command, args []string) {
-	return func(cmd *cobra.Command, args []string) {
-		displayVersion()
+func createVersionHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		return writeOutput(cmd, newVersionOutput(), displayVersion)
 	}
 }
 
@@ -18,7 +20,7 @@ func createInitializeHandler() func(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return err
 		}
-		return err
+		return writeOutput(cmd, &initOutput{DBPath: app.cfg.DBPath}, nil)
 	}
 }
 
@@ -29,17 +31,25 @@ func createRegisterHandler() func(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return err
 		}
-		return nil
+		return writeOutput(cmd, &registerOutput{Login: app.cfg.Login}, nil)
 	}
 }
 
 func createSyncHandler() func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
 		app := getAppFromCommand(cmd)
-		err := app.service.SyncSecrets(context.Background())
+		report, err := app.service.SyncSecrets(context.Background())
+		if report == nil {
+			return err
+		}
+
+		// NOTE: The report is printed even after a failure, to tell what was applied
+		outputErr := writeOutput(cmd, newSyncOutput(report), func(w io.Writer) error {
+			return displaySyncReport(w, report)
+		})
 		if err != nil {
 			return err
 		}
-		return nil
+		return outputErr
 	}
 }
@@ -2,12 +2,15 @@ package ctl
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/etoneja/go-keeper/internal/ctl/clipboard"
 	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
 	"github.com/etoneja/go-keeper/internal/ctl/types"
 	"github.com/spf13/cobra"
 )
@@ -16,16 +19,25 @@ func createSecretAddCommand(secretType string) *cobra.Command {
 	return &cobra.Command{
 		Use:   secretType,
 		Short: fmt.Sprintf("Add %s secret", secretType),
-		Run:   withErrorHandling(createSecretHandler(secretType)),
+		RunE:  withErrorHandling(createSecretHandler(secretType)),
 	}
 }
 
 func createSecretHandler(secretType string) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+
+		fields, err := customFieldsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
 		base := types.BaseSecret{
 			Type:     secretType,
 			Name:     getStringFlag(cmd, "name"),
 			Metadata: getStringFlag(cmd, "metadata"),
+			Tags:     tags,
+			Fields:   fields,
 		}
 
 		var data types.SecretData
@@ -41,20 +53,7 @@ func createSecretHandler(secretType string) func(cmd *cobra.Command, args []stri
 				Content: getStringFlag(cmd, "content"),
 			}
 		case constants.SecretTypeBinary:
-			filePath := getStringFlag(cmd, "file")
-			checker := NewFileChecker()
-			if err := checker.CheckFileSize(filePath); err != nil {
-				return err
-			}
-			content, err := os.ReadFile(filePath)
-			if err != nil {
-				return fmt.Errorf("failed to read file: %w", err)
-			}
-			data = types.FileData{
-				FileName: filepath.Base(filePath),
-				FileSize: int64(len(content)),
-				Content:  base64.StdEncoding.EncodeToString(content),
-			}
+			return addFileSecret(cmd, base)
 		case constants.SecretTypeCard:
 			data = types.CardData{
 				Number: getStringFlag(cmd, "number"),
@@ -78,75 +77,234 @@ func createSecretHandler(secretType string) func(cmd *cobra.Command, args []stri
 			return err
 		}
 
-		err = displaySecret(createdSecret, false)
+		output, err := newSecretOutput(createdSecret, false)
 		if err != nil {
 			return err
 		}
 
-		return nil
+		return writeOutput(cmd, output, func(w io.Writer) error {
+			return displaySecret(w, createdSecret, false)
+		})
+	}
+}
+
+// addFileSecret adds the binary secret of --file, see VaultService.CreateFileSecret.
+func addFileSecret(cmd *cobra.Command, base types.BaseSecret) error {
+	filePath := getStringFlag(cmd, "file")
+	checker := NewFileChecker()
+	if err := checker.CheckFileSize(filePath); err != nil {
+		return err
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	app := getAppFromCommand(cmd)
+
+	createdSecret, err := app.service.CreateFileSecret(context.Background(), base, filepath.Base(filePath), content)
+	if err != nil {
+		return err
 	}
+
+	output, err := newSecretOutput(createdSecret, false)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(cmd, output, func(w io.Writer) error {
+		return displaySecret(w, createdSecret, false)
+	})
+}
+
+// customFieldsFromFlags reads --field, --hidden-field and --url-field,
+// keeping the order within each flag.
+func customFieldsFromFlags(cmd *cobra.Command) ([]types.CustomField, error) {
+	flags := []struct {
+		name      string
+		fieldType string
+	}{
+		{name: "field", fieldType: constants.FieldTypeText},
+		{name: "hidden-field", fieldType: constants.FieldTypeHidden},
+		{name: "url-field", fieldType: constants.FieldTypeURL},
+	}
+
+	var fields []types.CustomField
+	for _, flag := range flags {
+		specs, _ := cmd.Flags().GetStringArray(flag.name)
+		for _, spec := range specs {
+			field, err := types.ParseCustomField(spec, flag.fieldType)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, field)
+		}
+	}
+
+	return fields, nil
 }
 
 func createSecretGetCommand() func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
-		uuid := args[0]
+		ref := args[0]
 		full, _ := cmd.Flags().GetBool("full")
 		exportPath, _ := cmd.Flags().GetString("export")
+		encrypt, _ := cmd.Flags().GetBool("encrypt")
+		field := getStringFlag(cmd, "field")
+		toClipboard, _ := cmd.Flags().GetBool("clipboard")
+		clearAfter, _ := cmd.Flags().GetDuration("clear-after")
 
-		app := getAppFromCommand(cmd)
+		if toClipboard && field == "" {
+			return errs.Validationf("--clipboard needs --field to choose the value to copy")
+		}
 
-		// TODO: if not uuid like, try find by name
+		app := getAppFromCommand(cmd)
 
-		secret, err := app.service.GetLocalSecret(context.Background(), uuid)
+		secret, err := app.service.GetLocalSecret(context.Background(), ref)
 		if err != nil {
 			return err
 		}
 
+		if field != "" {
+			value, err := secretField(secret, field)
+			if err != nil {
+				return err
+			}
+
+			if toClipboard {
+				// NOTE: Release the vault before waiting to clear the clipboard,
+				// so other keeperctl runs do not wait on its lock meanwhile
+				if err := app.Close(); err != nil {
+					return err
+				}
+				return copyToClipboard(clipboard.Detect(), value, clearAfter)
+			}
+
+			_, err = fmt.Fprint(cmd.OutOrStdout(), value)
+			return err
+		}
+
 		if exportPath != "" {
-			err := exportSecret(secret, exportPath)
+			var passphrase string
+			if encrypt {
+				passphrase, err = readBackupPassphrase(true)
+				if err != nil {
+					return err
+				}
+			}
+
+			err := app.service.ExportSecret(context.Background(), secret, exportPath, passphrase)
 			if err != nil {
 				return err
 			}
-			return nil
+
+			printMessage("%s Exported secret %s to %s", constants.EmojiSuccess, secret.UUID, exportPath)
+			return writeOutput(cmd, &fileWriteOutput{Path: exportPath, Secrets: 1}, nil)
 		}
 
-		err = displaySecret(secret, full)
+		output, err := newSecretOutput(secret, full)
 		if err != nil {
 			return err
 		}
 
-		return nil
+		return writeOutput(cmd, output, func(w io.Writer) error {
+			return displaySecret(w, secret, full)
+		})
 	}
 }
 
 func createSecretDeleteCommand() func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
-		uuid := args[0]
-
 		app := getAppFromCommand(cmd)
 
-		// TODO: if not uuid like, try find by name
-
-		err := app.service.DeleteLocalSecret(context.Background(), uuid)
+		secret, err := app.service.DeleteLocalSecret(context.Background(), args[0])
 		if err != nil {
 			return err
 		}
 
-		return nil
+		printMessage("%s Secret %s moved to the trash, restore it with 'keeperctl trash restore %s'",
+			constants.EmojiSuccess, secret.UUID, secret.UUID)
+		return writeOutput(cmd, &deleteOutput{UUID: secret.UUID, Deleted: true}, nil)
 	}
 }
 
 func createSecretsListCommand() func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		matchAny, _ := cmd.Flags().GetBool("any-tag")
+		tree, _ := cmd.Flags().GetBool("tree")
+
+		tags, err := types.NormalizeTags(tags)
+		if err != nil {
+			return err
+		}
+
+		folder, err := types.NormalizeFolder(getStringFlag(cmd, "folder"))
+		if err != nil {
+			return err
+		}
+
 		app := getAppFromCommand(cmd)
 
 		secrets, err := app.service.ListLocalSecrets(context.Background())
 		if err != nil {
 			return err
 		}
+		secrets = filterSecretsByTags(secrets, tags, matchAny)
+		secrets = filterSecretsByFolder(secrets, folder)
+
+		return writeOutput(cmd, newSecretsOutput(secrets), func(w io.Writer) error {
+			if tree {
+				return displaySecretTree(w, folder, secrets)
+			}
+			return displaySecrets(w, secrets)
+		})
+	}
+}
+
+func createSecretsSearchCommand() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		app := getAppFromCommand(cmd)
+
+		secrets, err := app.service.SearchLocalSecrets(context.Background(), strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+
+		return writeOutput(cmd, newSecretsOutput(secrets), func(w io.Writer) error {
+			return displaySecrets(w, secrets)
+		})
+	}
+}
 
-		displaySecrets(secrets)
+func createFolderListCommand() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		var folder string
+		if len(args) > 0 {
+			folder = args[0]
+		}
+
+		app := getAppFromCommand(cmd)
+		listing, err := app.service.ListFolder(context.Background(), folder)
+		if err != nil {
+			return err
+		}
+
+		return writeOutput(cmd, newFolderOutput(listing), func(w io.Writer) error {
+			return displayFolder(w, listing)
+		})
+	}
+}
+
+func createSecretMoveCommand() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		app := getAppFromCommand(cmd)
+		secret, err := app.service.MoveSecret(context.Background(), args[0], args[1])
+		if err != nil {
+			return err
+		}
 
-		return nil
+		printMessage("%s Secret %s is now %s", constants.EmojiSuccess, secret.UUID, secret.Name)
+		return writeOutput(cmd, newSecretSummaryOutput(secret), nil)
 	}
 }
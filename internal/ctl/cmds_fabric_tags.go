@@ -0,0 +1,50 @@
+package ctl
+
+import (
+	"context"
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+func createTagAddHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		app := getAppFromCommand(cmd)
+		secret, err := app.service.TagSecret(context.Background(), args[0], args[1:])
+		if err != nil {
+			return err
+		}
+
+		return writeOutput(cmd, newSecretSummaryOutput(secret), func(w io.Writer) error {
+			return displaySecretTags(w, secret)
+		})
+	}
+}
+
+func createTagRemoveHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		app := getAppFromCommand(cmd)
+		secret, err := app.service.UntagSecret(context.Background(), args[0], args[1:])
+		if err != nil {
+			return err
+		}
+
+		return writeOutput(cmd, newSecretSummaryOutput(secret), func(w io.Writer) error {
+			return displaySecretTags(w, secret)
+		})
+	}
+}
+
+func createTagsListHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		app := getAppFromCommand(cmd)
+		tags, err := app.service.ListTags(context.Background())
+		if err != nil {
+			return err
+		}
+
+		return writeOutput(cmd, newTagsOutput(tags), func(w io.Writer) error {
+			return displayTags(w, tags)
+		})
+	}
+}
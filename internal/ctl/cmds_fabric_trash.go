@@ -0,0 +1,49 @@
+package ctl
+
+import (
+	"context"
+	"io"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/spf13/cobra"
+)
+
+func createTrashListHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		app := getAppFromCommand(cmd)
+		secrets, err := app.service.ListTrash(context.Background())
+		if err != nil {
+			return err
+		}
+
+		return writeOutput(cmd, newSecretsOutput(secrets), func(w io.Writer) error {
+			return displayTrash(w, secrets)
+		})
+	}
+}
+
+func createTrashRestoreHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		app := getAppFromCommand(cmd)
+		secret, err := app.service.RestoreFromTrash(context.Background(), args[0])
+		if err != nil {
+			return err
+		}
+
+		printMessage("%s Secret %s restored from the trash as %s", constants.EmojiSuccess, secret.UUID, secret.Path())
+		return writeOutput(cmd, newSecretSummaryOutput(secret), nil)
+	}
+}
+
+func createTrashEmptyHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		app := getAppFromCommand(cmd)
+		secrets, err := app.service.EmptyTrash(context.Background())
+		if err != nil {
+			return err
+		}
+
+		printMessage("%s %d secrets erased with their attachments and history", constants.EmojiSuccess, len(secrets))
+		return writeOutput(cmd, newPurgeOutputs(secrets), nil)
+	}
+}
@@ -0,0 +1,69 @@
+package ctl
+
+import (
+	"context"
+	"io"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/spf13/cobra"
+)
+
+func createVaultGenerationsHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		app := getAppFromCommand(cmd)
+		generations, err := app.service.ListVaultGenerations()
+		if err != nil {
+			return err
+		}
+
+		return writeOutput(cmd, newGenerationsOutput(generations), func(w io.Writer) error {
+			return displayGenerations(w, generations)
+		})
+	}
+}
+
+func createVaultRestoreHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		generation, _ := cmd.Flags().GetInt("generation")
+
+		app := getAppFromCommand(cmd)
+		err := app.service.RestoreVaultGeneration(context.Background(), generation)
+		if err != nil {
+			return err
+		}
+
+		printMessage("%s Vault restored from generation %d, the replaced vault is now generation 1",
+			constants.EmojiSuccess, generation)
+		return writeOutput(cmd, &vaultRestoreOutput{Generation: generation}, nil)
+	}
+}
+
+func createVaultConvertHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		backend := getStringFlag(cmd, "to")
+
+		app := getAppFromCommand(cmd)
+		backupPath, err := app.service.ConvertVault(context.Background(), backend)
+		if err != nil {
+			return err
+		}
+
+		printMessage("%s Vault converted to the %s backend, the previous vault is kept at %s",
+			constants.EmojiSuccess, backend, backupPath)
+		return writeOutput(cmd, &vaultConvertOutput{Backend: backend, BackupPath: backupPath}, nil)
+	}
+}
+
+func createVaultStatsHandler() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		app := getAppFromCommand(cmd)
+		stats, err := app.service.VaultStats(context.Background())
+		if err != nil {
+			return err
+		}
+
+		return writeOutput(cmd, newVaultStatsOutput(stats), func(w io.Writer) error {
+			return displayVaultStats(w, stats)
+		})
+	}
+}
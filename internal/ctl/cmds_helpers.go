@@ -1,9 +1,9 @@
 package ctl
 
 import (
-	"fmt"
 	"log"
 
+	"github.com/etoneja/go-keeper/internal/ctl/backup"
 	"github.com/etoneja/go-keeper/internal/ctl/constants"
 	"github.com/etoneja/go-keeper/internal/ctl/errs"
 	"github.com/spf13/cobra"
@@ -15,16 +15,28 @@ func init() {
 	addPasswordCmd.Flags().String("password", "", "Password (required)")
 	addPasswordCmd.Flags().String("url", "", "URL (optional)")
 	addPasswordCmd.Flags().String("metadata", "", "Metadata (optional)")
+	addPasswordCmd.Flags().StringSlice("tag", nil, "Tag, repeat for several (optional)")
+	addPasswordCmd.Flags().StringArray("field", nil, "Custom text field as label=value, repeat for several (optional)")
+	addPasswordCmd.Flags().StringArray("hidden-field", nil, "Custom hidden field as label=value, masked on display (optional)")
+	addPasswordCmd.Flags().StringArray("url-field", nil, "Custom URL field as label=value (optional)")
 	markFlagsRequired(addPasswordCmd, "name", "username", "password")
 
 	addTextCmd.Flags().String("name", "", "Secret name (required)")
 	addTextCmd.Flags().String("content", "", "Text content (required)")
 	addTextCmd.Flags().String("metadata", "", "Metadata (optional)")
+	addTextCmd.Flags().StringSlice("tag", nil, "Tag, repeat for several (optional)")
+	addTextCmd.Flags().StringArray("field", nil, "Custom text field as label=value, repeat for several (optional)")
+	addTextCmd.Flags().StringArray("hidden-field", nil, "Custom hidden field as label=value, masked on display (optional)")
+	addTextCmd.Flags().StringArray("url-field", nil, "Custom URL field as label=value (optional)")
 	markFlagsRequired(addTextCmd, "name", "content")
 
 	addBinaryCmd.Flags().String("name", "", "Secret name (required)")
 	addBinaryCmd.Flags().String("file", "", "File path (required)")
 	addBinaryCmd.Flags().String("metadata", "", "Metadata (optional)")
+	addBinaryCmd.Flags().StringSlice("tag", nil, "Tag, repeat for several (optional)")
+	addBinaryCmd.Flags().StringArray("field", nil, "Custom text field as label=value, repeat for several (optional)")
+	addBinaryCmd.Flags().StringArray("hidden-field", nil, "Custom hidden field as label=value, masked on display (optional)")
+	addBinaryCmd.Flags().StringArray("url-field", nil, "Custom URL field as label=value (optional)")
 	markFlagsRequired(addBinaryCmd, "name", "file")
 
 	addCardCmd.Flags().String("name", "", "Secret name (required)")
@@ -33,10 +45,95 @@ func init() {
 	addCardCmd.Flags().String("expiry", "", "Expiry date (required)")
 	addCardCmd.Flags().String("cvv", "", "CVV code (required)")
 	addCardCmd.Flags().String("metadata", "", "Metadata (optional)")
+	addCardCmd.Flags().StringSlice("tag", nil, "Tag, repeat for several (optional)")
+	addCardCmd.Flags().StringArray("field", nil, "Custom text field as label=value, repeat for several (optional)")
+	addCardCmd.Flags().StringArray("hidden-field", nil, "Custom hidden field as label=value, masked on display (optional)")
+	addCardCmd.Flags().StringArray("url-field", nil, "Custom URL field as label=value (optional)")
 	markFlagsRequired(addCardCmd, "name", "number", "holder", "expiry", "cvv")
 
 	getCmd.Flags().Bool("full", false, "Show all data including passwords/CVV")
 	getCmd.Flags().String("export", "", "Export to file path")
+	getCmd.Flags().Bool("encrypt", false, "Encrypt the export with a passphrase")
+	getCmd.Flags().String("field", "", "Print only the raw value of this field, e.g. password")
+	getCmd.Flags().Bool("clipboard", false, "Copy the --field value to the clipboard instead of printing it")
+	getCmd.Flags().Duration("clear-after", constants.ClipboardClearAfter, "Clear the clipboard after this long, 0 keeps the value")
+	getCmd.MarkFlagsMutuallyExclusive("field", "export")
+
+	listCmd.Flags().StringSlice("tag", nil, "List only secrets with this tag, repeat for several")
+	listCmd.Flags().Bool("any-tag", false, "Match secrets with any of the --tag tags instead of all of them")
+	listCmd.Flags().String("folder", "", "List only secrets in this folder and below it")
+	listCmd.Flags().Bool("tree", false, "Show secrets as a folder tree")
+
+	backupCreateCmd.Flags().String("file", "", "Backup file path (required)")
+	markFlagsRequired(backupCreateCmd, "file")
+
+	backupRestoreCmd.Flags().String("file", "", "Backup file path (required)")
+	backupRestoreCmd.Flags().String("mode", backup.ModeMerge, "Restore mode: merge, replace or verify")
+	markFlagsRequired(backupRestoreCmd, "file")
+
+	importCmd.Flags().String("format", "", "Source format: keepass-xml, bitwarden-json or csv (required)")
+	importCmd.Flags().String("file", "", "File to import (required)")
+	importCmd.Flags().String("map", "", "CSV column mapping, e.g. name=Title,username=Login")
+	importCmd.Flags().Bool("dry-run", false, "Show what would be imported without changing the vault")
+	importCmd.Flags().Bool("allow-duplicates", false, "Import entries whose name is already taken")
+	markFlagsRequired(importCmd, "format", "file")
+
+	exportCmd.Flags().String("format", "", "Target format: keepass-xml, bitwarden-json or csv (required)")
+	exportCmd.Flags().String("file", "", "File to write (required)")
+	exportCmd.Flags().Bool("unencrypted", false, "Acknowledge that the export is not encrypted")
+	exportCmd.Flags().StringSlice("type", nil, "Export only these secret types")
+	exportCmd.Flags().String("name", "", "Export only secrets whose name matches this glob")
+	exportCmd.Flags().String("folder", "", "Export only secrets in this folder and below it")
+	exportCmd.Flags().StringSlice("tag", nil, "Export only secrets with this tag, repeat for several")
+	markFlagsRequired(exportCmd, "format", "file")
+
+	backupCmd.AddCommand(backupCreateCmd)
+	backupCmd.AddCommand(backupRestoreCmd)
+
+	vaultRestoreCmd.Flags().Int("generation", 1, "Generation to restore, 1 is the newest")
+
+	vaultConvertCmd.Flags().String("to", "", "Target backend: blob, paged or files (required)")
+	markFlagsRequired(vaultConvertCmd, "to")
+
+	bundleExportCmd.Flags().String("file", "", "Bundle file path (required)")
+	markFlagsRequired(bundleExportCmd, "file")
+
+	bundleImportCmd.Flags().String("file", "", "Bundle file path (required)")
+	markFlagsRequired(bundleImportCmd, "file")
+
+	bundleCmd.AddCommand(bundleExportCmd)
+	bundleCmd.AddCommand(bundleImportCmd)
+
+	vaultCmd.AddCommand(vaultGenerationsCmd)
+	vaultCmd.AddCommand(vaultRestoreCmd)
+	vaultCmd.AddCommand(vaultConvertCmd)
+	vaultCmd.AddCommand(vaultStatsCmd)
+
+	attachAddCmd.Flags().String("name", "", "Attachment name, defaults to the file name")
+	attachAddCmd.Flags().String("mime", "", "MIME type, detected from the name and content by default")
+	attachGetCmd.Flags().String("out", "", "File to write, - for stdout")
+
+	attachCmd.AddCommand(attachAddCmd)
+	attachCmd.AddCommand(attachListCmd)
+	attachCmd.AddCommand(attachGetCmd)
+	attachCmd.AddCommand(attachRemoveCmd)
+
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+
+	historyDiffCmd.Flags().Bool("full", false, "Show changed passwords, CVVs, file content and hidden fields")
+	historyCmd.AddCommand(historyDiffCmd)
+
+	restoreCmd.Flags().Int("version", 0, "Version to restore, see keeperctl history (required)")
+	markFlagsRequired(restoreCmd, "version")
+
+	tagCmd.AddCommand(tagAddCmd)
+	tagCmd.AddCommand(tagRemoveCmd)
+
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configListCmd)
 
 	addCmd.AddCommand(addPasswordCmd)
 	addCmd.AddCommand(addTextCmd)
@@ -52,6 +149,17 @@ func markFlagsRequired(cmd *cobra.Command, flags ...string) {
 	}
 }
 
+// usageArgs makes the errors of an argument validator usage errors, which
+// App.Run prints with a pointer to --help.
+func usageArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := validate(cmd, args); err != nil {
+			return errs.NewUsageError(err)
+		}
+		return nil
+	}
+}
+
 func addCommands(rootCmd *cobra.Command) {
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(versionCmd)
@@ -60,7 +168,23 @@ func addCommands(rootCmd *cobra.Command) {
 	rootCmd.AddCommand(getCmd)
 	rootCmd.AddCommand(deleteCmd)
 	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(searchCmd)
+	rootCmd.AddCommand(lsCmd)
+	rootCmd.AddCommand(mvCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(restoreCmd)
+	rootCmd.AddCommand(purgeCmd)
+	rootCmd.AddCommand(trashCmd)
+	rootCmd.AddCommand(tagCmd)
+	rootCmd.AddCommand(tagsCmd)
+	rootCmd.AddCommand(attachCmd)
 	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(vaultCmd)
+	rootCmd.AddCommand(bundleCmd)
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(configCmd)
 }
 
 func getAppFromCommand(cmd *cobra.Command) *App {
@@ -77,8 +201,23 @@ func getStringFlag(cmd *cobra.Command, name string) string {
 	return value
 }
 
-func withErrorHandling(fn func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) {
-	return func(cmd *cobra.Command, args []string) {
+// reportedError is a command failure whose message was already printed.
+type reportedError struct {
+	err error
+}
+
+func (e *reportedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *reportedError) Unwrap() error {
+	return e.err
+}
+
+// withErrorHandling prints the failure of a command and returns it, so that
+// it sets the exit code, see errs.ExitCode.
+func withErrorHandling(fn func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
 		err := fn(cmd, args)
 		short := cmd.Short
 		if err != nil {
@@ -86,8 +225,9 @@ func withErrorHandling(fn func(cmd *cobra.Command, args []string) error) func(cm
 			if errs.IsNotFound(err) {
 				emoji = constants.EmojiWarning
 			}
-			fmt.Printf("%s Failed to %s: %v\n", emoji, short, err)
-			return
+			printMessage("%s Failed to %s: %v", emoji, short, err)
+			return &reportedError{err: err}
 		}
+		return nil
 	}
 }
@@ -0,0 +1,47 @@
+package ctl
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history <uuid|path>",
+	Short: "List earlier versions of secret",
+	Long: `List the earlier versions of a secret, newest first. A version is kept every
+time the secret is modified, as limited by history_versions and
+history_max_age. Secrets in the trash are found too, secrets no longer in the
+vault by UUID.`,
+	Args: usageArgs(cobra.ExactArgs(1)),
+	RunE: withErrorHandling(createHistoryListHandler()),
+}
+
+var historyDiffCmd = &cobra.Command{
+	Use:   "diff <uuid|path> <version> <version>",
+	Short: "Compare two versions of secret field by field",
+	Long:  `Compare two versions of a secret field by field. A version is its number or "current".`,
+	Args:  usageArgs(cobra.ExactArgs(3)),
+	RunE:  withErrorHandling(createHistoryDiffHandler()),
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <uuid|path>",
+	Short: "Restore earlier version of secret",
+	Long: `Make an earlier version of a secret current again. The replaced state is kept
+as a new version, so a restore can be undone. A secret in the trash is taken
+out of it, one no longer in the vault is restored by UUID, without its
+attachments.`,
+	Args: usageArgs(cobra.ExactArgs(1)),
+	RunE: withErrorHandling(createRestoreHandler()),
+}
+
+var purgeCmd = &cobra.Command{
+	Use:   "purge <uuid|path>",
+	Short: "Erase secret with its attachments and history",
+	Long: `Erase a secret with its attachments and every earlier version, for secrets that
+must not be recoverable from the vault. Secrets in the trash are found too,
+secrets no longer in the vault by UUID. Sync erases the copies in the trash on
+other devices, vault generations written before keep their copy until they are
+rotated out.`,
+	Args: usageArgs(cobra.ExactArgs(1)),
+	RunE: withErrorHandling(createPurgeHandler()),
+}
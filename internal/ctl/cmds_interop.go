@@ -0,0 +1,17 @@
+package ctl
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import secrets from other password managers",
+	RunE:  withErrorHandling(createImportHandler()),
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export secrets for other password managers",
+	RunE:  withErrorHandling(createExportHandler()),
+}
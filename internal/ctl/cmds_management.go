@@ -5,25 +5,26 @@ import (
 )
 
 var versionCmd = &cobra.Command{
-	Use:   "version",
-	Short: "Show version information",
-	Run:   createVersionHandler(),
+	Use:         "version",
+	Short:       "Show version information",
+	Annotations: map[string]string{annotationNoVault: ""},
+	RunE:        withErrorHandling(createVersionHandler()),
 }
 
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize local storage",
-	Run:   withErrorHandling(createInitializeHandler()),
+	RunE:  withErrorHandling(createInitializeHandler()),
 }
 
 var registerCmd = &cobra.Command{
 	Use:   "register",
 	Short: "Register new user",
-	Run:   withErrorHandling(createRegisterHandler()),
+	RunE:  withErrorHandling(createRegisterHandler()),
 }
 
 var syncCmd = &cobra.Command{
 	Use:   "sync",
 	Short: "Sync with remote storage",
-	Run:   withErrorHandling(createSyncHandler()),
+	RunE:  withErrorHandling(createSyncHandler()),
 }
@@ -16,21 +16,48 @@ var addBinaryCmd = createSecretAddCommand(constants.SecretTypeBinary)
 var addCardCmd = createSecretAddCommand(constants.SecretTypeCard)
 
 var getCmd = &cobra.Command{
-	Use:   "get [uuid]",
-	Short: "Get secret by UUID",
-	Args:  cobra.ExactArgs(1),
-	Run:   withErrorHandling(createSecretGetCommand()),
+	Use:   "get <uuid|path>",
+	Short: "Get secret by UUID or path",
+	Args:  usageArgs(cobra.ExactArgs(1)),
+	RunE:  withErrorHandling(createSecretGetCommand()),
 }
 
 var deleteCmd = &cobra.Command{
-	Use:   "delete [uuid]",
-	Short: "Delete secret by UUID",
-	Args:  cobra.ExactArgs(1),
-	Run:   withErrorHandling(createSecretDeleteCommand()),
+	Use:   "delete <uuid|path>",
+	Short: "Delete secret by UUID or path",
+	Long: `Move a secret to the trash with its attachments. It is restored with
+keeperctl trash restore until it is purged, see keeperctl trash.`,
+	Args: usageArgs(cobra.ExactArgs(1)),
+	RunE: withErrorHandling(createSecretDeleteCommand()),
 }
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all secrets",
-	Run:   withErrorHandling(createSecretsListCommand()),
+	RunE:  withErrorHandling(createSecretsListCommand()),
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search secrets by name, tags and non-sensitive fields",
+	Long: `Search secrets by name, tags, metadata, username, URL, file name and card holder.
+Passwords, CVVs, card numbers and contents are never searched. All words must match,
+a trailing * matches a prefix and "double quotes" match a phrase. Best matches come first.`,
+	Args: usageArgs(cobra.MinimumNArgs(1)),
+	RunE: withErrorHandling(createSecretsSearchCommand()),
+}
+
+var lsCmd = &cobra.Command{
+	Use:   "ls [folder]",
+	Short: "List folder content",
+	Args:  usageArgs(cobra.MaximumNArgs(1)),
+	RunE:  withErrorHandling(createFolderListCommand()),
+}
+
+var mvCmd = &cobra.Command{
+	Use:   "mv <uuid|path> <new-path>",
+	Short: "Rename or move secret",
+	Long:  "Rename or move a secret. A new path ending in / or naming an existing folder moves the secret into that folder.",
+	Args:  usageArgs(cobra.ExactArgs(2)),
+	RunE:  withErrorHandling(createSecretMoveCommand()),
 }
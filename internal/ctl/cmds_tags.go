@@ -0,0 +1,30 @@
+package ctl
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Tag and untag secrets",
+}
+
+var tagAddCmd = &cobra.Command{
+	Use:   "add <uuid> <tag>...",
+	Short: "Add tags to secret",
+	Args:  usageArgs(cobra.MinimumNArgs(2)),
+	RunE:  withErrorHandling(createTagAddHandler()),
+}
+
+var tagRemoveCmd = &cobra.Command{
+	Use:   "remove <uuid> <tag>...",
+	Short: "Remove tags from secret",
+	Args:  usageArgs(cobra.MinimumNArgs(2)),
+	RunE:  withErrorHandling(createTagRemoveHandler()),
+}
+
+var tagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "List tags with secret counts",
+	RunE:  withErrorHandling(createTagsListHandler()),
+}
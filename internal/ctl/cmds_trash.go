@@ -0,0 +1,38 @@
+package ctl
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage deleted secrets",
+	Long: `Deleted secrets are kept in the trash with their attachments until they are
+restored or purged. Secrets in the trash longer than trash_days are purged
+when the vault is opened, sync carries both moves to other devices.`,
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List secrets in the trash",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE:  withErrorHandling(createTrashListHandler()),
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <uuid|path>",
+	Short: "Take secret out of the trash",
+	Long: `Take a secret out of the trash with its attachments. A path deleted more than
+once is ambiguous, use the UUID then.`,
+	Args: usageArgs(cobra.ExactArgs(1)),
+	RunE: withErrorHandling(createTrashRestoreHandler()),
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Purge every secret in the trash",
+	Long: `Erase every secret in the trash with its attachments and history, see
+keeperctl purge. Sync erases their copies on other devices too.`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: withErrorHandling(createTrashEmptyHandler()),
+}
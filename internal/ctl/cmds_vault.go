@@ -0,0 +1,34 @@
+package ctl
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var vaultCmd = &cobra.Command{
+	Use:   "vault",
+	Short: "Manage the local vault file",
+}
+
+var vaultGenerationsCmd = &cobra.Command{
+	Use:   "generations",
+	Short: "List kept vault generations",
+	RunE:  withErrorHandling(createVaultGenerationsHandler()),
+}
+
+var vaultRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore vault generation",
+	RunE:  withErrorHandling(createVaultRestoreHandler()),
+}
+
+var vaultConvertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Convert vault to another storage backend",
+	RunE:  withErrorHandling(createVaultConvertHandler()),
+}
+
+var vaultStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show vault size and compression savings",
+	RunE:  withErrorHandling(createVaultStatsHandler()),
+}
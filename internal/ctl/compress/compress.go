@@ -0,0 +1,103 @@
+// Package compress shrinks plain secret data before it is encrypted.
+// Compressed data starts with a format flag, data without it is plain, so
+// data written before compression, or with it turned off, still decodes.
+package compress
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	formatFlag = "GKZ\x01"
+
+	// minSize is below what the zstd frame overhead eats the savings
+	minSize = 64
+	// maxDecodedSize bounds the memory a hostile frame can make Decompress take
+	maxDecodedSize = 64 * 1024 * 1024
+)
+
+var (
+	// NOTE: NewWriter and NewReader fail only for invalid options
+	encoder, _ = zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+	decoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(maxDecodedSize))
+)
+
+// compressedMagics start files of formats that are compressed already.
+var compressedMagics = [][]byte{
+	{0x1f, 0x8b},                       // gzip
+	{0x28, 0xb5, 0x2f, 0xfd},           // zstd
+	{0xfd, '7', 'z', 'X', 'Z', 0x00},   // xz
+	{'B', 'Z', 'h'},                    // bzip2
+	{0x04, 0x22, 0x4d, 0x18},           // lz4
+	{'P', 'K', 0x03, 0x04},             // zip, docx, jar, apk
+	{'7', 'z', 0xbc, 0xaf, 0x27, 0x1c}, // 7z
+	{'R', 'a', 'r', '!'},               // rar
+	{0x89, 'P', 'N', 'G'},              // png
+	{0xff, 0xd8, 0xff},                 // jpeg
+	{'G', 'I', 'F', '8'},               // gif
+	{'O', 'g', 'g', 'S'},               // ogg
+	{'f', 'L', 'a', 'C'},               // flac
+	{'I', 'D', '3'},                    // mp3
+	{0x1a, 0x45, 0xdf, 0xa3},           // mkv, webm
+}
+
+// Compress returns data compressed behind the format flag, or data itself
+// when compressing does not make it noticeably smaller.
+func Compress(data []byte) []byte {
+	if len(data) < minSize {
+		return data
+	}
+
+	compressed := make([]byte, 0, len(data)/2+len(formatFlag))
+	compressed = append(compressed, formatFlag...)
+	compressed = encoder.EncodeAll(data, compressed)
+
+	// NOTE: Less than 1/16 saved is not worth decompressing on every read
+	if len(compressed) > len(data)-len(data)/16 {
+		return data
+	}
+	return compressed
+}
+
+// Decompress returns the data Compress was given. Data without the format
+// flag is returned as is.
+func Decompress(data []byte) ([]byte, error) {
+	if !IsCompressed(data) {
+		return data, nil
+	}
+
+	plain, err := decoder.DecodeAll(data[len(formatFlag):], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress data: %w", err)
+	}
+	return plain, nil
+}
+
+// IsCompressed reports whether data was compressed by Compress.
+func IsCompressed(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(formatFlag))
+}
+
+// LooksCompressed reports whether content starts like a file of a format
+// that is compressed already, which compressing again would not shrink.
+func LooksCompressed(content []byte) bool {
+	for _, magic := range compressedMagics {
+		if bytes.HasPrefix(content, magic) {
+			return true
+		}
+	}
+
+	// NOTE: mp4, mov and heic start with a box size, then ftyp
+	if len(content) >= 8 && string(content[4:8]) == "ftyp" {
+		return true
+	}
+	// NOTE: webp is a RIFF container, wav is one too but is not compressed
+	if len(content) >= 12 && string(content[:4]) == "RIFF" && string(content[8:12]) == "WEBP" {
+		return true
+	}
+
+	return false
+}
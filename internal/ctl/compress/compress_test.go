@@ -0,0 +1,72 @@
+package compress
+
+import (
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompress(t *testing.T) {
+	t.Run("compressible data round trips", func(t *testing.T) {
+		data := []byte(strings.Repeat(`{"key":"value","other":"setting"}`, 100))
+
+		compressed := Compress(data)
+		assert.True(t, IsCompressed(compressed))
+		assert.Less(t, len(compressed), len(data)/4)
+
+		plain, err := Decompress(compressed)
+		require.NoError(t, err)
+		assert.Equal(t, data, plain)
+	})
+
+	t.Run("small data stays plain", func(t *testing.T) {
+		data := []byte(`{"password":"hunter2"}`)
+		assert.Equal(t, data, Compress(data))
+	})
+
+	t.Run("random data stays plain", func(t *testing.T) {
+		data := make([]byte, 4096)
+		_, _ = rand.Read(data)
+		assert.Equal(t, data, Compress(data))
+	})
+
+	t.Run("plain data decodes as is", func(t *testing.T) {
+		data := []byte(`{"text":"written before compression"}`)
+
+		plain, err := Decompress(data)
+		require.NoError(t, err)
+		assert.Equal(t, data, plain)
+	})
+
+	t.Run("corrupt frame fails", func(t *testing.T) {
+		_, err := Decompress([]byte(formatFlag + "not zstd"))
+		assert.Error(t, err)
+	})
+}
+
+func TestLooksCompressed(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		want    bool
+	}{
+		{"gzip", []byte{0x1f, 0x8b, 0x08, 0x00}, true},
+		{"png", []byte("\x89PNG\r\n\x1a\n"), true},
+		{"jpeg", []byte{0xff, 0xd8, 0xff, 0xe0}, true},
+		{"zip", []byte("PK\x03\x04\x14\x00"), true},
+		{"mp4", []byte("\x00\x00\x00\x20ftypisom"), true},
+		{"webp", []byte("RIFF\x24\x00\x00\x00WEBPVP8 "), true},
+		{"wav", []byte("RIFF\x24\x00\x00\x00WAVEfmt "), false},
+		{"text", []byte("port = 8080\nhost = example.com\n"), false},
+		{"empty", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, LooksCompressed(tt.content))
+		})
+	}
+}
@@ -1,42 +1,177 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"os"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+)
+
+const (
+	defaultVaultBackups = 5
+	defaultLockTimeout  = 5 * time.Second
+	// defaultHistoryVersions earlier versions are kept of every secret
+	defaultHistoryVersions = 10
+	// defaultTrashDays is how long deleted secrets stay in the trash
+	defaultTrashDays = 30
+)
+
+const (
+	// SyncPrompt asks what to do with every secret that differs
+	SyncPrompt = "prompt"
+	// SyncLocal keeps the local copy: missing copies are created on either
+	// side and conflicts replace the remote copy
+	SyncLocal = "local"
+	// SyncRemote is SyncLocal except that conflicts replace the local copy
+	SyncRemote = "remote"
+	// SyncNewest is SyncLocal except that conflicts keep the newer copy
+	SyncNewest = "newest"
 )
 
+var SyncStrategies = []string{SyncPrompt, SyncLocal, SyncRemote, SyncNewest}
+
 type Config struct {
+	Profile       string
 	DBPath        string
 	Login         string
 	Password      string
 	ServerAddress string
+	TLS           TLSOptions
+	Output        string
+	SyncStrategy  string
+	VaultBackend  string
+	VaultBackups  int
+	LockTimeout   time.Duration
+	// Compression compresses secret data before it is encrypted, see compress.
+	// Off by default, as older clients cannot read compressed secrets
+	Compression bool
+	// HistoryVersions earlier versions are kept of every secret, none older
+	// than HistoryMaxAge unless it is zero
+	HistoryVersions int
+	HistoryMaxAge   time.Duration
+	// TrashRetention is how long deleted secrets stay in the trash, zero
+	// keeps them until the trash is emptied
+	TrashRetention time.Duration
 }
 
+// LoadCfg loads the config of the profile selected by GOKEEPER_PROFILE or
+// current_profile, see Load.
 func LoadCfg() (*Config, error) {
-	dbPath := os.Getenv("GOKEEPER_DB_PATH")
-	if dbPath == "" {
-		return nil, fmt.Errorf("GOKEEPER_DB_PATH environment variable is required")
+	return Load("")
+}
+
+// Load reads the named profile from the config file, or the default one
+// when name is empty, and overrides its values with environment variables.
+// Without a config file every value comes from the environment.
+func Load(name string) (*Config, error) {
+	profile := &Profile{}
+
+	path, err := Path()
+	if err != nil && name != "" {
+		return nil, err
+	}
+	if err == nil {
+		file, err := ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		name = file.ProfileName(name)
+		if name != "" {
+			selected, err := file.Profile(name)
+			if err != nil {
+				return nil, fmt.Errorf("%w, add it with 'keeperctl config set --profile %s db_path <path>'", err, name)
+			}
+			*profile = *selected
+		}
+	}
+
+	if err := profile.applyEnv(); err != nil {
+		return nil, err
 	}
 
-	login := os.Getenv("GOKEEPER_LOGIN")
-	if login == "" {
-		return nil, fmt.Errorf("GOKEEPER_LOGIN environment variable is required")
+	cfg := &Config{
+		Profile:       name,
+		DBPath:        profile.DBPath,
+		Login:         profile.Login,
+		Password:      os.Getenv("GOKEEPER_PASSWORD"),
+		ServerAddress: profile.ServerAddress,
+		TLS:           profile.TLS,
+		Output:        profile.Output,
+		SyncStrategy:  profile.SyncStrategy,
+		VaultBackend:  profile.VaultBackend,
+		VaultBackups:  defaultVaultBackups,
+		LockTimeout:   defaultLockTimeout,
+
+		HistoryVersions: defaultHistoryVersions,
+		TrashRetention:  defaultTrashDays * 24 * time.Hour,
 	}
 
-	password := os.Getenv("GOKEEPER_PASSWORD")
-	if password == "" {
-		return nil, fmt.Errorf("GOKEEPER_PASSWORD environment variable is required")
+	if profile.VaultBackups != nil {
+		cfg.VaultBackups = *profile.VaultBackups
+	}
+	if profile.Compression != nil {
+		cfg.Compression = *profile.Compression
+	}
+	if profile.HistoryVersions != nil {
+		cfg.HistoryVersions = *profile.HistoryVersions
 	}
+	if profile.TrashDays != nil {
+		cfg.TrashRetention = time.Duration(*profile.TrashDays) * 24 * time.Hour
+	}
+	if profile.HistoryMaxAge != "" {
+		// NOTE: Already validated by Profile.Set
+		cfg.HistoryMaxAge, _ = parseAge(profile.HistoryMaxAge)
+	}
+	if profile.LockTimeout != "" {
+		// NOTE: Already validated by Profile.Set
+		cfg.LockTimeout, _ = time.ParseDuration(profile.LockTimeout)
+	}
+	if cfg.SyncStrategy == "" {
+		cfg.SyncStrategy = SyncPrompt
+	}
+
+	if cfg.DBPath == "" {
+		return nil, errs.Validationf("db_path is required, set it in the profile or GOKEEPER_DB_PATH")
+	}
+	if cfg.Login == "" {
+		return nil, errs.Validationf("login is required, set it in the profile or GOKEEPER_LOGIN")
+	}
+	if cfg.Password == "" {
+		return nil, errs.Validationf("GOKEEPER_PASSWORD environment variable is required")
+	}
+
+	return cfg, nil
+}
+
+// ClientConfig returns the TLS config for the server connection, nil when
+// TLS is not enabled.
+func (o TLSOptions) ClientConfig() (*tls.Config, error) {
+	if !o.Enabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tls.ca_file: %w", err)
+		}
 
-	serverAddres := os.Getenv("GOKEEPER_SERVER_ADDR")
-	if serverAddres == "" {
-		return nil, fmt.Errorf("GOKEEPER_SERVER_ADDR environment variable is required")
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errs.Validationf("tls.ca_file %s holds no PEM certificates", o.CAFile)
+		}
+		tlsConfig.RootCAs = pool
 	}
 
-	return &Config{
-		DBPath:        dbPath,
-		Login:         login,
-		Password:      password,
-		ServerAddress: serverAddres,
-	}, nil
+	return tlsConfig, nil
 }
@@ -2,18 +2,24 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestLoadCfg(t *testing.T) {
+	t.Setenv(PathEnv, filepath.Join(t.TempDir(), "config.yaml"))
+
 	envVars := []string{
 		"GOKEEPER_DB_PATH",
 		"GOKEEPER_LOGIN",
 		"GOKEEPER_PASSWORD",
 		"GOKEEPER_SERVER_ADDR",
+		"GOKEEPER_VAULT_BACKUPS",
+		"GOKEEPER_LOCK_TIMEOUT",
 	}
 
 	originalEnv := make(map[string]string, len(envVars))
@@ -54,6 +60,133 @@ func TestLoadCfg(t *testing.T) {
 		assert.Equal(t, "testuser", cfg.Login)
 		assert.Equal(t, "testpass", cfg.Password)
 		assert.Equal(t, "localhost:8080", cfg.ServerAddress)
+		assert.Equal(t, defaultVaultBackups, cfg.VaultBackups)
+		assert.Equal(t, defaultLockTimeout, cfg.LockTimeout)
+		assert.False(t, cfg.Compression)
+		assert.Equal(t, defaultHistoryVersions, cfg.HistoryVersions)
+		assert.Equal(t, time.Duration(0), cfg.HistoryMaxAge)
+	})
+
+	t.Run("compression", func(t *testing.T) {
+		err := os.Setenv("GOKEEPER_COMPRESSION", "true")
+		require.NoError(t, err)
+
+		cfg, err := LoadCfg()
+		require.NoError(t, err)
+		assert.True(t, cfg.Compression)
+
+		err = os.Setenv("GOKEEPER_COMPRESSION", "sometimes")
+		require.NoError(t, err)
+
+		cfg, err = LoadCfg()
+		assert.Error(t, err)
+		assert.Nil(t, cfg)
+
+		err = os.Unsetenv("GOKEEPER_COMPRESSION")
+		require.NoError(t, err)
+	})
+
+	t.Run("history retention", func(t *testing.T) {
+		err := os.Setenv("GOKEEPER_HISTORY_VERSIONS", "3")
+		require.NoError(t, err)
+		err = os.Setenv("GOKEEPER_HISTORY_MAX_AGE", "30d")
+		require.NoError(t, err)
+
+		cfg, err := LoadCfg()
+		require.NoError(t, err)
+		assert.Equal(t, 3, cfg.HistoryVersions)
+		assert.Equal(t, 30*24*time.Hour, cfg.HistoryMaxAge)
+
+		err = os.Setenv("GOKEEPER_HISTORY_MAX_AGE", "12h")
+		require.NoError(t, err)
+
+		cfg, err = LoadCfg()
+		require.NoError(t, err)
+		assert.Equal(t, 12*time.Hour, cfg.HistoryMaxAge)
+
+		err = os.Setenv("GOKEEPER_HISTORY_MAX_AGE", "-1d")
+		require.NoError(t, err)
+
+		cfg, err = LoadCfg()
+		assert.Error(t, err)
+		assert.Nil(t, cfg)
+		assert.Contains(t, err.Error(), "GOKEEPER_HISTORY_MAX_AGE")
+
+		err = os.Unsetenv("GOKEEPER_HISTORY_MAX_AGE")
+		require.NoError(t, err)
+		err = os.Setenv("GOKEEPER_HISTORY_VERSIONS", "many")
+		require.NoError(t, err)
+
+		cfg, err = LoadCfg()
+		assert.Error(t, err)
+		assert.Nil(t, cfg)
+
+		err = os.Unsetenv("GOKEEPER_HISTORY_VERSIONS")
+		require.NoError(t, err)
+	})
+
+	t.Run("trash retention", func(t *testing.T) {
+		cfg, err := LoadCfg()
+		require.NoError(t, err)
+		assert.Equal(t, 30*24*time.Hour, cfg.TrashRetention)
+
+		err = os.Setenv("GOKEEPER_TRASH_DAYS", "0")
+		require.NoError(t, err)
+
+		cfg, err = LoadCfg()
+		require.NoError(t, err)
+		assert.Zero(t, cfg.TrashRetention)
+
+		err = os.Setenv("GOKEEPER_TRASH_DAYS", "-7")
+		require.NoError(t, err)
+
+		cfg, err = LoadCfg()
+		assert.Error(t, err)
+		assert.Nil(t, cfg)
+		assert.Contains(t, err.Error(), "GOKEEPER_TRASH_DAYS")
+
+		err = os.Unsetenv("GOKEEPER_TRASH_DAYS")
+		require.NoError(t, err)
+	})
+
+	t.Run("vault backups", func(t *testing.T) {
+		err := os.Setenv("GOKEEPER_VAULT_BACKUPS", "2")
+		require.NoError(t, err)
+
+		cfg, err := LoadCfg()
+		require.NoError(t, err)
+		assert.Equal(t, 2, cfg.VaultBackups)
+
+		err = os.Setenv("GOKEEPER_VAULT_BACKUPS", "-1")
+		require.NoError(t, err)
+
+		cfg, err = LoadCfg()
+		assert.Error(t, err)
+		assert.Nil(t, cfg)
+		assert.Contains(t, err.Error(), "GOKEEPER_VAULT_BACKUPS")
+
+		err = os.Unsetenv("GOKEEPER_VAULT_BACKUPS")
+		require.NoError(t, err)
+	})
+
+	t.Run("lock timeout", func(t *testing.T) {
+		err := os.Setenv("GOKEEPER_LOCK_TIMEOUT", "0")
+		require.NoError(t, err)
+
+		cfg, err := LoadCfg()
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), cfg.LockTimeout)
+
+		err = os.Setenv("GOKEEPER_LOCK_TIMEOUT", "soon")
+		require.NoError(t, err)
+
+		cfg, err = LoadCfg()
+		assert.Error(t, err)
+		assert.Nil(t, cfg)
+		assert.Contains(t, err.Error(), "GOKEEPER_LOCK_TIMEOUT")
+
+		err = os.Unsetenv("GOKEEPER_LOCK_TIMEOUT")
+		require.NoError(t, err)
 	})
 
 	t.Run("missing db path", func(t *testing.T) {
@@ -135,8 +268,7 @@ func TestLoadCfg(t *testing.T) {
 		}
 
 		cfg, err := LoadCfg()
-		assert.Error(t, err)
-		assert.Nil(t, cfg)
-		assert.Contains(t, err.Error(), "GOKEEPER_SERVER_ADDR")
+		require.NoError(t, err)
+		assert.Empty(t, cfg.ServerAddress)
 	})
 }
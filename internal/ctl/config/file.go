@@ -0,0 +1,491 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// PathEnv overrides the location of the config file
+	PathEnv = "GOKEEPER_CONFIG"
+	// ProfileEnv selects the profile when --profile is not given
+	ProfileEnv = "GOKEEPER_PROFILE"
+
+	// CurrentProfileKey is the file-wide key naming the default profile
+	CurrentProfileKey = "current_profile"
+)
+
+var outputFormats = []string{"table", "json", "yaml"}
+
+// TLSOptions configure the connection to the server. TLS is off unless
+// Enabled is set.
+type TLSOptions struct {
+	Enabled            bool   `yaml:"enabled,omitempty"`
+	CAFile             string `yaml:"ca_file,omitempty"`
+	ServerName         string `yaml:"server_name,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"`
+}
+
+// Profile is one named set of settings in the config file. The master
+// password is never stored, it only comes from GOKEEPER_PASSWORD.
+type Profile struct {
+	DBPath        string     `yaml:"db_path,omitempty"`
+	Login         string     `yaml:"login,omitempty"`
+	ServerAddress string     `yaml:"server_address,omitempty"`
+	TLS           TLSOptions `yaml:"tls,omitempty"`
+	Output        string     `yaml:"output,omitempty"`
+	SyncStrategy  string     `yaml:"sync_strategy,omitempty"`
+	VaultBackend  string     `yaml:"vault_backend,omitempty"`
+	VaultBackups  *int       `yaml:"vault_backups,omitempty"`
+	LockTimeout   string     `yaml:"lock_timeout,omitempty"`
+	Compression   *bool      `yaml:"compression,omitempty"`
+	// HistoryVersions and HistoryMaxAge limit the earlier versions kept of every secret
+	HistoryVersions *int   `yaml:"history_versions,omitempty"`
+	HistoryMaxAge   string `yaml:"history_max_age,omitempty"`
+	// TrashDays is how long deleted secrets stay in the trash, 0 keeps them
+	TrashDays *int `yaml:"trash_days,omitempty"`
+}
+
+// File is the config file, see Path.
+type File struct {
+	CurrentProfile string              `yaml:"current_profile,omitempty"`
+	Profiles       map[string]*Profile `yaml:"profiles,omitempty"`
+}
+
+// setting is a profile key as used by config get and set, with the
+// environment variable overriding it.
+type setting struct {
+	key string
+	env string
+	get func(p *Profile) string
+	set func(p *Profile, value string) error
+}
+
+var settings = []setting{
+	{
+		key: "db_path",
+		env: "GOKEEPER_DB_PATH",
+		get: func(p *Profile) string { return p.DBPath },
+		set: func(p *Profile, value string) error { p.DBPath = value; return nil },
+	},
+	{
+		key: "login",
+		env: "GOKEEPER_LOGIN",
+		get: func(p *Profile) string { return p.Login },
+		set: func(p *Profile, value string) error { p.Login = value; return nil },
+	},
+	{
+		key: "server_address",
+		env: "GOKEEPER_SERVER_ADDR",
+		get: func(p *Profile) string { return p.ServerAddress },
+		set: func(p *Profile, value string) error { p.ServerAddress = value; return nil },
+	},
+	{
+		key: "tls.enabled",
+		env: "GOKEEPER_TLS",
+		get: func(p *Profile) string { return formatBool(p.TLS.Enabled) },
+		set: func(p *Profile, value string) error { return parseBool("tls.enabled", value, &p.TLS.Enabled) },
+	},
+	{
+		key: "tls.ca_file",
+		env: "GOKEEPER_TLS_CA_FILE",
+		get: func(p *Profile) string { return p.TLS.CAFile },
+		set: func(p *Profile, value string) error { p.TLS.CAFile = value; return nil },
+	},
+	{
+		key: "tls.server_name",
+		env: "GOKEEPER_TLS_SERVER_NAME",
+		get: func(p *Profile) string { return p.TLS.ServerName },
+		set: func(p *Profile, value string) error { p.TLS.ServerName = value; return nil },
+	},
+	{
+		key: "tls.insecure_skip_verify",
+		env: "GOKEEPER_TLS_INSECURE_SKIP_VERIFY",
+		get: func(p *Profile) string { return formatBool(p.TLS.InsecureSkipVerify) },
+		set: func(p *Profile, value string) error {
+			return parseBool("tls.insecure_skip_verify", value, &p.TLS.InsecureSkipVerify)
+		},
+	},
+	{
+		key: "output",
+		env: "GOKEEPER_OUTPUT",
+		get: func(p *Profile) string { return p.Output },
+		set: func(p *Profile, value string) error {
+			if err := validateChoice("output", value, outputFormats); err != nil {
+				return err
+			}
+			p.Output = value
+			return nil
+		},
+	},
+	{
+		key: "sync_strategy",
+		env: "GOKEEPER_SYNC_STRATEGY",
+		get: func(p *Profile) string { return p.SyncStrategy },
+		set: func(p *Profile, value string) error {
+			if err := validateChoice("sync_strategy", value, SyncStrategies); err != nil {
+				return err
+			}
+			p.SyncStrategy = value
+			return nil
+		},
+	},
+	{
+		key: "vault_backend",
+		env: "GOKEEPER_VAULT_BACKEND",
+		get: func(p *Profile) string { return p.VaultBackend },
+		set: func(p *Profile, value string) error { p.VaultBackend = value; return nil },
+	},
+	{
+		key: "vault_backups",
+		env: "GOKEEPER_VAULT_BACKUPS",
+		get: func(p *Profile) string {
+			if p.VaultBackups == nil {
+				return ""
+			}
+			return strconv.Itoa(*p.VaultBackups)
+		},
+		set: func(p *Profile, value string) error {
+			if value == "" {
+				p.VaultBackups = nil
+				return nil
+			}
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				return errs.Validationf("vault_backups must be a non-negative integer, got %q", value)
+			}
+			p.VaultBackups = &parsed
+			return nil
+		},
+	},
+	{
+		key: "lock_timeout",
+		env: "GOKEEPER_LOCK_TIMEOUT",
+		get: func(p *Profile) string { return p.LockTimeout },
+		set: func(p *Profile, value string) error {
+			if value != "" {
+				if _, err := time.ParseDuration(value); err != nil {
+					return errs.Validationf("lock_timeout must be a duration such as 10s, got %q", value)
+				}
+			}
+			p.LockTimeout = value
+			return nil
+		},
+	},
+	{
+		key: "compression",
+		env: "GOKEEPER_COMPRESSION",
+		get: func(p *Profile) string {
+			if p.Compression == nil {
+				return ""
+			}
+			return strconv.FormatBool(*p.Compression)
+		},
+		set: func(p *Profile, value string) error {
+			if value == "" {
+				p.Compression = nil
+				return nil
+			}
+			var parsed bool
+			if err := parseBool("compression", value, &parsed); err != nil {
+				return err
+			}
+			p.Compression = &parsed
+			return nil
+		},
+	},
+	{
+		key: "history_versions",
+		env: "GOKEEPER_HISTORY_VERSIONS",
+		get: func(p *Profile) string {
+			if p.HistoryVersions == nil {
+				return ""
+			}
+			return strconv.Itoa(*p.HistoryVersions)
+		},
+		set: func(p *Profile, value string) error {
+			if value == "" {
+				p.HistoryVersions = nil
+				return nil
+			}
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				return errs.Validationf("history_versions must be a non-negative integer, got %q", value)
+			}
+			p.HistoryVersions = &parsed
+			return nil
+		},
+	},
+	{
+		key: "history_max_age",
+		env: "GOKEEPER_HISTORY_MAX_AGE",
+		get: func(p *Profile) string { return p.HistoryMaxAge },
+		set: func(p *Profile, value string) error {
+			if value != "" {
+				if _, err := parseAge(value); err != nil {
+					return errs.Validationf("history_max_age must be a number of days such as 90d or a duration such as 12h, got %q", value)
+				}
+			}
+			p.HistoryMaxAge = value
+			return nil
+		},
+	},
+	{
+		key: "trash_days",
+		env: "GOKEEPER_TRASH_DAYS",
+		get: func(p *Profile) string {
+			if p.TrashDays == nil {
+				return ""
+			}
+			return strconv.Itoa(*p.TrashDays)
+		},
+		set: func(p *Profile, value string) error {
+			if value == "" {
+				p.TrashDays = nil
+				return nil
+			}
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				return errs.Validationf("trash_days must be a non-negative integer, got %q", value)
+			}
+			p.TrashDays = &parsed
+			return nil
+		},
+	},
+}
+
+// parseAge parses a duration that may also be given in days, such as 30d.
+func parseAge(value string) (time.Duration, error) {
+	if days, found := strings.CutSuffix(value, "d"); found {
+		parsed, err := strconv.Atoi(days)
+		if err != nil || parsed < 0 {
+			return 0, fmt.Errorf("invalid number of days %q", days)
+		}
+		return time.Duration(parsed) * 24 * time.Hour, nil
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if parsed < 0 {
+		return 0, fmt.Errorf("negative duration %q", value)
+	}
+	return parsed, nil
+}
+
+// Keys returns the profile keys accepted by Get and Set, in file order.
+func Keys() []string {
+	keys := make([]string, 0, len(settings))
+	for _, s := range settings {
+		keys = append(keys, s.key)
+	}
+	return keys
+}
+
+func findSetting(key string) (setting, error) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, nil
+		}
+	}
+	if key == "password" {
+		return setting{}, errs.Validationf("the master password is never stored, use GOKEEPER_PASSWORD")
+	}
+	return setting{}, errs.Validationf("unknown config key %q, expected %s or one of %s",
+		key, CurrentProfileKey, strings.Join(Keys(), ", "))
+}
+
+// Get returns the value of key in the profile, empty when it is not set.
+func (p *Profile) Get(key string) (string, error) {
+	s, err := findSetting(key)
+	if err != nil {
+		return "", err
+	}
+	return s.get(p), nil
+}
+
+// Set validates and stores the value of key, an empty value unsets it.
+func (p *Profile) Set(key string, value string) error {
+	s, err := findSetting(key)
+	if err != nil {
+		return err
+	}
+	return s.set(p, value)
+}
+
+// Settings returns the keys set in the profile with their values.
+func (p *Profile) Settings() map[string]string {
+	values := make(map[string]string)
+	for _, s := range settings {
+		if value := s.get(p); value != "" {
+			values[s.key] = value
+		}
+	}
+	return values
+}
+
+// applyEnv overrides profile values with the environment variables that are set.
+func (p *Profile) applyEnv() error {
+	for _, s := range settings {
+		value, exists := os.LookupEnv(s.env)
+		if !exists || value == "" {
+			continue
+		}
+		if err := s.set(p, value); err != nil {
+			return fmt.Errorf("%s: %w", s.env, err)
+		}
+	}
+	return nil
+}
+
+// Path returns the location of the config file: GOKEEPER_CONFIG, or
+// go-keeper/config.yaml in $XDG_CONFIG_HOME or the platform equivalent.
+func Path() (string, error) {
+	if path := os.Getenv(PathEnv); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the config directory, set %s: %w", PathEnv, err)
+	}
+
+	return filepath.Join(dir, "go-keeper", "config.yaml"), nil
+}
+
+// ReadFile reads the config file at path. A missing file reads as empty.
+func ReadFile(path string) (*File, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &File{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	file := &File{}
+	if err := yaml.Unmarshal(content, file); err != nil {
+		return nil, errs.Validationf("failed to parse config file %s: %v", path, err)
+	}
+
+	for name, profile := range file.Profiles {
+		if profile == nil {
+			file.Profiles[name] = &Profile{}
+			continue
+		}
+		if err := profile.validate(); err != nil {
+			return nil, fmt.Errorf("profile %q in %s: %w", name, path, err)
+		}
+	}
+
+	return file, nil
+}
+
+// validate checks the values that the yaml decoder does not.
+func (p *Profile) validate() error {
+	for _, s := range settings {
+		if err := s.set(p, s.get(p)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Save writes the file, creating its directory. The file is readable by the
+// owner only since it names vaults and logins.
+func (f *File) Save(path string) error {
+	var content bytes.Buffer
+	encoder := yaml.NewEncoder(&content)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(f); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, content.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// ProfileName returns the selected profile: name when given, then
+// GOKEEPER_PROFILE, then current_profile. Empty means no profile.
+func (f *File) ProfileName(name string) string {
+	if name != "" {
+		return name
+	}
+	if name := os.Getenv(ProfileEnv); name != "" {
+		return name
+	}
+	return f.CurrentProfile
+}
+
+// Profile returns the named profile.
+func (f *File) Profile(name string) (*Profile, error) {
+	profile, exists := f.Profiles[name]
+	if !exists {
+		return nil, &errs.NotFoundError{Entity: "profile", UUID: name}
+	}
+	return profile, nil
+}
+
+// SetProfile returns the named profile, adding it when it does not exist.
+// The first profile added becomes the current one.
+func (f *File) SetProfile(name string) *Profile {
+	if f.Profiles == nil {
+		f.Profiles = make(map[string]*Profile)
+	}
+
+	profile, exists := f.Profiles[name]
+	if !exists {
+		profile = &Profile{}
+		f.Profiles[name] = profile
+	}
+	if f.CurrentProfile == "" {
+		f.CurrentProfile = name
+	}
+
+	return profile
+}
+
+func formatBool(value bool) string {
+	if !value {
+		return ""
+	}
+	return "true"
+}
+
+func parseBool(key string, value string, target *bool) error {
+	if value == "" {
+		*target = false
+		return nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return errs.Validationf("%s must be true or false, got %q", key, value)
+	}
+	*target = parsed
+	return nil
+}
+
+func validateChoice(key string, value string, choices []string) error {
+	if value == "" || slices.Contains(choices, value) {
+		return nil
+	}
+	return errs.Validationf("%s must be one of %s, got %q", key, strings.Join(choices, ", "), value)
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfigFile = `current_profile: personal
+profiles:
+  personal:
+    db_path: /vaults/personal.db
+    login: alice
+    output: json
+  work:
+    db_path: /vaults/work.db
+    login: alice@corp
+    server_address: keeper.corp:443
+    tls:
+      enabled: true
+      server_name: keeper.corp
+    sync_strategy: newest
+    vault_backups: 2
+    lock_timeout: 30s
+`
+
+func setupConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	t.Setenv(PathEnv, path)
+	t.Setenv(ProfileEnv, "")
+	for _, s := range settings {
+		t.Setenv(s.env, "")
+	}
+	t.Setenv("GOKEEPER_PASSWORD", "pw")
+
+	return path
+}
+
+func TestLoad_Profiles(t *testing.T) {
+	setupConfigFile(t, testConfigFile)
+
+	t.Run("current profile", func(t *testing.T) {
+		cfg, err := Load("")
+		require.NoError(t, err)
+		assert.Equal(t, "personal", cfg.Profile)
+		assert.Equal(t, "/vaults/personal.db", cfg.DBPath)
+		assert.Equal(t, "json", cfg.Output)
+		assert.Equal(t, SyncPrompt, cfg.SyncStrategy)
+		assert.Empty(t, cfg.ServerAddress)
+		assert.Equal(t, defaultVaultBackups, cfg.VaultBackups)
+	})
+
+	t.Run("named profile", func(t *testing.T) {
+		cfg, err := Load("work")
+		require.NoError(t, err)
+		assert.Equal(t, "alice@corp", cfg.Login)
+		assert.Equal(t, "keeper.corp:443", cfg.ServerAddress)
+		assert.True(t, cfg.TLS.Enabled)
+		assert.Equal(t, SyncNewest, cfg.SyncStrategy)
+		assert.Equal(t, 2, cfg.VaultBackups)
+		assert.Equal(t, 30*time.Second, cfg.LockTimeout)
+	})
+
+	t.Run("profile from env", func(t *testing.T) {
+		t.Setenv(ProfileEnv, "work")
+
+		cfg, err := Load("")
+		require.NoError(t, err)
+		assert.Equal(t, "work", cfg.Profile)
+	})
+
+	t.Run("env overrides file", func(t *testing.T) {
+		t.Setenv("GOKEEPER_DB_PATH", "/tmp/other.db")
+		t.Setenv("GOKEEPER_VAULT_BACKUPS", "7")
+
+		cfg, err := Load("work")
+		require.NoError(t, err)
+		assert.Equal(t, "/tmp/other.db", cfg.DBPath)
+		assert.Equal(t, 7, cfg.VaultBackups)
+		assert.Equal(t, "alice@corp", cfg.Login)
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		_, err := Load("home")
+		require.Error(t, err)
+		assert.True(t, errs.IsNotFound(err))
+	})
+}
+
+func TestReadFile_Invalid(t *testing.T) {
+	path := setupConfigFile(t, "profiles:\n  work:\n    output: xml\n")
+
+	_, err := ReadFile(path)
+	require.Error(t, err)
+	assert.True(t, errs.IsValidation(err))
+	assert.Contains(t, err.Error(), "output must be one of table, json, yaml")
+}
+
+func TestFile_SetAndSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "go-keeper", "config.yaml")
+
+	file, err := ReadFile(path)
+	require.NoError(t, err)
+
+	profile := file.SetProfile("work")
+	require.NoError(t, profile.Set("db_path", "/vaults/work.db"))
+	require.NoError(t, profile.Set("tls.enabled", "true"))
+	require.NoError(t, profile.Set("vault_backups", "0"))
+
+	assert.Error(t, profile.Set("sync_strategy", "merge"))
+	assert.Error(t, profile.Set("lock_timeout", "soon"))
+	assert.Error(t, profile.Set("password", "hunter2"))
+	assert.Error(t, profile.Set("colour", "blue"))
+
+	require.NoError(t, file.Save(path))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	reread, err := ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "work", reread.CurrentProfile)
+	assert.Equal(t, map[string]string{
+		"db_path":       "/vaults/work.db",
+		"tls.enabled":   "true",
+		"vault_backups": "0",
+	}, reread.Profiles["work"].Settings())
+}
@@ -0,0 +1,6 @@
+package constants
+
+import "time"
+
+// ClipboardClearAfter is how long a copied secret field stays in the clipboard by default.
+const ClipboardClearAfter = 45 * time.Second
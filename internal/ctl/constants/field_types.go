@@ -0,0 +1,7 @@
+package constants
+
+const (
+	FieldTypeText   = "text"
+	FieldTypeHidden = "hidden"
+	FieldTypeURL    = "url"
+)
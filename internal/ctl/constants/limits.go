@@ -1,10 +1,22 @@
 package constants
 
 const (
+	// MaxFileSize bounds binary secrets kept inline, larger ones are kept in an attachment
 	MaxFileSize         = 2 * 1024 * 1024 // 2 MB
 	MaxTextSize         = 1 * 1024 * 1024 // 1 MB
 	MaxCardHolderLength = 100
 	MaxUsernameLength   = 255
 	MaxPasswordLength   = 1024
 	MaxURLLength        = 2048
+	MaxTagLength        = 64
+	MaxFieldLabelLength = 100
+	MaxFieldValueLength = 4096
+	MaxCustomFields     = 64
+	// MaxAttachmentSize keeps attachments small enough to be held in memory
+	// whole, as the vault, backups and bundles do. The server enforces its
+	// own per-user quota
+	MaxAttachmentSize       = 128 * 1024 * 1024 // 128 MB
+	MaxAttachmentNameLength = 255
+	// AttachmentChunkSize is the plain size of the chunks attachments are sealed and synced in
+	AttachmentChunkSize = 256 * 1024 // 256 KB
 )
@@ -0,0 +1,11 @@
+package constants
+
+const (
+	// ManifestSecretID is the reserved remote record holding the sealed sync manifest.
+	ManifestSecretID = "go-keeper-manifest"
+
+	// ServerSyncTarget identifies the gRPC server among sync targets.
+	ServerSyncTarget = "server"
+	// BundleSyncTargetPrefix prefixes the ID of a sync bundle to identify it among sync targets.
+	BundleSyncTargetPrefix = "bundle:"
+)
@@ -0,0 +1,60 @@
+package crypto
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+)
+
+const (
+	secretFormatMagic   = "GKS"
+	secretFormatVersion = 2
+
+	secretHeaderSize = len(secretFormatMagic) + 1
+	secretADLabel    = "go-keeper/secret"
+)
+
+var ErrSecretBindingMismatch = errs.NewCorruptError(errors.New("ciphertext does not belong to this secret record"))
+
+// SecretBinding identifies the remote record a secret ciphertext was sealed for.
+// It is authenticated as associated data, so a blob moved to another record
+// or replayed under a different timestamp fails to decrypt.
+type SecretBinding struct {
+	SecretID     string
+	LastModified time.Time
+}
+
+func (b SecretBinding) associatedData(version byte) []byte {
+	return b.labeledAssociatedData(secretADLabel, version)
+}
+
+// streamAssociatedData differs from associatedData, so a sealed secret never
+// opens as a stream chunk or the other way round.
+func (b SecretBinding) streamAssociatedData() []byte {
+	return b.labeledAssociatedData(streamADLabel, streamFormatVersion)
+}
+
+func (b SecretBinding) labeledAssociatedData(label string, version byte) []byte {
+	ad := make([]byte, 0, len(label)+1+8+len(b.SecretID))
+	ad = append(ad, label...)
+	ad = append(ad, version)
+	ad = binary.BigEndian.AppendUint64(ad, uint64(b.LastModified.UTC().UnixMicro()))
+	ad = append(ad, b.SecretID...)
+	return ad
+}
+
+func secretHeader(version byte) []byte {
+	header := make([]byte, 0, secretHeaderSize)
+	header = append(header, secretFormatMagic...)
+	return append(header, version)
+}
+
+// IsBoundSecretData reports whether the ciphertext was sealed bound to its
+// record. Blobs uploaded by older clients were not.
+func IsBoundSecretData(data []byte) bool {
+	return len(data) > secretHeaderSize &&
+		string(data[:len(secretFormatMagic)]) == secretFormatMagic &&
+		data[len(secretFormatMagic)] == secretFormatVersion
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/zeebo/blake3"
 	"golang.org/x/crypto/argon2"
@@ -14,12 +15,16 @@ import (
 const (
 	storageSaltSize = 16
 	keySize         = chacha20poly1305.KeySize
+
+	dataHashPrefix  = "v2:"
+	dataHashContext = "go-keeper content hash v2"
 )
 
 type CryptorImpl struct {
 	masterPassword string
 	login          string
 	cachedKeys     map[string][]byte
+	hashKey        []byte
 }
 
 func NewCryptor(masterPassword, login string) Cryptor {
@@ -57,6 +62,18 @@ func (c *CryptorImpl) getSecretsKey() []byte {
 	return c.getDeriveKey(salt)
 }
 
+func (c *CryptorImpl) getHashKey() []byte {
+	if c.hashKey != nil {
+		return c.hashKey
+	}
+
+	key := make([]byte, keySize)
+	blake3.DeriveKey(dataHashContext, c.getSecretsKey(), key)
+
+	c.hashKey = key
+	return key
+}
+
 func (c *CryptorImpl) getServerKey() []byte {
 	salt := []byte(c.login + "|server")
 	return c.genDeriveKey(salt)
@@ -70,7 +87,7 @@ func (c *CryptorImpl) EncryptStorageData(plainData []byte) ([]byte, error) {
 	}
 
 	key := c.genDeriveKey(salt)
-	encrypted, err := c.encryptWithKey(plainData, key)
+	encrypted, err := c.encryptWithKey(plainData, key, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -87,30 +104,67 @@ func (c *CryptorImpl) DecryptStorageData(encryptedData []byte) ([]byte, error) {
 	ciphertext := encryptedData[storageSaltSize:]
 
 	key := c.genDeriveKey(salt)
-	return c.decryptWithKey(ciphertext, key)
+	return c.decryptWithKey(ciphertext, key, nil)
 }
 
-func (c *CryptorImpl) EncryptSecretData(plainData []byte) ([]byte, error) {
+func (c *CryptorImpl) EncryptSecretData(plainData []byte, binding SecretBinding) ([]byte, error) {
 	key := c.getSecretsKey()
-	return c.encryptWithKey(plainData, key)
+
+	encrypted, err := c.encryptWithKey(plainData, key, binding.associatedData(secretFormatVersion))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(secretHeader(secretFormatVersion), encrypted...), nil
 }
 
-func (c *CryptorImpl) DecryptSecretData(encryptedData []byte) ([]byte, error) {
-	key := c.getSecretsKey()
-	return c.decryptWithKey(encryptedData, key)
+// DecryptSecretData opens a ciphertext sealed by EncryptSecretData for the
+// record. Legacy blobs without the binding header are refused, see
+// DecryptLegacySecretData.
+func (c *CryptorImpl) DecryptSecretData(encryptedData []byte, binding SecretBinding) ([]byte, error) {
+	if !IsBoundSecretData(encryptedData) {
+		return nil, fmt.Errorf("%w: ciphertext is not bound to its record", ErrSecretBindingMismatch)
+	}
+
+	plainData, err := c.decryptWithKey(encryptedData[secretHeaderSize:], c.getSecretsKey(), binding.associatedData(secretFormatVersion))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSecretBindingMismatch, err)
+	}
+
+	return plainData, nil
 }
 
-func (c *CryptorImpl) CalculateDataHash(encryptedData []byte) string {
-	hash := blake3.Sum256(encryptedData)
+// DecryptLegacySecretData opens a blob sealed without associated data, before
+// ciphertexts were bound to their record. It is only meant for re-sealing
+// such blobs, as nothing ties them to the record they are served under.
+func (c *CryptorImpl) DecryptLegacySecretData(encryptedData []byte) ([]byte, error) {
+	return c.decryptWithKey(encryptedData, c.getSecretsKey(), nil)
+}
+
+func (c *CryptorImpl) CalculateDataHash(data []byte) string {
+	// NOTE: NewKeyed fails only for keys of the wrong size
+	hasher, _ := blake3.NewKeyed(c.getHashKey())
+	_, _ = hasher.Write(data)
+	return dataHashPrefix + base64.StdEncoding.EncodeToString(hasher.Sum(nil))
+}
+
+func (c *CryptorImpl) CalculateLegacyDataHash(data []byte) string {
+	hash := blake3.Sum256(data)
 	return base64.StdEncoding.EncodeToString(hash[:])
 }
 
+// IsLegacyDataHash reports whether hash was produced by the unkeyed format
+// used before content hashes were versioned.
+func IsLegacyDataHash(hash string) bool {
+	return !strings.HasPrefix(hash, dataHashPrefix)
+}
+
 func (c *CryptorImpl) GenerateServerPassword() string {
 	key := c.getServerKey()
 	return base64.StdEncoding.EncodeToString(key)
 }
 
-func (c *CryptorImpl) encryptWithKey(plainData, key []byte) ([]byte, error) {
+func (c *CryptorImpl) encryptWithKey(plainData, key, associatedData []byte) ([]byte, error) {
 	aead, err := chacha20poly1305.NewX(key)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create AEAD: %w", err)
@@ -121,11 +175,11 @@ func (c *CryptorImpl) encryptWithKey(plainData, key []byte) ([]byte, error) {
 		return nil, fmt.Errorf("failed to generate nonce: %w", err)
 	}
 
-	encrypted := aead.Seal(nonce, nonce, plainData, nil)
+	encrypted := aead.Seal(nonce, nonce, plainData, associatedData)
 	return encrypted, nil
 }
 
-func (c *CryptorImpl) decryptWithKey(encryptedData, key []byte) ([]byte, error) {
+func (c *CryptorImpl) decryptWithKey(encryptedData, key, associatedData []byte) ([]byte, error) {
 	aead, err := chacha20poly1305.NewX(key)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create AEAD: %w", err)
@@ -137,7 +191,7 @@ func (c *CryptorImpl) decryptWithKey(encryptedData, key []byte) ([]byte, error)
 	}
 
 	nonce, ciphertext := encryptedData[:nonceSize], encryptedData[nonceSize:]
-	plainData, err := aead.Open(nil, nonce, ciphertext, nil)
+	plainData, err := aead.Open(nil, nonce, ciphertext, associatedData)
 	if err != nil {
 		return nil, fmt.Errorf("decryption failed: %w", err)
 	}
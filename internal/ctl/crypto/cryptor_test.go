@@ -2,6 +2,7 @@ package crypto
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -9,6 +10,10 @@ import (
 
 func TestCryptorImpl(t *testing.T) {
 	cryptor := NewCryptor("masterpass", "testuser")
+	binding := SecretBinding{
+		SecretID:     "secret-1",
+		LastModified: time.Date(2025, 1, 2, 3, 4, 5, 6000, time.UTC),
+	}
 
 	t.Run("EncryptStorageData and DecryptStorageData", func(t *testing.T) {
 		plainData := []byte("test data for storage")
@@ -28,18 +33,96 @@ func TestCryptorImpl(t *testing.T) {
 		require.Error(t, err)
 	})
 
+	t.Run("StorageSealer", func(t *testing.T) {
+		salt, err := NewStorageSalt()
+		require.NoError(t, err)
+
+		sealer, err := cryptor.StorageSealer(salt)
+		require.NoError(t, err)
+
+		plainData := []byte("page data")
+		sealed, err := sealer.Seal(plainData, []byte("page-1"))
+		require.NoError(t, err)
+		assert.Len(t, sealed, len(plainData)+sealer.Overhead())
+
+		opened, err := sealer.Open(sealed, []byte("page-1"))
+		require.NoError(t, err)
+		assert.Equal(t, plainData, opened)
+
+		_, err = sealer.Open(sealed, []byte("page-2"))
+		require.Error(t, err, "associated data must match")
+
+		sameKey, err := cryptor.StorageSealer(salt)
+		require.NoError(t, err)
+		opened, err = sameKey.Open(sealed, []byte("page-1"))
+		require.NoError(t, err)
+		assert.Equal(t, plainData, opened)
+
+		_, err = cryptor.StorageSealer([]byte("short"))
+		require.Error(t, err)
+	})
+
 	t.Run("EncryptSecretData and DecryptSecretData", func(t *testing.T) {
 		plainData := []byte("secret data")
 
-		encrypted, err := cryptor.EncryptSecretData(plainData)
+		encrypted, err := cryptor.EncryptSecretData(plainData, binding)
 		require.NoError(t, err)
 		require.NotEmpty(t, encrypted)
 
-		decrypted, err := cryptor.DecryptSecretData(encrypted)
+		decrypted, err := cryptor.DecryptSecretData(encrypted, binding)
+		require.NoError(t, err)
+		assert.Equal(t, plainData, decrypted)
+	})
+
+	t.Run("DecryptSecretData with other secret ID fails", func(t *testing.T) {
+		encrypted, err := cryptor.EncryptSecretData([]byte("secret data"), binding)
+		require.NoError(t, err)
+
+		otherBinding := binding
+		otherBinding.SecretID = "secret-2"
+
+		_, err = cryptor.DecryptSecretData(encrypted, otherBinding)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrSecretBindingMismatch)
+	})
+
+	t.Run("DecryptSecretData with other last modified fails", func(t *testing.T) {
+		encrypted, err := cryptor.EncryptSecretData([]byte("secret data"), binding)
+		require.NoError(t, err)
+
+		otherBinding := binding
+		otherBinding.LastModified = binding.LastModified.Add(time.Second)
+
+		_, err = cryptor.DecryptSecretData(encrypted, otherBinding)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrSecretBindingMismatch)
+	})
+
+	t.Run("DecryptSecretData refuses legacy data", func(t *testing.T) {
+		impl := cryptor.(*CryptorImpl)
+		plainData := []byte("legacy secret data")
+
+		encrypted, err := impl.encryptWithKey(plainData, impl.getSecretsKey(), nil)
+		require.NoError(t, err)
+		assert.False(t, IsBoundSecretData(encrypted))
+
+		_, err = cryptor.DecryptSecretData(encrypted, binding)
+		assert.ErrorIs(t, err, ErrSecretBindingMismatch)
+
+		decrypted, err := cryptor.DecryptLegacySecretData(encrypted)
 		require.NoError(t, err)
 		assert.Equal(t, plainData, decrypted)
 	})
 
+	t.Run("DecryptSecretData with stripped header fails", func(t *testing.T) {
+		encrypted, err := cryptor.EncryptSecretData([]byte("secret data"), binding)
+		require.NoError(t, err)
+		assert.True(t, IsBoundSecretData(encrypted))
+
+		_, err = cryptor.DecryptLegacySecretData(encrypted[secretHeaderSize:])
+		require.Error(t, err)
+	})
+
 	t.Run("CalculateDataHash", func(t *testing.T) {
 		data := []byte("test data")
 		hash1 := cryptor.CalculateDataHash(data)
@@ -52,6 +135,21 @@ func TestCryptorImpl(t *testing.T) {
 		assert.NotEqual(t, hash1, hash3)
 	})
 
+	t.Run("CalculateDataHash is keyed and versioned", func(t *testing.T) {
+		data := []byte("test data")
+
+		hash := cryptor.CalculateDataHash(data)
+		legacyHash := cryptor.CalculateLegacyDataHash(data)
+
+		assert.False(t, IsLegacyDataHash(hash))
+		assert.True(t, IsLegacyDataHash(legacyHash))
+		assert.NotEqual(t, legacyHash, hash)
+
+		otherCryptor := NewCryptor("otherpass", "testuser")
+		assert.NotEqual(t, hash, otherCryptor.CalculateDataHash(data))
+		assert.Equal(t, legacyHash, otherCryptor.CalculateLegacyDataHash(data))
+	})
+
 	t.Run("GenerateServerPassword", func(t *testing.T) {
 		password1 := cryptor.GenerateServerPassword()
 		password2 := cryptor.GenerateServerPassword()
@@ -83,19 +181,19 @@ func TestCryptorImpl(t *testing.T) {
 	t.Run("secret data different nonces", func(t *testing.T) {
 		plainData := []byte("same secret data")
 
-		encrypted1, err := cryptor.EncryptSecretData(plainData)
+		encrypted1, err := cryptor.EncryptSecretData(plainData, binding)
 		require.NoError(t, err)
 
-		encrypted2, err := cryptor.EncryptSecretData(plainData)
+		encrypted2, err := cryptor.EncryptSecretData(plainData, binding)
 		require.NoError(t, err)
 
 		assert.NotEqual(t, encrypted1, encrypted2)
 
-		decrypted1, err := cryptor.DecryptSecretData(encrypted1)
+		decrypted1, err := cryptor.DecryptSecretData(encrypted1, binding)
 		require.NoError(t, err)
 		assert.Equal(t, plainData, decrypted1)
 
-		decrypted2, err := cryptor.DecryptSecretData(encrypted2)
+		decrypted2, err := cryptor.DecryptSecretData(encrypted2, binding)
 		require.NoError(t, err)
 		assert.Equal(t, plainData, decrypted2)
 	})
@@ -109,4 +207,102 @@ func TestCryptorImpl(t *testing.T) {
 		_, err = wrongCryptor.DecryptStorageData(encrypted)
 		require.Error(t, err)
 	})
+
+	t.Run("stream chunks", func(t *testing.T) {
+		sealer, err := cryptor.NewStreamSealer(binding, "content-hash")
+		require.NoError(t, err)
+
+		chunks := [][]byte{[]byte("first"), []byte("second"), []byte("last")}
+		sealed := make([][]byte, len(chunks))
+		for i, chunk := range chunks {
+			sealed[i], err = sealer.Seal(i, chunk, i == len(chunks)-1)
+			require.NoError(t, err)
+			assert.Len(t, sealed[i], len(chunk)+sealer.Overhead())
+		}
+
+		opener, err := cryptor.NewStreamOpener(binding, sealer.Header())
+		require.NoError(t, err)
+		for i, chunk := range chunks {
+			opened, err := opener.Open(i, sealed[i], i == len(chunks)-1)
+			require.NoError(t, err)
+			assert.Equal(t, chunk, opened)
+		}
+
+		_, err = opener.Open(0, sealed[1], false)
+		assert.ErrorIs(t, err, ErrSecretBindingMismatch, "reordered")
+		_, err = opener.Open(1, sealed[1], true)
+		assert.ErrorIs(t, err, ErrSecretBindingMismatch, "cut off")
+		_, err = opener.Open(2, sealed[2], false)
+		assert.ErrorIs(t, err, ErrSecretBindingMismatch, "extended")
+
+		otherRecord, err := cryptor.NewStreamOpener(SecretBinding{SecretID: "secret-2", LastModified: binding.LastModified}, sealer.Header())
+		require.NoError(t, err)
+		_, err = otherRecord.Open(0, sealed[0], false)
+		assert.ErrorIs(t, err, ErrSecretBindingMismatch, "moved to another record")
+
+		_, err = cryptor.NewStreamOpener(binding, []byte("GKT"))
+		require.Error(t, err)
+	})
+
+	t.Run("stream sealed again gives the same chunks", func(t *testing.T) {
+		first, err := cryptor.NewStreamSealer(binding, "content-hash")
+		require.NoError(t, err)
+		again, err := cryptor.NewStreamSealer(binding, "content-hash")
+		require.NoError(t, err)
+		other, err := cryptor.NewStreamSealer(binding, "other-hash")
+		require.NoError(t, err)
+
+		assert.Equal(t, first.Header(), again.Header())
+		assert.NotEqual(t, first.Header(), other.Header())
+
+		sealed, err := first.Seal(3, []byte("chunk"), false)
+		require.NoError(t, err)
+		resealed, err := again.Seal(3, []byte("chunk"), false)
+		require.NoError(t, err)
+		assert.Equal(t, sealed, resealed)
+	})
+}
+
+func TestPassphraseEncryption(t *testing.T) {
+	plainData := []byte("whole vault backup")
+
+	encrypted, err := EncryptWithPassphrase(plainData, "backup passphrase")
+	require.NoError(t, err)
+	assert.True(t, IsPassphraseEncrypted(encrypted))
+	assert.Equal(t, passphraseMagic, string(encrypted[:len(passphraseMagic)]))
+
+	t.Run("round trip", func(t *testing.T) {
+		decrypted, err := DecryptWithPassphrase(encrypted, "backup passphrase")
+		require.NoError(t, err)
+		assert.Equal(t, plainData, decrypted)
+	})
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		_, err := DecryptWithPassphrase(encrypted, "other passphrase")
+		assert.ErrorIs(t, err, ErrWrongPassphrase)
+	})
+
+	t.Run("altered header", func(t *testing.T) {
+		altered := append([]byte(nil), encrypted...)
+		altered[passphraseHeaderSize-1] ^= 0xff
+		_, err := DecryptWithPassphrase(altered, "backup passphrase")
+		assert.ErrorIs(t, err, ErrWrongPassphrase)
+	})
+
+	t.Run("oversized parameters are refused", func(t *testing.T) {
+		altered := append([]byte(nil), encrypted...)
+		altered[len(passphraseMagic)+5] = 0xff
+		_, err := DecryptWithPassphrase(altered, "backup passphrase")
+		assert.ErrorContains(t, err, "unsupported key derivation parameters")
+	})
+
+	t.Run("other data", func(t *testing.T) {
+		_, err := DecryptWithPassphrase([]byte("plain text"), "backup passphrase")
+		assert.ErrorIs(t, err, ErrNotPassphraseEncrypted)
+	})
+
+	t.Run("empty passphrase", func(t *testing.T) {
+		_, err := EncryptWithPassphrase(plainData, "")
+		assert.Error(t, err)
+	})
 }
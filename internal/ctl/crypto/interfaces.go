@@ -4,10 +4,26 @@ type Cryptor interface {
 	EncryptStorageData(plainData []byte) ([]byte, error)
 	DecryptStorageData(encryptedData []byte) ([]byte, error)
 
-	EncryptSecretData(plainData []byte) ([]byte, error)
-	DecryptSecretData(encryptedData []byte) ([]byte, error)
+	StorageSealer(salt []byte) (Sealer, error)
+
+	EncryptSecretData(plainData []byte, binding SecretBinding) ([]byte, error)
+	DecryptSecretData(encryptedData []byte, binding SecretBinding) ([]byte, error)
+	DecryptLegacySecretData(encryptedData []byte) ([]byte, error)
+
+	NewStreamSealer(binding SecretBinding, seed string) (StreamSealer, error)
+	NewStreamOpener(binding SecretBinding, header []byte) (StreamOpener, error)
 
 	CalculateDataHash(data []byte) string
+	CalculateLegacyDataHash(data []byte) string
 
 	GenerateServerPassword() string
 }
+
+// Sealer encrypts blocks under a key derived once, so callers sealing many
+// small blocks do not pay for a key derivation each time.
+type Sealer interface {
+	Seal(plainData, associatedData []byte) ([]byte, error)
+	Open(sealedData, associatedData []byte) ([]byte, error)
+	// Overhead is the number of bytes Seal adds to the plain data.
+	Overhead() int
+}
@@ -47,19 +47,48 @@ func (mr *MockCryptorMockRecorder) CalculateDataHash(data interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalculateDataHash", reflect.TypeOf((*MockCryptor)(nil).CalculateDataHash), data)
 }
 
+// CalculateLegacyDataHash mocks base method.
+func (m *MockCryptor) CalculateLegacyDataHash(data []byte) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CalculateLegacyDataHash", data)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// CalculateLegacyDataHash indicates an expected call of CalculateLegacyDataHash.
+func (mr *MockCryptorMockRecorder) CalculateLegacyDataHash(data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalculateLegacyDataHash", reflect.TypeOf((*MockCryptor)(nil).CalculateLegacyDataHash), data)
+}
+
+// DecryptLegacySecretData mocks base method.
+func (m *MockCryptor) DecryptLegacySecretData(encryptedData []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecryptLegacySecretData", encryptedData)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecryptLegacySecretData indicates an expected call of DecryptLegacySecretData.
+func (mr *MockCryptorMockRecorder) DecryptLegacySecretData(encryptedData interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecryptLegacySecretData", reflect.TypeOf((*MockCryptor)(nil).DecryptLegacySecretData), encryptedData)
+}
+
 // DecryptSecretData mocks base method.
-func (m *MockCryptor) DecryptSecretData(encryptedData []byte) ([]byte, error) {
+func (m *MockCryptor) DecryptSecretData(encryptedData []byte, binding SecretBinding) ([]byte, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DecryptSecretData", encryptedData)
+	ret := m.ctrl.Call(m, "DecryptSecretData", encryptedData, binding)
 	ret0, _ := ret[0].([]byte)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DecryptSecretData indicates an expected call of DecryptSecretData.
-func (mr *MockCryptorMockRecorder) DecryptSecretData(encryptedData interface{}) *gomock.Call {
+func (mr *MockCryptorMockRecorder) DecryptSecretData(encryptedData, binding interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecryptSecretData", reflect.TypeOf((*MockCryptor)(nil).DecryptSecretData), encryptedData)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecryptSecretData", reflect.TypeOf((*MockCryptor)(nil).DecryptSecretData), encryptedData, binding)
 }
 
 // DecryptStorageData mocks base method.
@@ -78,18 +107,18 @@ func (mr *MockCryptorMockRecorder) DecryptStorageData(encryptedData interface{})
 }
 
 // EncryptSecretData mocks base method.
-func (m *MockCryptor) EncryptSecretData(plainData []byte) ([]byte, error) {
+func (m *MockCryptor) EncryptSecretData(plainData []byte, binding SecretBinding) ([]byte, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "EncryptSecretData", plainData)
+	ret := m.ctrl.Call(m, "EncryptSecretData", plainData, binding)
 	ret0, _ := ret[0].([]byte)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // EncryptSecretData indicates an expected call of EncryptSecretData.
-func (mr *MockCryptorMockRecorder) EncryptSecretData(plainData interface{}) *gomock.Call {
+func (mr *MockCryptorMockRecorder) EncryptSecretData(plainData, binding interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EncryptSecretData", reflect.TypeOf((*MockCryptor)(nil).EncryptSecretData), plainData)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EncryptSecretData", reflect.TypeOf((*MockCryptor)(nil).EncryptSecretData), plainData, binding)
 }
 
 // EncryptStorageData mocks base method.
@@ -120,3 +149,115 @@ func (mr *MockCryptorMockRecorder) GenerateServerPassword() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateServerPassword", reflect.TypeOf((*MockCryptor)(nil).GenerateServerPassword))
 }
+
+// NewStreamOpener mocks base method.
+func (m *MockCryptor) NewStreamOpener(binding SecretBinding, header []byte) (StreamOpener, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewStreamOpener", binding, header)
+	ret0, _ := ret[0].(StreamOpener)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewStreamOpener indicates an expected call of NewStreamOpener.
+func (mr *MockCryptorMockRecorder) NewStreamOpener(binding, header interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewStreamOpener", reflect.TypeOf((*MockCryptor)(nil).NewStreamOpener), binding, header)
+}
+
+// NewStreamSealer mocks base method.
+func (m *MockCryptor) NewStreamSealer(binding SecretBinding, seed string) (StreamSealer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewStreamSealer", binding, seed)
+	ret0, _ := ret[0].(StreamSealer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewStreamSealer indicates an expected call of NewStreamSealer.
+func (mr *MockCryptorMockRecorder) NewStreamSealer(binding, seed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewStreamSealer", reflect.TypeOf((*MockCryptor)(nil).NewStreamSealer), binding, seed)
+}
+
+// StorageSealer mocks base method.
+func (m *MockCryptor) StorageSealer(salt []byte) (Sealer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StorageSealer", salt)
+	ret0, _ := ret[0].(Sealer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StorageSealer indicates an expected call of StorageSealer.
+func (mr *MockCryptorMockRecorder) StorageSealer(salt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StorageSealer", reflect.TypeOf((*MockCryptor)(nil).StorageSealer), salt)
+}
+
+// MockSealer is a mock of Sealer interface.
+type MockSealer struct {
+	ctrl     *gomock.Controller
+	recorder *MockSealerMockRecorder
+}
+
+// MockSealerMockRecorder is the mock recorder for MockSealer.
+type MockSealerMockRecorder struct {
+	mock *MockSealer
+}
+
+// NewMockSealer creates a new mock instance.
+func NewMockSealer(ctrl *gomock.Controller) *MockSealer {
+	mock := &MockSealer{ctrl: ctrl}
+	mock.recorder = &MockSealerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSealer) EXPECT() *MockSealerMockRecorder {
+	return m.recorder
+}
+
+// Open mocks base method.
+func (m *MockSealer) Open(sealedData, associatedData []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", sealedData, associatedData)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockSealerMockRecorder) Open(sealedData, associatedData interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockSealer)(nil).Open), sealedData, associatedData)
+}
+
+// Overhead mocks base method.
+func (m *MockSealer) Overhead() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Overhead")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// Overhead indicates an expected call of Overhead.
+func (mr *MockSealerMockRecorder) Overhead() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Overhead", reflect.TypeOf((*MockSealer)(nil).Overhead))
+}
+
+// Seal mocks base method.
+func (m *MockSealer) Seal(plainData, associatedData []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Seal", plainData, associatedData)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Seal indicates an expected call of Seal.
+func (mr *MockSealerMockRecorder) Seal(plainData, associatedData interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Seal", reflect.TypeOf((*MockSealer)(nil).Seal), plainData, associatedData)
+}
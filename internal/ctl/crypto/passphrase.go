@@ -0,0 +1,117 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Passphrase-encrypted data, independent of the master password and login:
+//
+//	magic "GKPE" | version (1 byte) | argon2 time (4) | argon2 memory KiB (4) |
+//	argon2 threads (1) | salt (16) | nonce (24) | ciphertext and tag
+//
+// Integers are big endian. The key is Argon2id of the passphrase and salt
+// with the parameters from the header, and the whole header is the associated
+// data of XChaCha20-Poly1305, so none of it can be altered unnoticed.
+const (
+	passphraseMagic   = "GKPE"
+	passphraseVersion = 1
+
+	passphraseTime    = 3
+	passphraseMemory  = 64 * 1024
+	passphraseThreads = 4
+
+	// NOTE: Bounds on parameters read from a header, so a crafted file
+	// cannot make decryption run out of memory or spin for hours
+	passphraseMaxTime   = 16
+	passphraseMaxMemory = 1024 * 1024
+
+	passphraseSaltSize   = 16
+	passphraseHeaderSize = len(passphraseMagic) + 1 + 4 + 4 + 1 + passphraseSaltSize + chacha20poly1305.NonceSizeX
+)
+
+var (
+	// ErrNotPassphraseEncrypted is returned for data in another format.
+	ErrNotPassphraseEncrypted = errs.NewValidationError(errors.New("not passphrase encrypted data"))
+	// ErrWrongPassphrase is returned when decryption fails, either for a
+	// wrong passphrase or for altered data.
+	ErrWrongPassphrase = errs.NewAuthError(errors.New("wrong passphrase or corrupted data"))
+)
+
+// IsPassphraseEncrypted reports whether data starts with a passphrase header.
+func IsPassphraseEncrypted(data []byte) bool {
+	return len(data) >= passphraseHeaderSize && string(data[:len(passphraseMagic)]) == passphraseMagic
+}
+
+// EncryptWithPassphrase encrypts plainData under a key derived from passphrase.
+func EncryptWithPassphrase(plainData []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase must not be empty")
+	}
+
+	random := make([]byte, passphraseSaltSize+chacha20poly1305.NonceSizeX)
+	if _, err := rand.Read(random); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	salt, nonce := random[:passphraseSaltSize], random[passphraseSaltSize:]
+
+	var header bytes.Buffer
+	header.WriteString(passphraseMagic)
+	header.WriteByte(passphraseVersion)
+	_ = binary.Write(&header, binary.BigEndian, uint32(passphraseTime))
+	_ = binary.Write(&header, binary.BigEndian, uint32(passphraseMemory))
+	header.WriteByte(passphraseThreads)
+	header.Write(salt)
+	header.Write(nonce)
+
+	key := argon2.IDKey([]byte(passphrase), salt, passphraseTime, passphraseMemory, passphraseThreads, keySize)
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AEAD: %w", err)
+	}
+
+	return aead.Seal(header.Bytes(), nonce, plainData, header.Bytes()), nil
+}
+
+// DecryptWithPassphrase reverses EncryptWithPassphrase.
+func DecryptWithPassphrase(encryptedData []byte, passphrase string) ([]byte, error) {
+	if !IsPassphraseEncrypted(encryptedData) {
+		return nil, ErrNotPassphraseEncrypted
+	}
+
+	header := encryptedData[:passphraseHeaderSize]
+	if version := header[len(passphraseMagic)]; version != passphraseVersion {
+		return nil, fmt.Errorf("unsupported passphrase encryption version %d", version)
+	}
+
+	params := header[len(passphraseMagic)+1:]
+	time := binary.BigEndian.Uint32(params[0:4])
+	memory := binary.BigEndian.Uint32(params[4:8])
+	threads := params[8]
+	salt := params[9 : 9+passphraseSaltSize]
+	nonce := params[9+passphraseSaltSize:]
+
+	if time == 0 || time > passphraseMaxTime || memory == 0 || memory > passphraseMaxMemory || threads == 0 {
+		return nil, fmt.Errorf("unsupported key derivation parameters t=%d m=%d p=%d", time, memory, threads)
+	}
+
+	key := argon2.IDKey([]byte(passphrase), salt, time, memory, threads, keySize)
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AEAD: %w", err)
+	}
+
+	plainData, err := aead.Open(nil, nonce, encryptedData[passphraseHeaderSize:], header)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	return plainData, nil
+}
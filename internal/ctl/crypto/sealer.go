@@ -0,0 +1,70 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// StorageSaltSize is the size of the salt the storage key is derived from.
+const StorageSaltSize = storageSaltSize
+
+// sealer holds an AEAD built from a storage key derived once per session.
+type sealer struct {
+	aead cipher.AEAD
+}
+
+// StorageSealer derives the storage key for salt once and returns a Sealer
+// for encrypting many blocks under it.
+func (c *CryptorImpl) StorageSealer(salt []byte) (Sealer, error) {
+	if len(salt) != storageSaltSize {
+		return nil, fmt.Errorf("invalid storage salt size %d", len(salt))
+	}
+
+	aead, err := chacha20poly1305.NewX(c.getDeriveKey(salt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AEAD: %w", err)
+	}
+
+	return &sealer{aead: aead}, nil
+}
+
+// NewStorageSalt returns a random salt for a new storage key.
+func NewStorageSalt() ([]byte, error) {
+	salt := make([]byte, storageSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+	return salt, nil
+}
+
+func (s *sealer) Seal(plainData, associatedData []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(plainData)+s.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return s.aead.Seal(nonce, nonce, plainData, associatedData), nil
+}
+
+func (s *sealer) Open(sealedData, associatedData []byte) ([]byte, error) {
+	nonceSize := s.aead.NonceSize()
+	if len(sealedData) < nonceSize {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+
+	nonce, ciphertext := sealedData[:nonceSize], sealedData[nonceSize:]
+	plainData, err := s.aead.Open(nil, nonce, ciphertext, associatedData)
+	if err != nil {
+		return nil, fmt.Errorf("decryption failed: %w", err)
+	}
+
+	return plainData, nil
+}
+
+func (s *sealer) Overhead() int {
+	return s.aead.NonceSize() + s.aead.Overhead()
+}
@@ -0,0 +1,130 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/zeebo/blake3"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	streamFormatMagic   = "GKT"
+	streamFormatVersion = 1
+
+	// streamPrefixSize leaves room in the nonce for the chunk index and the last chunk flag
+	streamPrefixSize = chacha20poly1305.NonceSizeX - 8 - 1
+	streamHeaderSize = len(streamFormatMagic) + 1 + streamPrefixSize
+	streamADLabel    = "go-keeper/stream"
+	streamSeedLabel  = "go-keeper stream nonce v1"
+)
+
+// StreamSealer seals a stream chunk by chunk in the STREAM construction:
+// every chunk nonce holds the stream prefix, the chunk index and whether the
+// chunk is the last one, so chunks cannot be reordered, dropped, duplicated
+// or cut off without Open failing.
+type StreamSealer interface {
+	// Header is stored with the stream, StreamOpener needs it.
+	Header() []byte
+	Seal(index int, plainChunk []byte, last bool) ([]byte, error)
+	// Overhead is the number of bytes Seal adds to every chunk.
+	Overhead() int
+}
+
+type StreamOpener interface {
+	Open(index int, sealedChunk []byte, last bool) ([]byte, error)
+}
+
+type stream struct {
+	aead   cipher.AEAD
+	prefix []byte
+	ad     []byte
+}
+
+// NewStreamSealer returns a sealer for a stream bound to the record. The
+// nonce prefix is derived from the binding and seed, which must identify the
+// plain data, so a stream sealed again, as a resumed upload does, gives the
+// same chunks rather than reusing nonces for other data.
+func (c *CryptorImpl) NewStreamSealer(binding SecretBinding, seed string) (StreamSealer, error) {
+	ad := binding.streamAssociatedData()
+
+	seedKey := make([]byte, keySize)
+	blake3.DeriveKey(streamSeedLabel, c.getSecretsKey(), seedKey)
+
+	// NOTE: NewKeyed fails only for keys of the wrong size
+	hasher, _ := blake3.NewKeyed(seedKey)
+	_, _ = hasher.Write(ad)
+	_, _ = hasher.Write([]byte(seed))
+	prefix := hasher.Sum(nil)[:streamPrefixSize]
+
+	return c.newStream(prefix, ad)
+}
+
+// NewStreamOpener returns an opener for a stream sealed for the record.
+func (c *CryptorImpl) NewStreamOpener(binding SecretBinding, header []byte) (StreamOpener, error) {
+	if len(header) != streamHeaderSize ||
+		string(header[:len(streamFormatMagic)]) != streamFormatMagic ||
+		header[len(streamFormatMagic)] != streamFormatVersion {
+		return nil, fmt.Errorf("invalid stream header")
+	}
+
+	return c.newStream(header[len(streamFormatMagic)+1:], binding.streamAssociatedData())
+}
+
+func (c *CryptorImpl) newStream(prefix, ad []byte) (*stream, error) {
+	aead, err := chacha20poly1305.NewX(c.getSecretsKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AEAD: %w", err)
+	}
+
+	return &stream{aead: aead, prefix: prefix, ad: ad}, nil
+}
+
+func (s *stream) Header() []byte {
+	header := make([]byte, 0, streamHeaderSize)
+	header = append(header, streamFormatMagic...)
+	header = append(header, streamFormatVersion)
+	return append(header, s.prefix...)
+}
+
+func (s *stream) Seal(index int, plainChunk []byte, last bool) ([]byte, error) {
+	nonce, err := s.nonce(index, last)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.aead.Seal(nil, nonce, plainChunk, s.ad), nil
+}
+
+func (s *stream) Open(index int, sealedChunk []byte, last bool) ([]byte, error) {
+	nonce, err := s.nonce(index, last)
+	if err != nil {
+		return nil, err
+	}
+
+	plainChunk, err := s.aead.Open(nil, nonce, sealedChunk, s.ad)
+	if err != nil {
+		return nil, fmt.Errorf("%w: chunk %d: %w", ErrSecretBindingMismatch, index, err)
+	}
+
+	return plainChunk, nil
+}
+
+func (s *stream) Overhead() int {
+	return s.aead.Overhead()
+}
+
+func (s *stream) nonce(index int, last bool) ([]byte, error) {
+	if index < 0 {
+		return nil, fmt.Errorf("invalid chunk index %d", index)
+	}
+
+	nonce := make([]byte, 0, chacha20poly1305.NonceSizeX)
+	nonce = append(nonce, s.prefix...)
+	nonce = binary.BigEndian.AppendUint64(nonce, uint64(index))
+	if last {
+		return append(nonce, 1), nil
+	}
+	return append(nonce, 0), nil
+}
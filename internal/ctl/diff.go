@@ -1,6 +1,12 @@
 package ctl
 
-import "github.com/etoneja/go-keeper/internal/ctl/types"
+import (
+	"fmt"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
 
 func diffSecrets(local []*types.LocalSecret, remote []*types.RemoteSecret) *types.SecretsDiff {
 	localMap := make(map[string]*types.LocalSecret)
@@ -38,3 +44,95 @@ func diffSecrets(local []*types.LocalSecret, remote []*types.RemoteSecret) *type
 
 	return diff
 }
+
+// detectRollbacks flags remote secrets older than a version already seen, and
+// secrets that vanished from the server without a newer manifest explaining it.
+// seen is the manifest stored locally, remote is nil when the server has none.
+// Secrets in the local trash that vanished are marked purged instead, since
+// the server empties its trash on its own.
+func detectRollbacks(diff *types.SecretsDiff, seen *types.SyncManifest, remote *types.SyncManifest) {
+	diff.Rollbacks = make(map[string]string)
+	diff.Purged = make(map[string]bool)
+
+	manifestTrusted := true
+	switch {
+	case remote == nil && seen.Revision > 0:
+		diff.Rollbacks[constants.ManifestSecretID] = "sync manifest is missing on server"
+		manifestTrusted = false
+	case remote != nil && remote.Revision < seen.Revision:
+		diff.Rollbacks[constants.ManifestSecretID] = fmt.Sprintf(
+			"server returned manifest revision %d, already seen %d", remote.Revision, seen.Revision)
+		manifestTrusted = false
+	}
+
+	highWaterMark := func(secretID string) (time.Time, bool) {
+		mark, exists := seen.HighWaterMark(secretID)
+		if remote == nil {
+			return mark, exists
+		}
+		remoteMark, remoteExists := remote.HighWaterMark(secretID)
+		if remoteExists && (!exists || remoteMark.After(mark)) {
+			return remoteMark, true
+		}
+		return mark, exists
+	}
+
+	checkRemote := func(secret *types.RemoteSecret) {
+		mark, exists := highWaterMark(secret.UUID)
+		if exists && secret.LastModified.Before(mark) {
+			diff.Rollbacks[secret.UUID] = fmt.Sprintf("on server is from %s, already seen %s",
+				secret.LastModified.Local().Format(timeFormat), mark.Local().Format(timeFormat))
+		}
+	}
+
+	for _, secret := range diff.RemoteOnly {
+		checkRemote(secret)
+	}
+
+	for _, pair := range diff.Both {
+		checkRemote(pair.Remote)
+	}
+
+	for _, secret := range diff.LocalOnly {
+		if _, seenBefore := seen.Secrets[secret.UUID]; !seenBefore {
+			continue
+		}
+
+		if !manifestTrusted {
+			diff.Rollbacks[secret.UUID] = "disappeared from server without a newer manifest"
+			continue
+		}
+
+		if remote == nil {
+			continue
+		}
+
+		if secret.InTrash() {
+			diff.Purged[secret.UUID] = true
+			continue
+		}
+
+		if _, listed := remote.Secrets[secret.UUID]; listed {
+			diff.Rollbacks[secret.UUID] = "is listed in the server manifest but missing on server"
+		}
+	}
+}
+
+// markPurged marks the remote secrets in the trash that were purged locally,
+// see storage.Storager.ListPurgedSecrets.
+func markPurged(diff *types.SecretsDiff, purged []string) {
+	if diff.Purged == nil {
+		diff.Purged = make(map[string]bool)
+	}
+
+	purgedSet := make(map[string]bool, len(purged))
+	for _, secretID := range purged {
+		purgedSet[secretID] = true
+	}
+
+	for _, secret := range diff.RemoteOnly {
+		if secret.InTrash() && purgedSet[secret.UUID] {
+			diff.Purged[secret.UUID] = true
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package ctl
 
 import (
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
 	"github.com/etoneja/go-keeper/internal/ctl/types"
 	"github.com/stretchr/testify/assert"
-	"testing"
 )
 
 func TestDiffSecrets(t *testing.T) {
@@ -74,3 +77,119 @@ func TestDiffSecrets(t *testing.T) {
 		assert.Equal(t, "2", diff.Both[0].Remote.UUID)
 	})
 }
+
+func TestDetectRollbacks(t *testing.T) {
+	now := time.Now().UTC()
+	older := now.Add(-time.Hour)
+
+	newManifest := func(revision uint64, entries map[string]time.Time) *types.SyncManifest {
+		manifest := types.NewSyncManifest()
+		manifest.Revision = revision
+		for id, lastModified := range entries {
+			manifest.Observe(id, lastModified, "hash")
+		}
+		return manifest
+	}
+
+	t.Run("first sync", func(t *testing.T) {
+		diff := diffSecrets(
+			[]*types.LocalSecret{{UUID: "1", LastModified: now}},
+			[]*types.RemoteSecret{{UUID: "2", LastModified: older}},
+		)
+
+		detectRollbacks(diff, types.NewSyncManifest(), nil)
+		assert.Empty(t, diff.Rollbacks)
+	})
+
+	t.Run("remote older than seen", func(t *testing.T) {
+		diff := diffSecrets(
+			[]*types.LocalSecret{{UUID: "1", LastModified: now}},
+			[]*types.RemoteSecret{{UUID: "1", LastModified: older}},
+		)
+		seen := newManifest(1, map[string]time.Time{"1": now})
+
+		detectRollbacks(diff, seen, seen)
+		_, flagged := diff.RollbackReason("1")
+		assert.True(t, flagged)
+	})
+
+	t.Run("remote older than remote manifest", func(t *testing.T) {
+		diff := diffSecrets(nil, []*types.RemoteSecret{{UUID: "1", LastModified: older}})
+
+		detectRollbacks(diff, types.NewSyncManifest(), newManifest(1, map[string]time.Time{"1": now}))
+		_, flagged := diff.RollbackReason("1")
+		assert.True(t, flagged)
+	})
+
+	t.Run("newer remote is not flagged", func(t *testing.T) {
+		diff := diffSecrets(
+			[]*types.LocalSecret{{UUID: "1", LastModified: older}},
+			[]*types.RemoteSecret{{UUID: "1", LastModified: now}},
+		)
+		seen := newManifest(1, map[string]time.Time{"1": older})
+
+		detectRollbacks(diff, seen, seen)
+		assert.Empty(t, diff.Rollbacks)
+	})
+
+	t.Run("deletion explained by newer manifest", func(t *testing.T) {
+		diff := diffSecrets([]*types.LocalSecret{{UUID: "1", LastModified: now}}, nil)
+		seen := newManifest(1, map[string]time.Time{"1": now})
+		remote := newManifest(2, nil)
+
+		detectRollbacks(diff, seen, remote)
+		assert.Empty(t, diff.Rollbacks)
+	})
+
+	t.Run("secret hidden by server", func(t *testing.T) {
+		diff := diffSecrets([]*types.LocalSecret{{UUID: "1", LastModified: now}}, nil)
+		seen := newManifest(1, map[string]time.Time{"1": now})
+
+		detectRollbacks(diff, seen, seen)
+		_, flagged := diff.RollbackReason("1")
+		assert.True(t, flagged)
+	})
+
+	t.Run("manifest missing", func(t *testing.T) {
+		diff := diffSecrets([]*types.LocalSecret{{UUID: "1", LastModified: now}}, nil)
+		seen := newManifest(1, map[string]time.Time{"1": now})
+
+		detectRollbacks(diff, seen, nil)
+		_, flagged := diff.RollbackReason(constants.ManifestSecretID)
+		assert.True(t, flagged)
+		_, flagged = diff.RollbackReason("1")
+		assert.True(t, flagged)
+	})
+
+	t.Run("manifest rolled back", func(t *testing.T) {
+		diff := diffSecrets([]*types.LocalSecret{{UUID: "1", LastModified: now}}, nil)
+		seen := newManifest(3, map[string]time.Time{"1": now})
+		remote := newManifest(2, nil)
+
+		detectRollbacks(diff, seen, remote)
+		_, flagged := diff.RollbackReason(constants.ManifestSecretID)
+		assert.True(t, flagged)
+		_, flagged = diff.RollbackReason("1")
+		assert.True(t, flagged)
+	})
+	t.Run("trash purged by server", func(t *testing.T) {
+		diff := diffSecrets([]*types.LocalSecret{{UUID: "1", LastModified: now, DeletedAt: now}}, nil)
+		seen := newManifest(1, map[string]time.Time{"1": now})
+
+		detectRollbacks(diff, seen, seen)
+		assert.Empty(t, diff.Rollbacks)
+		assert.True(t, diff.Purged["1"])
+	})
+
+	t.Run("trash purged locally", func(t *testing.T) {
+		diff := diffSecrets(nil, []*types.RemoteSecret{
+			{UUID: "1", LastModified: now, DeletedAt: now},
+			{UUID: "2", LastModified: now},
+		})
+
+		detectRollbacks(diff, types.NewSyncManifest(), nil)
+		markPurged(diff, []string{"1", "2"})
+		assert.True(t, diff.Purged["1"])
+		assert.False(t, diff.Purged["2"])
+	})
+}
@@ -2,41 +2,153 @@ package ctl
 
 import (
 	"fmt"
+	"io"
 	"runtime"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/etoneja/go-keeper/internal/buildinfo"
+	"github.com/etoneja/go-keeper/internal/ctl/backup"
+	"github.com/etoneja/go-keeper/internal/ctl/config"
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/fsutil"
+	"github.com/etoneja/go-keeper/internal/ctl/interop"
 	"github.com/etoneja/go-keeper/internal/ctl/types"
 )
 
 const timeFormat = "2006-01-02 15:04:05"
 
-func displaySecrets(responses []*types.LocalSecret) {
+func displaySecrets(w io.Writer, responses []*types.LocalSecret) error {
 	if len(responses) == 0 {
-		fmt.Println("No secrets found")
-		return
+		fmt.Fprintln(w, "No secrets found")
+		return nil
 	}
 
-	fmt.Printf("%-36s %-12s %-12s %s\n", "UUID", "Type", "Name", "Last Modified")
-	fmt.Println(strings.Repeat("-", 82))
+	// NOTE: Paths such as work/aws/prod/root outgrow a fixed column
+	nameWidth := 12
 	for _, resp := range responses {
-		fmt.Printf("%-36s %-12s %-12s %s\n",
+		nameWidth = max(nameWidth, utf8.RuneCountInString(resp.Name))
+	}
+
+	fmt.Fprintf(w, "%-36s %-12s %-*s %s\n", "UUID", "Type", nameWidth, "Name", "Last Modified")
+	fmt.Fprintln(w, strings.Repeat("-", 70+nameWidth))
+	for _, resp := range responses {
+		fmt.Fprintf(w, "%-36s %-12s %-*s %s\n",
 			resp.UUID,
 			resp.Type,
+			nameWidth,
 			resp.Name,
 			resp.LastModified.Local().Format(timeFormat))
 	}
+	return nil
+}
+
+func displayGenerations(w io.Writer, generations []fsutil.Generation) error {
+	if len(generations) == 0 {
+		fmt.Fprintln(w, "No generations found")
+		return nil
+	}
+
+	fmt.Fprintf(w, "%-10s %-19s %s\n", "Generation", "Written", "Size")
+	fmt.Fprintln(w, strings.Repeat("-", 42))
+	for _, generation := range generations {
+		fmt.Fprintf(w, "%-10d %-19s %d\n",
+			generation.Number,
+			generation.ModTime.Local().Format(timeFormat),
+			generation.Size)
+	}
+	return nil
+}
+
+func displayVaultStats(w io.Writer, stats *VaultStats) error {
+	compression := "off"
+	if stats.Compression {
+		compression = "on"
+	}
+
+	fmt.Fprintf(w, "Secrets: %d (%d compressed)\n", stats.Secrets, stats.Compressed)
+	fmt.Fprintf(w, "Secret Data: %d bytes, stored in %d bytes\n", stats.PlainSize, stats.StoredSize)
+	if stats.PlainSize > 0 {
+		fmt.Fprintf(w, "Saved: %d bytes (%.1f%%)\n", stats.Saved(), float64(stats.Saved())*100/float64(stats.PlainSize))
+	}
+	fmt.Fprintf(w, "Attachments: %d, %d bytes\n", stats.Attachments, stats.AttachmentSize)
+	fmt.Fprintf(w, "Compression: %s\n", compression)
+	return nil
+}
+
+func displayRestoreSummary(w io.Writer, path string, summary *backup.RestoreSummary) error {
+	if summary.Mode == backup.ModeVerify {
+		fmt.Fprintf(w, "%s Backup %s is intact and holds %d secrets\n", constants.EmojiSuccess, path, summary.Total)
+		return nil
+	}
+
+	fmt.Fprintf(w, "%s Restored %s in %s mode: %d added, %d updated, %d unchanged, %d deleted\n",
+		constants.EmojiSuccess, path, summary.Mode,
+		summary.Added, summary.Updated, summary.Unchanged, summary.Deleted)
+	return nil
+}
+
+func displayImportReport(w io.Writer, report *interop.ImportReport) error {
+	if len(report.Results) == 0 {
+		fmt.Fprintln(w, "No entries found")
+		return nil
+	}
+
+	fmt.Fprintf(w, "%-24s %-12s %-10s %-24s %s\n", "Entry", "Status", "Type", "Name", "Reason")
+	fmt.Fprintln(w, strings.Repeat("-", 86))
+	for _, result := range report.Results {
+		fmt.Fprintf(w, "%-24s %-12s %-10s %-24s %s\n",
+			result.Ref,
+			result.Status,
+			result.Type,
+			result.Name,
+			result.Reason)
+	}
+	fmt.Fprintln(w)
+
+	imported := fmt.Sprintf("%d imported", report.Count(interop.StatusImported))
+	if report.DryRun {
+		imported = fmt.Sprintf("Dry run, nothing was written: %d to import", report.Count(interop.StatusWouldImport))
+	}
+	fmt.Fprintf(w, "%s, %d duplicates skipped, %d failed\n",
+		imported, report.Count(interop.StatusDuplicate), report.Count(interop.StatusFailed))
+	return nil
 }
 
-func displaySecret(secret *types.LocalSecret, full bool) error {
-	fmt.Printf("UUID: %s\n", secret.UUID)
-	fmt.Printf("Type: %s\n", secret.Type)
-	fmt.Printf("Name: %s\n", secret.Name)
-	fmt.Printf("Last Modified: %s\n", secret.LastModified.Local().Format(timeFormat))
+func displaySyncReport(w io.Writer, report *SyncReport) error {
+	if len(report.Secrets) == 0 {
+		fmt.Fprintln(w, "Everything is in sync")
+		return nil
+	}
+
+	fmt.Fprintf(w, "%-36s %-24s %-7s %s\n", "UUID", "Action", "Status", "Error")
+	fmt.Fprintln(w, strings.Repeat("-", 86))
+	for _, secret := range report.Secrets {
+		status, message := syncStatusOK, ""
+		if secret.Err != nil {
+			status, message = syncStatusFailed, secret.Err.Error()
+		}
+		fmt.Fprintf(w, "%-36s %-24s %-7s %s\n", secret.UUID, secret.Action, status, message)
+	}
+	fmt.Fprintln(w)
+
+	failed := len(report.Failed())
+	fmt.Fprintf(w, "%d synced, %d failed\n", len(report.Secrets)-failed, failed)
+	return nil
+}
+
+func displaySecret(w io.Writer, secret *types.LocalSecret, full bool) error {
+	fmt.Fprintf(w, "UUID: %s\n", secret.UUID)
+	fmt.Fprintf(w, "Type: %s\n", secret.Type)
+	fmt.Fprintf(w, "Name: %s\n", secret.Name)
+	fmt.Fprintf(w, "Last Modified: %s\n", secret.LastModified.Local().Format(timeFormat))
 	if secret.Metadata != "" {
-		fmt.Printf("Metadata: %s\n", secret.Metadata)
+		fmt.Fprintf(w, "Metadata: %s\n", secret.Metadata)
+	}
+	if len(secret.Tags) > 0 {
+		fmt.Fprintf(w, "Tags: %s\n", strings.Join(secret.Tags, ", "))
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 
 	data, err := secret.ParseData()
 	if err != nil {
@@ -45,42 +157,57 @@ func displaySecret(secret *types.LocalSecret, full bool) error {
 
 	switch data := data.(type) {
 	case types.LoginData:
-		fmt.Printf("Username: %s\n", data.Username)
+		fmt.Fprintf(w, "Username: %s\n", data.Username)
 		if full {
-			fmt.Printf("Password: %s\n", data.Password)
+			fmt.Fprintf(w, "Password: %s\n", data.Password)
 		} else {
-			fmt.Printf("Password: ********\n")
+			fmt.Fprintf(w, "Password: ********\n")
 		}
 		if data.URL != "" {
-			fmt.Printf("URL: %s\n", data.URL)
+			fmt.Fprintf(w, "URL: %s\n", data.URL)
 		}
 
 	case types.TextData:
-		fmt.Printf("Content: %s\n", data.Content)
+		fmt.Fprintf(w, "Content: %s\n", data.Content)
 
 	case types.FileData:
-		fmt.Printf("File Name: %s\n", data.FileName)
-		fmt.Printf("File Size: %d bytes\n", data.FileSize)
-		fmt.Printf("Content Size: %d bytes (base64)\n", len(data.Content))
+		fmt.Fprintf(w, "File Name: %s\n", data.FileName)
+		fmt.Fprintf(w, "File Size: %d bytes\n", data.FileSize)
+		if data.Attachment != "" {
+			fmt.Fprintf(w, "Attachment: %s\n", data.Attachment)
+		} else {
+			fmt.Fprintf(w, "Content Size: %d bytes (base64)\n", len(data.Content))
+		}
 
 	case types.CardData:
-		fmt.Printf("Card Number: %s\n", data.Number)
-		fmt.Printf("Card Holder: %s\n", data.Holder)
-		fmt.Printf("Expiry: %s\n", data.Expiry)
+		fmt.Fprintf(w, "Card Number: %s\n", data.Number)
+		fmt.Fprintf(w, "Card Holder: %s\n", data.Holder)
+		fmt.Fprintf(w, "Expiry: %s\n", data.Expiry)
 		if full {
-			fmt.Printf("CVV: %s\n", data.CVV)
+			fmt.Fprintf(w, "CVV: %s\n", data.CVV)
 		} else {
-			fmt.Printf("CVV: ***\n")
+			fmt.Fprintf(w, "CVV: ***\n")
 		}
 
 	default:
 		return fmt.Errorf("unknown data type: %T", data)
 	}
 
+	if len(secret.Fields) > 0 {
+		fmt.Fprintln(w)
+		for _, field := range secret.Fields {
+			value := field.Value
+			if field.IsHidden() && !full {
+				value = "********"
+			}
+			fmt.Fprintf(w, "%s: %s\n", field.Label, value)
+		}
+	}
+
 	return nil
 }
 
-func displayVersion() {
+func displayVersion(w io.Writer) error {
 	commitShort := buildinfo.Commit
 	if len(commitShort) > 12 {
 		commitShort = commitShort[:12]
@@ -88,12 +215,170 @@ func displayVersion() {
 
 	platform := fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH)
 
-	fmt.Println("GoKeeper - Secret Manager")
-	fmt.Println("┌──────────────────┬─────────────────────────────────┐")
-	fmt.Printf("│ Version          │ %-31s │\n", buildinfo.Version)
-	fmt.Printf("│ Build Date       │ %-31s │\n", buildinfo.BuildTime)
-	fmt.Printf("│ Git Commit       │ %-31s │\n", commitShort)
-	fmt.Printf("│ Go Version       │ %-31s │\n", runtime.Version())
-	fmt.Printf("│ Platform         │ %-31s │\n", platform)
-	fmt.Println("└──────────────────┴─────────────────────────────────┘")
+	fmt.Fprintln(w, "GoKeeper - Secret Manager")
+	fmt.Fprintln(w, "┌──────────────────┬─────────────────────────────────┐")
+	fmt.Fprintf(w, "│ Version          │ %-31s │\n", buildinfo.Version)
+	fmt.Fprintf(w, "│ Build Date       │ %-31s │\n", buildinfo.BuildTime)
+	fmt.Fprintf(w, "│ Git Commit       │ %-31s │\n", commitShort)
+	fmt.Fprintf(w, "│ Go Version       │ %-31s │\n", runtime.Version())
+	fmt.Fprintf(w, "│ Platform         │ %-31s │\n", platform)
+	fmt.Fprintln(w, "└──────────────────┴─────────────────────────────────┘")
+	return nil
+}
+
+func displayConfigProfiles(w io.Writer, path string, profiles []*configProfileOutput) error {
+	if len(profiles) == 0 {
+		fmt.Fprintf(w, "No profiles found in %s\n", path)
+		return nil
+	}
+
+	for i, profile := range profiles {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		marker := " "
+		if profile.Current {
+			marker = "*"
+		}
+		fmt.Fprintf(w, "%s %s\n", marker, profile.Name)
+
+		for _, key := range config.Keys() {
+			if value, exists := profile.Settings[key]; exists {
+				fmt.Fprintf(w, "    %-24s %s\n", key+":", value)
+			}
+		}
+	}
+	return nil
+}
+
+func displaySecretTags(w io.Writer, secret *types.LocalSecret) error {
+	if len(secret.Tags) == 0 {
+		fmt.Fprintf(w, "Secret %s has no tags\n", secret.UUID)
+		return nil
+	}
+
+	fmt.Fprintf(w, "Secret %s is tagged %s\n", secret.UUID, strings.Join(secret.Tags, ", "))
+	return nil
+}
+
+func displayTags(w io.Writer, tags []TagCount) error {
+	if len(tags) == 0 {
+		fmt.Fprintln(w, "No tags found")
+		return nil
+	}
+
+	fmt.Fprintf(w, "%-32s %s\n", "Tag", "Secrets")
+	fmt.Fprintln(w, strings.Repeat("-", 40))
+	for _, tag := range tags {
+		fmt.Fprintf(w, "%-32s %d\n", tag.Tag, tag.Secrets)
+	}
+	return nil
+}
+
+func displayAttachments(w io.Writer, secret *types.LocalSecret, attachments []*types.Attachment) error {
+	if len(attachments) == 0 {
+		fmt.Fprintf(w, "Secret %s has no attachments\n", secret.UUID)
+		return nil
+	}
+
+	nameWidth := 12
+	for _, attachment := range attachments {
+		nameWidth = max(nameWidth, utf8.RuneCountInString(attachment.Name))
+	}
+
+	fmt.Fprintf(w, "%-36s %-*s %-24s %10s %s\n", "UUID", nameWidth, "Name", "Type", "Size", "Last Modified")
+	fmt.Fprintln(w, strings.Repeat("-", 93+nameWidth))
+	for _, attachment := range attachments {
+		fmt.Fprintf(w, "%-36s %-*s %-24s %10d %s\n",
+			attachment.UUID,
+			nameWidth,
+			attachment.Name,
+			attachment.MIMEType,
+			attachment.Size,
+			attachment.LastModified.Local().Format(timeFormat))
+	}
+	return nil
+}
+
+func displayHistory(w io.Writer, history *SecretHistory) error {
+	switch {
+	case history.Current == nil:
+		fmt.Fprintf(w, "Secret %s is no longer in the vault\n", history.UUID)
+	case history.Current.InTrash():
+		fmt.Fprintf(w, "Secret %s, %s, in the trash since %s\n",
+			history.UUID, history.Current.Name, history.Current.DeletedAt.Local().Format(timeFormat))
+	default:
+		fmt.Fprintf(w, "Secret %s, %s, last modified %s\n",
+			history.UUID, history.Current.Name, history.Current.LastModified.Local().Format(timeFormat))
+	}
+
+	if len(history.Versions) == 0 {
+		fmt.Fprintln(w, "No earlier versions found")
+		return nil
+	}
+
+	nameWidth := 12
+	for _, version := range history.Versions {
+		nameWidth = max(nameWidth, utf8.RuneCountInString(version.Secret.Name))
+	}
+
+	fmt.Fprintf(w, "%-7s %-19s %-19s %-*s %s\n", "Version", "Last Modified", "Replaced", nameWidth, "Name", "Tags")
+	fmt.Fprintln(w, strings.Repeat("-", 54+nameWidth))
+	for _, version := range history.Versions {
+		fmt.Fprintf(w, "%-7d %-19s %-19s %-*s %s\n",
+			version.Version,
+			version.Secret.LastModified.Local().Format(timeFormat),
+			version.ArchivedAt.Local().Format(timeFormat),
+			nameWidth,
+			version.Secret.Name,
+			strings.Join(version.Secret.Tags, ", "))
+	}
+	return nil
+}
+
+func displayTrash(w io.Writer, secrets []*types.LocalSecret) error {
+	if len(secrets) == 0 {
+		fmt.Fprintln(w, "Trash is empty")
+		return nil
+	}
+
+	nameWidth := 12
+	for _, secret := range secrets {
+		nameWidth = max(nameWidth, utf8.RuneCountInString(secret.Name))
+	}
+
+	fmt.Fprintf(w, "%-36s %-12s %-*s %s\n", "UUID", "Type", nameWidth, "Name", "Deleted")
+	fmt.Fprintln(w, strings.Repeat("-", 70+nameWidth))
+	for _, secret := range secrets {
+		fmt.Fprintf(w, "%-36s %-12s %-*s %s\n",
+			secret.UUID,
+			secret.Type,
+			nameWidth,
+			secret.Name,
+			secret.DeletedAt.Local().Format(timeFormat))
+	}
+	return nil
+}
+
+func displayVersionDiff(w io.Writer, diff *VersionDiff) error {
+	if len(diff.Changes) == 0 {
+		fmt.Fprintf(w, "No changes between %s and %s\n", diff.From, diff.To)
+		return nil
+	}
+
+	fmt.Fprintf(w, "Secret %s, %s -> %s\n", diff.UUID, diff.From, diff.To)
+	for _, change := range diff.Changes {
+		switch {
+		case change.Redacted:
+			fmt.Fprintf(w, "~ %s: %s, use --full to show\n", change.Field, change.Change)
+		case change.Change == fieldAdded:
+			fmt.Fprintf(w, "+ %s: %q\n", change.Field, change.To)
+		case change.Change == fieldRemoved:
+			fmt.Fprintf(w, "- %s: %q\n", change.Field, change.From)
+		default:
+			fmt.Fprintf(w, "~ %s: %q -> %q\n", change.Field, change.From, change.To)
+		}
+	}
+	return nil
 }
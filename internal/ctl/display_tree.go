@@ -0,0 +1,103 @@
+package ctl
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+// secretTreeNode is a folder of the secret tree.
+type secretTreeNode struct {
+	folders map[string]*secretTreeNode
+	secrets []*types.LocalSecret
+}
+
+func newSecretTree(folder string, secrets []*types.LocalSecret) *secretTreeNode {
+	root := &secretTreeNode{folders: make(map[string]*secretTreeNode)}
+	for _, secret := range secrets {
+		relative := secret.Path()
+		if folder != "" {
+			relative = strings.TrimPrefix(relative, folder+types.PathSeparator)
+		}
+
+		node := root
+		segments := strings.Split(relative, types.PathSeparator)
+		for _, segment := range segments[:len(segments)-1] {
+			child, exists := node.folders[segment]
+			if !exists {
+				child = &secretTreeNode{folders: make(map[string]*secretTreeNode)}
+				node.folders[segment] = child
+			}
+			node = child
+		}
+		node.secrets = append(node.secrets, secret)
+	}
+	return root
+}
+
+func displaySecretTree(w io.Writer, folder string, secrets []*types.LocalSecret) error {
+	if len(secrets) == 0 {
+		fmt.Fprintln(w, "No secrets found")
+		return nil
+	}
+
+	root := folder + types.PathSeparator
+	if folder == "" {
+		root = "."
+	}
+	fmt.Fprintln(w, root)
+
+	newSecretTree(folder, secrets).display(w, "")
+	return nil
+}
+
+// display prints folders first, then secrets, each group sorted by name.
+func (n *secretTreeNode) display(w io.Writer, indent string) {
+	names := make([]string, 0, len(n.folders))
+	for name := range n.folders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	secrets := append([]*types.LocalSecret(nil), n.secrets...)
+	sortSecretsByPath(secrets)
+
+	count := len(names) + len(secrets)
+	branch := func(i int) (string, string) {
+		if i == count-1 {
+			return "└── ", "    "
+		}
+		return "├── ", "│   "
+	}
+
+	for i, name := range names {
+		prefix, childIndent := branch(i)
+		fmt.Fprintf(w, "%s%s%s%s\n", indent, prefix, name, types.PathSeparator)
+		n.folders[name].display(w, indent+childIndent)
+	}
+
+	for i, secret := range secrets {
+		prefix, _ := branch(len(names) + i)
+		_, name := types.SplitPath(secret.Path())
+		fmt.Fprintf(w, "%s%s%s  (%s, %s)\n", indent, prefix, name, secret.Type, secret.UUID)
+	}
+}
+
+func displayFolder(w io.Writer, listing *FolderListing) error {
+	if len(listing.Folders) == 0 && len(listing.Secrets) == 0 {
+		fmt.Fprintln(w, "No secrets found")
+		return nil
+	}
+
+	for _, name := range listing.Folders {
+		fmt.Fprintf(w, "%s%s\n", name, types.PathSeparator)
+	}
+	for _, secret := range listing.Secrets {
+		_, name := types.SplitPath(secret.Path())
+		fmt.Fprintf(w, "%-24s %-12s %s\n", name, secret.Type, secret.UUID)
+	}
+	return nil
+}
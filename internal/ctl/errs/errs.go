@@ -1,6 +1,9 @@
 package errs
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 type NotFoundError struct {
 	Entity string
@@ -12,10 +15,180 @@ func (e *NotFoundError) Error() string {
 }
 
 func IsNotFound(err error) bool {
-	_, ok := err.(*NotFoundError)
-	return ok
+	var notFoundErr *NotFoundError
+	return errors.As(err, &notFoundErr)
 }
 
 func NewSecretNotFoundError(uuid string) error {
 	return &NotFoundError{Entity: "secret", UUID: uuid}
 }
+
+func NewAttachmentNotFoundError(uuid string) error {
+	return &NotFoundError{Entity: "attachment", UUID: uuid}
+}
+
+type TamperedError struct {
+	Entity string
+	UUID   string
+	Err    error
+}
+
+func (e *TamperedError) Error() string {
+	return fmt.Sprintf("%s %s failed integrity check, possible tampering: %v", e.Entity, e.UUID, e.Err)
+}
+
+func (e *TamperedError) Unwrap() error {
+	return e.Err
+}
+
+func IsTampered(err error) bool {
+	var tamperedErr *TamperedError
+	return errors.As(err, &tamperedErr)
+}
+
+func NewSecretTamperedError(uuid string, err error) error {
+	return &TamperedError{Entity: "secret", UUID: uuid, Err: err}
+}
+
+func NewAttachmentTamperedError(uuid string, err error) error {
+	return &TamperedError{Entity: "attachment", UUID: uuid, Err: err}
+}
+
+// ValidationError reports input that was rejected before anything changed.
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+func IsValidation(err error) bool {
+	var validationErr *ValidationError
+	return errors.As(err, &validationErr)
+}
+
+func NewValidationError(err error) error {
+	return &ValidationError{Err: err}
+}
+
+// Validationf formats a validation error message.
+func Validationf(format string, args ...any) error {
+	return &ValidationError{Err: fmt.Errorf(format, args...)}
+}
+
+// AuthError reports rejected credentials: a wrong password, passphrase or token.
+type AuthError struct {
+	Err error
+}
+
+func (e *AuthError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+func IsAuth(err error) bool {
+	var authErr *AuthError
+	return errors.As(err, &authErr)
+}
+
+func NewAuthError(err error) error {
+	return &AuthError{Err: err}
+}
+
+// ConflictError reports state that changed or already exists: a locked
+// vault, a file that would be overwritten, a concurrent write.
+type ConflictError struct {
+	Err error
+}
+
+func (e *ConflictError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+func IsConflict(err error) bool {
+	var conflictErr *ConflictError
+	return errors.As(err, &conflictErr)
+}
+
+func NewConflictError(err error) error {
+	return &ConflictError{Err: err}
+}
+
+// NetworkError reports a server that could not be reached.
+type NetworkError struct {
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+func IsNetwork(err error) bool {
+	var networkErr *NetworkError
+	return errors.As(err, &networkErr)
+}
+
+func NewNetworkError(err error) error {
+	return &NetworkError{Err: err}
+}
+
+// CorruptError reports a vault or file whose content is damaged.
+type CorruptError struct {
+	Err error
+}
+
+func (e *CorruptError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CorruptError) Unwrap() error {
+	return e.Err
+}
+
+// IsCorrupt also matches tampered secrets.
+func IsCorrupt(err error) bool {
+	var corruptErr *CorruptError
+	return errors.As(err, &corruptErr) || IsTampered(err)
+}
+
+func NewCorruptError(err error) error {
+	return &CorruptError{Err: err}
+}
+
+// UsageError reports a command line that could not be parsed.
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+func IsUsage(err error) bool {
+	var usageErr *UsageError
+	return errors.As(err, &usageErr)
+}
+
+func NewUsageError(err error) error {
+	return &UsageError{Err: err}
+}
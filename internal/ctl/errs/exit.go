@@ -0,0 +1,40 @@
+package errs
+
+// Exit codes of keeperctl, documented in README.md.
+const (
+	ExitOK         = 0
+	ExitFailure    = 1
+	ExitUsage      = 2
+	ExitNotFound   = 3
+	ExitValidation = 4
+	ExitAuth       = 5
+	ExitConflict   = 6
+	ExitNetwork    = 7
+	ExitCorrupt    = 8
+)
+
+// ExitCode maps an error to the exit code of its kind, ExitFailure for
+// errors of no known kind. When the chain holds several kinds, the most
+// severe wins, in the order the cases are checked.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case IsUsage(err):
+		return ExitUsage
+	case IsCorrupt(err):
+		return ExitCorrupt
+	case IsAuth(err):
+		return ExitAuth
+	case IsNetwork(err):
+		return ExitNetwork
+	case IsConflict(err):
+		return ExitConflict
+	case IsNotFound(err):
+		return ExitNotFound
+	case IsValidation(err):
+		return ExitValidation
+	default:
+		return ExitFailure
+	}
+}
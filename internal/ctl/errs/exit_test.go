@@ -0,0 +1,38 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	base := errors.New("base")
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", err: nil, want: ExitOK},
+		{name: "untyped", err: base, want: ExitFailure},
+		{name: "usage", err: NewUsageError(base), want: ExitUsage},
+		{name: "not found", err: NewSecretNotFoundError("id"), want: ExitNotFound},
+		{name: "validation", err: Validationf("bad input"), want: ExitValidation},
+		{name: "auth", err: NewAuthError(base), want: ExitAuth},
+		{name: "conflict", err: NewConflictError(base), want: ExitConflict},
+		{name: "network", err: NewNetworkError(base), want: ExitNetwork},
+		{name: "corrupt", err: NewCorruptError(base), want: ExitCorrupt},
+		{name: "tampered is corrupt", err: NewSecretTamperedError("id", base), want: ExitCorrupt},
+		{name: "wrapped", err: fmt.Errorf("context: %w", NewNetworkError(base)), want: ExitNetwork},
+		{name: "most severe wins", err: NewValidationError(NewCorruptError(base)), want: ExitCorrupt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ExitCode(tt.err))
+		})
+	}
+}
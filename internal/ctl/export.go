@@ -4,19 +4,34 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
+	"slices"
 
+	"github.com/etoneja/go-keeper/internal/ctl/backup"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/fsutil"
 	"github.com/etoneja/go-keeper/internal/ctl/types"
 )
 
-func exportSecret(secret *types.LocalSecret, exportPath string) error {
+const exportFileMode = 0600
+
+// exportSecret writes the secret content to a new file readable by its owner
+// only. With a passphrase the secret is written as an encrypted one-secret
+// backup archive instead, to be brought back with backup restore. Binary
+// secrets kept in an attachment need it among attachments, see fileAttachments.
+func exportSecret(secret *types.LocalSecret, attachments []*types.Attachment, exportPath string, passphrase string) error {
 	if _, err := os.Stat(exportPath); err == nil {
-		return fmt.Errorf("file already exists: %s", exportPath)
+		return errs.NewConflictError(fmt.Errorf("file already exists: %s", exportPath))
 	} else if !os.IsNotExist(err) {
 		return fmt.Errorf("failed to check file existence: %w", err)
 	}
 
+	if passphrase != "" {
+		archive := backup.NewArchive([]*types.LocalSecret{secret})
+		archive.AddAttachments(attachments)
+		return backup.Write(exportPath, passphrase, archive)
+	}
+
 	data, err := secret.ParseData()
 	if err != nil {
 		return err
@@ -25,6 +40,16 @@ func exportSecret(secret *types.LocalSecret, exportPath string) error {
 	var content []byte
 	switch data := data.(type) {
 	case types.FileData:
+		if data.Attachment != "" {
+			index := slices.IndexFunc(attachments, func(attachment *types.Attachment) bool {
+				return attachment.UUID == data.Attachment
+			})
+			if index < 0 {
+				return errs.NewAttachmentNotFoundError(data.Attachment)
+			}
+			content = attachments[index].Content
+			break
+		}
 		content, err = base64.StdEncoding.DecodeString(data.Content)
 		if err != nil {
 			return fmt.Errorf("failed to decode base64 content: %w", err)
@@ -36,18 +61,5 @@ func exportSecret(secret *types.LocalSecret, exportPath string) error {
 		}
 	}
 
-	tmpPath := exportPath + ".tmp"
-
-	if err := os.WriteFile(tmpPath, content, 0644); err != nil {
-		return fmt.Errorf("failed to write temporary file: %w", err)
-	}
-
-	if err := os.Rename(tmpPath, exportPath); err != nil {
-		if removeErr := os.Remove(tmpPath); removeErr != nil {
-			log.Printf("failed to remove temporary file %s: %v", tmpPath, removeErr)
-		}
-		return fmt.Errorf("failed to replace file: %w", err)
-	}
-
-	return nil
+	return fsutil.WriteFileAtomic(exportPath, content, exportFileMode)
 }
@@ -0,0 +1,105 @@
+package ctl
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/clipboard"
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+// secretField returns the raw value of one field of the secret: uuid, name,
+// type, metadata, a data field as named in the JSON output, the label of a
+// custom field, or the key of a "key: value" metadata line.
+func secretField(secret *types.LocalSecret, field string) (string, error) {
+	output, err := newSecretOutput(secret, true)
+	if err != nil {
+		return "", err
+	}
+
+	switch field {
+	case "uuid":
+		return secret.UUID, nil
+	case "name":
+		return secret.Name, nil
+	case "type":
+		return secret.Type, nil
+	case "metadata":
+		return secret.Metadata, nil
+	}
+
+	if value, exists := output.Data[field]; exists {
+		return fmt.Sprint(value), nil
+	}
+
+	if customField, exists := secret.Field(field); exists {
+		return customField.Value, nil
+	}
+
+	if value, exists := metadataField(secret.Metadata, field); exists {
+		return value, nil
+	}
+
+	fields := make([]string, 0, len(output.Data))
+	for name := range output.Data {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+
+	for _, customField := range secret.Fields {
+		fields = append(fields, customField.Label)
+	}
+
+	return "", errs.Validationf("secret %s has no field %q, expected uuid, name, type, metadata, %s or a metadata key",
+		secret.UUID, field, strings.Join(fields, ", "))
+}
+
+// metadataField finds "key: value" lines in metadata, comparing keys
+// case-insensitively. Values of repeated keys are joined by newlines.
+func metadataField(metadata string, key string) (string, bool) {
+	var values []string
+	for _, line := range strings.Split(metadata, "\n") {
+		lineKey, value, found := strings.Cut(line, ":")
+		if found && strings.EqualFold(strings.TrimSpace(lineKey), key) {
+			values = append(values, strings.TrimSpace(value))
+		}
+	}
+
+	return strings.Join(values, "\n"), len(values) > 0
+}
+
+// copyToClipboard copies value and, unless clearAfter is zero, waits that
+// long or for an interrupt before clearing the clipboard again.
+func copyToClipboard(backend clipboard.Clipboard, value string, clearAfter time.Duration) error {
+	if err := backend.Copy(value); err != nil {
+		return err
+	}
+
+	if clearAfter <= 0 {
+		printMessage("%s Copied to the clipboard with %s", constants.EmojiSuccess, backend.Name())
+		return nil
+	}
+
+	printMessage("%s Copied to the clipboard with %s, clearing it in %s", constants.EmojiSuccess, backend.Name(), clearAfter)
+
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupted)
+
+	timer := time.NewTimer(clearAfter)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-interrupted:
+	}
+
+	return backend.Clear()
+}
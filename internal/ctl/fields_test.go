@@ -0,0 +1,117 @@
+package ctl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretField(t *testing.T) {
+	secret := newTestLoginSecret(t)
+	secret.Metadata = "group: Mail\nTOTP: otpauth://totp/x?secret=ABC"
+
+	tests := []struct {
+		name  string
+		field string
+		want  string
+	}{
+		{name: "data field", field: "password", want: "hunter2"},
+		{name: "url", field: "url", want: "https://mail.example.com"},
+		{name: "name", field: "name", want: "mail"},
+		{name: "metadata key", field: "totp", want: "otpauth://totp/x?secret=ABC"},
+		{name: "whole metadata", field: "metadata", want: secret.Metadata},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := secretField(secret, tt.field)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, value)
+		})
+	}
+
+	t.Run("custom field", func(t *testing.T) {
+		withFields := *secret
+		withFields.Fields = []types.CustomField{{Type: constants.FieldTypeHidden, Label: "PIN", Value: "1234"}}
+
+		value, err := secretField(&withFields, "pin")
+		require.NoError(t, err)
+		assert.Equal(t, "1234", value)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := secretField(secret, "cvv")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "password, url, username")
+	})
+}
+
+type fakeClipboard struct {
+	value   string
+	cleared bool
+}
+
+func (f *fakeClipboard) Copy(value string) error {
+	f.value = value
+	return nil
+}
+
+func (f *fakeClipboard) Clear() error {
+	f.value, f.cleared = "", true
+	return nil
+}
+
+func (f *fakeClipboard) Name() string {
+	return "fake"
+}
+
+func TestCopyToClipboard(t *testing.T) {
+	t.Run("clears after the timeout", func(t *testing.T) {
+		backend := &fakeClipboard{}
+		require.NoError(t, copyToClipboard(backend, "hunter2", time.Millisecond))
+		assert.True(t, backend.cleared)
+	})
+
+	t.Run("zero keeps the value", func(t *testing.T) {
+		backend := &fakeClipboard{}
+		require.NoError(t, copyToClipboard(backend, "hunter2", 0))
+		assert.Equal(t, "hunter2", backend.value)
+		assert.False(t, backend.cleared)
+	})
+}
+
+func TestVaultService_CustomFieldsPersist(t *testing.T) {
+	ctx := context.Background()
+	service := newTestVaultService(t)
+
+	fields := []types.CustomField{
+		{Type: constants.FieldTypeText, Label: "Bank", Value: "Example"},
+		{Type: constants.FieldTypeURL, Label: "Portal", Value: "https://bank.example.com"},
+		{Type: constants.FieldTypeHidden, Label: "PIN", Value: "1234"},
+	}
+	secret, err := types.NewSecretModel(
+		types.BaseSecret{Type: "text", Name: "bank", Metadata: "legacy notes", Fields: fields},
+		types.TextData{Content: "account"},
+		service.cryptor,
+	)
+	require.NoError(t, err)
+	_, err = service.CreateLocalSecret(ctx, secret)
+	require.NoError(t, err)
+
+	stored, err := service.GetLocalSecret(ctx, "bank")
+	require.NoError(t, err)
+	assert.Equal(t, fields, stored.Fields)
+	assert.Equal(t, "legacy notes", stored.Metadata)
+	assert.Equal(t, secret.Hash, stored.Hash)
+
+	listed, err := service.ListLocalSecrets(ctx)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Empty(t, listed[0].Fields, "listing does not load data")
+}
@@ -0,0 +1,82 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "vault.db")
+
+	require.NoError(t, WriteFileAtomic(path, []byte("first"), 0600))
+	require.NoError(t, WriteFileAtomic(path, []byte("second"), 0600))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temp files must not be left behind")
+}
+
+func TestRotateGenerations(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "vault.db")
+
+	require.NoError(t, RotateGenerations(path, 2), "missing file is not an error")
+
+	for _, content := range []string{"v1", "v2", "v3", "v4"} {
+		require.NoError(t, RotateGenerations(path, 2))
+		require.NoError(t, WriteFileAtomic(path, []byte(content), 0600))
+	}
+
+	read := func(p string) string {
+		data, err := os.ReadFile(p)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	assert.Equal(t, "v4", read(path))
+	assert.Equal(t, "v3", read(GenerationPath(path, 1)))
+	assert.Equal(t, "v2", read(GenerationPath(path, 2)))
+	assert.NoFileExists(t, GenerationPath(path, 3))
+
+	generations, err := ListGenerations(path, 2)
+	require.NoError(t, err)
+	require.Len(t, generations, 2)
+	assert.Equal(t, 1, generations[0].Number)
+	assert.Equal(t, 2, generations[1].Number)
+
+	require.NoError(t, RotateGenerations(path, 0))
+	assert.Equal(t, "v3", read(GenerationPath(path, 1)), "rotation disabled")
+}
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db.lock")
+
+	lock, err := LockFile(path, 0)
+	require.NoError(t, err)
+
+	_, err = LockFile(path, 0)
+	assert.ErrorIs(t, err, ErrLocked, "fail fast")
+
+	_, err = LockFile(path, 2*lockPollInterval)
+	assert.ErrorIs(t, err, ErrLocked, "wait and give up")
+
+	require.NoError(t, lock.Unlock())
+	require.NoError(t, lock.Unlock(), "unlock is idempotent")
+
+	lock, err = LockFile(path, 0)
+	require.NoError(t, err)
+	require.NoError(t, lock.Unlock())
+}
@@ -0,0 +1,85 @@
+package fsutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Generation describes one rotated copy of a file.
+type Generation struct {
+	Number  int
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// GenerationPath returns the path of the n-th rotated copy of path.
+func GenerationPath(path string, n int) string {
+	return path + "." + strconv.Itoa(n)
+}
+
+// RotateGenerations shifts path.1..path.(keep-1) up by one, dropping the
+// oldest, and keeps the current content of path as path.1. The current file
+// stays in place, so a crash never leaves path missing.
+func RotateGenerations(path string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	err := os.Remove(GenerationPath(path, keep))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to drop oldest generation: %w", err)
+	}
+
+	for n := keep - 1; n >= 1; n-- {
+		err := os.Rename(GenerationPath(path, n), GenerationPath(path, n+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to rotate generation %d: %w", n, err)
+		}
+	}
+
+	first := GenerationPath(path, 1)
+	if err := os.Link(path, first); err != nil {
+		// NOTE: Fallback for filesystems without hard links
+		data, readErr := os.ReadFile(path)
+		if readErr != nil {
+			return fmt.Errorf("failed to read current file: %w", readErr)
+		}
+		if err := WriteFileAtomic(first, data, 0600); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListGenerations returns the existing rotated copies of path, newest first.
+func ListGenerations(path string, keep int) ([]Generation, error) {
+	var generations []Generation
+	for n := 1; n <= keep; n++ {
+		genPath := GenerationPath(path, n)
+		info, err := os.Stat(genPath)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		generations = append(generations, Generation{
+			Number:  n,
+			Path:    genPath,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	}
+
+	return generations, nil
+}
@@ -0,0 +1,65 @@
+package fsutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+)
+
+const lockPollInterval = 100 * time.Millisecond
+
+// ErrLocked is returned when another process holds the lock.
+var ErrLocked = errs.NewConflictError(errors.New("locked by another process"))
+
+// FileLock is an exclusive advisory lock held on a lock file.
+type FileLock struct {
+	file *os.File
+}
+
+// LockFile takes an exclusive lock on path, creating it if needed. A zero
+// timeout fails fast, a negative one waits forever.
+func LockFile(path string, timeout time.Duration) (*FileLock, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLock(file)
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if locked {
+			return &FileLock{file: file}, nil
+		}
+
+		if timeout >= 0 && !time.Now().Before(deadline) {
+			_ = file.Close()
+			return nil, fmt.Errorf("%s: %w", path, ErrLocked)
+		}
+
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// Unlock releases the lock. The lock file itself is left in place, removing
+// it would race with a process about to lock it.
+func (l *FileLock) Unlock() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+
+	err := unlock(l.file)
+	closeErr := l.file.Close()
+	l.file = nil
+
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
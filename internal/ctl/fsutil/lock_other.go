@@ -0,0 +1,16 @@
+//go:build !unix && !windows
+
+package fsutil
+
+import (
+	"os"
+)
+
+// NOTE: No advisory locks on this platform, the changed-on-disk check still applies
+func tryLock(file *os.File) (bool, error) {
+	return true, nil
+}
+
+func unlock(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,275 @@
+package interop
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+// Bitwarden unencrypted JSON export.
+type bitwardenExport struct {
+	Encrypted bool              `json:"encrypted"`
+	Folders   []bitwardenFolder `json:"folders,omitempty"`
+	Items     []bitwardenItem   `json:"items"`
+}
+
+type bitwardenFolder struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type bitwardenItem struct {
+	ID         string                `json:"id,omitempty"`
+	FolderID   string                `json:"folderId,omitempty"`
+	Type       int                   `json:"type"`
+	Name       string                `json:"name"`
+	Notes      string                `json:"notes,omitempty"`
+	Fields     []bitwardenField      `json:"fields,omitempty"`
+	Login      *bitwardenLogin       `json:"login,omitempty"`
+	SecureNote *bitwardenSecureNote  `json:"secureNote,omitempty"`
+	Card       *bitwardenCard        `json:"card,omitempty"`
+	Identity   map[string]any        `json:"identity,omitempty"`
+	Attachment []bitwardenAttachment `json:"attachments,omitempty"`
+}
+
+type bitwardenField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Type  int    `json:"type"`
+}
+
+type bitwardenLogin struct {
+	URIs     []bitwardenURI `json:"uris,omitempty"`
+	Username string         `json:"username"`
+	Password string         `json:"password"`
+	TOTP     string         `json:"totp,omitempty"`
+}
+
+type bitwardenURI struct {
+	URI string `json:"uri"`
+}
+
+type bitwardenSecureNote struct {
+	Type int `json:"type"`
+}
+
+type bitwardenCard struct {
+	CardholderName string `json:"cardholderName"`
+	Brand          string `json:"brand,omitempty"`
+	Number         string `json:"number"`
+	ExpMonth       string `json:"expMonth"`
+	ExpYear        string `json:"expYear"`
+	Code           string `json:"code"`
+}
+
+type bitwardenAttachment struct {
+	FileName string `json:"fileName"`
+}
+
+const (
+	bitwardenTypeLogin      = 1
+	bitwardenTypeSecureNote = 2
+	bitwardenTypeCard       = 3
+	bitwardenTypeIdentity   = 4
+)
+
+// Types of Bitwarden custom fields.
+const (
+	bitwardenFieldText   = 0
+	bitwardenFieldHidden = 1
+	bitwardenFieldLinked = 3
+)
+
+// Fields of exported items holding what the format has no place for.
+const (
+	bitwardenMetadataField = "metadata"
+	bitwardenTagsField     = "tags"
+)
+
+func parseBitwardenJSON(r io.Reader) ([]*Record, error) {
+	var export bitwardenExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, errs.Validationf("failed to parse Bitwarden JSON: %w", err)
+	}
+
+	if export.Encrypted {
+		return nil, errs.Validationf("encrypted Bitwarden exports are not supported, export as unencrypted JSON")
+	}
+
+	folders := make(map[string]string, len(export.Folders))
+	for _, folder := range export.Folders {
+		folders[folder.ID] = folder.Name
+	}
+
+	records := make([]*Record, 0, len(export.Items))
+	for i, item := range export.Items {
+		records = append(records, bitwardenRecord(fmt.Sprintf("item %d", i+1), item, folders))
+	}
+
+	return records, nil
+}
+
+func bitwardenRecord(ref string, item bitwardenItem, folders map[string]string) *Record {
+	record := newRecord(ref)
+	record.Name = item.Name
+	record.addMetadata("folder", folders[item.FolderID])
+
+	for _, field := range item.Fields {
+		switch {
+		case field.Type == bitwardenFieldHidden:
+			record.addField(constants.FieldTypeHidden, field.Name, field.Value)
+		case field.Type == bitwardenFieldLinked:
+			// NOTE: Linked fields only point at a login field
+		case field.Type == bitwardenFieldText && field.Name == bitwardenMetadataField:
+			record.addMetadata(field.Name, field.Value)
+		case field.Type == bitwardenFieldText && field.Name == bitwardenTagsField:
+			record.addTags(field.Value)
+		default:
+			record.addField(constants.FieldTypeText, field.Name, field.Value)
+		}
+	}
+
+	for _, attachment := range item.Attachment {
+		// NOTE: Exports list attachments without their content
+		record.addMetadata("attachment not exported", attachment.FileName)
+	}
+
+	switch item.Type {
+	case bitwardenTypeLogin:
+		login := item.Login
+		if login == nil {
+			login = &bitwardenLogin{}
+		}
+
+		data := types.LoginData{Username: login.Username, Password: login.Password}
+		for i, uri := range login.URIs {
+			if i == 0 {
+				data.URL = uri.URI
+				continue
+			}
+			record.addMetadata("url", uri.URI)
+		}
+
+		record.Type = constants.SecretTypePassword
+		record.Data = data
+		record.addField(constants.FieldTypeHidden, "totp", login.TOTP)
+		record.addMetadata("notes", item.Notes)
+	case bitwardenTypeSecureNote:
+		record.Type = constants.SecretTypeText
+		record.Data = types.TextData{Content: item.Notes}
+	case bitwardenTypeCard:
+		card := item.Card
+		if card == nil {
+			card = &bitwardenCard{}
+		}
+
+		record.Type = constants.SecretTypeCard
+		record.Data = types.CardData{
+			Number: card.Number,
+			Holder: card.CardholderName,
+			Expiry: bitwardenExpiry(card.ExpMonth, card.ExpYear),
+			CVV:    card.Code,
+		}
+		record.addMetadata("brand", card.Brand)
+		record.addMetadata("notes", item.Notes)
+	case bitwardenTypeIdentity:
+		record.Type = constants.SecretTypeText
+		record.Data = types.TextData{Content: identityContent(item.Identity)}
+		record.addMetadata("notes", item.Notes)
+	default:
+		record.Err = fmt.Errorf("unsupported Bitwarden item type %d", item.Type)
+	}
+
+	return record
+}
+
+// bitwardenExpiry formats month and year as MM/YYYY, leaving other input as is
+// for validation to report.
+func bitwardenExpiry(month, year string) string {
+	if parsed, err := strconv.Atoi(month); err == nil && parsed >= 1 && parsed <= 12 {
+		month = fmt.Sprintf("%02d", parsed)
+	}
+	return month + "/" + year
+}
+
+// identityContent renders identity fields as "key: value" lines.
+func identityContent(identity map[string]any) string {
+	record := newRecord("")
+	for key, value := range identity {
+		if value == nil {
+			continue
+		}
+		record.addMetadata(key, fmt.Sprint(value))
+	}
+	return strings.TrimSpace(record.MetadataString())
+}
+
+func writeBitwardenJSON(w io.Writer, secrets []*types.LocalSecret, side *sideFiles) error {
+	export := bitwardenExport{Items: make([]bitwardenItem, 0, len(secrets))}
+
+	for _, secret := range secrets {
+		data, err := secret.ParseData()
+		if err != nil {
+			return fmt.Errorf("failed to parse secret %s: %w", secret.UUID, err)
+		}
+
+		item := bitwardenItem{ID: secret.UUID, Name: secret.Name}
+		if secret.Metadata != "" {
+			item.Fields = append(item.Fields, bitwardenField{Name: bitwardenMetadataField, Value: secret.Metadata})
+		}
+		for _, field := range secret.Fields {
+			fieldType := bitwardenFieldText
+			if field.IsHidden() {
+				fieldType = bitwardenFieldHidden
+			}
+			item.Fields = append(item.Fields, bitwardenField{Name: field.Label, Value: field.Value, Type: fieldType})
+		}
+		if len(secret.Tags) > 0 {
+			item.Fields = append(item.Fields, bitwardenField{Name: bitwardenTagsField, Value: strings.Join(secret.Tags, ",")})
+		}
+
+		switch data := data.(type) {
+		case types.LoginData:
+			item.Type = bitwardenTypeLogin
+			item.Login = &bitwardenLogin{Username: data.Username, Password: data.Password}
+			if data.URL != "" {
+				item.Login.URIs = []bitwardenURI{{URI: data.URL}}
+			}
+		case types.TextData:
+			item.Type = bitwardenTypeSecureNote
+			item.SecureNote = &bitwardenSecureNote{}
+			item.Notes = data.Content
+		case types.CardData:
+			month, year := splitExpiry(data.Expiry)
+			item.Type = bitwardenTypeCard
+			item.Card = &bitwardenCard{
+				CardholderName: data.Holder,
+				Number:         data.Number,
+				ExpMonth:       month,
+				ExpYear:        year,
+				Code:           data.CVV,
+			}
+		case types.FileData:
+			// NOTE: The format has no file items, the file goes next to the export
+			item.Type = bitwardenTypeSecureNote
+			item.SecureNote = &bitwardenSecureNote{}
+			item.Notes = "File: " + side.add(secret, data)
+		}
+
+		export.Items = append(export.Items, item)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(export); err != nil {
+		return fmt.Errorf("failed to write Bitwarden JSON: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package interop
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bitwardenFixture = `{
+	"encrypted": false,
+	"folders": [{"id": "f1", "name": "Work"}],
+	"items": [
+		{
+			"type": 1, "name": "Mail", "folderId": "f1", "notes": "shared",
+			"fields": [
+				{"name": "PIN", "value": "1234", "type": 1},
+				{"name": "Account", "value": "42", "type": 0},
+				{"name": "Username", "value": null, "type": 3, "linkedId": 100},
+				{"name": "tags", "value": "work, Shared Mail", "type": 0}
+			],
+			"login": {
+				"uris": [{"uri": "https://mail.example.com"}, {"uri": "https://webmail.example.com"}],
+				"username": "alice", "password": "s3cret", "totp": "otpauth://totp/x"
+			}
+		},
+		{"type": 2, "name": "Wifi", "notes": "guest / welcome", "secureNote": {"type": 0}},
+		{
+			"type": 3, "name": "Visa", "card": {
+				"cardholderName": "Alice", "brand": "Visa", "number": "4111111111111111",
+				"expMonth": "7", "expYear": "2030", "code": "123"
+			}
+		},
+		{"type": 4, "name": "Passport", "identity": {"firstName": "Alice", "passportNumber": "X1", "middleName": null}},
+		{"type": 9, "name": "Future"}
+	]
+}`
+
+func TestParseBitwardenJSON(t *testing.T) {
+	records, err := Parse(strings.NewReader(bitwardenFixture), FormatBitwardenJSON, ParseOptions{})
+	require.NoError(t, err)
+	require.Len(t, records, 5)
+
+	login := records[0]
+	assert.Equal(t, constants.SecretTypePassword, login.Type)
+	assert.Equal(t, types.LoginData{Username: "alice", Password: "s3cret", URL: "https://mail.example.com"}, login.Data)
+	assert.Equal(t, map[string]string{
+		"folder": "Work",
+		"url":    "https://webmail.example.com",
+		"notes":  "shared",
+	}, login.Metadata)
+	assert.Equal(t, []types.CustomField{
+		{Type: constants.FieldTypeHidden, Label: "PIN", Value: "1234"},
+		{Type: constants.FieldTypeText, Label: "Account", Value: "42"},
+		{Type: constants.FieldTypeHidden, Label: "totp", Value: "otpauth://totp/x"},
+	}, login.Fields)
+	assert.Equal(t, []string{"work", "Shared-Mail"}, login.Tags)
+
+	assert.Equal(t, types.TextData{Content: "guest / welcome"}, records[1].Data)
+
+	card := records[2]
+	assert.Equal(t, types.CardData{Number: "4111111111111111", Holder: "Alice", Expiry: "07/2030", CVV: "123"}, card.Data)
+	assert.Equal(t, "Visa", card.Metadata["brand"])
+
+	assert.Equal(t, types.TextData{Content: "firstName: Alice\npassportNumber: X1"}, records[3].Data)
+
+	assert.ErrorContains(t, records[4].Err, "unsupported Bitwarden item type 9")
+}
+
+func TestParseBitwardenJSON_Encrypted(t *testing.T) {
+	_, err := Parse(strings.NewReader(`{"encrypted": true, "items": []}`), FormatBitwardenJSON, ParseOptions{})
+	assert.ErrorContains(t, err, "encrypted Bitwarden exports are not supported")
+}
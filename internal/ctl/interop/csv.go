@@ -0,0 +1,320 @@
+package interop
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+// Secret fields CSV columns can be mapped to.
+const (
+	csvFieldType     = "type"
+	csvFieldName     = "name"
+	csvFieldUsername = "username"
+	csvFieldPassword = "password"
+	csvFieldURL      = "url"
+	csvFieldNotes    = "notes"
+	csvFieldContent  = "content"
+	csvFieldNumber   = "number"
+	csvFieldHolder   = "holder"
+	csvFieldExpiry   = "expiry"
+	csvFieldCVV      = "cvv"
+	csvFieldTags     = "tags"
+)
+
+// csvAliases are the column names recognized for each field when no mapping
+// is given, compared case-insensitively.
+var csvAliases = map[string][]string{
+	csvFieldType:     {"type"},
+	csvFieldName:     {"name", "title"},
+	csvFieldUsername: {"username", "user name", "user", "login", "login_username"},
+	csvFieldPassword: {"password", "login_password"},
+	csvFieldURL:      {"url", "uri", "website", "login_uri"},
+	csvFieldNotes:    {"notes", "note", "comments", "extra"},
+	csvFieldContent:  {"content", "text"},
+	csvFieldNumber:   {"number", "card number", "card_number"},
+	csvFieldHolder:   {"holder", "cardholder", "cardholder name", "card_holder"},
+	csvFieldExpiry:   {"expiry", "expiration", "exp"},
+	csvFieldCVV:      {"cvv", "code", "security code"},
+	csvFieldTags:     {"tags"},
+}
+
+// csvTypes maps values of the type column to secret types.
+var csvTypes = map[string]string{
+	constants.SecretTypePassword: constants.SecretTypePassword,
+	"login":                      constants.SecretTypePassword,
+	constants.SecretTypeText:     constants.SecretTypeText,
+	"note":                       constants.SecretTypeText,
+	"securenote":                 constants.SecretTypeText,
+	constants.SecretTypeCard:     constants.SecretTypeCard,
+}
+
+// ParseCSVMapping parses "field=Column,field=Column" into a mapping of secret
+// fields to CSV column names.
+func ParseCSVMapping(spec string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	if strings.TrimSpace(spec) == "" {
+		return mapping, nil
+	}
+
+	for _, pair := range strings.Split(spec, ",") {
+		field, column, found := strings.Cut(pair, "=")
+		field = strings.ToLower(strings.TrimSpace(field))
+		column = strings.TrimSpace(column)
+		if !found || column == "" {
+			return nil, errs.Validationf("invalid mapping %q, expected field=Column", pair)
+		}
+		if _, known := csvAliases[field]; !known {
+			return nil, errs.Validationf("unknown field %q in mapping, expected one of %s", field, csvFieldList())
+		}
+		mapping[field] = column
+	}
+
+	return mapping, nil
+}
+
+func csvFieldList() string {
+	fields := make([]string, 0, len(csvAliases))
+	for field := range csvAliases {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return strings.Join(fields, ", ")
+}
+
+func parseCSV(r io.Reader, mapping map[string]string) ([]*Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errs.Validationf("failed to read CSV header: %w", err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	columns, err := resolveCSVColumns(header, mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*Record
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errs.Validationf("failed to read CSV: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		records = append(records, csvRecord(fmt.Sprintf("line %d", line), header, columns, row))
+	}
+
+	return records, nil
+}
+
+// resolveCSVColumns returns the column index of every mapped field, taking
+// explicit mappings first and known column names for the rest.
+func resolveCSVColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	indexes := make(map[string]int, len(header))
+	for i, column := range header {
+		indexes[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+
+	columns := make(map[string]int)
+	used := make(map[int]bool)
+	for field, column := range mapping {
+		index, exists := indexes[strings.ToLower(column)]
+		if !exists {
+			return nil, errs.Validationf("column %q mapped to %s is not in the CSV header", column, field)
+		}
+		columns[field] = index
+		used[index] = true
+	}
+
+	for field, aliases := range csvAliases {
+		if _, mapped := columns[field]; mapped {
+			continue
+		}
+		for _, alias := range aliases {
+			if index, exists := indexes[alias]; exists && !used[index] {
+				columns[field] = index
+				used[index] = true
+				break
+			}
+		}
+	}
+
+	if _, exists := columns[csvFieldName]; !exists {
+		return nil, errs.Validationf("no name column found, map one with name=Column")
+	}
+
+	return columns, nil
+}
+
+func csvRecord(ref string, header []string, columns map[string]int, row []string) *Record {
+	record := newRecord(ref)
+
+	mapped := make(map[int]bool, len(columns))
+	value := func(field string) string {
+		index, exists := columns[field]
+		if !exists || index >= len(row) {
+			return ""
+		}
+		mapped[index] = true
+		return row[index]
+	}
+
+	record.Name = value(csvFieldName)
+
+	secretType := ""
+	if typeValue := strings.ToLower(strings.TrimSpace(value(csvFieldType))); typeValue != "" {
+		known, exists := csvTypes[typeValue]
+		if !exists {
+			record.Err = fmt.Errorf("unsupported type %q", typeValue)
+			return record
+		}
+		secretType = known
+	}
+
+	if secretType == "" {
+		switch {
+		case value(csvFieldNumber) != "":
+			secretType = constants.SecretTypeCard
+		case value(csvFieldUsername) != "" || value(csvFieldPassword) != "":
+			secretType = constants.SecretTypePassword
+		default:
+			secretType = constants.SecretTypeText
+		}
+	}
+
+	record.Type = secretType
+	record.addTags(value(csvFieldTags))
+	switch secretType {
+	case constants.SecretTypePassword:
+		record.Data = types.LoginData{
+			Username: value(csvFieldUsername),
+			Password: value(csvFieldPassword),
+			URL:      value(csvFieldURL),
+		}
+		record.addMetadata("notes", value(csvFieldNotes))
+		record.addMetadata("content", value(csvFieldContent))
+	case constants.SecretTypeCard:
+		record.Data = types.CardData{
+			Number: value(csvFieldNumber),
+			Holder: value(csvFieldHolder),
+			Expiry: value(csvFieldExpiry),
+			CVV:    value(csvFieldCVV),
+		}
+		record.addMetadata("notes", value(csvFieldNotes))
+	case constants.SecretTypeText:
+		content := value(csvFieldContent)
+		notes := value(csvFieldNotes)
+		if content == "" {
+			content, notes = notes, ""
+		}
+		record.Data = types.TextData{Content: content}
+		record.addMetadata("notes", notes)
+		record.addMetadata("url", value(csvFieldURL))
+	}
+
+	for i, cell := range row {
+		if mapped[i] || i >= len(header) {
+			continue
+		}
+		if fieldType, label, isField := csvFieldColumn(header[i]); isField {
+			record.addField(fieldType, label, cell)
+			continue
+		}
+		record.addMetadata(header[i], cell)
+	}
+
+	return record
+}
+
+// csvFieldColumn splits a custom field column, named "type:Label" such as
+// "hidden:PIN", into the field type and label.
+func csvFieldColumn(column string) (string, string, bool) {
+	fieldType, label, found := strings.Cut(column, ":")
+	if !found || !slices.Contains(types.FieldTypes, fieldType) {
+		return "", "", false
+	}
+	return fieldType, label, true
+}
+
+// csvExportHeader names the columns of exported CSV files, which import reads
+// back. A column per custom field follows them, see csvFieldColumn.
+var csvExportHeader = []string{
+	csvFieldType, csvFieldName, csvFieldUsername, csvFieldPassword, csvFieldURL, csvFieldContent,
+	csvFieldNumber, csvFieldHolder, csvFieldExpiry, csvFieldCVV, "file", "metadata", csvFieldTags,
+}
+
+func writeCSV(w io.Writer, secrets []*types.LocalSecret, side *sideFiles) error {
+	header := slices.Clone(csvExportHeader)
+	for _, secret := range secrets {
+		for _, field := range secret.Fields {
+			column := field.Type + ":" + field.Label
+			if !slices.Contains(header, column) {
+				header = append(header, column)
+			}
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	for _, secret := range secrets {
+		data, err := secret.ParseData()
+		if err != nil {
+			return fmt.Errorf("failed to parse secret %s: %w", secret.UUID, err)
+		}
+
+		row := make(map[string]string, len(header))
+		switch data := data.(type) {
+		case types.LoginData:
+			row[csvFieldUsername] = data.Username
+			row[csvFieldPassword] = data.Password
+			row[csvFieldURL] = data.URL
+		case types.TextData:
+			row[csvFieldContent] = data.Content
+		case types.CardData:
+			row[csvFieldNumber] = data.Number
+			row[csvFieldHolder] = data.Holder
+			row[csvFieldExpiry] = data.Expiry
+			row[csvFieldCVV] = data.CVV
+		case types.FileData:
+			row["file"] = side.add(secret, data)
+		}
+		row[csvFieldType] = secret.Type
+		row[csvFieldName] = secret.Name
+		row["metadata"] = secret.Metadata
+		row[csvFieldTags] = strings.Join(secret.Tags, ",")
+		for _, field := range secret.Fields {
+			row[field.Type+":"+field.Label] = field.Value
+		}
+
+		record := make([]string, len(header))
+		for i, column := range header {
+			record[i] = row[column]
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
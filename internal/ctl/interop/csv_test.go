@@ -0,0 +1,92 @@
+package interop
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCSV(t *testing.T) {
+	t.Run("known columns", func(t *testing.T) {
+		input := "\ufeffTitle,Username,Password,URL,Notes,Department\n" +
+			"Mail,alice,s3cret,https://mail.example.com,,IT\n" +
+			"Wifi,,,,\"guest\nwelcome\",\n" +
+			"Card,,,,,\n"
+
+		records, err := Parse(strings.NewReader(input), FormatCSV, ParseOptions{})
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+
+		assert.Equal(t, "line 2", records[0].Ref)
+		assert.Equal(t, constants.SecretTypePassword, records[0].Type)
+		assert.Equal(t, types.LoginData{Username: "alice", Password: "s3cret", URL: "https://mail.example.com"}, records[0].Data)
+		assert.Equal(t, "Department: IT", records[0].MetadataString())
+
+		assert.Equal(t, "line 3", records[1].Ref)
+		assert.Equal(t, types.TextData{Content: "guest\nwelcome"}, records[1].Data)
+
+		assert.Equal(t, "line 5", records[2].Ref)
+		assert.Equal(t, constants.SecretTypeText, records[2].Type)
+	})
+
+	t.Run("explicit mapping and type column", func(t *testing.T) {
+		mapping, err := ParseCSVMapping("name=Label, number=PAN, holder=Owner, expiry=Valid, cvv=CVC")
+		require.NoError(t, err)
+
+		input := "Kind,Label,PAN,Owner,Valid,CVC\n" +
+			"card,Visa,4111111111111111,Alice,07/30,123\n" +
+			"wallet,Other,,,,\n"
+
+		records, err := Parse(strings.NewReader(input), FormatCSV, ParseOptions{CSVMapping: mapping})
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+
+		assert.Equal(t, types.CardData{Number: "4111111111111111", Holder: "Alice", Expiry: "07/30", CVV: "123"}, records[0].Data)
+		assert.Equal(t, "Kind: card", records[0].MetadataString())
+		assert.NoError(t, records[1].Err)
+
+		mapping, err = ParseCSVMapping("type=Kind,name=Label")
+		require.NoError(t, err)
+		records, err = Parse(strings.NewReader(input), FormatCSV, ParseOptions{CSVMapping: mapping})
+		require.NoError(t, err)
+		assert.ErrorContains(t, records[1].Err, `unsupported type "wallet"`)
+	})
+
+	t.Run("tags and custom field columns", func(t *testing.T) {
+		input := "name,password,tags,hidden:PIN,text:Account,Department\n" +
+			"Mail,s3cret,\"work,mail\",1234,42,IT\n"
+
+		records, err := Parse(strings.NewReader(input), FormatCSV, ParseOptions{})
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+
+		assert.Equal(t, []string{"work", "mail"}, records[0].Tags)
+		assert.Equal(t, []types.CustomField{
+			{Type: constants.FieldTypeHidden, Label: "PIN", Value: "1234"},
+			{Type: constants.FieldTypeText, Label: "Account", Value: "42"},
+		}, records[0].Fields)
+		assert.Equal(t, "Department: IT", records[0].MetadataString())
+	})
+
+	t.Run("invalid mapping", func(t *testing.T) {
+		_, err := ParseCSVMapping("colour=Red")
+		assert.ErrorContains(t, err, "unknown field")
+
+		_, err = ParseCSVMapping("name")
+		assert.ErrorContains(t, err, "expected field=Column")
+
+		mapping, err := ParseCSVMapping("name=Missing")
+		require.NoError(t, err)
+		_, err = Parse(strings.NewReader("Title\nx\n"), FormatCSV, ParseOptions{CSVMapping: mapping})
+		assert.ErrorContains(t, err, `column "Missing" mapped to name`)
+	})
+
+	t.Run("no name column", func(t *testing.T) {
+		_, err := Parse(strings.NewReader("Login,Password\na,b\n"), FormatCSV, ParseOptions{})
+		assert.ErrorContains(t, err, "no name column found")
+	})
+}
@@ -0,0 +1,140 @@
+package interop
+
+import (
+	"io"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+// Filter selects the secrets to export. Empty fields match everything.
+type Filter struct {
+	Types []string
+	// NamePattern is a glob such as "prod-*", see path.Match.
+	NamePattern string
+	// Folder selects a subtree such as "work/aws", see types.NormalizeFolder.
+	Folder string
+	// Tags selects secrets carrying all of them, see types.NormalizeTags.
+	Tags []string
+}
+
+// Validate checks the filter before any secret is matched against it.
+func (f Filter) Validate() error {
+	for _, secretType := range f.Types {
+		switch secretType {
+		case constants.SecretTypePassword, constants.SecretTypeText, constants.SecretTypeBinary, constants.SecretTypeCard:
+		default:
+			return errs.Validationf("unknown secret type %q", secretType)
+		}
+	}
+
+	if _, err := path.Match(f.NamePattern, ""); err != nil {
+		return errs.Validationf("invalid name pattern %q: %w", f.NamePattern, err)
+	}
+
+	normalized, err := types.NormalizeTags(f.Tags)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(normalized, f.Tags) {
+		return errs.Validationf("tags %q are not normalized", f.Tags)
+	}
+
+	return nil
+}
+
+// Match reports whether the secret passes the filter.
+func (f Filter) Match(secret *types.LocalSecret) bool {
+	if len(f.Types) > 0 {
+		found := false
+		for _, secretType := range f.Types {
+			found = found || secretType == secret.Type
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if f.NamePattern != "" {
+		matched, _ := path.Match(f.NamePattern, secret.Name)
+		if !matched {
+			return false
+		}
+	}
+
+	for _, tag := range f.Tags {
+		if !secret.HasTag(tag) {
+			return false
+		}
+	}
+
+	return types.InFolder(secret.Path(), f.Folder)
+}
+
+// SideFile is a binary secret a format cannot embed, to be written next to
+// the export under Path, relative to the export's directory.
+type SideFile struct {
+	Secret *types.LocalSecret
+	Path   string
+}
+
+// sideFiles names the side files of one export.
+type sideFiles struct {
+	dir   string
+	files []SideFile
+}
+
+// add records the binary secret and returns the path the export refers to it by.
+func (s *sideFiles) add(secret *types.LocalSecret, data types.FileData) string {
+	name := filepath.Base(strings.ReplaceAll(data.FileName, "\\", "/"))
+	if name == "." || name == "/" || name == ".." {
+		name = "file"
+	}
+
+	sidePath := path.Join(s.dir, secret.UUID+"-"+name)
+	s.files = append(s.files, SideFile{Secret: secret, Path: sidePath})
+
+	return sidePath
+}
+
+// Export renders secrets, which must have their data loaded, in format.
+// Binaries the format cannot embed, and those kept in attachments, are
+// returned as side files under filesDir, for the caller to write.
+func Export(w io.Writer, format string, secrets []*types.LocalSecret, filesDir string) ([]SideFile, error) {
+	side := &sideFiles{dir: filesDir}
+
+	var err error
+	switch format {
+	case FormatKeePassXML:
+		err = writeKeePassXML(w, secrets, side)
+	case FormatBitwardenJSON:
+		err = writeBitwardenJSON(w, secrets, side)
+	case FormatCSV:
+		err = writeCSV(w, secrets, side)
+	default:
+		return nil, errs.Validationf("unknown format %q, expected %s, %s or %s",
+			format, FormatKeePassXML, FormatBitwardenJSON, FormatCSV)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return side.files, nil
+}
+
+// splitExpiry splits MM/YY or MM/YYYY into month and four digit year.
+func splitExpiry(expiry string) (string, string) {
+	month, year, found := strings.Cut(expiry, "/")
+	if !found {
+		return expiry, ""
+	}
+	if len(year) == 2 {
+		year = "20" + year
+	}
+	return month, year
+}
@@ -0,0 +1,131 @@
+package interop
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newExportFixture(t *testing.T) []*types.LocalSecret {
+	cryptor := crypto.NewCryptor("password", "login")
+
+	fields := []types.CustomField{
+		{Type: constants.FieldTypeHidden, Label: "PIN", Value: "1234"},
+		{Type: constants.FieldTypeText, Label: "Account", Value: "42"},
+	}
+
+	newSecret := func(secretType, name, metadata string, data types.SecretData, tags ...string) *types.LocalSecret {
+		base := types.BaseSecret{Type: secretType, Name: name, Metadata: metadata, Tags: tags}
+		if secretType == constants.SecretTypePassword {
+			base.Fields = fields
+		}
+		secret, err := types.NewSecretModel(base, data, cryptor)
+		require.NoError(t, err)
+		return secret
+	}
+
+	return []*types.LocalSecret{
+		newSecret(constants.SecretTypePassword, "Mail", "work",
+			types.LoginData{Username: "alice", Password: "s3cret", URL: "https://mail.example.com"}, "work", "mail"),
+		newSecret(constants.SecretTypeText, "Wifi", "",
+			types.TextData{Content: "guest\nwelcome"}, "work"),
+		newSecret(constants.SecretTypeCard, "Visa", "",
+			types.CardData{Number: "4111111111111111", Holder: "Alice", Expiry: "07/2030", CVV: "123"}),
+		newSecret(constants.SecretTypeBinary, "Key", "",
+			types.FileData{FileName: "../id_ed25519", FileSize: 3, Content: "AAEC"}),
+	}
+}
+
+func TestExport(t *testing.T) {
+	secrets := newExportFixture(t)
+
+	for _, format := range []string{FormatCSV, FormatBitwardenJSON, FormatKeePassXML} {
+		t.Run(format, func(t *testing.T) {
+			var out bytes.Buffer
+			sideFiles, err := Export(&out, format, secrets, "export.files")
+			require.NoError(t, err)
+
+			if format == FormatKeePassXML {
+				assert.Empty(t, sideFiles, "KeePass embeds binaries")
+			} else {
+				require.Len(t, sideFiles, 1)
+				assert.Equal(t, "export.files/"+secrets[3].UUID+"-id_ed25519", sideFiles[0].Path)
+			}
+
+			records, err := Parse(&out, format, ParseOptions{})
+			require.NoError(t, err)
+
+			byName := make(map[string]*Record)
+			for _, record := range records {
+				byName[record.Name] = record
+			}
+
+			for _, secret := range secrets[:3] {
+				record, exists := byName[secret.Name]
+				require.True(t, exists, secret.Name)
+				require.NoError(t, record.Err)
+
+				data, err := secret.ParseData()
+				require.NoError(t, err)
+				assert.Equal(t, secret.Type, record.Type, secret.Name)
+				assert.Equal(t, data, record.Data, secret.Name)
+				assert.Equal(t, secret.Fields, record.Fields, secret.Name)
+
+				imported, err := record.Secret(crypto.NewCryptor("password", "login"))
+				require.NoError(t, err)
+				assert.Equal(t, secret.Tags, imported.Tags, secret.Name)
+			}
+
+			if format == FormatKeePassXML {
+				record, exists := byName["Key"]
+				require.True(t, exists)
+				assert.Equal(t, types.FileData{FileName: "../id_ed25519", FileSize: 3, Content: "AAEC"}, record.Data)
+			}
+		})
+	}
+}
+
+func TestExport_AttachedFile(t *testing.T) {
+	data := types.FileData{FileName: "disk.img", FileSize: constants.MaxFileSize + 1, Attachment: "attachment-uuid"}
+	secret, err := types.NewSecretModel(types.BaseSecret{Type: constants.SecretTypeBinary, Name: "Disk"},
+		data, crypto.NewCryptor("password", "login"))
+	require.NoError(t, err)
+
+	for _, format := range []string{FormatCSV, FormatBitwardenJSON, FormatKeePassXML} {
+		var out bytes.Buffer
+		sideFiles, err := Export(&out, format, []*types.LocalSecret{secret}, "export.files")
+		require.NoError(t, err)
+		require.Len(t, sideFiles, 1, format)
+		assert.Contains(t, out.String(), sideFiles[0].Path, format)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	secrets := newExportFixture(t)
+
+	filter := Filter{Types: []string{constants.SecretTypePassword, constants.SecretTypeCard}}
+	require.NoError(t, filter.Validate())
+	assert.True(t, filter.Match(secrets[0]))
+	assert.False(t, filter.Match(secrets[1]))
+
+	filter = Filter{NamePattern: "W*"}
+	require.NoError(t, filter.Validate())
+	assert.False(t, filter.Match(secrets[0]))
+	assert.True(t, filter.Match(secrets[1]))
+
+	filter = Filter{Tags: []string{"mail", "work"}}
+	require.NoError(t, filter.Validate())
+	assert.True(t, filter.Match(secrets[0]))
+	assert.False(t, filter.Match(secrets[1]))
+	assert.False(t, filter.Match(secrets[2]))
+
+	assert.Error(t, Filter{Types: []string{"wallet"}}.Validate())
+	assert.Error(t, Filter{Tags: []string{"Work"}}.Validate())
+	assert.Error(t, Filter{Tags: []string{"two words"}}.Validate())
+	assert.Error(t, Filter{NamePattern: "["}.Validate())
+}
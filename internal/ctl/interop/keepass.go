@@ -0,0 +1,359 @@
+package interop
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/google/uuid"
+)
+
+// KeePass 2 XML export, as written by KeePass and KeePassXC.
+type keePassFile struct {
+	XMLName  xml.Name        `xml:"KeePassFile"`
+	Binaries []keePassBinary `xml:"Meta>Binaries>Binary"`
+	Groups   []keePassGroup  `xml:"Root>Group"`
+}
+
+type keePassBinary struct {
+	ID         string `xml:"ID,attr"`
+	Compressed string `xml:"Compressed,attr,omitempty"`
+	Value      string `xml:",chardata"`
+}
+
+type keePassGroup struct {
+	UUID    string         `xml:"UUID,omitempty"`
+	Name    string         `xml:"Name"`
+	Entries []keePassEntry `xml:"Entry"`
+	Groups  []keePassGroup `xml:"Group"`
+}
+
+type keePassEntry struct {
+	UUID        string              `xml:"UUID,omitempty"`
+	Tags        string              `xml:"Tags,omitempty"`
+	Strings     []keePassString     `xml:"String"`
+	Attachments []keePassAttachment `xml:"Binary"`
+}
+
+type keePassString struct {
+	Key   string       `xml:"Key"`
+	Value keePassValue `xml:"Value"`
+}
+
+// keePassValue is a string value. KeePass marks protected ones with
+// ProtectInMemory in XML exports and with Protected in databases.
+type keePassValue struct {
+	Protected       string `xml:"Protected,attr,omitempty"`
+	ProtectInMemory string `xml:"ProtectInMemory,attr,omitempty"`
+	Text            string `xml:",chardata"`
+}
+
+func (v keePassValue) isProtected() bool {
+	return strings.EqualFold(v.Protected, "True") || strings.EqualFold(v.ProtectInMemory, "True")
+}
+
+type keePassAttachment struct {
+	Key   string `xml:"Key"`
+	Value struct {
+		Ref  string `xml:"Ref,attr"`
+		Data string `xml:",chardata"`
+	} `xml:"Value"`
+}
+
+const (
+	keePassTitle    = "Title"
+	keePassUserName = "UserName"
+	keePassPassword = "Password"
+	keePassURL      = "URL"
+	keePassNotes    = "Notes"
+
+	// keePassMetadata holds the metadata of exported entries
+	keePassMetadata = "Metadata"
+
+	// Card fields are custom strings, KeePass has no card entries
+	keePassCardNumber = "Card Number"
+	keePassCardHolder = "Card Holder"
+	keePassCardExpiry = "Expiry"
+	keePassCardCVV    = "CVV"
+)
+
+// keePassOTPKeys are the strings KeePass and KeePassXC keep one-time password
+// seeds in, imported as hidden fields even when not protected.
+var keePassOTPKeys = []string{
+	"otp", "TOTP Seed",
+	"TimeOtp-Secret", "TimeOtp-Secret-Hex", "TimeOtp-Secret-Base32", "TimeOtp-Secret-Base64",
+	"HmacOtp-Secret", "HmacOtp-Secret-Hex", "HmacOtp-Secret-Base32", "HmacOtp-Secret-Base64",
+}
+
+func parseKeePassXML(r io.Reader) ([]*Record, error) {
+	var file keePassFile
+	if err := xml.NewDecoder(r).Decode(&file); err != nil {
+		return nil, errs.Validationf("failed to parse KeePass XML: %w", err)
+	}
+
+	binaries := make(map[string]keePassBinary, len(file.Binaries))
+	for _, binary := range file.Binaries {
+		binaries[binary.ID] = binary
+	}
+
+	parser := &keePassParser{binaries: binaries}
+	for _, group := range file.Groups {
+		// NOTE: The top group is the database itself, not part of entry paths
+		parser.walk(group, "")
+	}
+
+	return parser.records, nil
+}
+
+type keePassParser struct {
+	binaries map[string]keePassBinary
+	records  []*Record
+	entries  int
+}
+
+func (p *keePassParser) walk(group keePassGroup, path string) {
+	for _, entry := range group.Entries {
+		p.entries++
+		p.addEntry(entry, path)
+	}
+
+	for _, child := range group.Groups {
+		childPath := child.Name
+		if path != "" {
+			childPath = path + "/" + child.Name
+		}
+		p.walk(child, childPath)
+	}
+}
+
+func (p *keePassParser) addEntry(entry keePassEntry, groupPath string) {
+	ref := fmt.Sprintf("entry %d", p.entries)
+	record := newRecord(ref)
+	record.addMetadata("group", groupPath)
+	record.addTags(entry.Tags)
+
+	fields := make(map[string]string, len(entry.Strings))
+	for _, field := range entry.Strings {
+		fields[field.Key] = field.Value.Text
+	}
+	record.Name = fields[keePassTitle]
+
+	username, password := fields[keePassUserName], fields[keePassPassword]
+	notes := fields[keePassNotes]
+	mapped := map[string]bool{keePassTitle: true, keePassUserName: true, keePassPassword: true, keePassURL: true, keePassNotes: true}
+	switch {
+	case fields[keePassCardNumber] != "":
+		record.Type = constants.SecretTypeCard
+		record.Data = types.CardData{
+			Number: fields[keePassCardNumber],
+			Holder: fields[keePassCardHolder],
+			Expiry: fields[keePassCardExpiry],
+			CVV:    fields[keePassCardCVV],
+		}
+		record.addMetadata("username", username)
+		record.addMetadata("notes", notes)
+		record.addMetadata("url", fields[keePassURL])
+		for _, key := range []string{keePassCardNumber, keePassCardHolder, keePassCardExpiry, keePassCardCVV} {
+			mapped[key] = true
+		}
+	case username != "" || password != "":
+		record.Type = constants.SecretTypePassword
+		record.Data = types.LoginData{Username: username, Password: password, URL: fields[keePassURL]}
+		record.addMetadata("notes", notes)
+	case notes != "":
+		record.Type = constants.SecretTypeText
+		record.Data = types.TextData{Content: notes}
+		record.addMetadata("url", fields[keePassURL])
+	case len(entry.Attachments) == 0:
+		record.Err = fmt.Errorf("entry has no username, password or notes")
+	}
+
+	for _, field := range entry.Strings {
+		if mapped[field.Key] {
+			continue
+		}
+		if field.Key == keePassMetadata {
+			record.addMetadata(field.Key, field.Value.Text)
+			continue
+		}
+		if field.Value.isProtected() || slices.Contains(keePassOTPKeys, field.Key) {
+			record.addField(constants.FieldTypeHidden, field.Key, field.Value.Text)
+			continue
+		}
+		record.addField(constants.FieldTypeText, field.Key, field.Value.Text)
+	}
+
+	if record.Type != "" || record.Err != nil {
+		p.records = append(p.records, record)
+	}
+
+	// NOTE: An entry holding nothing but one file is that file
+	standalone := record.Type == "" && record.Err == nil && len(entry.Attachments) == 1
+	for i, attachment := range entry.Attachments {
+		attachmentRecord := p.attachmentRecord(record, fmt.Sprintf("%s attachment %d", ref, i+1), attachment)
+		if standalone {
+			attachmentRecord.Ref = ref
+			if record.Name != "" {
+				attachmentRecord.Name = record.Name
+			}
+			for key, value := range record.Metadata {
+				attachmentRecord.Metadata[key] = value
+			}
+		}
+		p.records = append(p.records, attachmentRecord)
+	}
+}
+
+// attachmentRecord maps an attachment to a binary secret named after its entry.
+func (p *keePassParser) attachmentRecord(entry *Record, ref string, attachment keePassAttachment) *Record {
+	record := newRecord(ref)
+	record.Name = attachment.Key
+	if entry.Name != "" {
+		record.Name = entry.Name + "/" + attachment.Key
+	}
+	record.Type = constants.SecretTypeBinary
+	record.addMetadata("group", entry.Metadata["group"])
+
+	encoded, compressed := attachment.Value.Data, false
+	if attachment.Value.Ref != "" {
+		binary, exists := p.binaries[attachment.Value.Ref]
+		if !exists {
+			record.Err = fmt.Errorf("attachment references missing binary %s", attachment.Value.Ref)
+			return record
+		}
+		encoded, compressed = binary.Value, strings.EqualFold(binary.Compressed, "true")
+	}
+
+	content, err := decodeKeePassBinary(encoded, compressed)
+	if err != nil {
+		record.Err = err
+		return record
+	}
+
+	record.Data = types.FileData{
+		FileName: attachment.Key,
+		FileSize: int64(len(content)),
+		Content:  base64.StdEncoding.EncodeToString(content),
+	}
+
+	return record
+}
+
+func decodeKeePassBinary(encoded string, compressed bool) ([]byte, error) {
+	content, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode attachment: %w", err)
+	}
+
+	if !compressed {
+		return content, nil
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress attachment: %w", err)
+	}
+	defer reader.Close()
+
+	content, err = io.ReadAll(io.LimitReader(reader, constants.MaxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress attachment: %w", err)
+	}
+
+	return content, nil
+}
+
+func writeKeePassXML(w io.Writer, secrets []*types.LocalSecret, side *sideFiles) error {
+	file := keePassFile{Groups: []keePassGroup{{UUID: keePassUUID("go-keeper"), Name: "go-keeper"}}}
+	root := &file.Groups[0]
+
+	for _, secret := range secrets {
+		data, err := secret.ParseData()
+		if err != nil {
+			return fmt.Errorf("failed to parse secret %s: %w", secret.UUID, err)
+		}
+
+		entry := keePassEntry{UUID: keePassUUID(secret.UUID), Tags: strings.Join(secret.Tags, ";")}
+		add := func(key, value string) {
+			if value != "" {
+				entry.Strings = append(entry.Strings, keePassString{Key: key, Value: keePassValue{Text: value}})
+			}
+		}
+		addProtected := func(key, value string) {
+			if value != "" {
+				entry.Strings = append(entry.Strings, keePassString{Key: key, Value: keePassValue{ProtectInMemory: "True", Text: value}})
+			}
+		}
+
+		add(keePassTitle, secret.Name)
+		switch data := data.(type) {
+		case types.LoginData:
+			add(keePassUserName, data.Username)
+			addProtected(keePassPassword, data.Password)
+			add(keePassURL, data.URL)
+		case types.TextData:
+			add(keePassNotes, data.Content)
+		case types.CardData:
+			add(keePassCardNumber, data.Number)
+			add(keePassCardHolder, data.Holder)
+			add(keePassCardExpiry, data.Expiry)
+			addProtected(keePassCardCVV, data.CVV)
+		case types.FileData:
+			if data.Attachment != "" {
+				// NOTE: Files kept in attachments are too large to embed
+				add("File", side.add(secret, data))
+				break
+			}
+
+			// NOTE: Binaries are embedded in the file, as KeePass itself does
+			binaryID := strconv.Itoa(len(file.Binaries))
+			file.Binaries = append(file.Binaries, keePassBinary{ID: binaryID, Value: data.Content})
+
+			attachment := keePassAttachment{Key: data.FileName}
+			attachment.Value.Ref = binaryID
+			entry.Attachments = append(entry.Attachments, attachment)
+		}
+		add(keePassMetadata, secret.Metadata)
+		for _, field := range secret.Fields {
+			if field.IsHidden() {
+				addProtected(field.Label, field.Value)
+				continue
+			}
+			add(field.Label, field.Value)
+		}
+
+		root.Entries = append(root.Entries, entry)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write KeePass XML: %w", err)
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "\t")
+	if err := encoder.Encode(file); err != nil {
+		return fmt.Errorf("failed to write KeePass XML: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// keePassUUID derives the base64 16 byte UUID KeePass expects from an ID.
+func keePassUUID(id string) string {
+	if parsed, err := uuid.Parse(id); err == nil {
+		return base64.StdEncoding.EncodeToString(parsed[:])
+	}
+
+	parsed := uuid.NewSHA1(uuid.NameSpaceOID, []byte(id))
+	return base64.StdEncoding.EncodeToString(parsed[:])
+}
@@ -0,0 +1,92 @@
+package interop
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const keePassFixture = `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<KeePassFile>
+	<Meta>
+		<Binaries>
+			<Binary ID="0" Compressed="True">H4sIACdJ1moC/ysuzlDITq1USKosSS0GANrkDrENAAAA</Binary>
+		</Binaries>
+	</Meta>
+	<Root>
+		<Group>
+			<Name>Database</Name>
+			<Entry>
+				<Tags>work;mail</Tags>
+				<String><Key>Title</Key><Value>Mail</Value></String>
+				<String><Key>UserName</Key><Value>alice</Value></String>
+				<String><Key>Password</Key><Value Protected="True">s3cret</Value></String>
+				<String><Key>URL</Key><Value>https://mail.example.com</Value></String>
+				<String><Key>Notes</Key><Value>work account</Value></String>
+				<String><Key>Recovery</Key><Value>ABCD-EFGH</Value></String>
+				<String><Key>PIN</Key><Value ProtectInMemory="True">1234</Value></String>
+				<String><Key>otp</Key><Value>otpauth://totp/x</Value></String>
+				<History>
+					<Entry>
+						<String><Key>Title</Key><Value>Mail (old)</Value></String>
+					</Entry>
+				</History>
+			</Entry>
+			<Group>
+				<Name>Servers</Name>
+				<Entry>
+					<String><Key>Title</Key><Value>Bastion</Value></String>
+					<String><Key>Notes</Key><Value>ssh -J bastion</Value></String>
+					<Binary><Key>id_ed25519</Key><Value Ref="0"/></Binary>
+				</Entry>
+				<Entry>
+					<String><Key>Title</Key><Value>Empty</Value></String>
+				</Entry>
+			</Group>
+		</Group>
+	</Root>
+</KeePassFile>`
+
+func TestParseKeePassXML(t *testing.T) {
+	records, err := Parse(strings.NewReader(keePassFixture), FormatKeePassXML, ParseOptions{})
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+
+	login := records[0]
+	assert.Equal(t, "entry 1", login.Ref)
+	assert.Equal(t, "Mail", login.Name)
+	assert.Equal(t, types.LoginData{Username: "alice", Password: "s3cret", URL: "https://mail.example.com"}, login.Data)
+	assert.Equal(t, "notes: work account", login.MetadataString())
+	assert.Equal(t, []types.CustomField{
+		{Type: constants.FieldTypeText, Label: "Recovery", Value: "ABCD-EFGH"},
+		{Type: constants.FieldTypeHidden, Label: "PIN", Value: "1234"},
+		{Type: constants.FieldTypeHidden, Label: "otp", Value: "otpauth://totp/x"},
+	}, login.Fields)
+	assert.Equal(t, []string{"work", "mail"}, login.Tags)
+
+	note := records[1]
+	assert.Equal(t, types.TextData{Content: "ssh -J bastion"}, note.Data)
+	assert.Equal(t, "Servers", note.Metadata["group"])
+
+	attachment := records[2]
+	assert.Equal(t, "entry 2 attachment 1", attachment.Ref)
+	assert.Equal(t, "Bastion/id_ed25519", attachment.Name)
+	file, ok := attachment.Data.(types.FileData)
+	require.True(t, ok)
+	content, err := base64.StdEncoding.DecodeString(file.Content)
+	require.NoError(t, err)
+	assert.Equal(t, "ssh key bytes", string(content))
+	assert.Equal(t, int64(len(content)), file.FileSize)
+
+	assert.ErrorContains(t, records[3].Err, "no username, password or notes")
+}
+
+func TestParseKeePassXML_Malformed(t *testing.T) {
+	_, err := Parse(strings.NewReader("<KeePassFile><Root>"), FormatKeePassXML, ParseOptions{})
+	assert.ErrorContains(t, err, "failed to parse KeePass XML")
+}
@@ -0,0 +1,149 @@
+package interop
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+// Formats of other password managers secrets are exchanged with.
+const (
+	FormatKeePassXML    = "keepass-xml"
+	FormatBitwardenJSON = "bitwarden-json"
+	FormatCSV           = "csv"
+)
+
+// Record is an entry read from another password manager, mapped to a secret.
+type Record struct {
+	// Ref locates the entry in the source, such as "line 4" or "item 2".
+	Ref      string
+	Name     string
+	Type     string
+	Data     types.SecretData
+	Metadata map[string]string
+	// Fields are the source's custom fields, see addField.
+	Fields []types.CustomField
+	Tags   []string
+	// Err is set when the entry could not be mapped to any secret type.
+	Err error
+}
+
+func newRecord(ref string) *Record {
+	return &Record{Ref: ref, Metadata: make(map[string]string)}
+}
+
+// addMetadata keeps a source field that has no place in the secret data.
+func (r *Record) addMetadata(key, value string) {
+	key = strings.TrimSpace(key)
+	if key == "" || strings.TrimSpace(value) == "" {
+		return
+	}
+
+	if existing, exists := r.Metadata[key]; exists {
+		value = existing + "\n" + value
+	}
+	r.Metadata[key] = value
+}
+
+// addField keeps a custom field of the source as a custom field of the
+// secret. Values of a label given twice are joined, hidden if either is.
+// Text that does not fit a field is kept as metadata instead.
+func (r *Record) addField(fieldType, label, value string) {
+	label = strings.TrimSpace(label)
+	if label == "" || strings.TrimSpace(value) == "" {
+		return
+	}
+
+	for i, field := range r.Fields {
+		if strings.EqualFold(field.Label, label) {
+			r.Fields[i].Value = field.Value + "\n" + value
+			if fieldType == constants.FieldTypeHidden {
+				r.Fields[i].Type = fieldType
+			}
+			return
+		}
+	}
+
+	// NOTE: Hidden values must not end up in metadata, which is shown in
+	// full, validation reports them instead
+	tooMany := len(r.Fields) >= constants.MaxCustomFields
+	tooLong := len(label) > constants.MaxFieldLabelLength || len(value) > constants.MaxFieldValueLength
+	if (tooMany || tooLong) && fieldType != constants.FieldTypeHidden {
+		r.addMetadata(label, value)
+		return
+	}
+
+	r.Fields = append(r.Fields, types.CustomField{Type: fieldType, Label: label, Value: value})
+}
+
+// addTags keeps tags given as a list separated by commas or semicolons.
+// Whitespace in a tag becomes a dash, as tags cannot hold it.
+func (r *Record) addTags(list string) {
+	tags := strings.FieldsFunc(list, func(c rune) bool { return c == ',' || c == ';' })
+	for _, tag := range tags {
+		tag = strings.Join(strings.Fields(tag), "-")
+		if tag != "" {
+			r.Tags = append(r.Tags, tag)
+		}
+	}
+}
+
+// MetadataString renders unmapped fields as "key: value" lines sorted by key.
+func (r *Record) MetadataString() string {
+	keys := make([]string, 0, len(r.Metadata))
+	for key := range r.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("%s: %s", key, r.Metadata[key]))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Secret builds the validated secret for the record.
+func (r *Record) Secret(cryptor crypto.Cryptor) (*types.LocalSecret, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	base := types.BaseSecret{
+		Type:     r.Type,
+		Name:     strings.TrimSpace(r.Name),
+		Metadata: r.MetadataString(),
+		Tags:     r.Tags,
+		Fields:   r.Fields,
+	}
+
+	return types.NewSecretModel(base, r.Data, cryptor)
+}
+
+// ParseOptions tune how records are read.
+type ParseOptions struct {
+	// CSVMapping maps secret fields to CSV column names, see ParseCSVMapping.
+	CSVMapping map[string]string
+}
+
+// Parse reads the records of a file in format.
+func Parse(r io.Reader, format string, opts ParseOptions) ([]*Record, error) {
+	switch format {
+	case FormatKeePassXML:
+		return parseKeePassXML(r)
+	case FormatBitwardenJSON:
+		return parseBitwardenJSON(r)
+	case FormatCSV:
+		return parseCSV(r, opts.CSVMapping)
+	default:
+		return nil, errs.Validationf("unknown format %q, expected %s, %s or %s",
+			format, FormatKeePassXML, FormatBitwardenJSON, FormatCSV)
+	}
+}
@@ -0,0 +1,46 @@
+package interop
+
+// Import statuses of a record.
+const (
+	StatusImported    = "imported"
+	StatusWouldImport = "would import"
+	StatusDuplicate   = "duplicate"
+	StatusFailed      = "failed"
+)
+
+// ImportOptions control how records are added to the vault.
+type ImportOptions struct {
+	ParseOptions
+
+	// DryRun reports what would be imported without changing the vault.
+	DryRun bool
+	// AllowDuplicates imports records whose name is already taken.
+	AllowDuplicates bool
+}
+
+// ImportResult is the outcome for one record.
+type ImportResult struct {
+	Ref    string
+	Name   string
+	Type   string
+	Status string
+	// Reason explains skipped and failed records.
+	Reason string
+}
+
+// ImportReport lists the outcome of every record in source order.
+type ImportReport struct {
+	DryRun  bool
+	Results []*ImportResult
+}
+
+// Count returns the number of records with status.
+func (r *ImportReport) Count(status string) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
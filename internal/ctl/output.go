@@ -0,0 +1,558 @@
+package ctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/buildinfo"
+	"github.com/etoneja/go-keeper/internal/ctl/backup"
+	"github.com/etoneja/go-keeper/internal/ctl/config"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/fsutil"
+	"github.com/etoneja/go-keeper/internal/ctl/interop"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats of the global --output flag. The JSON and YAML schemas are
+// documented in docs/output.md and only ever gain fields.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// messageWriter receives human-readable messages, kept off stdout so that
+// structured output stays parseable.
+var messageWriter io.Writer = os.Stderr
+
+func printMessage(format string, args ...any) {
+	fmt.Fprintf(messageWriter, format+"\n", args...)
+}
+
+func validateOutputFormat(format string) error {
+	switch format {
+	case outputTable, outputJSON, outputYAML:
+		return nil
+	default:
+		return errs.Validationf("unknown output format %q, expected %s, %s or %s", format, outputTable, outputJSON, outputYAML)
+	}
+}
+
+func getOutputFormat(cmd *cobra.Command) string {
+	flag := cmd.Flag("output")
+	if flag == nil {
+		return outputTable
+	}
+	return flag.Value.String()
+}
+
+// writeOutput prints value to the command's stdout in the selected format,
+// calling table for the table format. A nil table prints nothing.
+func writeOutput(cmd *cobra.Command, value any, table func(w io.Writer) error) error {
+	w := cmd.OutOrStdout()
+
+	switch format := getOutputFormat(cmd); format {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case outputYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(value); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case outputTable:
+		if table == nil {
+			return nil
+		}
+		return table(w)
+	default:
+		return validateOutputFormat(format)
+	}
+}
+
+// secretSummaryOutput is a secret as listed, without its data.
+type secretSummaryOutput struct {
+	UUID         string    `json:"uuid" yaml:"uuid"`
+	Type         string    `json:"type" yaml:"type"`
+	Name         string    `json:"name" yaml:"name"`
+	LastModified time.Time `json:"last_modified" yaml:"last_modified"`
+	Metadata     string    `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Tags         []string  `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// DeletedAt is set while the secret is in the trash.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" yaml:"deleted_at,omitempty"`
+}
+
+// secretOutput is a secret with its data. Redacted fields are left out of
+// Data and named in Redacted, redacted custom fields as fields.<label>.
+type secretOutput struct {
+	secretSummaryOutput `yaml:",inline"`
+
+	Data     map[string]any      `json:"data" yaml:"data"`
+	Fields   []customFieldOutput `json:"fields,omitempty" yaml:"fields,omitempty"`
+	Redacted []string            `json:"redacted,omitempty" yaml:"redacted,omitempty"`
+}
+
+type customFieldOutput struct {
+	Label string `json:"label" yaml:"label"`
+	Type  string `json:"type" yaml:"type"`
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+func newSecretSummaryOutput(secret *types.LocalSecret) secretSummaryOutput {
+	output := secretSummaryOutput{
+		UUID:         secret.UUID,
+		Type:         secret.Type,
+		Name:         secret.Name,
+		LastModified: secret.LastModified.UTC(),
+		Metadata:     secret.Metadata,
+		Tags:         secret.Tags,
+	}
+	if secret.InTrash() {
+		deletedAt := secret.DeletedAt.UTC()
+		output.DeletedAt = &deletedAt
+	}
+	return output
+}
+
+func newSecretsOutput(secrets []*types.LocalSecret) []secretSummaryOutput {
+	output := make([]secretSummaryOutput, 0, len(secrets))
+	for _, secret := range secrets {
+		output = append(output, newSecretSummaryOutput(secret))
+	}
+	return output
+}
+
+// newSecretOutput flattens the secret data, redacting passwords, CVVs and
+// file content unless full is set, as the table does.
+func newSecretOutput(secret *types.LocalSecret, full bool) (*secretOutput, error) {
+	data, err := secret.ParseData()
+	if err != nil {
+		return nil, err
+	}
+
+	output := &secretOutput{
+		secretSummaryOutput: newSecretSummaryOutput(secret),
+		Data:                make(map[string]any),
+	}
+	sensitive := func(field string, value any) {
+		if full {
+			output.Data[field] = value
+			return
+		}
+		output.Redacted = append(output.Redacted, field)
+	}
+
+	switch data := data.(type) {
+	case types.LoginData:
+		output.Data["username"] = data.Username
+		sensitive("password", data.Password)
+		output.Data["url"] = data.URL
+	case types.TextData:
+		output.Data["content"] = data.Content
+	case types.FileData:
+		output.Data["file_name"] = data.FileName
+		output.Data["file_size"] = data.FileSize
+		if data.Attachment != "" {
+			output.Data["attachment"] = data.Attachment
+		} else {
+			sensitive("content", data.Content)
+		}
+	case types.CardData:
+		output.Data["number"] = data.Number
+		output.Data["holder"] = data.Holder
+		output.Data["expiry"] = data.Expiry
+		sensitive("cvv", data.CVV)
+	default:
+		return nil, fmt.Errorf("unknown data type: %T", data)
+	}
+
+	for _, field := range secret.Fields {
+		fieldOutput := customFieldOutput{Label: field.Label, Type: field.Type, Value: field.Value}
+		if field.IsHidden() && !full {
+			fieldOutput.Value = ""
+			output.Redacted = append(output.Redacted, "fields."+field.Label)
+		}
+		output.Fields = append(output.Fields, fieldOutput)
+	}
+
+	return output, nil
+}
+
+// historyOutput is a secret with its earlier versions, Current is left out
+// once the secret is no longer in the vault.
+type historyOutput struct {
+	UUID     string                `json:"uuid" yaml:"uuid"`
+	Current  *secretSummaryOutput  `json:"current,omitempty" yaml:"current,omitempty"`
+	Versions []secretVersionOutput `json:"versions" yaml:"versions"`
+}
+
+type secretVersionOutput struct {
+	secretSummaryOutput `yaml:",inline"`
+
+	Version    int       `json:"version" yaml:"version"`
+	ArchivedAt time.Time `json:"archived_at" yaml:"archived_at"`
+}
+
+func newHistoryOutput(history *SecretHistory) *historyOutput {
+	output := &historyOutput{
+		UUID:     history.UUID,
+		Versions: make([]secretVersionOutput, 0, len(history.Versions)),
+	}
+	if history.Current != nil {
+		current := newSecretSummaryOutput(history.Current)
+		output.Current = &current
+	}
+	for _, version := range history.Versions {
+		output.Versions = append(output.Versions, secretVersionOutput{
+			secretSummaryOutput: newSecretSummaryOutput(version.Secret),
+			Version:             version.Version,
+			ArchivedAt:          version.ArchivedAt.UTC(),
+		})
+	}
+	return output
+}
+
+// versionDiffOutput lists the fields that differ between two versions,
+// redacted values are left out.
+type versionDiffOutput struct {
+	UUID    string              `json:"uuid" yaml:"uuid"`
+	From    string              `json:"from" yaml:"from"`
+	To      string              `json:"to" yaml:"to"`
+	Changes []fieldChangeOutput `json:"changes" yaml:"changes"`
+}
+
+type fieldChangeOutput struct {
+	Field    string `json:"field" yaml:"field"`
+	Change   string `json:"change" yaml:"change"`
+	From     string `json:"from,omitempty" yaml:"from,omitempty"`
+	To       string `json:"to,omitempty" yaml:"to,omitempty"`
+	Redacted bool   `json:"redacted,omitempty" yaml:"redacted,omitempty"`
+}
+
+func newVersionDiffOutput(diff *VersionDiff) *versionDiffOutput {
+	output := &versionDiffOutput{
+		UUID:    diff.UUID,
+		From:    diff.From,
+		To:      diff.To,
+		Changes: make([]fieldChangeOutput, 0, len(diff.Changes)),
+	}
+	for _, change := range diff.Changes {
+		output.Changes = append(output.Changes, fieldChangeOutput(change))
+	}
+	return output
+}
+
+type purgeOutput struct {
+	UUID   string `json:"uuid" yaml:"uuid"`
+	Purged bool   `json:"purged" yaml:"purged"`
+}
+
+func newPurgeOutputs(secrets []*types.LocalSecret) []purgeOutput {
+	output := make([]purgeOutput, 0, len(secrets))
+	for _, secret := range secrets {
+		output = append(output, purgeOutput{UUID: secret.UUID, Purged: true})
+	}
+	return output
+}
+
+type deleteOutput struct {
+	UUID    string `json:"uuid" yaml:"uuid"`
+	Deleted bool   `json:"deleted" yaml:"deleted"`
+}
+
+// Statuses of synced secrets.
+const (
+	syncStatusOK     = "ok"
+	syncStatusFailed = "failed"
+)
+
+type syncedSecretOutput struct {
+	UUID     string `json:"uuid" yaml:"uuid"`
+	Action   string `json:"action" yaml:"action"`
+	Status   string `json:"status" yaml:"status"`
+	Error    string `json:"error,omitempty" yaml:"error,omitempty"`
+	Rollback string `json:"rollback,omitempty" yaml:"rollback,omitempty"`
+}
+
+type syncOutput struct {
+	Target     string               `json:"target" yaml:"target"`
+	LocalOnly  int                  `json:"local_only" yaml:"local_only"`
+	RemoteOnly int                  `json:"remote_only" yaml:"remote_only"`
+	Both       int                  `json:"both" yaml:"both"`
+	Rollback   string               `json:"rollback,omitempty" yaml:"rollback,omitempty"`
+	Secrets    []syncedSecretOutput `json:"secrets" yaml:"secrets"`
+}
+
+func newSyncOutput(report *SyncReport) *syncOutput {
+	output := &syncOutput{
+		Target:     report.Target,
+		LocalOnly:  report.LocalOnly,
+		RemoteOnly: report.RemoteOnly,
+		Both:       report.Both,
+		Rollback:   report.Rollback,
+		Secrets:    make([]syncedSecretOutput, 0, len(report.Secrets)),
+	}
+	for _, secret := range report.Secrets {
+		synced := syncedSecretOutput{
+			UUID:     secret.UUID,
+			Action:   secret.Action.String(),
+			Status:   syncStatusOK,
+			Rollback: secret.Rollback,
+		}
+		if secret.Err != nil {
+			synced.Status, synced.Error = syncStatusFailed, secret.Err.Error()
+		}
+		output.Secrets = append(output.Secrets, synced)
+	}
+	return output
+}
+
+type generationOutput struct {
+	Generation int       `json:"generation" yaml:"generation"`
+	Written    time.Time `json:"written" yaml:"written"`
+	Size       int64     `json:"size" yaml:"size"`
+}
+
+func newGenerationsOutput(generations []fsutil.Generation) []generationOutput {
+	output := make([]generationOutput, 0, len(generations))
+	for _, generation := range generations {
+		output = append(output, generationOutput{
+			Generation: generation.Number,
+			Written:    generation.ModTime.UTC(),
+			Size:       generation.Size,
+		})
+	}
+	return output
+}
+
+type vaultRestoreOutput struct {
+	Generation int `json:"generation" yaml:"generation"`
+}
+
+type vaultConvertOutput struct {
+	Backend    string `json:"backend" yaml:"backend"`
+	BackupPath string `json:"backup_path" yaml:"backup_path"`
+}
+
+type vaultStatsOutput struct {
+	Secrets           int   `json:"secrets" yaml:"secrets"`
+	CompressedSecrets int   `json:"compressed_secrets" yaml:"compressed_secrets"`
+	PlainSize         int64 `json:"plain_size" yaml:"plain_size"`
+	StoredSize        int64 `json:"stored_size" yaml:"stored_size"`
+	Saved             int64 `json:"saved" yaml:"saved"`
+	Attachments       int   `json:"attachments" yaml:"attachments"`
+	AttachmentSize    int64 `json:"attachment_size" yaml:"attachment_size"`
+	Compression       bool  `json:"compression" yaml:"compression"`
+}
+
+func newVaultStatsOutput(stats *VaultStats) *vaultStatsOutput {
+	return &vaultStatsOutput{
+		Secrets:           stats.Secrets,
+		CompressedSecrets: stats.Compressed,
+		PlainSize:         stats.PlainSize,
+		StoredSize:        stats.StoredSize,
+		Saved:             stats.Saved(),
+		Attachments:       stats.Attachments,
+		AttachmentSize:    stats.AttachmentSize,
+		Compression:       stats.Compression,
+	}
+}
+
+// fileWriteOutput reports secrets written to a file: backups, bundles and exports.
+type fileWriteOutput struct {
+	Path      string `json:"path" yaml:"path"`
+	Secrets   int    `json:"secrets" yaml:"secrets"`
+	FilesDir  string `json:"files_dir,omitempty" yaml:"files_dir,omitempty"`
+	SideFiles int    `json:"side_files,omitempty" yaml:"side_files,omitempty"`
+}
+
+type bundleImportOutput struct {
+	Path          string      `json:"path" yaml:"path"`
+	BundleUpdated bool        `json:"bundle_updated" yaml:"bundle_updated"`
+	Sync          *syncOutput `json:"sync" yaml:"sync"`
+}
+
+type restoreOutput struct {
+	Path      string `json:"path" yaml:"path"`
+	Mode      string `json:"mode" yaml:"mode"`
+	Total     int    `json:"total" yaml:"total"`
+	Added     int    `json:"added" yaml:"added"`
+	Updated   int    `json:"updated" yaml:"updated"`
+	Unchanged int    `json:"unchanged" yaml:"unchanged"`
+	Deleted   int    `json:"deleted" yaml:"deleted"`
+}
+
+func newRestoreOutput(path string, summary *backup.RestoreSummary) *restoreOutput {
+	return &restoreOutput{
+		Path:      path,
+		Mode:      summary.Mode,
+		Total:     summary.Total,
+		Added:     summary.Added,
+		Updated:   summary.Updated,
+		Unchanged: summary.Unchanged,
+		Deleted:   summary.Deleted,
+	}
+}
+
+type importResultOutput struct {
+	Entry  string `json:"entry" yaml:"entry"`
+	Status string `json:"status" yaml:"status"`
+	Type   string `json:"type,omitempty" yaml:"type,omitempty"`
+	Name   string `json:"name,omitempty" yaml:"name,omitempty"`
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+type importOutput struct {
+	DryRun  bool                 `json:"dry_run" yaml:"dry_run"`
+	Results []importResultOutput `json:"results" yaml:"results"`
+}
+
+func newImportOutput(report *interop.ImportReport) *importOutput {
+	output := &importOutput{
+		DryRun:  report.DryRun,
+		Results: make([]importResultOutput, 0, len(report.Results)),
+	}
+	for _, result := range report.Results {
+		output.Results = append(output.Results, importResultOutput{
+			Entry:  result.Ref,
+			Status: result.Status,
+			Type:   result.Type,
+			Name:   result.Name,
+			Reason: result.Reason,
+		})
+	}
+	return output
+}
+
+type versionOutput struct {
+	Version   string `json:"version" yaml:"version"`
+	BuildTime string `json:"build_time" yaml:"build_time"`
+	Commit    string `json:"commit" yaml:"commit"`
+	GoVersion string `json:"go_version" yaml:"go_version"`
+	Platform  string `json:"platform" yaml:"platform"`
+}
+
+func newVersionOutput() *versionOutput {
+	return &versionOutput{
+		Version:   buildinfo.Version,
+		BuildTime: buildinfo.BuildTime,
+		Commit:    buildinfo.Commit,
+		GoVersion: runtime.Version(),
+		Platform:  fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+	}
+}
+
+type initOutput struct {
+	DBPath string `json:"db_path" yaml:"db_path"`
+}
+
+type registerOutput struct {
+	Login string `json:"login" yaml:"login"`
+}
+
+type configValueOutput struct {
+	Profile string `json:"profile,omitempty" yaml:"profile,omitempty"`
+	Key     string `json:"key" yaml:"key"`
+	Value   string `json:"value" yaml:"value"`
+}
+
+type configProfileOutput struct {
+	Name     string            `json:"name" yaml:"name"`
+	Current  bool              `json:"current" yaml:"current"`
+	Settings map[string]string `json:"settings" yaml:"settings"`
+}
+
+func newConfigProfilesOutput(file *config.File) []*configProfileOutput {
+	names := make([]string, 0, len(file.Profiles))
+	for name := range file.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	output := make([]*configProfileOutput, 0, len(names))
+	for _, name := range names {
+		output = append(output, &configProfileOutput{
+			Name:     name,
+			Current:  name == file.CurrentProfile,
+			Settings: file.Profiles[name].Settings(),
+		})
+	}
+	return output
+}
+
+type tagOutput struct {
+	Tag     string `json:"tag" yaml:"tag"`
+	Secrets int    `json:"secrets" yaml:"secrets"`
+}
+
+func newTagsOutput(tags []TagCount) []tagOutput {
+	output := make([]tagOutput, 0, len(tags))
+	for _, tag := range tags {
+		output = append(output, tagOutput{Tag: tag.Tag, Secrets: tag.Secrets})
+	}
+	return output
+}
+
+type folderOutput struct {
+	Folder  string                `json:"folder" yaml:"folder"`
+	Folders []string              `json:"folders" yaml:"folders"`
+	Secrets []secretSummaryOutput `json:"secrets" yaml:"secrets"`
+}
+
+func newFolderOutput(listing *FolderListing) *folderOutput {
+	folders := listing.Folders
+	if folders == nil {
+		folders = []string{}
+	}
+
+	return &folderOutput{
+		Folder:  listing.Folder,
+		Folders: folders,
+		Secrets: newSecretsOutput(listing.Secrets),
+	}
+}
+
+// attachmentOutput is an attachment without its content.
+type attachmentOutput struct {
+	UUID         string    `json:"uuid" yaml:"uuid"`
+	SecretUUID   string    `json:"secret_uuid" yaml:"secret_uuid"`
+	Name         string    `json:"name" yaml:"name"`
+	MIMEType     string    `json:"mime_type" yaml:"mime_type"`
+	Size         int64     `json:"size" yaml:"size"`
+	LastModified time.Time `json:"last_modified" yaml:"last_modified"`
+}
+
+type attachmentWriteOutput struct {
+	attachmentOutput `yaml:",inline"`
+
+	Path string `json:"path" yaml:"path"`
+}
+
+func newAttachmentOutput(attachment *types.Attachment) attachmentOutput {
+	return attachmentOutput{
+		UUID:         attachment.UUID,
+		SecretUUID:   attachment.SecretUUID,
+		Name:         attachment.Name,
+		MIMEType:     attachment.MIMEType,
+		Size:         attachment.Size,
+		LastModified: attachment.LastModified.UTC(),
+	}
+}
+
+func newAttachmentsOutput(attachments []*types.Attachment) []attachmentOutput {
+	output := make([]attachmentOutput, 0, len(attachments))
+	for _, attachment := range attachments {
+		output = append(output, newAttachmentOutput(attachment))
+	}
+	return output
+}
@@ -0,0 +1,166 @@
+package ctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func newTestOutputCommand(t *testing.T, format string) (*cobra.Command, *bytes.Buffer) {
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().StringP("output", "o", outputTable, "")
+	require.NoError(t, cmd.Flags().Set("output", format))
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	return cmd, &out
+}
+
+func newTestLoginSecret(t *testing.T) *types.LocalSecret {
+	service := newTestVaultService(t)
+
+	secret, err := types.NewSecretModel(
+		types.BaseSecret{Type: "password", Name: "mail", Metadata: "work"},
+		types.LoginData{Username: "alice", Password: "hunter2", URL: "https://mail.example.com"},
+		service.cryptor,
+	)
+	require.NoError(t, err)
+
+	return secret
+}
+
+func TestNewSecretOutput(t *testing.T) {
+	secret := newTestLoginSecret(t)
+
+	t.Run("redacts sensitive fields", func(t *testing.T) {
+		output, err := newSecretOutput(secret, false)
+		require.NoError(t, err)
+
+		assert.Equal(t, "alice", output.Data["username"])
+		assert.NotContains(t, output.Data, "password")
+		assert.Equal(t, []string{"password"}, output.Redacted)
+	})
+
+	t.Run("full shows everything", func(t *testing.T) {
+		output, err := newSecretOutput(secret, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, "hunter2", output.Data["password"])
+		assert.Empty(t, output.Redacted)
+	})
+
+	t.Run("redacts hidden custom fields", func(t *testing.T) {
+		withFields := *secret
+		withFields.Fields = []types.CustomField{
+			{Type: constants.FieldTypeText, Label: "Bank", Value: "Example"},
+			{Type: constants.FieldTypeHidden, Label: "PIN", Value: "1234"},
+		}
+
+		output, err := newSecretOutput(&withFields, false)
+		require.NoError(t, err)
+		assert.Equal(t, []customFieldOutput{
+			{Label: "Bank", Type: "text", Value: "Example"},
+			{Label: "PIN", Type: "hidden"},
+		}, output.Fields)
+		assert.Equal(t, []string{"password", "fields.PIN"}, output.Redacted)
+
+		var table bytes.Buffer
+		require.NoError(t, displaySecret(&table, &withFields, false))
+		assert.Contains(t, table.String(), "Bank: Example\n")
+		assert.Contains(t, table.String(), "PIN: ********\n")
+		assert.NotContains(t, table.String(), "1234")
+	})
+}
+
+func TestWriteOutput(t *testing.T) {
+	secret := newTestLoginSecret(t)
+	output, err := newSecretOutput(secret, false)
+	require.NoError(t, err)
+
+	t.Run("json", func(t *testing.T) {
+		cmd, out := newTestOutputCommand(t, outputJSON)
+		require.NoError(t, writeOutput(cmd, output, nil))
+
+		var decoded map[string]any
+		require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+		assert.Equal(t, secret.UUID, decoded["uuid"])
+		assert.Equal(t, "mail", decoded["name"])
+		assert.Equal(t, "work", decoded["metadata"])
+		assert.Contains(t, decoded, "last_modified")
+		assert.Equal(t, map[string]any{"username": "alice", "url": "https://mail.example.com"}, decoded["data"])
+		assert.Equal(t, []any{"password"}, decoded["redacted"])
+		assert.NotContains(t, out.String(), "hunter2")
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		cmd, out := newTestOutputCommand(t, outputYAML)
+		require.NoError(t, writeOutput(cmd, output, nil))
+
+		var decoded map[string]any
+		require.NoError(t, yaml.Unmarshal(out.Bytes(), &decoded))
+		assert.Equal(t, secret.UUID, decoded["uuid"])
+		assert.Contains(t, decoded, "last_modified")
+		assert.Equal(t, map[string]any{"username": "alice", "url": "https://mail.example.com"}, decoded["data"])
+		assert.NotContains(t, out.String(), "hunter2")
+	})
+
+	t.Run("table", func(t *testing.T) {
+		cmd, out := newTestOutputCommand(t, outputTable)
+		err := writeOutput(cmd, output, func(w io.Writer) error {
+			return displaySecret(w, secret, false)
+		})
+		require.NoError(t, err)
+
+		assert.Contains(t, out.String(), "Name: mail")
+		assert.Contains(t, out.String(), "Password: ********")
+	})
+
+	t.Run("empty list is an empty array", func(t *testing.T) {
+		cmd, out := newTestOutputCommand(t, outputJSON)
+		require.NoError(t, writeOutput(cmd, newSecretsOutput(nil), nil))
+
+		assert.JSONEq(t, "[]", out.String())
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		cmd, _ := newTestOutputCommand(t, "xml")
+		assert.Error(t, writeOutput(cmd, output, nil))
+	})
+}
+
+func TestSyncReport(t *testing.T) {
+	report := &SyncReport{
+		Target: "server",
+		Secrets: []*SyncedSecret{
+			{UUID: "a", Action: ActionCreateRemote},
+			{UUID: "b", Action: ActionCreateLocal, Err: errs.NewNetworkError(errors.New("connection refused"))},
+		},
+	}
+
+	err := report.Err()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 of 2 secrets failed to sync")
+	assert.Equal(t, errs.ExitNetwork, errs.ExitCode(err))
+
+	output := newSyncOutput(report)
+	assert.Equal(t, syncStatusOK, output.Secrets[0].Status)
+	assert.Equal(t, syncStatusFailed, output.Secrets[1].Status)
+	assert.Equal(t, "connection refused", output.Secrets[1].Error)
+
+	var table bytes.Buffer
+	require.NoError(t, displaySyncReport(&table, report))
+	assert.Contains(t, table.String(), "1 synced, 1 failed")
+
+	assert.NoError(t, (&SyncReport{}).Err())
+}
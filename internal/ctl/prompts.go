@@ -2,35 +2,122 @@ package ctl
 
 import (
 	"fmt"
+	"io"
+	"os"
 
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
 	"github.com/etoneja/go-keeper/internal/ctl/types"
 	"github.com/manifoldco/promptui"
 )
 
+// promptOutput keeps prompts on stderr, next to the other human-readable messages.
+var promptOutput io.WriteCloser = nopWriteCloser{os.Stderr}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
 func PromptForLocalOnlyAction(localSecret *types.LocalSecret) (ActionType, error) {
 	prompt := promptui.Select{
-		Label: fmt.Sprintf("Secret '%s' exists locally but not on server", localSecret.UUID),
-		Items: LocalOnlyActions,
+		Label:  fmt.Sprintf("Secret '%s'%s exists locally but not on server", localSecret.UUID, trashNote(localSecret.InTrash())),
+		Items:  LocalOnlyActions,
+		Stdout: promptOutput,
 	}
 	return runActionPrompt(prompt)
 }
 
 func PromptForRemoteOnlyAction(remoteSecret *types.RemoteSecret) (ActionType, error) {
 	prompt := promptui.Select{
-		Label: fmt.Sprintf("Secret '%s' exists on server but not locally", remoteSecret.UUID),
-		Items: RemoteOnlyActions,
+		Label:  fmt.Sprintf("Secret '%s'%s exists on server but not locally", remoteSecret.UUID, trashNote(remoteSecret.InTrash())),
+		Items:  RemoteOnlyActions,
+		Stdout: promptOutput,
 	}
 	return runActionPrompt(prompt)
 }
 
 func PromptForConflictCheckPairAction(secretCheckPair *types.SecretCheckPair) (ActionType, error) {
 	prompt := promptui.Select{
-		Label: fmt.Sprintf("Different versions of secret '%s' exists locally and on server", secretCheckPair.Local.UUID),
-		Items: ConflictCheckPairActions,
+		Label: fmt.Sprintf("Different versions of secret '%s' exists locally%s and on server%s",
+			secretCheckPair.Local.UUID, trashNote(secretCheckPair.Local.InTrash()), trashNote(secretCheckPair.Remote.InTrash())),
+		Items:  ConflictCheckPairActions,
+		Stdout: promptOutput,
 	}
 	return runActionPrompt(prompt)
 }
 
+func PromptForRollbackLocalOnlyAction(localSecret *types.LocalSecret, reason string) (ActionType, error) {
+	prompt := promptui.Select{
+		Label:  fmt.Sprintf("Possible rollback: secret '%s' %s", localSecret.UUID, reason),
+		Items:  RollbackLocalOnlyActions,
+		Stdout: promptOutput,
+	}
+	return runActionPrompt(prompt)
+}
+
+func PromptForRollbackRemoteOnlyAction(remoteSecret *types.RemoteSecret, reason string) (ActionType, error) {
+	prompt := promptui.Select{
+		Label:  fmt.Sprintf("Possible rollback: secret '%s' %s", remoteSecret.UUID, reason),
+		Items:  RollbackRemoteOnlyActions,
+		Stdout: promptOutput,
+	}
+	return runActionPrompt(prompt)
+}
+
+func PromptForRollbackCheckPairAction(secretCheckPair *types.SecretCheckPair, reason string) (ActionType, error) {
+	prompt := promptui.Select{
+		Label:  fmt.Sprintf("Possible rollback: secret '%s' %s", secretCheckPair.Local.UUID, reason),
+		Items:  RollbackCheckPairActions,
+		Stdout: promptOutput,
+	}
+	return runActionPrompt(prompt)
+}
+
+func trashNote(inTrash bool) string {
+	if inTrash {
+		return " (in trash)"
+	}
+	return ""
+}
+
+// PromptForPassphrase reads a passphrase without echoing it, asking twice
+// when confirm is set.
+func PromptForPassphrase(label string, confirm bool) (string, error) {
+	prompt := promptui.Prompt{
+		Label:  label,
+		Mask:   '*',
+		Stdout: promptOutput,
+	}
+
+	passphrase, err := prompt.Run()
+	if err != nil {
+		return "", err
+	}
+
+	if passphrase == "" {
+		return "", errs.Validationf("passphrase must not be empty")
+	}
+
+	if !confirm {
+		return passphrase, nil
+	}
+
+	prompt.Label = "Repeat " + label
+	repeated, err := prompt.Run()
+	if err != nil {
+		return "", err
+	}
+
+	if repeated != passphrase {
+		return "", errs.Validationf("passphrases do not match")
+	}
+
+	return passphrase, nil
+}
+
 func runActionPrompt(prompt promptui.Select) (ActionType, error) {
 	_, result, err := prompt.Run()
 	if err != nil {
@@ -2,12 +2,14 @@ package ctl
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
 	"sync"
 
 	"github.com/etoneja/go-keeper/internal/ctl/client"
 	"github.com/etoneja/go-keeper/internal/ctl/config"
 	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
 	"github.com/etoneja/go-keeper/internal/ctl/storage"
 )
 
@@ -40,7 +42,7 @@ func (s *VaultService) getStorage(ctx context.Context) (storage.Storager, error)
 
 	cryptor := crypto.NewCryptor(s.cfg.Password, s.cfg.Login)
 
-	storage, err := storage.NewStorage(ctx, cryptor, s.cfg.DBPath)
+	storage, err := storage.NewStorage(ctx, cryptor, s.storageConfig())
 	if err != nil {
 		return nil, err
 	}
@@ -50,6 +52,21 @@ func (s *VaultService) getStorage(ctx context.Context) (storage.Storager, error)
 	return s.storage, nil
 }
 
+func (s *VaultService) storageConfig() storage.Config {
+	return storage.Config{
+		Path:        s.cfg.DBPath,
+		Backend:     s.cfg.VaultBackend,
+		Backups:     s.cfg.VaultBackups,
+		LockTimeout: s.cfg.LockTimeout,
+		Compress:    s.cfg.Compression,
+		History: storage.HistoryRetention{
+			Versions: s.cfg.HistoryVersions,
+			MaxAge:   s.cfg.HistoryMaxAge,
+		},
+		TrashRetention: s.cfg.TrashRetention,
+	}
+}
+
 func (s *VaultService) getClient(ctx context.Context) (client.Clienter, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -58,11 +75,20 @@ func (s *VaultService) getClient(ctx context.Context) (client.Clienter, error) {
 		return s.client, nil
 	}
 
+	if s.cfg.ServerAddress == "" {
+		return nil, errs.Validationf("no server address configured, set server_address in the profile or GOKEEPER_SERVER_ADDR")
+	}
+
+	tlsConfig, err := s.cfg.TLS.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	serverPassword := s.cryptor.GenerateServerPassword()
 
-	client := client.NewGRPCClient(s.cfg.ServerAddress, s.cfg.Login, serverPassword)
+	client := client.NewGRPCClient(s.cfg.ServerAddress, s.cfg.Login, serverPassword, tlsConfig)
 
-	err := client.Connect(ctx)
+	err = client.Connect(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -76,17 +102,18 @@ func (s *VaultService) Close() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	var storageErr, clientErr error
 	if s.storage != nil {
 		if err := s.storage.Close(); err != nil {
-			log.Printf("failed to close storage: %v", err)
+			storageErr = fmt.Errorf("failed to close storage: %w", err)
 		}
 	}
 
 	if s.client != nil {
 		if err := s.client.Close(); err != nil {
-			log.Printf("failed to close client: %v", err)
+			clientErr = fmt.Errorf("failed to close client: %w", err)
 		}
 	}
 
-	return nil
+	return errors.Join(storageErr, clientErr)
 }
@@ -0,0 +1,257 @@
+package ctl
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/storage"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/google/uuid"
+)
+
+// AttachFile attaches content to the secret found by UUID or path. The MIME
+// type is detected from the name and content when empty. Names are unique
+// within a secret. The secret itself is not modified.
+func (s *VaultService) AttachFile(ctx context.Context, ref, name, mimeType string, content []byte) (*types.Attachment, error) {
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := resolveSecret(ctx, storage, ref, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if mimeType == "" {
+		mimeType = detectMIMEType(name, content)
+	}
+
+	attachment, err := types.NewAttachment(s.cryptor, secret.UUID, name, mimeType, content)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := storage.ListAttachments(ctx, secret.UUID)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range attachments {
+		if existing.Name == attachment.Name {
+			return nil, errs.NewConflictError(fmt.Errorf("secret %s already has an attachment named %q", secret.UUID, attachment.Name))
+		}
+	}
+
+	if err := storage.CreateAttachment(ctx, attachment); err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// CreateFileSecret creates a binary secret of content. Files larger than
+// MaxFileSize are kept in an attachment of the secret, so that they are
+// sealed and synced in chunks like any attachment.
+func (s *VaultService) CreateFileSecret(ctx context.Context, base types.BaseSecret, fileName string, content []byte) (*types.LocalSecret, error) {
+	data := types.FileData{FileName: fileName, FileSize: int64(len(content))}
+	if len(content) <= constants.MaxFileSize {
+		data.Content = base64.StdEncoding.EncodeToString(content)
+		secret, err := types.NewSecretModel(base, data, s.cryptor)
+		if err != nil {
+			return nil, err
+		}
+		return s.CreateLocalSecret(ctx, secret)
+	}
+
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: Both are built before either is stored, so that a rejected file
+	// leaves no secret behind
+	data.Attachment = uuid.New().String()
+	secret, err := types.NewSecretModel(base, data, s.cryptor)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment, err := types.NewAttachment(s.cryptor, secret.UUID, fileName, detectMIMEType(fileName, content), content)
+	if err != nil {
+		return nil, err
+	}
+	attachment.UUID = data.Attachment
+
+	if err := checkPathFree(ctx, storage, secret.Path(), secret.UUID); err != nil {
+		return nil, err
+	}
+
+	createdSecret, err := storage.CreateSecret(ctx, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := storage.CreateAttachment(ctx, attachment); err != nil {
+		return nil, err
+	}
+
+	return createdSecret, nil
+}
+
+// ExportSecret writes the secret content to a new file, see exportSecret.
+// Data must be loaded.
+func (s *VaultService) ExportSecret(ctx context.Context, secret *types.LocalSecret, exportPath, passphrase string) error {
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return err
+	}
+
+	attachments, err := fileAttachments(ctx, storage, secret)
+	if err != nil {
+		return err
+	}
+
+	return exportSecret(secret, attachments, exportPath, passphrase)
+}
+
+// fileAttachments returns the attachment a binary secret keeps its file in,
+// with its content, or none for other secrets. Data must be loaded.
+func fileAttachments(ctx context.Context, storage storage.Storager, secret *types.LocalSecret) ([]*types.Attachment, error) {
+	data, err := secret.ParseData()
+	if err != nil {
+		return nil, err
+	}
+
+	file, ok := data.(types.FileData)
+	if !ok || file.Attachment == "" {
+		return nil, nil
+	}
+
+	attachment, err := storage.GetAttachment(ctx, file.Attachment, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*types.Attachment{attachment}, nil
+}
+
+// ListAttachments returns the secret found by UUID or path and its
+// attachments, without their content.
+func (s *VaultService) ListAttachments(ctx context.Context, ref string) (*types.LocalSecret, []*types.Attachment, error) {
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	secret, err := resolveSecret(ctx, storage, ref, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attachments, err := storage.ListAttachments(ctx, secret.UUID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return secret, attachments, nil
+}
+
+// GetAttachment returns an attachment of the secret, found by UUID or name,
+// with its content.
+func (s *VaultService) GetAttachment(ctx context.Context, ref, attachmentRef string) (*types.Attachment, error) {
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := resolveSecret(ctx, storage, ref, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolveAttachment(ctx, storage, secret.UUID, attachmentRef, true)
+}
+
+// DeleteAttachment deletes an attachment of the secret, found by UUID or
+// name, and returns it without its content.
+func (s *VaultService) DeleteAttachment(ctx context.Context, ref, attachmentRef string) (*types.Attachment, error) {
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := resolveSecret(ctx, storage, ref, false)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment, err := resolveAttachment(ctx, storage, secret.UUID, attachmentRef, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if secret.Type == constants.SecretTypeBinary {
+		loaded, err := storage.GetSecret(ctx, secret.UUID, true)
+		if err != nil {
+			return nil, err
+		}
+		files, err := fileAttachments(ctx, storage, loaded)
+		if err != nil {
+			return nil, err
+		}
+		if len(files) > 0 && files[0].UUID == attachment.UUID {
+			return nil, errs.NewConflictError(fmt.Errorf("attachment %q holds the file of secret %s, delete the secret instead",
+				attachment.Name, secret.UUID))
+		}
+	}
+
+	if err := storage.DeleteAttachment(ctx, attachment.UUID); err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// resolveAttachment finds an attachment of the secret by UUID or, failing
+// that, by name. Attachments added on two devices may share a name.
+func resolveAttachment(ctx context.Context, storage storage.Storager, secretID, ref string, loadContent bool) (*types.Attachment, error) {
+	attachments, err := storage.ListAttachments(ctx, secretID)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*types.Attachment
+	for _, attachment := range attachments {
+		if attachment.UUID == ref {
+			matches = []*types.Attachment{attachment}
+			break
+		}
+		if attachment.Name == ref {
+			matches = append(matches, attachment)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, errs.NewAttachmentNotFoundError(ref)
+	case 1:
+		return storage.GetAttachment(ctx, matches[0].UUID, loadContent)
+	default:
+		return nil, errs.NewConflictError(fmt.Errorf("%d attachments are named %q, use the UUID instead", len(matches), ref))
+	}
+}
+
+// detectMIMEType guesses the type from the file extension, then from the content.
+func detectMIMEType(name string, content []byte) string {
+	if mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(name))); mimeType != "" {
+		return mimeType
+	}
+	return http.DetectContentType(content)
+}
@@ -0,0 +1,214 @@
+package ctl
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/etoneja/go-keeper/internal/ctl/backup"
+	"github.com/etoneja/go-keeper/internal/ctl/config"
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultService_Attachments(t *testing.T) {
+	ctx := context.Background()
+	service := newTestVaultService(t)
+
+	secret := addTestTextSecret(t, service, "work/vpn", "config")
+
+	attachment, err := service.AttachFile(ctx, "work/vpn", "client.ovpn", "", []byte("remote vpn.example.com"))
+	require.NoError(t, err)
+	assert.Equal(t, secret.UUID, attachment.SecretUUID)
+	assert.Equal(t, "text/plain; charset=utf-8", attachment.MIMEType)
+
+	_, err = service.AttachFile(ctx, secret.UUID, "client.ovpn", "", []byte("other"))
+	assert.True(t, errs.IsConflict(err), "names are unique within a secret")
+
+	_, err = service.AttachFile(ctx, secret.UUID, "ca.pem", "application/x-pem-file", []byte("cert"))
+	require.NoError(t, err)
+
+	stored, err := service.GetLocalSecret(ctx, secret.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, secret.Hash, stored.Hash, "attaching does not modify the secret")
+
+	_, attachments, err := service.ListAttachments(ctx, secret.UUID)
+	require.NoError(t, err)
+	require.Len(t, attachments, 2)
+	assert.Equal(t, "ca.pem", attachments[0].Name)
+
+	loaded, err := service.GetAttachment(ctx, secret.UUID, "client.ovpn")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("remote vpn.example.com"), loaded.Content)
+
+	loaded, err = service.GetAttachment(ctx, secret.UUID, attachment.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, "client.ovpn", loaded.Name)
+
+	other := addTestTextSecret(t, service, "other", "content")
+	_, err = service.GetAttachment(ctx, other.UUID, attachment.UUID)
+	assert.True(t, errs.IsNotFound(err), "attachments are found within their secret only")
+
+	deleted, err := service.DeleteAttachment(ctx, secret.UUID, "ca.pem")
+	require.NoError(t, err)
+	assert.Equal(t, "ca.pem", deleted.Name)
+
+	_, err = service.DeleteLocalSecret(ctx, secret.UUID)
+	require.NoError(t, err)
+
+	storage, err := service.getStorage(ctx)
+	require.NoError(t, err)
+	remaining, err := storage.ListAttachments(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, remaining, 1, "a secret in the trash keeps its attachments")
+
+	_, err = service.EmptyTrash(ctx)
+	require.NoError(t, err)
+
+	remaining, err = storage.ListAttachments(ctx, "")
+	require.NoError(t, err)
+	assert.Empty(t, remaining, "purging a secret deletes its attachments")
+}
+
+func TestVaultService_CreateFileSecret(t *testing.T) {
+	ctx := context.Background()
+	service := newTestVaultService(t)
+	dir := t.TempDir()
+
+	small, err := service.CreateFileSecret(ctx, types.BaseSecret{Type: constants.SecretTypeBinary, Name: "small"},
+		"small.bin", []byte("small"))
+	require.NoError(t, err)
+
+	data, err := small.ParseData()
+	require.NoError(t, err)
+	assert.Equal(t, types.FileData{FileName: "small.bin", FileSize: 5, Content: "c21hbGw="}, data)
+
+	content := bytes.Repeat([]byte("large"), constants.MaxFileSize)
+	large, err := service.CreateFileSecret(ctx, types.BaseSecret{Type: constants.SecretTypeBinary, Name: "large"},
+		"disk.img", content)
+	require.NoError(t, err)
+
+	data, err = large.ParseData()
+	require.NoError(t, err)
+	file := data.(types.FileData)
+	assert.Empty(t, file.Content)
+	assert.Equal(t, int64(len(content)), file.FileSize)
+
+	_, attachments, err := service.ListAttachments(ctx, large.UUID)
+	require.NoError(t, err)
+	require.Len(t, attachments, 1)
+	assert.Equal(t, file.Attachment, attachments[0].UUID)
+
+	_, err = service.DeleteAttachment(ctx, large.UUID, "disk.img")
+	assert.True(t, errs.IsConflict(err), "the file of a binary secret is not deleted on its own")
+
+	exportPath := filepath.Join(dir, "disk.img")
+	require.NoError(t, service.ExportSecret(ctx, large, exportPath, ""))
+	exported, err := os.ReadFile(exportPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, exported)
+
+	archivePath := filepath.Join(dir, "disk.gkbak")
+	require.NoError(t, service.ExportSecret(ctx, large, archivePath, "passphrase"))
+
+	restoring := newTestVaultService(t)
+	_, err = restoring.RestoreBackup(ctx, archivePath, "passphrase", backup.ModeMerge)
+	require.NoError(t, err)
+	attachment, err := restoring.GetAttachment(ctx, large.UUID, file.Attachment)
+	require.NoError(t, err)
+	assert.Equal(t, content, attachment.Content, "the archive carries the file")
+
+	_, err = service.CreateFileSecret(ctx, types.BaseSecret{Type: constants.SecretTypeBinary, Name: "huge"},
+		"huge.img", make([]byte, constants.MaxAttachmentSize+1))
+	assert.True(t, errs.IsValidation(err))
+
+	_, err = service.GetLocalSecret(ctx, "huge")
+	assert.True(t, errs.IsNotFound(err), "a rejected file leaves no secret behind")
+}
+
+func TestVaultService_SyncAttachments(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "sync.gkb")
+
+	first := newTestVaultService(t)
+	first.cfg.SyncStrategy = config.SyncNewest
+	second := newTestVaultService(t)
+	second.cfg.SyncStrategy = config.SyncNewest
+
+	secret := addTestTextSecret(t, first, "keys", "content")
+	attachment, err := first.AttachFile(ctx, secret.UUID, "id_ed25519", "", []byte("private key"))
+	require.NoError(t, err)
+
+	_, err = first.ExportBundle(ctx, path)
+	require.NoError(t, err)
+
+	report, _, err := second.ImportBundle(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, ActionCreateLocal, report.Secrets[0].Action)
+	assert.Equal(t, ActionDownloadAttachment, report.Secrets[1].Action)
+
+	loaded, err := second.GetAttachment(ctx, secret.UUID, "id_ed25519")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("private key"), loaded.Content)
+
+	_, err = second.DeleteAttachment(ctx, secret.UUID, attachment.UUID)
+	require.NoError(t, err)
+	added, err := second.AttachFile(ctx, secret.UUID, "id_ed25519.pub", "", []byte("public key"))
+	require.NoError(t, err)
+
+	report, updated, err := second.ImportBundle(ctx, path)
+	require.NoError(t, err)
+	assert.True(t, updated)
+	assert.ElementsMatch(t, []*SyncedSecret{
+		{UUID: attachment.UUID, Action: ActionDeleteRemoteAttachment},
+		{UUID: added.UUID, Action: ActionUploadAttachment},
+	}, report.Secrets)
+
+	report, _, err = first.ImportBundle(ctx, path)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*SyncedSecret{
+		{UUID: attachment.UUID, Action: ActionDeleteLocalAttachment},
+		{UUID: added.UUID, Action: ActionDownloadAttachment},
+	}, report.Secrets)
+
+	_, attachments, err := first.ListAttachments(ctx, secret.UUID)
+	require.NoError(t, err)
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "id_ed25519.pub", attachments[0].Name)
+
+	report, _, err = first.ImportBundle(ctx, path)
+	require.NoError(t, err)
+	assert.Empty(t, report.Secrets, "attachments in sync need no action")
+}
+
+func TestChooseAttachmentAction(t *testing.T) {
+	tests := []struct {
+		name  string
+		state attachmentState
+		want  ActionType
+	}{
+		{"in sync", attachmentState{local: true, remote: true, secretLocal: true, secretRemote: true}, ""},
+		{"secret deleted on both sides", attachmentState{local: true, remote: true}, ActionDeleteAttachment},
+		{"added locally", attachmentState{local: true, secretLocal: true, secretRemote: true}, ActionUploadAttachment},
+		{"deleted remotely", attachmentState{local: true, secretLocal: true, secretRemote: true, seen: true}, ActionDeleteLocalAttachment},
+		{"secret uploaded again", attachmentState{local: true, secretLocal: true, secretRemote: true, seen: true, restored: true}, ActionUploadAttachment},
+		{"secret not uploaded", attachmentState{local: true, secretLocal: true}, ""},
+		{"local orphan", attachmentState{local: true}, ActionDeleteLocalAttachment},
+		{"added remotely", attachmentState{remote: true, secretLocal: true, secretRemote: true}, ActionDownloadAttachment},
+		{"deleted locally", attachmentState{remote: true, secretLocal: true, secretRemote: true, seen: true}, ActionDeleteRemoteAttachment},
+		{"secret downloaded again", attachmentState{remote: true, secretLocal: true, secretRemote: true, seen: true, restored: true}, ActionDownloadAttachment},
+		{"secret not downloaded", attachmentState{remote: true, secretRemote: true}, ""},
+		{"remote orphan", attachmentState{remote: true}, ActionDeleteRemoteAttachment},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, chooseAttachmentAction(tt.state))
+		})
+	}
+}
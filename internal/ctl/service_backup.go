@@ -0,0 +1,164 @@
+package ctl
+
+import (
+	"context"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/backup"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+// CreateBackup writes every secret to a new archive encrypted with passphrase
+// and returns the number of secrets in it.
+func (s *VaultService) CreateBackup(ctx context.Context, path string, passphrase string) (int, error) {
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	listed, err := storage.ListSecrets(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	secrets := make([]*types.LocalSecret, 0, len(listed))
+	for _, secret := range listed {
+		loaded, err := storage.GetSecret(ctx, secret.UUID, true)
+		if err != nil {
+			return 0, err
+		}
+		secrets = append(secrets, loaded)
+	}
+
+	archive := backup.NewArchive(secrets)
+
+	listedAttachments, err := storage.ListAttachments(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+
+	attachments := make([]*types.Attachment, 0, len(listedAttachments))
+	for _, attachment := range listedAttachments {
+		loaded, err := storage.GetAttachment(ctx, attachment.UUID, true)
+		if err != nil {
+			return 0, err
+		}
+		attachments = append(attachments, loaded)
+	}
+	archive.AddAttachments(attachments)
+
+	err = backup.Write(path, passphrase, archive)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(secrets), nil
+}
+
+// RestoreBackup applies the archive at path to the vault in the given mode.
+func (s *VaultService) RestoreBackup(ctx context.Context, path string, passphrase string, mode string) (*backup.RestoreSummary, error) {
+	err := backup.ValidateMode(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := backup.Read(path, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &backup.RestoreSummary{Mode: mode, Total: len(archive.Secrets)}
+	if mode == backup.ModeVerify {
+		return summary, nil
+	}
+
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	localSecrets, err := listWithTrash(ctx, storage)
+	if err != nil {
+		return nil, err
+	}
+
+	localMap := make(map[string]*types.LocalSecret, len(localSecrets))
+	for _, secret := range localSecrets {
+		localMap[secret.UUID] = secret
+	}
+
+	localAttachments, err := storage.ListAttachments(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	attachmentExists := make(map[string]bool, len(localAttachments))
+	for _, attachment := range localAttachments {
+		attachmentExists[attachment.UUID] = true
+	}
+
+	// NOTE: Restored secrets are new modifications, sync would take the
+	// copies on other devices back over their archived timestamps otherwise
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	archived := make(map[string]bool, len(archive.Secrets))
+	for _, archivedSecret := range archive.Secrets {
+		archived[archivedSecret.UUID] = true
+		secret := archivedSecret.LocalSecret(s.cryptor)
+
+		local, exists := localMap[secret.UUID]
+		switch {
+		case !exists:
+			secret.LastModified = now
+			_, err := storage.CreateSecret(ctx, secret)
+			if err != nil {
+				return nil, err
+			}
+			summary.Added++
+		case local.Hash == secret.Hash && !local.InTrash():
+			summary.Unchanged++
+		case mode == backup.ModeReplace || secret.LastModified.After(local.LastModified):
+			secret.LastModified = now
+			err := storage.UpdateSecret(ctx, secret)
+			if err != nil {
+				return nil, err
+			}
+			summary.Updated++
+		default:
+			summary.Unchanged++
+		}
+
+		// NOTE: Attachments never change, only missing ones are restored
+		for _, attachment := range archivedSecret.LocalAttachments(s.cryptor) {
+			if attachmentExists[attachment.UUID] {
+				continue
+			}
+			if err := storage.CreateAttachment(ctx, attachment); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// NOTE: Secrets missing from the archive go to the trash with their
+	// attachments, so a restore of the wrong archive is undone from there
+	if mode == backup.ModeReplace {
+		for _, secret := range localSecrets {
+			if archived[secret.UUID] || secret.InTrash() {
+				continue
+			}
+
+			secret, err := storage.GetSecret(ctx, secret.UUID, true)
+			if err != nil {
+				return nil, err
+			}
+
+			err = trashSecret(ctx, storage, secret)
+			if err != nil {
+				return nil, err
+			}
+			summary.Deleted++
+		}
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,175 @@
+package ctl
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/backup"
+	"github.com/etoneja/go-keeper/internal/ctl/bundle"
+	"github.com/etoneja/go-keeper/internal/ctl/config"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestVaultService(t *testing.T) *VaultService {
+	service := NewVaultService(&config.Config{
+		DBPath:   filepath.Join(t.TempDir(), "vault.db"),
+		Login:    "login",
+		Password: "password",
+	})
+	require.NoError(t, service.Initialize(context.Background()))
+	t.Cleanup(func() { _ = service.Close() })
+
+	return service
+}
+
+func addTestTextSecret(t *testing.T, service *VaultService, name string, content string) *types.LocalSecret {
+	secret, err := types.NewSecretModel(
+		types.BaseSecret{Type: "text", Name: name},
+		types.TextData{Content: content},
+		service.cryptor,
+	)
+	require.NoError(t, err)
+
+	_, err = service.CreateLocalSecret(context.Background(), secret)
+	require.NoError(t, err)
+
+	return secret
+}
+
+func TestVaultService_RestoreBackup(t *testing.T) {
+	ctx := context.Background()
+
+	source := newTestVaultService(t)
+	kept := addTestTextSecret(t, source, "kept", "one")
+	edited := addTestTextSecret(t, source, "edited", "two")
+
+	path := filepath.Join(t.TempDir(), "vault.gkbak")
+	count, err := source.CreateBackup(ctx, path, "backup passphrase")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	newTarget := func(t *testing.T) *VaultService {
+		target := newTestVaultService(t)
+		_, err := target.CreateLocalSecret(ctx, kept)
+		require.NoError(t, err)
+
+		older := *edited
+		older.LastModified = edited.LastModified.Add(-time.Hour)
+		require.NoError(t, older.SetData(target.cryptor, types.TextData{Content: "old"}))
+		_, err = target.CreateLocalSecret(ctx, &older)
+		require.NoError(t, err)
+
+		addTestTextSecret(t, target, "local only", "three")
+		return target
+	}
+
+	t.Run("verify", func(t *testing.T) {
+		target := newTarget(t)
+		summary, err := target.RestoreBackup(ctx, path, "backup passphrase", backup.ModeVerify)
+		require.NoError(t, err)
+		assert.Equal(t, 2, summary.Total)
+		assert.Zero(t, summary.Added+summary.Updated+summary.Deleted)
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		target := newTarget(t)
+		summary, err := target.RestoreBackup(ctx, path, "backup passphrase", backup.ModeMerge)
+		require.NoError(t, err)
+		assert.Equal(t, &backup.RestoreSummary{Mode: backup.ModeMerge, Total: 2, Updated: 1, Unchanged: 1}, summary)
+
+		secrets, err := target.ListLocalSecrets(ctx)
+		require.NoError(t, err)
+		assert.Len(t, secrets, 3)
+
+		restored, err := target.GetLocalSecret(ctx, edited.UUID)
+		require.NoError(t, err)
+		assert.Equal(t, edited.Hash, restored.Hash)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		target := newTarget(t)
+		summary, err := target.RestoreBackup(ctx, path, "backup passphrase", backup.ModeReplace)
+		require.NoError(t, err)
+		assert.Equal(t, &backup.RestoreSummary{Mode: backup.ModeReplace, Total: 2, Updated: 1, Unchanged: 1, Deleted: 1}, summary)
+
+		secrets, err := target.ListLocalSecrets(ctx)
+		require.NoError(t, err)
+		assert.Len(t, secrets, 2)
+	})
+
+	t.Run("unknown mode", func(t *testing.T) {
+		_, err := source.RestoreBackup(ctx, path, "backup passphrase", "overwrite")
+		assert.ErrorContains(t, err, "unknown restore mode")
+	})
+}
+
+func TestVaultService_RestoreBackupThenSync(t *testing.T) {
+	ctx := context.Background()
+
+	service := newTestSyncService(t)
+	secret := addTestTextSecret(t, service, "edited", "backed up")
+
+	path := filepath.Join(t.TempDir(), "vault.gkbak")
+	_, err := service.CreateBackup(ctx, path, "backup passphrase")
+	require.NoError(t, err)
+
+	storage, err := service.getStorage(ctx)
+	require.NoError(t, err)
+	edited, err := storage.GetSecret(ctx, secret.UUID, true)
+	require.NoError(t, err)
+	require.NoError(t, edited.SetData(service.cryptor, types.TextData{Content: "edited later"}))
+	edited.LastModified = time.Now().UTC().Truncate(time.Microsecond)
+	require.NoError(t, storage.UpdateSecret(ctx, edited))
+
+	target, err := bundle.Create(service.cryptor, filepath.Join(t.TempDir(), "sync.gkb"))
+	require.NoError(t, err)
+	_, err = service.syncWithTarget(ctx, target)
+	require.NoError(t, err)
+
+	summary, err := service.RestoreBackup(ctx, path, "backup passphrase", backup.ModeReplace)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Updated)
+
+	report, err := service.syncWithTarget(ctx, target)
+	require.NoError(t, err)
+	require.NoError(t, report.Err())
+
+	restored, err := service.GetLocalSecret(ctx, secret.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, secret.Hash, restored.Hash)
+
+	remoteSecret, err := target.GetSecret(ctx, secret.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, secret.Hash, remoteSecret.Hash)
+	assert.Equal(t, restored.LastModified, remoteSecret.LastModified)
+}
+
+func TestVaultService_BackupAttachments(t *testing.T) {
+	ctx := context.Background()
+
+	source := newTestVaultService(t)
+	secret := addTestTextSecret(t, source, "keys", "content")
+	attachment, err := source.AttachFile(ctx, secret.UUID, "id_ed25519", "", []byte("private key"))
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "vault.gkbak")
+	_, err = source.CreateBackup(ctx, path, "backup passphrase")
+	require.NoError(t, err)
+
+	target := newTestVaultService(t)
+	_, err = target.RestoreBackup(ctx, path, "backup passphrase", backup.ModeMerge)
+	require.NoError(t, err)
+
+	restored, err := target.GetAttachment(ctx, secret.UUID, "id_ed25519")
+	require.NoError(t, err)
+	assert.Equal(t, attachment.UUID, restored.UUID)
+	assert.Equal(t, attachment.Hash, restored.Hash)
+	assert.Equal(t, []byte("private key"), restored.Content)
+
+	_, err = target.RestoreBackup(ctx, path, "backup passphrase", backup.ModeMerge)
+	require.NoError(t, err, "restoring again keeps existing attachments")
+}
@@ -0,0 +1,95 @@
+package ctl
+
+import (
+	"context"
+
+	"github.com/etoneja/go-keeper/internal/ctl/bundle"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+// ExportBundle writes every local secret with its attachments and a sync
+// manifest to a new bundle at path and returns the number of secrets exported.
+func (s *VaultService) ExportBundle(ctx context.Context, path string) (int, error) {
+	b, err := bundle.Create(s.cryptor, path)
+	if err != nil {
+		return 0, err
+	}
+
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	localSecrets, err := storage.ListSecrets(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.migrateLocalHashes(ctx, localSecrets)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, secret := range localSecrets {
+		err := s.uploadLocalSecret(ctx, b, secret.UUID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	attachments, err := storage.ListAttachments(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+
+	for _, attachment := range attachments {
+		err := s.uploadAttachment(ctx, b, attachment.UUID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	manifests, err := s.loadManifests(ctx, b, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.publishSyncManifest(ctx, b, &types.SecretsDiff{}, manifests)
+	if err != nil {
+		return 0, err
+	}
+
+	err = b.Save()
+	if err != nil {
+		return 0, err
+	}
+
+	return len(localSecrets), nil
+}
+
+// ImportBundle syncs the vault with the bundle at path as if it were the
+// server. Changes the sync makes to the bundle are saved back to it, so it
+// carries them to the next device. It also reports whether the bundle was
+// updated, which it is even when some secrets failed to sync.
+func (s *VaultService) ImportBundle(ctx context.Context, path string) (*SyncReport, bool, error) {
+	b, err := bundle.Open(s.cryptor, path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	report, err := s.syncWithTarget(ctx, b)
+	if err != nil {
+		return report, false, err
+	}
+
+	if !b.Modified() {
+		return report, false, report.Err()
+	}
+
+	err = b.Save()
+	if err != nil {
+		return report, false, err
+	}
+
+	return report, true, report.Err()
+}
@@ -0,0 +1,311 @@
+package ctl
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/storage"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+// currentVersion names the secret as it is now where a version number is expected.
+const currentVersion = "current"
+
+// Changes of a field between two versions of a secret.
+const (
+	fieldAdded   = "added"
+	fieldRemoved = "removed"
+	fieldChanged = "changed"
+)
+
+// SecretHistory is a secret with its earlier versions, newest first.
+// Current is nil once the secret is no longer in the vault.
+type SecretHistory struct {
+	UUID     string
+	Current  *types.LocalSecret
+	Versions []*types.SecretVersion
+}
+
+// FieldChange is a field that differs between two versions of a secret.
+// Sensitive values are left out unless asked for, see newSecretOutput.
+type FieldChange struct {
+	Field    string
+	Change   string
+	From     string
+	To       string
+	Redacted bool
+}
+
+// VersionDiff is the field-level difference between two versions of a secret.
+type VersionDiff struct {
+	UUID    string
+	From    string
+	To      string
+	Changes []FieldChange
+}
+
+// resolveHistory finds a secret by UUID or path, in the trash too, or a
+// secret no longer in the vault by the UUID its history is kept under. The
+// secret is nil then.
+func resolveHistory(ctx context.Context, storage storage.Storager, ref string, loadData bool) (string, *types.LocalSecret, error) {
+	secret, err := resolveSecret(ctx, storage, ref, loadData)
+	if err == nil {
+		return secret.UUID, secret, nil
+	}
+	if !errs.IsNotFound(err) {
+		return "", nil, err
+	}
+
+	trashed, trashErr := resolveTrashed(ctx, storage, ref, loadData)
+	if trashErr == nil {
+		return trashed.UUID, trashed, nil
+	}
+	if !errs.IsNotFound(trashErr) {
+		return "", nil, trashErr
+	}
+
+	versions, listErr := storage.ListSecretVersions(ctx, ref)
+	if listErr != nil {
+		return "", nil, listErr
+	}
+	if len(versions) == 0 {
+		return "", nil, err
+	}
+
+	return ref, nil, nil
+}
+
+// SecretHistory returns the secret found by UUID or path with its earlier
+// versions, see resolveHistory.
+func (s *VaultService) SecretHistory(ctx context.Context, ref string) (*SecretHistory, error) {
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secretID, secret, err := resolveHistory(ctx, storage, ref, false)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := storage.ListSecretVersions(ctx, secretID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SecretHistory{UUID: secretID, Current: secret, Versions: versions}, nil
+}
+
+// DiffSecretVersions compares two versions of the secret field by field.
+// A version is its number or "current".
+func (s *VaultService) DiffSecretVersions(ctx context.Context, ref string, from string, to string, full bool) (*VersionDiff, error) {
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secretID, secret, err := resolveHistory(ctx, storage, ref, true)
+	if err != nil {
+		return nil, err
+	}
+
+	fromSecret, err := secretVersion(ctx, storage, secretID, secret, from)
+	if err != nil {
+		return nil, err
+	}
+	toSecret, err := secretVersion(ctx, storage, secretID, secret, to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := diffSecretFields(fromSecret, toSecret, full)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VersionDiff{UUID: secretID, From: from, To: to, Changes: changes}, nil
+}
+
+func secretVersion(ctx context.Context, storage storage.Storager, secretID string, current *types.LocalSecret, spec string) (*types.LocalSecret, error) {
+	if spec == currentVersion {
+		if current == nil {
+			return nil, errs.Validationf("secret %s is no longer in the vault, it has no current version", secretID)
+		}
+		return current, nil
+	}
+
+	version, err := parseVersion(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	secretVersion, err := storage.GetSecretVersion(ctx, secretID, version)
+	if err != nil {
+		return nil, err
+	}
+	return secretVersion.Secret, nil
+}
+
+func parseVersion(spec string) (int, error) {
+	version, err := strconv.Atoi(spec)
+	if err != nil || version < 1 {
+		return 0, errs.Validationf("version must be a positive number or %q, got %q", currentVersion, spec)
+	}
+	return version, nil
+}
+
+// diffSecretFields compares the secrets as the JSON output shows them, data
+// fields as data.<name> and custom fields as fields.<label>.
+func diffSecretFields(from, to *types.LocalSecret, full bool) ([]FieldChange, error) {
+	fromFields, fromSensitive, err := versionFields(from)
+	if err != nil {
+		return nil, err
+	}
+	toFields, toSensitive, err := versionFields(to)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(fromFields)+len(toFields))
+	for name := range fromFields {
+		names = append(names, name)
+	}
+	for name := range toFields {
+		if _, exists := fromFields[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []FieldChange
+	for _, name := range names {
+		fromValue, inFrom := fromFields[name]
+		toValue, inTo := toFields[name]
+
+		change := FieldChange{Field: name, From: fromValue, To: toValue}
+		switch {
+		case !inFrom:
+			change.Change = fieldAdded
+		case !inTo:
+			change.Change = fieldRemoved
+		case fromValue != toValue:
+			change.Change = fieldChanged
+		default:
+			continue
+		}
+
+		if !full && (slices.Contains(fromSensitive, name) || slices.Contains(toSensitive, name)) {
+			change.From, change.To, change.Redacted = "", "", true
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// versionFields flattens the secret into field values, with the names of
+// the sensitive ones.
+func versionFields(secret *types.LocalSecret) (map[string]string, []string, error) {
+	output, err := newSecretOutput(secret, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	redacted, err := newSecretOutput(secret, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fields := map[string]string{
+		"name": secret.Name,
+		"type": secret.Type,
+	}
+	// NOTE: Empty metadata and tags are absent, as in the JSON output
+	if secret.Metadata != "" {
+		fields["metadata"] = secret.Metadata
+	}
+	if len(secret.Tags) > 0 {
+		fields["tags"] = strings.Join(secret.Tags, ", ")
+	}
+	for name, value := range output.Data {
+		fields["data."+name] = fmt.Sprint(value)
+	}
+	for _, field := range output.Fields {
+		fields["fields."+field.Label] = field.Value
+	}
+
+	sensitive := make([]string, 0, len(redacted.Redacted))
+	for _, name := range redacted.Redacted {
+		if strings.HasPrefix(name, "fields.") {
+			sensitive = append(sensitive, name)
+			continue
+		}
+		sensitive = append(sensitive, "data."+name)
+	}
+
+	return fields, sensitive, nil
+}
+
+// RestoreSecretVersion makes an earlier version of the secret found by UUID
+// or path current again, as a new modification, so the current state stays
+// in the history. A secret in the trash is taken out of it, one no longer in
+// the vault is created again without its attachments.
+func (s *VaultService) RestoreSecretVersion(ctx context.Context, ref string, version int) (*types.LocalSecret, error) {
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secretID, current, err := resolveHistory(ctx, storage, ref, false)
+	if err != nil {
+		return nil, err
+	}
+
+	secretVersion, err := storage.GetSecretVersion(ctx, secretID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := secretVersion.Secret
+	secret.LastModified = time.Now().UTC().Truncate(time.Microsecond)
+	secret.RefreshHash(s.cryptor)
+
+	if err := checkPathFree(ctx, storage, secret.Path(), secret.UUID); err != nil {
+		return nil, err
+	}
+
+	if current == nil {
+		return storage.CreateSecret(ctx, secret)
+	}
+
+	if err := storage.UpdateSecret(ctx, secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// PurgeSecret erases the secret found by UUID or path with its attachments
+// and history, see resolveHistory. Sync erases its copies in the trash
+// elsewhere.
+func (s *VaultService) PurgeSecret(ctx context.Context, ref string) (string, error) {
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	secretID, _, err := resolveHistory(ctx, storage, ref, false)
+	if err != nil {
+		return "", err
+	}
+
+	if err := storage.PurgeSecret(ctx, secretID); err != nil {
+		return "", err
+	}
+
+	return secretID, nil
+}
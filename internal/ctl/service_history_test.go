@@ -0,0 +1,122 @@
+package ctl
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/config"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultService_History(t *testing.T) {
+	ctx := context.Background()
+	service := NewVaultService(&config.Config{
+		DBPath:          filepath.Join(t.TempDir(), "vault.db"),
+		Login:           "login",
+		Password:        "password",
+		HistoryVersions: 10,
+	})
+	require.NoError(t, service.Initialize(ctx))
+	t.Cleanup(func() { _ = service.Close() })
+
+	secret, err := types.NewSecretModel(
+		types.BaseSecret{Type: "password", Name: "mail"},
+		types.LoginData{Username: "alice", Password: "first"},
+		service.cryptor,
+	)
+	require.NoError(t, err)
+	secret.LastModified = secret.LastModified.Add(-time.Minute)
+	_, err = service.CreateLocalSecret(ctx, secret)
+	require.NoError(t, err)
+
+	storage, err := service.getStorage(ctx)
+	require.NoError(t, err)
+	require.NoError(t, secret.SetData(service.cryptor, types.LoginData{Username: "alice", Password: "second"}))
+	secret.LastModified = secret.LastModified.Add(time.Second)
+	require.NoError(t, storage.UpdateSecret(ctx, secret))
+
+	_, err = service.MoveSecret(ctx, secret.UUID, "work/mail")
+	require.NoError(t, err)
+
+	history, err := service.SecretHistory(ctx, "work/mail")
+	require.NoError(t, err)
+	assert.Equal(t, secret.UUID, history.UUID)
+	require.NotNil(t, history.Current)
+	require.Len(t, history.Versions, 2)
+	assert.Equal(t, 2, history.Versions[0].Version)
+	assert.Equal(t, "mail", history.Versions[0].Secret.Name)
+
+	diff, err := service.DiffSecretVersions(ctx, secret.UUID, "1", currentVersion, false)
+	require.NoError(t, err)
+	assert.Equal(t, []FieldChange{
+		{Field: "data.password", Change: fieldChanged, Redacted: true},
+		{Field: "name", Change: fieldChanged, From: "mail", To: "work/mail"},
+	}, diff.Changes)
+
+	diff, err = service.DiffSecretVersions(ctx, secret.UUID, "1", "2", true)
+	require.NoError(t, err)
+	assert.Equal(t, []FieldChange{
+		{Field: "data.password", Change: fieldChanged, From: "first", To: "second"},
+	}, diff.Changes)
+
+	_, err = service.DiffSecretVersions(ctx, secret.UUID, "0", "2", true)
+	assert.True(t, errs.IsValidation(err))
+
+	restored, err := service.RestoreSecretVersion(ctx, "work/mail", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "mail", restored.Name)
+
+	current, err := service.GetLocalSecret(ctx, secret.UUID)
+	require.NoError(t, err)
+	data, err := current.ParseData()
+	require.NoError(t, err)
+	assert.Equal(t, "first", data.(types.LoginData).Password)
+
+	_, err = service.DeleteLocalSecret(ctx, secret.UUID)
+	require.NoError(t, err)
+
+	// NOTE: Moving to the trash is not a version, the history is found by path
+	history, err = service.SecretHistory(ctx, "mail")
+	require.NoError(t, err)
+	require.NotNil(t, history.Current)
+	assert.True(t, history.Current.InTrash())
+	require.Len(t, history.Versions, 3)
+
+	restored, err = service.RestoreSecretVersion(ctx, secret.UUID, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "work/mail", restored.Name)
+
+	current, err = service.GetLocalSecret(ctx, "work/mail")
+	require.NoError(t, err)
+	assert.False(t, current.InTrash())
+
+	// NOTE: The history of a secret no longer in the vault is found by UUID
+	require.NoError(t, storage.DeleteSecret(ctx, secret.UUID))
+
+	history, err = service.SecretHistory(ctx, secret.UUID)
+	require.NoError(t, err)
+	assert.Nil(t, history.Current)
+	require.Len(t, history.Versions, 5)
+
+	_, err = service.DiffSecretVersions(ctx, secret.UUID, "5", currentVersion, false)
+	assert.True(t, errs.IsValidation(err))
+
+	restored, err = service.RestoreSecretVersion(ctx, secret.UUID, 5)
+	require.NoError(t, err)
+	assert.Equal(t, "work/mail", restored.Name)
+
+	_, err = service.GetLocalSecret(ctx, "work/mail")
+	require.NoError(t, err)
+
+	purged, err := service.PurgeSecret(ctx, "work/mail")
+	require.NoError(t, err)
+	assert.Equal(t, secret.UUID, purged)
+
+	_, err = service.SecretHistory(ctx, secret.UUID)
+	assert.True(t, errs.IsNotFound(err))
+}
@@ -0,0 +1,164 @@
+package ctl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/fsutil"
+	"github.com/etoneja/go-keeper/internal/ctl/interop"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+// ExportSummary describes what an export wrote.
+type ExportSummary struct {
+	Secrets int
+	// FilesDir holds the binaries the format could not embed, empty when none.
+	FilesDir  string
+	SideFiles int
+}
+
+// ImportSecrets adds the entries of a file exported by another password
+// manager, skipping those whose name is already taken unless allowed.
+func (s *VaultService) ImportSecrets(ctx context.Context, path string, format string, opts interop.ImportOptions) (*interop.ImportReport, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open import file: %w", err)
+	}
+	defer file.Close()
+
+	records, err := interop.Parse(file, format, opts.ParseOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := storage.ListSecrets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, len(existing)+len(records))
+	for _, secret := range existing {
+		names[secret.Path()] = true
+	}
+
+	report := &interop.ImportReport{DryRun: opts.DryRun}
+	for _, record := range records {
+		result := &interop.ImportResult{
+			Ref:  record.Ref,
+			Name: strings.TrimSpace(record.Name),
+			Type: record.Type,
+		}
+		report.Results = append(report.Results, result)
+
+		secret, err := record.Secret(s.cryptor)
+		if err != nil {
+			result.Status = interop.StatusFailed
+			result.Reason = err.Error()
+			continue
+		}
+
+		if names[secret.Name] && !opts.AllowDuplicates {
+			result.Status = interop.StatusDuplicate
+			result.Reason = "a secret with this name already exists"
+			continue
+		}
+		names[secret.Name] = true
+
+		if opts.DryRun {
+			result.Status = interop.StatusWouldImport
+			continue
+		}
+
+		_, err = storage.CreateSecret(ctx, secret)
+		if err != nil {
+			return nil, err
+		}
+		result.Status = interop.StatusImported
+	}
+
+	return report, nil
+}
+
+// ExportSecrets writes the secrets passing filter in plain text to a new file
+// in format, readable by its owner only. Binaries the format cannot embed go
+// to a directory named after the file.
+func (s *VaultService) ExportSecrets(ctx context.Context, path string, format string, filter interop.Filter) (*ExportSummary, error) {
+	err := filter.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		return nil, errs.NewConflictError(fmt.Errorf("file already exists: %s", path))
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to check file existence: %w", err)
+	}
+
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	listed, err := storage.ListSecrets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var secrets []*types.LocalSecret
+	for _, secret := range listed {
+		if !filter.Match(secret) {
+			continue
+		}
+
+		loaded, err := storage.GetSecret(ctx, secret.UUID, true)
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, loaded)
+	}
+
+	filesDir := filepath.Base(path) + ".files"
+
+	var content bytes.Buffer
+	sideFiles, err := interop.Export(&content, format, secrets, filesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &ExportSummary{Secrets: len(secrets), SideFiles: len(sideFiles)}
+	if len(sideFiles) > 0 {
+		summary.FilesDir = filepath.Join(filepath.Dir(path), filesDir)
+		if err := os.Mkdir(summary.FilesDir, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create files directory: %w", err)
+		}
+	}
+
+	for _, sideFile := range sideFiles {
+		attachments, err := fileAttachments(ctx, storage, sideFile.Secret)
+		if err != nil {
+			return nil, err
+		}
+
+		err = exportSecret(sideFile.Secret, attachments, filepath.Join(filepath.Dir(path), filepath.FromSlash(sideFile.Path)), "")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = fsutil.WriteFileAtomic(path, content.Bytes(), exportFileMode)
+	if err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
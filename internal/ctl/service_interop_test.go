@@ -0,0 +1,103 @@
+package ctl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/interop"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultService_ImportSecrets(t *testing.T) {
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "export.csv")
+	require.NoError(t, os.WriteFile(path, []byte("name,username,password,notes\n"+
+		"Mail,alice,s3cret,\n"+
+		"Taken,bob,pw,\n"+
+		"Mail,alice,other,\n"+
+		"Broken,carol,,\n"+
+		"Note,,,remember\n"), 0600))
+
+	statuses := func(report *interop.ImportReport) []string {
+		var result []string
+		for _, r := range report.Results {
+			result = append(result, r.Status)
+		}
+		return result
+	}
+
+	service := newTestVaultService(t)
+	addTestTextSecret(t, service, "Taken", "already here")
+
+	report, err := service.ImportSecrets(ctx, path, interop.FormatCSV, interop.ImportOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		interop.StatusWouldImport,
+		interop.StatusDuplicate,
+		interop.StatusDuplicate,
+		interop.StatusFailed,
+		interop.StatusWouldImport,
+	}, statuses(report))
+	assert.Equal(t, "data validation failed: password is required", report.Results[3].Reason)
+
+	secrets, err := service.ListLocalSecrets(ctx)
+	require.NoError(t, err)
+	assert.Len(t, secrets, 1, "dry run must not write")
+
+	report, err = service.ImportSecrets(ctx, path, interop.FormatCSV, interop.ImportOptions{AllowDuplicates: true})
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Count(interop.StatusImported))
+
+	secrets, err = service.ListLocalSecrets(ctx)
+	require.NoError(t, err)
+	assert.Len(t, secrets, 5)
+}
+
+func TestVaultService_ExportSecrets(t *testing.T) {
+	ctx := context.Background()
+
+	service := newTestVaultService(t)
+	addTestTextSecret(t, service, "note", "hello")
+	file, err := types.NewSecretModel(
+		types.BaseSecret{Type: constants.SecretTypeBinary, Name: "key"},
+		types.FileData{FileName: "id_ed25519", FileSize: 3, Content: "AAEC"},
+		service.cryptor,
+	)
+	require.NoError(t, err)
+	_, err = service.CreateLocalSecret(ctx, file)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "export.csv")
+
+	summary, err := service.ExportSecrets(ctx, path, interop.FormatCSV, interop.Filter{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Secrets)
+	assert.Equal(t, filepath.Join(dir, "export.csv.files"), summary.FilesDir)
+
+	content, err := os.ReadFile(filepath.Join(summary.FilesDir, file.UUID+"-id_ed25519"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 1, 2}, content)
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	_, err = service.ExportSecrets(ctx, path, interop.FormatCSV, interop.Filter{})
+	assert.ErrorContains(t, err, "file already exists")
+
+	summary, err = service.ExportSecrets(ctx, filepath.Join(dir, "notes.json"), interop.FormatBitwardenJSON,
+		interop.Filter{Types: []string{constants.SecretTypeText}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Secrets)
+	assert.Empty(t, summary.FilesDir)
+}
@@ -12,6 +12,10 @@ func (s *VaultService) CreateLocalSecret(ctx context.Context, secret *types.Loca
 		return nil, err
 	}
 
+	if err := checkPathFree(ctx, storage, secret.Path(), secret.UUID); err != nil {
+		return nil, err
+	}
+
 	newSecret, err := storage.CreateSecret(ctx, secret)
 	if err != nil {
 		return nil, err
@@ -20,13 +24,14 @@ func (s *VaultService) CreateLocalSecret(ctx context.Context, secret *types.Loca
 	return newSecret, nil
 }
 
-func (s *VaultService) GetLocalSecret(ctx context.Context, secretID string) (*types.LocalSecret, error) {
+// GetLocalSecret returns the secret found by UUID or full path, with its data.
+func (s *VaultService) GetLocalSecret(ctx context.Context, ref string) (*types.LocalSecret, error) {
 	storage, err := s.getStorage(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	secret, err := storage.GetSecret(ctx, secretID, true)
+	secret, err := resolveSecret(ctx, storage, ref, true)
 	if err != nil {
 		return nil, err
 	}
@@ -48,21 +53,35 @@ func (s *VaultService) ListLocalSecrets(ctx context.Context) ([]*types.LocalSecr
 	return secrets, nil
 }
 
-func (s *VaultService) DeleteLocalSecret(ctx context.Context, secretID string) error {
+// SearchLocalSecrets returns the secrets matching a full-text query, best
+// match first, without their data.
+func (s *VaultService) SearchLocalSecrets(ctx context.Context, query string) ([]*types.LocalSecret, error) {
 	storage, err := s.getStorage(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = storage.GetSecret(ctx, secretID, false)
+	return storage.SearchSecrets(ctx, query)
+}
+
+// DeleteLocalSecret moves the secret found by UUID or full path to the trash
+// and returns it, without its data. Its attachments stay with it until it is
+// purged, see RestoreFromTrash and EmptyTrash.
+func (s *VaultService) DeleteLocalSecret(ctx context.Context, ref string) (*types.LocalSecret, error) {
+	storage, err := s.getStorage(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = storage.DeleteSecret(ctx, secretID)
+	secret, err := resolveSecret(ctx, storage, ref, true)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if err := trashSecret(ctx, storage, secret); err != nil {
+		return nil, err
 	}
 
-	return nil
+	secret.Data, secret.Fields = nil, nil
+	return secret, nil
 }
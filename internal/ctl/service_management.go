@@ -18,7 +18,7 @@ func (s *VaultService) Initialize(ctx context.Context) error {
 
 	cryptor := crypto.NewCryptor(s.cfg.Password, s.cfg.Login)
 
-	err := storage.InitializeStorage(ctx, cryptor, s.cfg.DBPath)
+	err := storage.InitializeStorage(ctx, cryptor, s.storageConfig())
 	if err != nil {
 		return err
 	}
@@ -40,16 +40,16 @@ func (s *VaultService) Register(ctx context.Context) error {
 	return nil
 }
 
-func (s *VaultService) SyncSecrets(ctx context.Context) error {
-	diff, err := s.getDiff(ctx)
+func (s *VaultService) SyncSecrets(ctx context.Context) (*SyncReport, error) {
+	client, err := s.getClient(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = s.processDiff(ctx, diff)
+	report, err := s.syncWithTarget(ctx, client)
 	if err != nil {
-		return err
+		return report, err
 	}
 
-	return nil
+	return report, report.Err()
 }
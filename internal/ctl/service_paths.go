@@ -0,0 +1,211 @@
+package ctl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/storage"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+// FolderListing is the content of one folder: its direct subfolders, by
+// name, and the secrets directly in it.
+type FolderListing struct {
+	Folder  string
+	Folders []string
+	Secrets []*types.LocalSecret
+}
+
+// resolveSecret finds a secret by UUID or, failing that, by its full path.
+// Secrets in the trash are not found, see resolveTrashed.
+func resolveSecret(ctx context.Context, storage storage.Storager, ref string, loadData bool) (*types.LocalSecret, error) {
+	secret, err := storage.GetSecret(ctx, ref, loadData)
+	if err == nil && secret.InTrash() {
+		return nil, fmt.Errorf("%w, it is in the trash, restore it with 'keeperctl trash restore %s'",
+			errs.NewSecretNotFoundError(ref), ref)
+	}
+	if err == nil || !errs.IsNotFound(err) {
+		return secret, err
+	}
+
+	path, pathErr := types.NormalizePath(ref)
+	if pathErr != nil {
+		return nil, err
+	}
+
+	secrets, listErr := storage.ListSecrets(ctx)
+	if listErr != nil {
+		return nil, listErr
+	}
+
+	var matches []*types.LocalSecret
+	for _, secret := range secrets {
+		if secret.Path() == path {
+			matches = append(matches, secret)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, errs.NewSecretNotFoundError(ref)
+	case 1:
+		return storage.GetSecret(ctx, matches[0].UUID, loadData)
+	default:
+		return nil, errs.NewConflictError(fmt.Errorf("%d secrets are named %q, use the UUID instead", len(matches), path))
+	}
+}
+
+// checkPathFree fails when a secret other than exceptID has the path.
+func checkPathFree(ctx context.Context, storage storage.Storager, path string, exceptID string) error {
+	secrets, err := storage.ListSecrets(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, secret := range secrets {
+		if secret.UUID != exceptID && secret.Path() == path {
+			return errs.NewConflictError(fmt.Errorf("a secret named %q already exists: %s", path, secret.UUID))
+		}
+		if secret.UUID != exceptID && types.InFolder(secret.Path(), path) {
+			return errs.NewConflictError(fmt.Errorf("%q is a folder", path))
+		}
+		if secret.UUID != exceptID && types.InFolder(path, secret.Path()) {
+			return errs.NewConflictError(fmt.Errorf("%q is a secret, not a folder", secret.Path()))
+		}
+	}
+
+	return nil
+}
+
+// MoveSecret renames the secret found by UUID or path. A target ending in
+// "/" or naming an existing folder moves the secret into it, keeping its name.
+func (s *VaultService) MoveSecret(ctx context.Context, ref string, target string) (*types.LocalSecret, error) {
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := resolveSecret(ctx, storage, ref, true)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := types.NormalizeFolder(target)
+	if err != nil {
+		return nil, err
+	}
+
+	isFolder := strings.HasSuffix(strings.TrimSpace(target), types.PathSeparator)
+	if !isFolder && path != "" {
+		isFolder, err = folderExists(ctx, storage, path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if isFolder || path == "" {
+		_, name := types.SplitPath(secret.Path())
+		path = types.JoinPath(path, name)
+	}
+
+	if path == secret.Path() {
+		return secret, nil
+	}
+
+	if err := checkPathFree(ctx, storage, path, secret.UUID); err != nil {
+		return nil, err
+	}
+
+	secret.Name = path
+	secret.LastModified = time.Now().UTC().Truncate(time.Microsecond)
+
+	if err := storage.UpdateSecret(ctx, secret); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+func folderExists(ctx context.Context, storage storage.Storager, folder string) (bool, error) {
+	secrets, err := storage.ListSecrets(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, secret := range secrets {
+		if types.InFolder(secret.Path(), folder) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// ListFolder returns the direct content of folder, "" being the root.
+func (s *VaultService) ListFolder(ctx context.Context, folder string) (*FolderListing, error) {
+	folder, err := types.NormalizeFolder(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, err := s.ListLocalSecrets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	listing := &FolderListing{Folder: folder}
+	folders := make(map[string]bool)
+	for _, secret := range secrets {
+		path := secret.Path()
+		if !types.InFolder(path, folder) {
+			continue
+		}
+
+		relative := strings.TrimPrefix(path, folder+types.PathSeparator)
+		if folder == "" {
+			relative = path
+		}
+
+		if subfolder, _, found := strings.Cut(relative, types.PathSeparator); found {
+			folders[subfolder] = true
+			continue
+		}
+		listing.Secrets = append(listing.Secrets, secret)
+	}
+
+	if folder != "" && len(folders) == 0 && len(listing.Secrets) == 0 {
+		return nil, &errs.NotFoundError{Entity: "folder", UUID: folder}
+	}
+
+	for name := range folders {
+		listing.Folders = append(listing.Folders, name)
+	}
+	sort.Strings(listing.Folders)
+	sortSecretsByPath(listing.Secrets)
+
+	return listing, nil
+}
+
+// filterSecretsByFolder keeps the secrets in folder or below it.
+func filterSecretsByFolder(secrets []*types.LocalSecret, folder string) []*types.LocalSecret {
+	if folder == "" {
+		return secrets
+	}
+
+	var filtered []*types.LocalSecret
+	for _, secret := range secrets {
+		if types.InFolder(secret.Path(), folder) {
+			filtered = append(filtered, secret)
+		}
+	}
+	return filtered
+}
+
+func sortSecretsByPath(secrets []*types.LocalSecret) {
+	sort.SliceStable(secrets, func(i, j int) bool {
+		return secrets[i].Path() < secrets[j].Path()
+	})
+}
@@ -0,0 +1,95 @@
+package ctl
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultService_Paths(t *testing.T) {
+	ctx := context.Background()
+	service := newTestVaultService(t)
+
+	root := addTestTextSecret(t, service, "work/aws/prod/root", "one")
+	addTestTextSecret(t, service, "work/aws/staging", "two")
+	addTestTextSecret(t, service, "work/mail", "three")
+	addTestTextSecret(t, service, "personal", "four")
+
+	t.Run("get by path", func(t *testing.T) {
+		secret, err := service.GetLocalSecret(ctx, "/work/aws/prod/root")
+		require.NoError(t, err)
+		assert.Equal(t, root.UUID, secret.UUID)
+
+		_, err = service.GetLocalSecret(ctx, "work/aws")
+		assert.True(t, errs.IsNotFound(err))
+	})
+
+	t.Run("paths are unique", func(t *testing.T) {
+		secret := *root
+		secret.UUID = "another"
+		_, err := service.CreateLocalSecret(ctx, &secret)
+		assert.True(t, errs.IsConflict(err))
+
+		secret.Name = "work/aws"
+		_, err = service.CreateLocalSecret(ctx, &secret)
+		assert.True(t, errs.IsConflict(err), "a folder name is taken")
+
+		secret.Name = "personal/mail"
+		_, err = service.CreateLocalSecret(ctx, &secret)
+		assert.True(t, errs.IsConflict(err), "a secret is not a folder")
+	})
+
+	t.Run("ls", func(t *testing.T) {
+		listing, err := service.ListFolder(ctx, "work")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"aws"}, listing.Folders)
+		require.Len(t, listing.Secrets, 1)
+		assert.Equal(t, "work/mail", listing.Secrets[0].Name)
+
+		listing, err = service.ListFolder(ctx, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"work"}, listing.Folders)
+		require.Len(t, listing.Secrets, 1)
+
+		_, err = service.ListFolder(ctx, "home")
+		assert.True(t, errs.IsNotFound(err))
+	})
+
+	t.Run("tree", func(t *testing.T) {
+		secrets, err := service.ListLocalSecrets(ctx)
+		require.NoError(t, err)
+
+		var out bytes.Buffer
+		require.NoError(t, displaySecretTree(&out, "work", filterSecretsByFolder(secrets, "work")))
+		assert.Contains(t, out.String(), "work/\n├── aws/\n│   ├── prod/\n│   │   └── root  (text, ")
+		assert.Contains(t, out.String(), "└── mail  (text, ")
+		assert.NotContains(t, out.String(), "personal")
+	})
+
+	t.Run("mv", func(t *testing.T) {
+		moved, err := service.MoveSecret(ctx, "work/mail", "personal/")
+		assert.True(t, errs.IsConflict(err), "personal is a secret")
+		assert.Nil(t, moved)
+
+		moved, err = service.MoveSecret(ctx, "work/mail", "work/aws")
+		require.NoError(t, err)
+		assert.Equal(t, "work/aws/mail", moved.Name)
+		assert.True(t, moved.LastModified.After(root.LastModified))
+
+		moved, err = service.MoveSecret(ctx, moved.UUID, "archive/old-mail")
+		require.NoError(t, err)
+		assert.Equal(t, "archive/old-mail", moved.Name)
+
+		stored, err := service.GetLocalSecret(ctx, "archive/old-mail")
+		require.NoError(t, err)
+		assert.Equal(t, moved.UUID, stored.UUID)
+		assert.Equal(t, moved.Hash, stored.Hash)
+
+		_, err = service.MoveSecret(ctx, moved.UUID, "work/aws/staging")
+		assert.True(t, errs.IsConflict(err))
+	})
+}
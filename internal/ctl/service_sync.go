@@ -3,103 +3,235 @@ package ctl
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/etoneja/go-keeper/internal/ctl/client"
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
 	"github.com/etoneja/go-keeper/internal/ctl/types"
 )
 
-func (s *VaultService) getDiff(ctx context.Context) (*types.SecretsDiff, error) {
-	storage, err := s.getStorage(ctx)
+// SyncReport records what a sync did with each secret that differed.
+type SyncReport struct {
+	Target     string
+	LocalOnly  int
+	RemoteOnly int
+	Both       int
+	// Rollback explains a suspected rollback of the target as a whole.
+	Rollback string
+	Secrets  []*SyncedSecret
+}
+
+// SyncedSecret is the action taken on one secret.
+type SyncedSecret struct {
+	UUID     string
+	Action   ActionType
+	Rollback string
+	// Err is set when the action failed.
+	Err error
+}
+
+// Failed returns the secrets whose action failed.
+func (r *SyncReport) Failed() []*SyncedSecret {
+	var failed []*SyncedSecret
+	for _, secret := range r.Secrets {
+		if secret.Err != nil {
+			failed = append(failed, secret)
+		}
+	}
+	return failed
+}
+
+// Err summarizes the failed secrets, nil when every action succeeded. It
+// wraps the first failure, so its kind decides the exit code.
+func (r *SyncReport) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%d of %d secrets failed to sync, first %s: %w",
+		len(failed), len(r.Secrets), failed[0].UUID, failed[0].Err)
+}
+
+// syncWithTarget reconciles the vault with the target and publishes the
+// resulting sync manifest there. Secrets that fail are recorded in the report
+// and the sync goes on, see SyncReport.Err. Other errors stop the sync, with
+// the report of what was done so far when there is one.
+func (s *VaultService) syncWithTarget(ctx context.Context, target client.SyncTarget) (*SyncReport, error) {
+	diff, manifests, err := s.getDiff(ctx, target)
 	if err != nil {
 		return nil, err
 	}
 
-	localSecrets, err := storage.ListSecrets(ctx)
+	report, err := s.processDiff(ctx, target, diff)
 	if err != nil {
-		return nil, err
+		return report, err
 	}
 
-	client, err := s.getClient(ctx)
+	err = s.syncAttachments(ctx, target, diff, manifests, report)
 	if err != nil {
-		return nil, err
+		return report, err
 	}
 
-	remoteSecrets, err := client.ListSecrets(ctx)
+	err = s.publishSyncManifest(ctx, target, diff, manifests)
 	if err != nil {
-		return nil, err
+		return report, err
+	}
+
+	return report, nil
+}
+
+func (s *VaultService) getDiff(ctx context.Context, target client.SyncTarget) (*types.SecretsDiff, *manifestState, error) {
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	localSecrets, err := listWithTrash(ctx, storage)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = s.migrateLocalHashes(ctx, localSecrets)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	remoteSecrets, manifestRecord, err := s.listRemoteSecrets(ctx, target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	manifests, err := s.loadManifests(ctx, target, manifestRecord)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = s.resealRemoteSecrets(ctx, target, remoteSecrets, manifests)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	diff := diffSecrets(localSecrets, remoteSecrets)
-	return diff, err
+	detectRollbacks(diff, manifests.seen, manifests.remote)
+
+	purged, err := storage.ListPurgedSecrets(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	markPurged(diff, purged)
+
+	return diff, manifests, nil
 }
 
-func (s *VaultService) processDiff(ctx context.Context, diff *types.SecretsDiff) error {
-	fmt.Printf("local_only: %d, remote_only: %d, both: %d\n",
-		len(diff.LocalOnly), len(diff.RemoteOnly), len(diff.Both))
+func (s *VaultService) processDiff(ctx context.Context, target client.SyncTarget, diff *types.SecretsDiff) (*SyncReport, error) {
+	report := &SyncReport{
+		Target:     target.TargetID(),
+		LocalOnly:  len(diff.LocalOnly),
+		RemoteOnly: len(diff.RemoteOnly),
+		Both:       len(diff.Both),
+	}
 
-	for _, secret := range diff.LocalOnly {
-		err := s.syncLocalSecret(ctx, secret)
-		if err != nil {
+	printMessage("local_only: %d, remote_only: %d, both: %d", report.LocalOnly, report.RemoteOnly, report.Both)
+
+	if reason, flagged := diff.RollbackReason(constants.ManifestSecretID); flagged {
+		report.Rollback = reason
+		printMessage("%s Possible rollback: %s", constants.EmojiWarning, reason)
+	}
+
+	// NOTE: Without an action nothing was chosen, so an error there, such as
+	// an interrupted prompt, stops the sync
+	record := func(secretID string, action ActionType, reason string, err error) error {
+		if action == "" {
 			return err
 		}
+		if err != nil {
+			printMessage("%s Failed to %s secret '%s': %v", constants.EmojiError, action, secretID, err)
+		}
+		report.Secrets = append(report.Secrets, &SyncedSecret{UUID: secretID, Action: action, Rollback: reason, Err: err})
+		return nil
+	}
+
+	for _, secret := range diff.LocalOnly {
+		reason, _ := diff.RollbackReason(secret.UUID)
+		action, err := s.syncLocalSecret(ctx, target, secret, reason, diff.Purged[secret.UUID])
+		if err := record(secret.UUID, action, reason, err); err != nil {
+			return report, err
+		}
 	}
 
 	for _, secret := range diff.RemoteOnly {
-		err := s.syncRemoteSecret(ctx, secret)
-		if err != nil {
-			return err
+		reason, _ := diff.RollbackReason(secret.UUID)
+		action, err := s.syncRemoteSecret(ctx, target, secret, reason, diff.Purged[secret.UUID])
+		if err := record(secret.UUID, action, reason, err); err != nil {
+			return report, err
 		}
 	}
 
 	for _, pair := range diff.Both {
-		err := s.syncSecretCheckPair(ctx, pair)
-		if err != nil {
-			return err
+		reason, _ := diff.RollbackReason(pair.Local.UUID)
+		action, err := s.syncSecretCheckPair(ctx, target, pair, reason)
+		if err := record(pair.Local.UUID, action, reason, err); err != nil {
+			return report, err
 		}
 	}
 
-	return nil
+	return report, nil
 }
 
+// deleteLocalSecret moves the local secret to the trash.
 func (s *VaultService) deleteLocalSecret(ctx context.Context, secretID string) error {
-	fmt.Printf("Deleting local secret '%s'\n", secretID)
+	printMessage("Moving local secret '%s' to trash", secretID)
 
 	storage, err := s.getStorage(ctx)
 	if err != nil {
 		return err
 	}
 
-	err = storage.DeleteSecret(ctx, secretID)
+	localSecret, err := storage.GetSecret(ctx, secretID, true)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return trashSecret(ctx, storage, localSecret)
 }
 
-func (s *VaultService) createRemoteSecret(ctx context.Context, secretID string) error {
-	fmt.Printf("Creating remote secret '%s'\n", secretID)
+// purgeLocalSecret erases the local secret purged on another device.
+func (s *VaultService) purgeLocalSecret(ctx context.Context, secretID string) error {
+	printMessage("Purging local secret '%s'", secretID)
 
 	storage, err := s.getStorage(ctx)
 	if err != nil {
 		return err
 	}
 
-	localSecret, err := storage.GetSecret(ctx, secretID, true)
+	return storage.PurgeSecret(ctx, secretID)
+}
+
+func (s *VaultService) createRemoteSecret(ctx context.Context, target client.SyncTarget, secretID string) error {
+	printMessage("Creating remote secret '%s'", secretID)
+
+	return s.uploadLocalSecret(ctx, target, secretID)
+}
+
+func (s *VaultService) uploadLocalSecret(ctx context.Context, target client.SyncTarget, secretID string) error {
+	storage, err := s.getStorage(ctx)
 	if err != nil {
 		return err
 	}
 
-	remoteSecret, err := types.ConvertLocalSecretToRemoteSecret(s.cryptor, localSecret)
+	localSecret, err := storage.GetSecret(ctx, secretID, true)
 	if err != nil {
 		return err
 	}
 
-	client, err := s.getClient(ctx)
+	remoteSecret, err := types.ConvertLocalSecretToRemoteSecret(s.cryptor, localSecret, s.cfg.Compression)
 	if err != nil {
 		return err
 	}
 
-	err = client.SetSecret(ctx, remoteSecret)
+	err = target.SetSecret(ctx, remoteSecret)
 	if err != nil {
 		return err
 	}
@@ -107,19 +239,10 @@ func (s *VaultService) createRemoteSecret(ctx context.Context, secretID string)
 	return nil
 }
 
-func (s *VaultService) createLocalSecret(ctx context.Context, secretID string) error {
-	fmt.Printf("Creating local secret '%s'\n", secretID)
-
-	client, err := s.getClient(ctx)
-	if err != nil {
-		return err
-	}
-	remoteSecret, err := client.GetSecret(ctx, secretID)
-	if err != nil {
-		return err
-	}
+func (s *VaultService) createLocalSecret(ctx context.Context, target client.SyncTarget, secretID string) error {
+	printMessage("Creating local secret '%s'", secretID)
 
-	localSecret, err := types.ConvertRemoteSecretToLocalSecret(s.cryptor, remoteSecret)
+	localSecret, err := s.downloadRemoteSecret(ctx, target, secretID)
 	if err != nil {
 		return err
 	}
@@ -137,128 +260,262 @@ func (s *VaultService) createLocalSecret(ctx context.Context, secretID string) e
 	return nil
 }
 
-func (s *VaultService) deleteRemoteSecret(ctx context.Context, secretID string) error {
-	fmt.Printf("Deleting remote secret '%s'\n", secretID)
-
-	client, err := s.getClient(ctx)
-	if err != nil {
-		return err
-	}
-
-	err = client.DeleteSecret(ctx, secretID)
+func (s *VaultService) downloadRemoteSecret(ctx context.Context, target client.SyncTarget, secretID string) (*types.LocalSecret, error) {
+	remoteSecret, err := target.GetSecret(ctx, secretID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return types.ConvertRemoteSecretToLocalSecret(s.cryptor, remoteSecret)
 }
 
-func (s *VaultService) replaceLocalSecret(ctx context.Context, secretID string) error {
-	fmt.Printf("Replacing remote secret '%s'\n", secretID)
+// deleteRemoteSecret moves the remote secret to the trash. The move is sealed
+// in the secret like any other modification.
+func (s *VaultService) deleteRemoteSecret(ctx context.Context, target client.SyncTarget, secretID string) error {
+	printMessage("Moving remote secret '%s' to trash", secretID)
 
-	err := s.deleteLocalSecret(ctx, secretID)
+	secret, err := s.downloadRemoteSecret(ctx, target, secretID)
 	if err != nil {
 		return err
 	}
 
-	err = s.createLocalSecret(ctx, secretID)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	secret.DeletedAt = now
+	secret.LastModified = now
+
+	remoteSecret, err := types.ConvertLocalSecretToRemoteSecret(s.cryptor, secret, s.cfg.Compression)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return target.SetSecret(ctx, remoteSecret)
 }
 
-func (s *VaultService) replaceRemoteSecret(ctx context.Context, secretID string) error {
-	fmt.Printf("Replacing remote secret '%s'\n", secretID)
+// purgeRemoteSecret erases the remote secret purged locally.
+func (s *VaultService) purgeRemoteSecret(ctx context.Context, target client.SyncTarget, secretID string) error {
+	printMessage("Purging remote secret '%s'", secretID)
 
-	err := s.deleteRemoteSecret(ctx, secretID)
+	return target.DeleteSecret(ctx, secretID)
+}
+
+// replaceLocalSecret overwrites the local secret with the remote one, which
+// keeps the local content in the history.
+func (s *VaultService) replaceLocalSecret(ctx context.Context, target client.SyncTarget, secretID string) error {
+	printMessage("Replacing local secret '%s'", secretID)
+
+	localSecret, err := s.downloadRemoteSecret(ctx, target, secretID)
 	if err != nil {
 		return err
 	}
 
-	err = s.createRemoteSecret(ctx, secretID)
+	storage, err := s.getStorage(ctx)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return storage.UpdateSecret(ctx, localSecret)
 }
 
-func (s *VaultService) syncLocalSecret(ctx context.Context, localSecret *types.LocalSecret) error {
-	action, err := PromptForLocalOnlyAction(localSecret)
+func (s *VaultService) replaceRemoteSecret(ctx context.Context, target client.SyncTarget, secretID string) error {
+	printMessage("Replacing remote secret '%s'", secretID)
+
+	return s.uploadLocalSecret(ctx, target, secretID)
+}
+
+func (s *VaultService) syncLocalSecret(ctx context.Context, target client.SyncTarget, localSecret *types.LocalSecret, rollbackReason string, purged bool) (ActionType, error) {
+	action, err := s.chooseLocalOnlyAction(localSecret, rollbackReason, purged)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	switch action {
 	case ActionDeleteLocal:
 		err := s.deleteLocalSecret(ctx, localSecret.UUID)
 		if err != nil {
-			return err
+			return action, err
 		}
 	case ActionCreateRemote:
-		err := s.createRemoteSecret(ctx, localSecret.UUID)
+		err := s.createRemoteSecret(ctx, target, localSecret.UUID)
 		if err != nil {
-			return err
+			return action, err
+		}
+	case ActionPurgeLocal:
+		err := s.purgeLocalSecret(ctx, localSecret.UUID)
+		if err != nil {
+			return action, err
 		}
 	case ActionSkip:
-		fmt.Printf("Ignoring secret '%s'\n", localSecret.UUID)
+		printMessage("Ignoring secret '%s'", localSecret.UUID)
 	}
 
-	return nil
+	return action, nil
 }
 
-func (s *VaultService) syncRemoteSecret(ctx context.Context, remoteSecret *types.RemoteSecret) error {
-	action, err := PromptForRemoteOnlyAction(remoteSecret)
+func (s *VaultService) syncRemoteSecret(ctx context.Context, target client.SyncTarget, remoteSecret *types.RemoteSecret, rollbackReason string, purged bool) (ActionType, error) {
+	action, err := s.chooseRemoteOnlyAction(remoteSecret, rollbackReason, purged)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	switch action {
 	case ActionCreateLocal:
-		err := s.createLocalSecret(ctx, remoteSecret.UUID)
+		err := s.createLocalSecret(ctx, target, remoteSecret.UUID)
 		if err != nil {
-			return err
+			return action, err
 		}
 	case ActionDeleteRemote:
-		err := s.deleteRemoteSecret(ctx, remoteSecret.UUID)
+		err := s.deleteRemoteSecret(ctx, target, remoteSecret.UUID)
 		if err != nil {
-			return err
+			return action, err
+		}
+	case ActionPurgeRemote:
+		err := s.purgeRemoteSecret(ctx, target, remoteSecret.UUID)
+		if err != nil {
+			return action, err
 		}
 	case ActionSkip:
-		fmt.Printf("Ignoring secret '%s'\n", remoteSecret.UUID)
+		printMessage("Ignoring secret '%s'", remoteSecret.UUID)
 	}
 
-	return nil
+	return action, nil
 }
 
-func (s *VaultService) syncSecretCheckPair(ctx context.Context, checkPair *types.SecretCheckPair) error {
+func (s *VaultService) syncSecretCheckPair(ctx context.Context, target client.SyncTarget, checkPair *types.SecretCheckPair, rollbackReason string) (ActionType, error) {
 	if checkPair.IsIdentical() {
-		return nil
+		return "", nil
+	}
+
+	migrated, err := s.migrateRemoteHash(ctx, target, checkPair)
+	if err != nil {
+		return ActionMigrateRemoteHash, err
+	}
+	if migrated {
+		return ActionMigrateRemoteHash, nil
 	}
 
 	// TODO: Add option to show diff
 
-	action, err := PromptForConflictCheckPairAction(checkPair)
+	action, err := s.chooseCheckPairAction(checkPair, rollbackReason)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	switch action {
 	case ActionReplaceLocal:
-		err := s.replaceLocalSecret(ctx, checkPair.Local.UUID)
+		err := s.replaceLocalSecret(ctx, target, checkPair.Local.UUID)
 		if err != nil {
-			return err
+			return action, err
 		}
 	case ActionReplaceRemote:
-		err := s.replaceRemoteSecret(ctx, checkPair.Remote.UUID)
+		err := s.replaceRemoteSecret(ctx, target, checkPair.Remote.UUID)
 		if err != nil {
-			return err
+			return action, err
 		}
 	case ActionSkip:
-		fmt.Printf("Ignoring secret '%s'\n", checkPair.Local.UUID)
+		printMessage("Ignoring secret '%s'", checkPair.Local.UUID)
+	}
+
+	return action, nil
+}
+
+// migrateLocalHashes rewrites legacy unkeyed hashes of local secrets in the
+// current format, so they compare equal to copies migrated on other devices.
+func (s *VaultService) migrateLocalHashes(ctx context.Context, localSecrets []*types.LocalSecret) error {
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, localSecret := range localSecrets {
+		if !localSecret.HasLegacyHash() {
+			continue
+		}
+
+		secret, err := storage.GetSecret(ctx, localSecret.UUID, true)
+		if err != nil {
+			return err
+		}
+
+		secret.RefreshHash(s.cryptor)
+
+		err = storage.UpdateSecret(ctx, secret)
+		if err != nil {
+			return err
+		}
+
+		localSecret.Hash = secret.Hash
+	}
+
+	return nil
+}
+
+// migrateRemoteHash re-uploads a remote secret that still carries a legacy
+// hash when its content matches the local copy, instead of reporting a conflict.
+func (s *VaultService) migrateRemoteHash(ctx context.Context, target client.SyncTarget, checkPair *types.SecretCheckPair) (bool, error) {
+	if !crypto.IsLegacyDataHash(checkPair.Remote.Hash) ||
+		!checkPair.Local.LastModified.Equal(checkPair.Remote.LastModified) {
+		return false, nil
+	}
+
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	localSecret, err := storage.GetSecret(ctx, checkPair.Local.UUID, true)
+	if err != nil {
+		return false, err
 	}
 
+	if localSecret.LegacyHash(s.cryptor) != checkPair.Remote.Hash {
+		return false, nil
+	}
+
+	printMessage("Migrating hash of remote secret '%s'", checkPair.Remote.UUID)
+
+	err = s.uploadLocalSecret(ctx, target, checkPair.Remote.UUID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// resealRemoteSecrets seals remote secrets uploaded before ciphertexts were
+// bound to their record again, until the manifest records that every secret
+// on the target is bound. Secrets that cannot be opened are left as they are
+// and fail when they are downloaded.
+func (s *VaultService) resealRemoteSecrets(ctx context.Context, target client.SyncTarget, remoteSecrets []*types.RemoteSecret, manifests *manifestState) error {
+	if manifests.bound {
+		return nil
+	}
+
+	bound := true
+	for _, secret := range remoteSecrets {
+		remoteSecret, err := target.GetSecret(ctx, secret.UUID)
+		if err != nil {
+			return err
+		}
+		if crypto.IsBoundSecretData(remoteSecret.Data) {
+			continue
+		}
+
+		printMessage("Re-sealing remote secret '%s'", secret.UUID)
+
+		resealed, err := types.ResealRemoteSecret(s.cryptor, remoteSecret)
+		if err != nil {
+			printMessage("%s Failed to re-seal secret '%s': %v", constants.EmojiWarning, secret.UUID, err)
+			bound = false
+			continue
+		}
+
+		err = target.SetSecret(ctx, resealed)
+		if err != nil {
+			return err
+		}
+	}
+
+	manifests.bound = bound
+
 	return nil
 }
@@ -0,0 +1,228 @@
+package ctl
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/etoneja/go-keeper/internal/ctl/client"
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+// attachmentState tells where an attachment and its secret are once the
+// secrets are synced.
+type attachmentState struct {
+	local, remote             bool
+	secretLocal, secretRemote bool
+	// seen is set when the last sync with the target recorded the attachment
+	seen bool
+	// restored is set when this sync copied the secret to the side missing
+	// the attachment, which then was deleted with the secret, not on its own
+	restored bool
+}
+
+// chooseAttachmentAction decides what to do with an attachment. Attachments
+// never change, so unlike secrets they never conflict: one missing on a side
+// is either new on the other or was deleted there, which the seen manifest
+// tells apart. Attachments of secrets gone from both sides are deleted.
+func chooseAttachmentAction(state attachmentState) ActionType {
+	orphaned := !state.secretLocal && !state.secretRemote
+
+	switch {
+	case state.local && state.remote:
+		if orphaned {
+			return ActionDeleteAttachment
+		}
+	case state.local:
+		switch {
+		case orphaned, state.seen && !state.restored:
+			return ActionDeleteLocalAttachment
+		case state.secretRemote:
+			return ActionUploadAttachment
+		}
+	case state.remote:
+		switch {
+		case orphaned, state.seen && !state.restored:
+			return ActionDeleteRemoteAttachment
+		case state.secretLocal:
+			return ActionDownloadAttachment
+		}
+	}
+
+	// NOTE: Attachments of a secret skipped during sync wait for it
+	return ""
+}
+
+// syncAttachments reconciles attachments once the secrets are synced and
+// records what it did in the report. A suspected rollback of the target
+// never deletes local attachments, it uploads them again.
+func (s *VaultService) syncAttachments(ctx context.Context, target client.SyncTarget, diff *types.SecretsDiff, manifests *manifestState, report *SyncReport) error {
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return err
+	}
+
+	localSecrets, err := listWithTrash(ctx, storage)
+	if err != nil {
+		return err
+	}
+
+	remoteSecrets, _, err := s.listRemoteSecrets(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	localAttachments, err := storage.ListAttachments(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	remoteAttachments, err := target.ListAttachments(ctx)
+	if err != nil {
+		return err
+	}
+
+	states := make(map[string]*attachmentState)
+	secretIDs := make(map[string]string)
+	stateOf := func(attachmentID, secretID string) *attachmentState {
+		state, exists := states[attachmentID]
+		if !exists {
+			state = &attachmentState{}
+			states[attachmentID] = state
+			secretIDs[attachmentID] = secretID
+		}
+		return state
+	}
+	for _, attachment := range localAttachments {
+		stateOf(attachment.UUID, attachment.SecretUUID).local = true
+	}
+	for _, attachment := range remoteAttachments {
+		stateOf(attachment.UUID, attachment.SecretUUID).remote = true
+	}
+
+	secretsLocal := make(map[string]bool, len(localSecrets))
+	for _, secret := range localSecrets {
+		secretsLocal[secret.UUID] = true
+	}
+	secretsRemote := make(map[string]bool, len(remoteSecrets))
+	for _, secret := range remoteSecrets {
+		secretsRemote[secret.UUID] = true
+	}
+
+	restoredLocal := make(map[string]bool)
+	restoredRemote := make(map[string]bool)
+	for _, secret := range report.Secrets {
+		switch {
+		case secret.Err != nil:
+		case secret.Action == ActionCreateLocal:
+			restoredLocal[secret.UUID] = true
+		case secret.Action == ActionCreateRemote:
+			restoredRemote[secret.UUID] = true
+		}
+	}
+
+	seen := manifests.seen
+	if _, rolledBack := diff.RollbackReason(constants.ManifestSecretID); rolledBack {
+		seen = types.NewSyncManifest()
+	}
+
+	for _, attachmentID := range slices.Sorted(maps.Keys(states)) {
+		state := states[attachmentID]
+		secretID := secretIDs[attachmentID]
+		state.secretLocal = secretsLocal[secretID]
+		state.secretRemote = secretsRemote[secretID]
+		state.seen = seen.HasAttachment(attachmentID)
+		state.restored = (state.local && restoredRemote[secretID]) || (state.remote && restoredLocal[secretID])
+
+		action := chooseAttachmentAction(*state)
+		if action == "" {
+			continue
+		}
+
+		err := s.applyAttachmentAction(ctx, target, attachmentID, action)
+		if err != nil {
+			printMessage("%s Failed to %s '%s': %v", constants.EmojiError, action, attachmentID, err)
+		}
+		report.Secrets = append(report.Secrets, &SyncedSecret{UUID: attachmentID, Action: action, Err: err})
+	}
+
+	return nil
+}
+
+func (s *VaultService) applyAttachmentAction(ctx context.Context, target client.SyncTarget, attachmentID string, action ActionType) error {
+	switch action {
+	case ActionUploadAttachment:
+		printMessage("Uploading attachment '%s'", attachmentID)
+		return s.uploadAttachment(ctx, target, attachmentID)
+	case ActionDownloadAttachment:
+		printMessage("Downloading attachment '%s'", attachmentID)
+		return s.downloadAttachment(ctx, target, attachmentID)
+	case ActionDeleteLocalAttachment:
+		return s.deleteLocalAttachment(ctx, attachmentID)
+	case ActionDeleteRemoteAttachment:
+		return s.deleteRemoteAttachment(ctx, target, attachmentID)
+	case ActionDeleteAttachment:
+		if err := s.deleteRemoteAttachment(ctx, target, attachmentID); err != nil {
+			return err
+		}
+		return s.deleteLocalAttachment(ctx, attachmentID)
+	}
+	return nil
+}
+
+func (s *VaultService) uploadAttachment(ctx context.Context, target client.SyncTarget, attachmentID string) error {
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return err
+	}
+
+	attachment, err := storage.GetAttachment(ctx, attachmentID, true)
+	if err != nil {
+		return err
+	}
+
+	remoteAttachment, content, err := types.ConvertAttachmentToRemoteAttachment(s.cryptor, attachment)
+	if err != nil {
+		return err
+	}
+
+	return target.UploadAttachment(ctx, remoteAttachment, content)
+}
+
+func (s *VaultService) downloadAttachment(ctx context.Context, target client.SyncTarget, attachmentID string) error {
+	remoteAttachment, content, err := target.DownloadAttachment(ctx, attachmentID)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	attachment, err := types.ConvertRemoteAttachmentToAttachment(s.cryptor, remoteAttachment, content)
+	if err != nil {
+		return err
+	}
+
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return err
+	}
+
+	return storage.CreateAttachment(ctx, attachment)
+}
+
+func (s *VaultService) deleteLocalAttachment(ctx context.Context, attachmentID string) error {
+	printMessage("Deleting local attachment '%s'", attachmentID)
+
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return err
+	}
+
+	return storage.DeleteAttachment(ctx, attachmentID)
+}
+
+func (s *VaultService) deleteRemoteAttachment(ctx context.Context, target client.SyncTarget, attachmentID string) error {
+	printMessage("Deleting remote attachment '%s'", attachmentID)
+
+	return target.DeleteAttachment(ctx, attachmentID)
+}
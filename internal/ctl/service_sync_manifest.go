@@ -0,0 +1,141 @@
+package ctl
+
+import (
+	"context"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/client"
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+// manifestState holds the manifests used to detect rollbacks during one sync.
+type manifestState struct {
+	seen   *types.SyncManifest
+	remote *types.SyncManifest
+	// bound is set when every secret on the target is sealed bound to its record
+	bound bool
+}
+
+// listRemoteSecrets returns the remote secrets and, separately, the record
+// holding the sync manifest, which is nil when the target has none.
+func (s *VaultService) listRemoteSecrets(ctx context.Context, target client.SyncTarget) ([]*types.RemoteSecret, *types.RemoteSecret, error) {
+	remoteSecrets, err := target.ListSecrets(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var manifestRecord *types.RemoteSecret
+	secrets := make([]*types.RemoteSecret, 0, len(remoteSecrets))
+	for _, secret := range remoteSecrets {
+		if secret.UUID == constants.ManifestSecretID {
+			manifestRecord = secret
+			continue
+		}
+		secrets = append(secrets, secret)
+	}
+
+	return secrets, manifestRecord, nil
+}
+
+func (s *VaultService) loadManifests(ctx context.Context, target client.SyncTarget, manifestRecord *types.RemoteSecret) (*manifestState, error) {
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen, err := storage.GetSyncManifest(ctx, target.TargetID())
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := &manifestState{seen: seen, bound: seen.Bound}
+	if manifestRecord == nil {
+		return manifests, nil
+	}
+
+	remoteRecord, err := target.GetSecret(ctx, manifestRecord.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := types.ConvertRemoteSecretToManifest(s.cryptor, remoteRecord)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests.remote = remote
+	manifests.bound = manifests.bound || remote.Bound
+
+	return manifests, nil
+}
+
+// publishSyncManifest records the secrets and attachments now on the target,
+// both remotely and in the vault. High-water marks of skipped rollbacks are
+// kept, so they are flagged again on the next sync.
+func (s *VaultService) publishSyncManifest(ctx context.Context, target client.SyncTarget, diff *types.SecretsDiff, manifests *manifestState) error {
+	remoteSecrets, _, err := s.listRemoteSecrets(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	remoteAttachments, err := target.ListAttachments(ctx)
+	if err != nil {
+		return err
+	}
+
+	next := types.NewSyncManifest()
+	next.Bound = manifests.bound
+	for _, secret := range remoteSecrets {
+		next.Observe(secret.UUID, secret.LastModified, secret.Hash)
+	}
+	for _, attachment := range remoteAttachments {
+		next.ObserveAttachment(attachment.UUID, attachment.LastModified, attachment.Hash)
+	}
+
+	// NOTE: After a manifest rollback every secret seen before stays listed,
+	// so other devices flag its disappearance instead of deleting it
+	_, manifestRolledBack := diff.RollbackReason(constants.ManifestSecretID)
+	for secretID, entry := range manifests.seen.Secrets {
+		if _, flagged := diff.RollbackReason(secretID); flagged || manifestRolledBack {
+			next.Observe(secretID, entry.LastModified, entry.Hash)
+		}
+	}
+
+	if manifests.remote != nil {
+		for secretID, entry := range manifests.remote.Secrets {
+			if _, flagged := diff.RollbackReason(secretID); flagged {
+				next.Observe(secretID, entry.LastModified, entry.Hash)
+			}
+		}
+	}
+
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return err
+	}
+
+	remote := manifests.remote
+	if remote != nil && remote.Revision >= manifests.seen.Revision && next.Equal(remote) {
+		return storage.SaveSyncManifest(ctx, target.TargetID(), remote)
+	}
+
+	next.Revision = manifests.seen.Revision
+	if remote != nil && remote.Revision > next.Revision {
+		next.Revision = remote.Revision
+	}
+	next.Revision++
+	next.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+
+	manifestRecord, err := types.ConvertManifestToRemoteSecret(s.cryptor, next)
+	if err != nil {
+		return err
+	}
+
+	err = target.SetSecret(ctx, manifestRecord)
+	if err != nil {
+		return err
+	}
+
+	return storage.SaveSyncManifest(ctx, target.TargetID(), next)
+}
@@ -0,0 +1,115 @@
+package ctl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/etoneja/go-keeper/internal/ctl/bundle"
+	"github.com/etoneja/go-keeper/internal/ctl/config"
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var legacyPrefix = []byte("legacy:")
+
+// legacyCryptor stands in for blobs sealed before ciphertexts were bound to
+// their record, which it keeps in plain text behind legacyPrefix.
+type legacyCryptor struct {
+	crypto.Cryptor
+}
+
+func (c legacyCryptor) DecryptLegacySecretData(encryptedData []byte) ([]byte, error) {
+	if !bytes.HasPrefix(encryptedData, legacyPrefix) {
+		return nil, errors.New("decryption failed")
+	}
+	return bytes.TrimPrefix(encryptedData, legacyPrefix), nil
+}
+
+func newTestSyncService(t *testing.T) *VaultService {
+	service := newTestVaultService(t)
+	service.cryptor = legacyCryptor{service.cryptor}
+	service.cfg.SyncStrategy = config.SyncNewest
+
+	return service
+}
+
+// setLegacyRemoteSecret stores the secret on the target as an older client would.
+func setLegacyRemoteSecret(t *testing.T, service *VaultService, target *bundle.Bundle, secret *types.LocalSecret) {
+	ctx := context.Background()
+
+	remoteSecret, err := types.ConvertLocalSecretToRemoteSecret(service.cryptor, secret, false)
+	require.NoError(t, err)
+
+	binding := crypto.SecretBinding{SecretID: remoteSecret.UUID, LastModified: remoteSecret.LastModified}
+	plainData, err := service.cryptor.DecryptSecretData(remoteSecret.Data, binding)
+	require.NoError(t, err)
+
+	remoteSecret.Data = append(append([]byte{}, legacyPrefix...), plainData...)
+	require.NoError(t, target.SetSecret(ctx, remoteSecret))
+}
+
+func TestVaultService_SyncLegacySecrets(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("re-sealed until the target is bound", func(t *testing.T) {
+		source := newTestSyncService(t)
+		secret := addTestTextSecret(t, source, "legacy", "content")
+
+		target, err := bundle.Create(source.cryptor, filepath.Join(t.TempDir(), "sync.gkb"))
+		require.NoError(t, err)
+		setLegacyRemoteSecret(t, source, target, secret)
+
+		service := newTestSyncService(t)
+		report, err := service.syncWithTarget(ctx, target)
+		require.NoError(t, err)
+		require.NoError(t, report.Err())
+
+		remoteSecret, err := target.GetSecret(ctx, secret.UUID)
+		require.NoError(t, err)
+		assert.True(t, crypto.IsBoundSecretData(remoteSecret.Data))
+		assert.Equal(t, secret.LastModified, remoteSecret.LastModified)
+
+		synced, err := service.GetLocalSecret(ctx, secret.UUID)
+		require.NoError(t, err)
+		assert.Equal(t, secret.Hash, synced.Hash)
+
+		storage, err := service.getStorage(ctx)
+		require.NoError(t, err)
+		seen, err := storage.GetSyncManifest(ctx, target.TargetID())
+		require.NoError(t, err)
+		assert.True(t, seen.Bound)
+	})
+
+	t.Run("refused once the target is bound", func(t *testing.T) {
+		source := newTestSyncService(t)
+		addTestTextSecret(t, source, "bound", "content")
+
+		target, err := bundle.Create(source.cryptor, filepath.Join(t.TempDir(), "sync.gkb"))
+		require.NoError(t, err)
+
+		_, err = source.syncWithTarget(ctx, target)
+		require.NoError(t, err)
+
+		replayed := addTestTextSecret(t, newTestSyncService(t), "replayed", "content")
+		setLegacyRemoteSecret(t, source, target, replayed)
+
+		service := newTestSyncService(t)
+		report, err := service.syncWithTarget(ctx, target)
+		require.NoError(t, err)
+
+		failed := report.Failed()
+		require.Len(t, failed, 1)
+		assert.Equal(t, replayed.UUID, failed[0].UUID)
+		assert.True(t, errs.IsTampered(failed[0].Err))
+
+		remoteSecret, err := target.GetSecret(ctx, replayed.UUID)
+		require.NoError(t, err)
+		assert.False(t, crypto.IsBoundSecretData(remoteSecret.Data))
+	})
+}
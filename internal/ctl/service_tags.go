@@ -0,0 +1,122 @@
+package ctl
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+// TagCount is a tag with the number of secrets carrying it.
+type TagCount struct {
+	Tag     string
+	Secrets int
+}
+
+// TagSecret adds tags to the secret found by UUID or path. The secret counts
+// as modified, so the change is synced.
+func (s *VaultService) TagSecret(ctx context.Context, ref string, tags []string) (*types.LocalSecret, error) {
+	return s.updateSecretTags(ctx, ref, tags, func(secret *types.LocalSecret, tags []string) []string {
+		return append(secret.Tags, tags...)
+	})
+}
+
+// UntagSecret removes tags from the secret, tags it does not carry are ignored.
+func (s *VaultService) UntagSecret(ctx context.Context, ref string, tags []string) (*types.LocalSecret, error) {
+	return s.updateSecretTags(ctx, ref, tags, func(secret *types.LocalSecret, tags []string) []string {
+		return slices.DeleteFunc(slices.Clone(secret.Tags), func(tag string) bool {
+			return slices.Contains(tags, tag)
+		})
+	})
+}
+
+func (s *VaultService) updateSecretTags(
+	ctx context.Context,
+	ref string,
+	tags []string,
+	update func(secret *types.LocalSecret, tags []string) []string,
+) (*types.LocalSecret, error) {
+	tags, err := types.NormalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := resolveSecret(ctx, storage, ref, true)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := types.NormalizeTags(update(secret, tags))
+	if err != nil {
+		return nil, err
+	}
+	if slices.Equal(updated, secret.Tags) {
+		return secret, nil
+	}
+
+	secret.Tags = updated
+	secret.LastModified = time.Now().UTC().Truncate(time.Microsecond)
+	secret.RefreshHash(s.cryptor)
+
+	if err := storage.UpdateSecret(ctx, secret); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// ListTags returns every tag in use with its count, most used first.
+func (s *VaultService) ListTags(ctx context.Context) ([]TagCount, error) {
+	secrets, err := s.ListLocalSecrets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, secret := range secrets {
+		for _, tag := range secret.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, TagCount{Tag: tag, Secrets: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Secrets != tags[j].Secrets {
+			return tags[i].Secrets > tags[j].Secrets
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+
+	return tags, nil
+}
+
+// filterSecretsByTags keeps the secrets carrying all tags, or any of them
+// when matchAny is set. No tags keeps every secret.
+func filterSecretsByTags(secrets []*types.LocalSecret, tags []string, matchAny bool) []*types.LocalSecret {
+	if len(tags) == 0 {
+		return secrets
+	}
+
+	var filtered []*types.LocalSecret
+	for _, secret := range secrets {
+		matches := slices.ContainsFunc(tags, secret.HasTag)
+		if !matchAny {
+			matches = !slices.ContainsFunc(tags, func(tag string) bool { return !secret.HasTag(tag) })
+		}
+		if matches {
+			filtered = append(filtered, secret)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,60 @@
+package ctl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultService_Tags(t *testing.T) {
+	ctx := context.Background()
+	service := newTestVaultService(t)
+
+	mail := addTestTextSecret(t, service, "mail", "one")
+	bank := addTestTextSecret(t, service, "bank", "two")
+	addTestTextSecret(t, service, "notes", "three")
+
+	tagged, err := service.TagSecret(ctx, mail.UUID, []string{"Work", "mail"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mail", "work"}, tagged.Tags)
+	assert.NotEqual(t, mail.Hash, tagged.Hash)
+	assert.True(t, tagged.LastModified.After(mail.LastModified))
+
+	_, err = service.TagSecret(ctx, bank.UUID, []string{"work", "money"})
+	require.NoError(t, err)
+
+	stored, err := service.GetLocalSecret(ctx, mail.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mail", "work"}, stored.Tags)
+
+	secrets, err := service.ListLocalSecrets(ctx)
+	require.NoError(t, err)
+
+	names := func(tags []string, matchAny bool) []string {
+		var names []string
+		for _, secret := range filterSecretsByTags(secrets, tags, matchAny) {
+			names = append(names, secret.Name)
+		}
+		return names
+	}
+	assert.ElementsMatch(t, []string{"mail", "bank"}, names([]string{"work"}, false))
+	assert.ElementsMatch(t, []string{"mail"}, names([]string{"work", "mail"}, false))
+	assert.ElementsMatch(t, []string{"mail", "bank"}, names([]string{"mail", "money"}, true))
+	assert.Len(t, names(nil, false), 3)
+
+	tags, err := service.ListTags(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []TagCount{{Tag: "work", Secrets: 2}, {Tag: "mail", Secrets: 1}, {Tag: "money", Secrets: 1}}, tags)
+
+	untagged, err := service.UntagSecret(ctx, mail.UUID, []string{"work", "absent"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mail"}, untagged.Tags)
+
+	_, err = service.DeleteLocalSecret(ctx, mail.UUID)
+	require.NoError(t, err)
+	tags, err = service.ListTags(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []TagCount{{Tag: "money", Secrets: 1}, {Tag: "work", Secrets: 1}}, tags)
+}
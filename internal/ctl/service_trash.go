@@ -0,0 +1,139 @@
+package ctl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/storage"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+// trashSecret moves the secret to the trash as a new modification, so that
+// sync carries the move to other devices. Data must be loaded.
+func trashSecret(ctx context.Context, storage storage.Storager, secret *types.LocalSecret) error {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	secret.DeletedAt = now
+	secret.LastModified = now
+
+	return storage.UpdateSecret(ctx, secret)
+}
+
+// listWithTrash returns the secrets in the vault, those in the trash too,
+// without their data.
+func listWithTrash(ctx context.Context, storage storage.Storager) ([]*types.LocalSecret, error) {
+	secrets, err := storage.ListSecrets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	trash, err := storage.ListTrash(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(secrets, trash...), nil
+}
+
+// resolveTrashed finds a secret in the trash by UUID or, failing that, by
+// the path it had. The most recently deleted secrets come first, so a path
+// deleted twice is ambiguous.
+func resolveTrashed(ctx context.Context, storage storage.Storager, ref string, loadData bool) (*types.LocalSecret, error) {
+	secret, err := storage.GetSecret(ctx, ref, loadData)
+	if err == nil && secret.InTrash() {
+		return secret, nil
+	}
+	if err != nil && !errs.IsNotFound(err) {
+		return nil, err
+	}
+
+	notFound := &errs.NotFoundError{Entity: "secret in trash", UUID: ref}
+
+	path, pathErr := types.NormalizePath(ref)
+	if pathErr != nil {
+		return nil, notFound
+	}
+
+	trash, err := storage.ListTrash(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*types.LocalSecret
+	for _, secret := range trash {
+		if secret.Path() == path {
+			matches = append(matches, secret)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, notFound
+	case 1:
+		return storage.GetSecret(ctx, matches[0].UUID, loadData)
+	default:
+		return nil, errs.NewConflictError(fmt.Errorf("%d secrets named %q are in the trash, use the UUID instead", len(matches), path))
+	}
+}
+
+// ListTrash returns the secrets in the trash, most recently deleted first.
+func (s *VaultService) ListTrash(ctx context.Context) ([]*types.LocalSecret, error) {
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.ListTrash(ctx)
+}
+
+// RestoreFromTrash takes the secret found by UUID or path out of the trash
+// with its attachments, as a new modification. It fails when another secret
+// took its path in the meantime.
+func (s *VaultService) RestoreFromTrash(ctx context.Context, ref string) (*types.LocalSecret, error) {
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := resolveTrashed(ctx, storage, ref, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkPathFree(ctx, storage, secret.Path(), secret.UUID); err != nil {
+		return nil, fmt.Errorf("%w, move it away with 'keeperctl mv' first", err)
+	}
+
+	secret.DeletedAt = time.Time{}
+	secret.LastModified = time.Now().UTC().Truncate(time.Microsecond)
+
+	if err := storage.UpdateSecret(ctx, secret); err != nil {
+		return nil, err
+	}
+
+	secret.Data, secret.Fields = nil, nil
+	return secret, nil
+}
+
+// EmptyTrash purges every secret in the trash with its attachments and
+// history, and returns them.
+func (s *VaultService) EmptyTrash(ctx context.Context) ([]*types.LocalSecret, error) {
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	trash, err := storage.ListTrash(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, secret := range trash {
+		if err := storage.PurgeSecret(ctx, secret.UUID); err != nil {
+			return nil, err
+		}
+	}
+
+	return trash, nil
+}
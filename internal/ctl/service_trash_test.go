@@ -0,0 +1,141 @@
+package ctl
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/etoneja/go-keeper/internal/ctl/config"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultService_Trash(t *testing.T) {
+	ctx := context.Background()
+	service := newTestVaultService(t)
+
+	secret := addTestTextSecret(t, service, "work/notes", "content")
+	_, err := service.AttachFile(ctx, secret.UUID, "notes.txt", "", []byte("attached"))
+	require.NoError(t, err)
+
+	_, err = service.DeleteLocalSecret(ctx, "work/notes")
+	require.NoError(t, err)
+
+	secrets, err := service.ListLocalSecrets(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, secrets)
+
+	_, err = service.GetLocalSecret(ctx, secret.UUID)
+	assert.True(t, errs.IsNotFound(err))
+	assert.ErrorContains(t, err, "keeperctl trash restore")
+
+	trash, err := service.ListTrash(ctx)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	assert.Equal(t, secret.UUID, trash[0].UUID)
+	assert.True(t, trash[0].InTrash())
+
+	// NOTE: The path is free again while the secret is in the trash
+	other := addTestTextSecret(t, service, "work/notes", "other content")
+
+	_, err = service.RestoreFromTrash(ctx, "work/notes")
+	assert.True(t, errs.IsConflict(err))
+
+	_, err = service.MoveSecret(ctx, other.UUID, "work/other")
+	require.NoError(t, err)
+
+	restored, err := service.RestoreFromTrash(ctx, "work/notes")
+	require.NoError(t, err)
+	assert.Equal(t, secret.UUID, restored.UUID)
+	assert.False(t, restored.InTrash())
+
+	loaded, err := service.GetAttachment(ctx, secret.UUID, "notes.txt")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("attached"), loaded.Content)
+
+	_, err = service.RestoreFromTrash(ctx, "work/notes")
+	assert.True(t, errs.IsNotFound(err))
+
+	_, err = service.DeleteLocalSecret(ctx, "work/notes")
+	require.NoError(t, err)
+	_, err = service.DeleteLocalSecret(ctx, "work/other")
+	require.NoError(t, err)
+
+	purged, err := service.EmptyTrash(ctx)
+	require.NoError(t, err)
+	assert.Len(t, purged, 2)
+
+	trash, err = service.ListTrash(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, trash)
+
+	_, err = service.SecretHistory(ctx, secret.UUID)
+	assert.True(t, errs.IsNotFound(err))
+}
+
+func TestVaultService_SyncTrash(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "sync.gkb")
+
+	first := newTestVaultService(t)
+	second := newTestVaultService(t)
+	second.cfg.SyncStrategy = config.SyncNewest
+
+	secret := addTestTextSecret(t, first, "notes", "content")
+	_, err := first.ExportBundle(ctx, path)
+	require.NoError(t, err)
+	_, _, err = second.ImportBundle(ctx, path)
+	require.NoError(t, err)
+
+	// NOTE: Moves to and out of the trash are carried over without asking
+	_, err = first.DeleteLocalSecret(ctx, secret.UUID)
+	require.NoError(t, err)
+
+	report, _, err := first.ImportBundle(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, []*SyncedSecret{{UUID: secret.UUID, Action: ActionReplaceRemote}}, report.Secrets)
+
+	report, _, err = second.ImportBundle(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, []*SyncedSecret{{UUID: secret.UUID, Action: ActionReplaceLocal}}, report.Secrets)
+
+	trash, err := second.ListTrash(ctx)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+
+	_, err = second.RestoreFromTrash(ctx, secret.UUID)
+	require.NoError(t, err)
+	_, _, err = second.ImportBundle(ctx, path)
+	require.NoError(t, err)
+
+	report, _, err = first.ImportBundle(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, []*SyncedSecret{{UUID: secret.UUID, Action: ActionReplaceLocal}}, report.Secrets)
+
+	_, err = first.GetLocalSecret(ctx, secret.UUID)
+	require.NoError(t, err)
+
+	// NOTE: A purge is carried over once the secret is in the trash everywhere
+	_, err = first.DeleteLocalSecret(ctx, secret.UUID)
+	require.NoError(t, err)
+	_, _, err = first.ImportBundle(ctx, path)
+	require.NoError(t, err)
+	_, _, err = second.ImportBundle(ctx, path)
+	require.NoError(t, err)
+
+	_, err = first.EmptyTrash(ctx)
+	require.NoError(t, err)
+
+	report, _, err = first.ImportBundle(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, []*SyncedSecret{{UUID: secret.UUID, Action: ActionPurgeRemote}}, report.Secrets)
+
+	report, _, err = second.ImportBundle(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, []*SyncedSecret{{UUID: secret.UUID, Action: ActionPurgeLocal}}, report.Secrets)
+
+	trash, err = second.ListTrash(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, trash)
+}
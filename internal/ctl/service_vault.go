@@ -0,0 +1,66 @@
+package ctl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/fsutil"
+	"github.com/etoneja/go-keeper/internal/ctl/storage"
+)
+
+// VaultStats sums up the vault data. Compression tells whether secret data
+// written from now on is compressed, earlier data is stored as it was written.
+type VaultStats struct {
+	storage.DataStats
+	Compression bool
+}
+
+// Saved is the number of bytes compression saves on secret data.
+func (s *VaultStats) Saved() int64 {
+	return s.PlainSize - s.StoredSize
+}
+
+func (s *VaultService) VaultStats(ctx context.Context) (*VaultStats, error) {
+	storage, err := s.getStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := storage.DataStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VaultStats{DataStats: *stats, Compression: s.cfg.Compression}, nil
+}
+
+func (s *VaultService) ListVaultGenerations() ([]fsutil.Generation, error) {
+	return storage.ListGenerations(s.storageConfig())
+}
+
+func (s *VaultService) RestoreVaultGeneration(ctx context.Context, generation int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.storage != nil {
+		return fmt.Errorf("cannot restore while the vault is open")
+	}
+
+	cryptor := crypto.NewCryptor(s.cfg.Password, s.cfg.Login)
+
+	return storage.RestoreGeneration(ctx, cryptor, s.storageConfig(), generation)
+}
+
+func (s *VaultService) ConvertVault(ctx context.Context, backend string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.storage != nil {
+		return "", fmt.Errorf("cannot convert while the vault is open")
+	}
+
+	cryptor := crypto.NewCryptor(s.cfg.Password, s.cfg.Login)
+
+	return storage.ConvertStorage(ctx, cryptor, s.storageConfig(), backend)
+}
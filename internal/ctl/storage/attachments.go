@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+func (s *SQLiteStorage) CreateAttachment(ctx context.Context, attachment *types.Attachment) error {
+	query := `
+		INSERT INTO secret_attachments (uuid, secret_uuid, name, mime_type, size, last_modified, hash, content)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := s.db.ExecContext(ctx, query,
+		attachment.UUID,
+		attachment.SecretUUID,
+		attachment.Name,
+		attachment.MIMEType,
+		attachment.Size,
+		attachment.LastModified,
+		attachment.Hash,
+		attachment.Content,
+	)
+	if err != nil {
+		return err
+	}
+
+	s.markDirty()
+
+	return nil
+}
+
+func (s *SQLiteStorage) GetAttachment(ctx context.Context, uuid string, loadContent bool) (*types.Attachment, error) {
+	query := `
+		SELECT uuid, secret_uuid, name, mime_type, size, last_modified, hash,
+			case when ? then content else null end as content
+		FROM secret_attachments
+		WHERE uuid = ?
+	`
+
+	attachment := &types.Attachment{}
+	err := s.db.QueryRowContext(ctx, query, loadContent, uuid).Scan(
+		&attachment.UUID,
+		&attachment.SecretUUID,
+		&attachment.Name,
+		&attachment.MIMEType,
+		&attachment.Size,
+		&attachment.LastModified,
+		&attachment.Hash,
+		&attachment.Content,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NewAttachmentNotFoundError(uuid)
+		}
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// DeleteAttachment deletes one attachment. Deleting a secret leaves its
+// attachments, so that sync can replace the secret without downloading
+// them again; the vault service deletes them with the secret.
+func (s *SQLiteStorage) DeleteAttachment(ctx context.Context, uuid string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM secret_attachments WHERE uuid = ?`, uuid)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errs.NewAttachmentNotFoundError(uuid)
+	}
+
+	s.markDirty()
+
+	return nil
+}
+
+func (s *SQLiteStorage) ListAttachments(ctx context.Context, secretID string) ([]*types.Attachment, error) {
+	query := `
+		SELECT uuid, secret_uuid, name, mime_type, size, last_modified, hash
+		FROM secret_attachments
+		WHERE ? = '' OR secret_uuid = ?
+		ORDER BY secret_uuid, name, uuid
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, secretID, secretID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	var attachments []*types.Attachment
+	for rows.Next() {
+		attachment := &types.Attachment{}
+		err := rows.Scan(
+			&attachment.UUID,
+			&attachment.SecretUUID,
+			&attachment.Name,
+			&attachment.MIMEType,
+			&attachment.Size,
+			&attachment.LastModified,
+			&attachment.Hash,
+		)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, rows.Err()
+}
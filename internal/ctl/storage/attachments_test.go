@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteStorage_Attachments(t *testing.T) {
+	ctx := context.Background()
+	cryptor := crypto.NewCryptor("password", "login")
+
+	for _, backend := range []string{BackendBlob, BackendPaged, BackendFiles} {
+		t.Run(backend, func(t *testing.T) {
+			cfg := Config{Path: filepath.Join(t.TempDir(), "vault"), Backend: backend}
+			require.NoError(t, initializeSQLiteStorage(ctx, cryptor, cfg))
+
+			storage, err := openSQLiteStorage(ctx, cryptor, cfg)
+			require.NoError(t, err)
+
+			_, err = storage.CreateSecret(ctx, newTestSecret(t, "secret-1", 16))
+			require.NoError(t, err)
+
+			now := time.Now().UTC().Truncate(time.Microsecond)
+			attachments := []*types.Attachment{
+				{UUID: "att-1", SecretUUID: "secret-1", Name: "b.pdf", MIMEType: "application/pdf", Size: 3, LastModified: now, Hash: "h1", Content: []byte("pdf")},
+				{UUID: "att-2", SecretUUID: "secret-1", Name: "a.txt", MIMEType: "text/plain", Size: 4, LastModified: now, Hash: "h2", Content: []byte("text")},
+				{UUID: "att-3", SecretUUID: "secret-2", Name: "c.txt", MIMEType: "text/plain", Size: 1, LastModified: now, Hash: "h3", Content: []byte("c")},
+			}
+			for _, attachment := range attachments {
+				require.NoError(t, storage.CreateAttachment(ctx, attachment))
+			}
+			require.NoError(t, storage.Close())
+
+			storage, err = openSQLiteStorage(ctx, cryptor, cfg)
+			require.NoError(t, err)
+			defer storage.Close()
+
+			listed, err := storage.ListAttachments(ctx, "secret-1")
+			require.NoError(t, err)
+			require.Len(t, listed, 2)
+			assert.Equal(t, "a.txt", listed[0].Name)
+			assert.Nil(t, listed[0].Content, "listing must not load content")
+
+			all, err := storage.ListAttachments(ctx, "")
+			require.NoError(t, err)
+			assert.Len(t, all, 3)
+
+			loaded, err := storage.GetAttachment(ctx, "att-1", true)
+			require.NoError(t, err)
+			assert.Equal(t, []byte("pdf"), loaded.Content)
+			assert.True(t, now.Equal(loaded.LastModified))
+
+			loaded, err = storage.GetAttachment(ctx, "att-1", false)
+			require.NoError(t, err)
+			assert.Nil(t, loaded.Content)
+
+			require.NoError(t, storage.DeleteSecret(ctx, "secret-1"))
+			listed, err = storage.ListAttachments(ctx, "secret-1")
+			require.NoError(t, err)
+			assert.Len(t, listed, 2, "attachments outlive their secret")
+
+			require.NoError(t, storage.DeleteAttachment(ctx, "att-1"))
+			_, err = storage.GetAttachment(ctx, "att-1", false)
+			assert.True(t, errs.IsNotFound(err))
+			assert.True(t, errs.IsNotFound(storage.DeleteAttachment(ctx, "att-1")))
+		})
+	}
+}
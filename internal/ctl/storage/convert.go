@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/fsutil"
+)
+
+// ConvertStorage rewrites the vault with another backend in place. The
+// vault as it was is kept next to it, its path is returned.
+func ConvertStorage(ctx context.Context, cryptor crypto.Cryptor, cfg Config, backend string) (string, error) {
+	if _, err := newVaultFile(cryptor, Config{Backend: backend}); err != nil {
+		return "", err
+	}
+
+	lock, err := lockVault(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	backupPath, err := convertStorage(ctx, cryptor, cfg, backend)
+	return backupPath, unlockVault(lock, err)
+}
+
+func convertStorage(ctx context.Context, cryptor crypto.Cryptor, cfg Config, backend string) (string, error) {
+	current, err := detectBackend(cfg.Path)
+	if err != nil {
+		return "", err
+	}
+	if current == backend {
+		return "", fmt.Errorf("vault already uses the %s backend", backend)
+	}
+
+	backupPath := fmt.Sprintf("%s.pre-convert-%s", cfg.Path, current)
+	if _, err := os.Stat(backupPath); err == nil {
+		return "", fmt.Errorf("%s already exists, move it away first", backupPath)
+	}
+
+	source, err := openVaultFile(cryptor, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	db, err := source.load(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Printf("Error closing database: %v", err)
+		}
+	}()
+
+	tmpPath := cfg.Path + ".converting"
+	if err := os.RemoveAll(tmpPath); err != nil {
+		return "", fmt.Errorf("failed to remove stale conversion: %w", err)
+	}
+
+	target, err := newVaultFile(cryptor, Config{Path: tmpPath, Backend: backend})
+	if err != nil {
+		return "", err
+	}
+
+	if err := target.save(ctx, db); err != nil {
+		_ = os.RemoveAll(tmpPath)
+		return "", err
+	}
+
+	if err := os.Rename(cfg.Path, backupPath); err != nil {
+		_ = os.RemoveAll(tmpPath)
+		return "", fmt.Errorf("failed to move the old vault aside: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, cfg.Path); err != nil {
+		if rollbackErr := os.Rename(backupPath, cfg.Path); rollbackErr != nil {
+			return "", fmt.Errorf("failed to move the converted vault in place, the old one is at %s: %w", backupPath, err)
+		}
+		return "", fmt.Errorf("failed to move the converted vault in place: %w", err)
+	}
+
+	return backupPath, fsutil.SyncDir(filepath.Dir(cfg.Path))
+}
@@ -0,0 +1,818 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/fsutil"
+	"github.com/zeebo/blake3"
+)
+
+// Directory vault layout:
+//
+//	index.gkv             magic "GKDV" | version | salt | sealed index
+//	secrets/<uuid>.gks    epoch | sealed rows owned by one secret, bound to its UUID and epoch
+//
+// The index holds the schema, the rows of global tables such as the sync
+// state and the secrets of the vault. Rows of the secrets table and of tables
+// with a secret_uuid column live in the file of the secret they belong to, so
+// changing a secret rewrites only its file. Every file is replaced atomically,
+// the storage key is derived once from the salt in the index.
+//
+// Each secret file carries an epoch, counting its writes. The index records
+// the epoch of every file as of its last write: files missing from the index
+// or absent from the directory are rejected, and so are files older than the
+// index. Adding or removing secrets writes the index first, marking those
+// secrets pending so that either state of their files loads after a crash,
+// and once more when the files are in place.
+const (
+	dirMagic         = "GKDV"
+	dirFormatVersion = 2
+	dirIndexName     = "index.gkv"
+	dirSecretsName   = "secrets"
+	dirSecretExt     = ".gks"
+	dirEpochSize     = 8
+
+	dirIndexADLabel  = "go-keeper/dir-index"
+	dirSecretADLabel = "go-keeper/dir-secret"
+
+	// secretsOwnerColumn is the column of the secrets table naming the owner.
+	secretsOwnerColumn = "uuid"
+	// ownedRowsColumn marks the rows of other tables as owned by a secret.
+	ownedRowsColumn = "secret_uuid"
+)
+
+var errDirCorrupted = errs.NewCorruptError(errors.New("directory vault is corrupted"))
+
+func init() {
+	gob.Register(time.Time{})
+}
+
+type dirVaultFile struct {
+	cryptor crypto.Cryptor
+	path    string
+
+	sealer       crypto.Sealer
+	salt         []byte
+	tablesDigest [32]byte
+	// pending is set when the index still marks secrets of an interrupted save
+	pending       bool
+	secretDigests map[string][32]byte
+	epochs        map[string]uint64
+	stamps        map[string]fileStamp
+}
+
+// dirTable holds rows of one table, values as scanned by database/sql.
+type dirTable struct {
+	Name    string
+	Columns []string
+	Rows    [][]any
+}
+
+type dirIndex struct {
+	SchemaVersion int
+	Schema        []string
+	Tables        []dirTable
+	// Secrets lists the secret files by UUID, in order
+	Secrets []dirIndexEntry
+}
+
+// dirIndexEntry names a secret file and the oldest epoch it may have.
+// Pending secrets are being added or removed, their file may be absent.
+type dirIndexEntry struct {
+	ID      string
+	Epoch   uint64
+	Pending bool
+}
+
+type dirSecret struct {
+	Tables []dirTable
+}
+
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// dirContents is a vault split into the index and per-secret row sets.
+type dirContents struct {
+	index   dirIndex
+	secrets map[string]*dirSecret
+}
+
+func (f *dirVaultFile) indexPath() string {
+	return filepath.Join(f.path, dirIndexName)
+}
+
+func (f *dirVaultFile) secretPath(secretID string) string {
+	return filepath.Join(f.path, dirSecretsName, secretID+dirSecretExt)
+}
+
+func (f *dirVaultFile) load(ctx context.Context) (*sql.DB, error) {
+	stamps, err := f.stampFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(f.indexPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault index: %w", err)
+	}
+
+	header := len(dirMagic) + 1 + crypto.StorageSaltSize
+	if len(data) < header || string(data[:len(dirMagic)]) != dirMagic {
+		return nil, fmt.Errorf("%w: bad index header", errDirCorrupted)
+	}
+	if data[len(dirMagic)] != dirFormatVersion {
+		return nil, fmt.Errorf("unsupported directory vault version %d", data[len(dirMagic)])
+	}
+
+	salt := bytes.Clone(data[len(dirMagic)+1 : header])
+	sealer, err := f.cryptor.StorageSealer(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	indexData, err := sealer.Open(data[header:], dirIndexAD(data[:header]))
+	if err != nil {
+		return nil, vaultDecryptError(err)
+	}
+
+	contents := &dirContents{secrets: make(map[string]*dirSecret)}
+	if err := gob.NewDecoder(bytes.NewReader(indexData)).Decode(&contents.index); err != nil {
+		return nil, fmt.Errorf("%w: index: %w", errDirCorrupted, err)
+	}
+
+	entries := make(map[string]dirIndexEntry, len(contents.index.Secrets))
+	pending := false
+	for _, entry := range contents.index.Secrets {
+		entries[entry.ID] = entry
+		pending = pending || entry.Pending
+	}
+
+	secretDigests := make(map[string][32]byte)
+	epochs := make(map[string]uint64)
+	for name := range stamps {
+		secretID, ok := secretIDFromName(name)
+		if !ok {
+			continue
+		}
+
+		entry, exists := entries[secretID]
+		if !exists {
+			return nil, fmt.Errorf("%w: unexpected secret file %s", errDirCorrupted, secretID)
+		}
+
+		sealed, err := os.ReadFile(f.secretPath(secretID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secret file: %w", err)
+		}
+		if len(sealed) < dirEpochSize {
+			return nil, fmt.Errorf("%w: secret %s is truncated", errDirCorrupted, secretID)
+		}
+
+		epoch := binary.BigEndian.Uint64(sealed[:dirEpochSize])
+		plain, err := sealer.Open(sealed[dirEpochSize:], dirSecretAD(secretID, epoch))
+		if err != nil {
+			return nil, fmt.Errorf("%w: secret %s: %w", errDirCorrupted, secretID, err)
+		}
+		if epoch < entry.Epoch {
+			return nil, fmt.Errorf("%w: secret %s is older than the index", errDirCorrupted, secretID)
+		}
+
+		secret := &dirSecret{}
+		if err := gob.NewDecoder(bytes.NewReader(plain)).Decode(secret); err != nil {
+			return nil, fmt.Errorf("%w: secret %s: %w", errDirCorrupted, secretID, err)
+		}
+
+		contents.secrets[secretID] = secret
+		secretDigests[secretID] = blake3.Sum256(plain)
+		epochs[secretID] = epoch
+	}
+
+	for _, entry := range contents.index.Secrets {
+		if _, exists := epochs[entry.ID]; !exists && !entry.Pending {
+			return nil, fmt.Errorf("%w: secret %s is missing", errDirCorrupted, entry.ID)
+		}
+	}
+
+	db, err := contents.restore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tablesDigest, err := contents.index.tablesDigest()
+	if err != nil {
+		return nil, err
+	}
+
+	f.sealer = sealer
+	f.salt = salt
+	f.tablesDigest = tablesDigest
+	f.pending = pending
+	f.secretDigests = secretDigests
+	f.epochs = epochs
+	f.stamps = stamps
+
+	return db, nil
+}
+
+func (f *dirVaultFile) save(ctx context.Context, db *sql.DB) error {
+	if f.stamps != nil {
+		stamps, err := f.stampFiles()
+		if err != nil {
+			return err
+		}
+		if !sameStamps(stamps, f.stamps) {
+			return fmt.Errorf("%w: %s", ErrVaultChanged, f.path)
+		}
+	} else if _, err := os.Stat(f.path); err == nil {
+		return fmt.Errorf("%w: %s was created", ErrVaultChanged, f.path)
+	}
+
+	contents, err := splitVault(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	if f.sealer == nil {
+		salt, err := crypto.NewStorageSalt()
+		if err != nil {
+			return err
+		}
+		sealer, err := f.cryptor.StorageSealer(salt)
+		if err != nil {
+			return err
+		}
+		f.sealer = sealer
+		f.salt = salt
+		f.secretDigests = make(map[string][32]byte)
+		f.epochs = make(map[string]uint64)
+	}
+
+	if err := os.MkdirAll(filepath.Join(f.path, dirSecretsName), 0700); err != nil {
+		return fmt.Errorf("failed to create vault directory: %w", err)
+	}
+
+	plains := make(map[string][]byte, len(contents.secrets))
+	secretDigests := make(map[string][32]byte, len(contents.secrets))
+	membershipChanged := false
+	for secretID, secret := range contents.secrets {
+		plain, err := encodeGob(secret)
+		if err != nil {
+			return err
+		}
+		plains[secretID] = plain
+		secretDigests[secretID] = blake3.Sum256(plain)
+
+		_, exists := f.epochs[secretID]
+		membershipChanged = membershipChanged || !exists
+	}
+	for secretID := range f.epochs {
+		_, exists := contents.secrets[secretID]
+		membershipChanged = membershipChanged || !exists
+	}
+
+	tablesDigest, err := contents.index.tablesDigest()
+	if err != nil {
+		return err
+	}
+
+	// NOTE: Edits of secrets leave the index as it is, so that they do not
+	// conflict in the index when the directory is synced by other means
+	writeIndex := f.stamps == nil || f.pending || membershipChanged || tablesDigest != f.tablesDigest
+	if writeIndex {
+		contents.index.Secrets = f.indexEntries(contents.secrets)
+		if err := f.writeIndex(&contents.index); err != nil {
+			return err
+		}
+	}
+
+	epochs := make(map[string]uint64, len(contents.secrets))
+	for secretID, plain := range plains {
+		epoch, exists := f.epochs[secretID]
+		if exists && f.secretDigests[secretID] == secretDigests[secretID] {
+			epochs[secretID] = epoch
+			continue
+		}
+
+		epoch++
+		sealed, err := f.sealer.Seal(plain, dirSecretAD(secretID, epoch))
+		if err != nil {
+			return fmt.Errorf("failed to encrypt secret %s: %w", secretID, err)
+		}
+		file := binary.BigEndian.AppendUint64(make([]byte, 0, dirEpochSize+len(sealed)), epoch)
+		if err := fsutil.WriteFileAtomic(f.secretPath(secretID), append(file, sealed...), 0600); err != nil {
+			return err
+		}
+		epochs[secretID] = epoch
+	}
+
+	for secretID := range f.epochs {
+		if _, exists := contents.secrets[secretID]; exists {
+			continue
+		}
+		err := os.Remove(f.secretPath(secretID))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove secret file: %w", err)
+		}
+	}
+	f.secretDigests = secretDigests
+	f.epochs = epochs
+
+	if writeIndex && (membershipChanged || f.pending) {
+		contents.index.Secrets = f.indexEntries(contents.secrets)
+		if err := f.writeIndex(&contents.index); err != nil {
+			return err
+		}
+	}
+	f.tablesDigest = tablesDigest
+	f.pending = false
+
+	if err := fsutil.SyncDir(filepath.Join(f.path, dirSecretsName)); err != nil {
+		return err
+	}
+
+	stamps, err := f.stampFiles()
+	if err != nil {
+		return err
+	}
+	f.stamps = stamps
+
+	return nil
+}
+
+// indexEntries lists the secret files for the index. Files written so far
+// keep their epoch, secrets is the new set: secrets leaving or joining it are
+// pending, as their files are still to be removed or written.
+func (f *dirVaultFile) indexEntries(secrets map[string]*dirSecret) []dirIndexEntry {
+	var entries []dirIndexEntry
+	for secretID, epoch := range f.epochs {
+		_, kept := secrets[secretID]
+		entries = append(entries, dirIndexEntry{ID: secretID, Epoch: epoch, Pending: !kept})
+	}
+	for secretID := range secrets {
+		if _, exists := f.epochs[secretID]; !exists {
+			entries = append(entries, dirIndexEntry{ID: secretID, Epoch: 1, Pending: true})
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b dirIndexEntry) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return entries
+}
+
+func (f *dirVaultFile) writeIndex(index *dirIndex) error {
+	indexData, err := encodeGob(index)
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, 0, len(dirMagic)+1+len(f.salt))
+	header = append(header, dirMagic...)
+	header = append(header, dirFormatVersion)
+	header = append(header, f.salt...)
+
+	sealed, err := f.sealer.Seal(indexData, dirIndexAD(header))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt vault index: %w", err)
+	}
+
+	return fsutil.WriteFileAtomic(f.indexPath(), append(header, sealed...), 0600)
+}
+
+// tablesDigest identifies the schema and global rows, leaving out the secret files.
+func (i *dirIndex) tablesDigest() ([32]byte, error) {
+	data, err := encodeGob(&dirIndex{SchemaVersion: i.SchemaVersion, Schema: i.Schema, Tables: i.Tables})
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return blake3.Sum256(data), nil
+}
+
+func (f *dirVaultFile) snapshot(dst string) error {
+	stamps, err := f.stampFiles()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Join(dst, dirSecretsName), 0700); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	for name := range stamps {
+		if err := copyVaultFile(filepath.Join(f.path, name), filepath.Join(dst, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stampFiles returns size and mtime of the index and every secret file,
+// keyed by their path relative to the vault directory.
+func (f *dirVaultFile) stampFiles() (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp)
+
+	info, err := os.Stat(f.indexPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault index: %w", err)
+	}
+	stamps[dirIndexName] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+
+	entries, err := os.ReadDir(filepath.Join(f.path, dirSecretsName))
+	if errors.Is(err, os.ErrNotExist) {
+		return stamps, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vault directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), dirSecretExt) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		name := filepath.Join(dirSecretsName, entry.Name())
+		stamps[name] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+	}
+
+	return stamps, nil
+}
+
+func sameStamps(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, stamp := range a {
+		other, exists := b[name]
+		if !exists || other.size != stamp.size || !other.modTime.Equal(stamp.modTime) {
+			return false
+		}
+	}
+	return true
+}
+
+func secretIDFromName(name string) (string, bool) {
+	dir, file := filepath.Split(name)
+	if filepath.Clean(dir) != dirSecretsName || !strings.HasSuffix(file, dirSecretExt) {
+		return "", false
+	}
+	return strings.TrimSuffix(file, dirSecretExt), true
+}
+
+func dirIndexAD(header []byte) []byte {
+	return append([]byte(dirIndexADLabel), header...)
+}
+
+func dirSecretAD(secretID string, epoch uint64) []byte {
+	ad := append([]byte(dirSecretADLabel), secretID...)
+	return binary.BigEndian.AppendUint64(ad, epoch)
+}
+
+func encodeGob(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		return nil, fmt.Errorf("failed to encode vault rows: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ownerColumn returns the column naming the secret that owns the rows of
+// table, or "" for global tables.
+func ownerColumn(table string, columns []string) string {
+	if table == "secrets" {
+		return secretsOwnerColumn
+	}
+	for _, column := range columns {
+		if column == ownedRowsColumn {
+			return ownedRowsColumn
+		}
+	}
+	return ""
+}
+
+// schemaEntry is one object of sqlite_master.
+type schemaEntry struct {
+	kind string
+	name string
+	sql  string
+}
+
+func readSchema(ctx context.Context, db *sql.DB) ([]schemaEntry, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT type, name, sql FROM sqlite_master
+		WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite_%'
+		ORDER BY rowid
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	var entries []schemaEntry
+	for rows.Next() {
+		var entry schemaEntry
+		if err := rows.Scan(&entry.kind, &entry.name, &entry.sql); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// storedTables returns the tables whose rows are persisted. Virtual tables
+// hold derived indexes, they and their shadow tables are recreated from the
+// schema and refilled by their owners.
+func storedTables(entries []schemaEntry) (tables []string, schema []string) {
+	var virtual []string
+	for _, entry := range entries {
+		if entry.kind == "table" && isVirtualTable(entry.sql) {
+			virtual = append(virtual, entry.name)
+		}
+	}
+
+	isShadow := func(name string) bool {
+		for _, table := range virtual {
+			if strings.HasPrefix(name, table+"_") {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, entry := range entries {
+		if isShadow(entry.name) {
+			continue
+		}
+		schema = append(schema, entry.sql)
+		if entry.kind == "table" && !isVirtualTable(entry.sql) {
+			tables = append(tables, entry.name)
+		}
+	}
+
+	return tables, schema
+}
+
+func isVirtualTable(statement string) bool {
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(statement)), "CREATE VIRTUAL TABLE")
+}
+
+// splitVault reads db into the index and per-secret row sets. Rows are
+// ordered by all their columns so unchanged data encodes identically.
+func splitVault(ctx context.Context, db *sql.DB) (*dirContents, error) {
+	entries, err := readSchema(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var version int
+	if err := db.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&version); err != nil {
+		return nil, err
+	}
+
+	tables, schema := storedTables(entries)
+	contents := &dirContents{
+		index:   dirIndex{SchemaVersion: version, Schema: schema},
+		secrets: make(map[string]*dirSecret),
+	}
+
+	for _, table := range tables {
+		columns, rows, err := readTable(ctx, db, table)
+		if err != nil {
+			return nil, err
+		}
+
+		owner := ownerColumn(table, columns)
+		if owner == "" {
+			contents.index.Tables = append(contents.index.Tables, dirTable{Name: table, Columns: columns, Rows: rows})
+			continue
+		}
+
+		ownerIndex := slices.Index(columns, owner)
+
+		grouped := make(map[string][][]any)
+		for _, row := range rows {
+			secretID, ok := row[ownerIndex].(string)
+			if !ok || secretID == "" || strings.ContainsAny(secretID, `/\`) || strings.HasPrefix(secretID, ".") {
+				return nil, fmt.Errorf("table %s has a row with an invalid %s", table, owner)
+			}
+			grouped[secretID] = append(grouped[secretID], row)
+		}
+
+		for secretID, ownedRows := range grouped {
+			secret, exists := contents.secrets[secretID]
+			if !exists {
+				secret = &dirSecret{}
+				contents.secrets[secretID] = secret
+			}
+			secret.Tables = append(secret.Tables, dirTable{Name: table, Columns: columns, Rows: ownedRows})
+		}
+	}
+
+	return contents, nil
+}
+
+func readTable(ctx context.Context, db *sql.DB, table string) ([]string, [][]any, error) {
+	columnCount, err := countColumns(ctx, db, table)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	order := make([]string, columnCount)
+	for i := range order {
+		order[i] = strconv.Itoa(i + 1)
+	}
+
+	query := fmt.Sprintf(`SELECT * FROM %s ORDER BY %s`, quoteIdentifier(table), strings.Join(order, ", "))
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var result [][]any
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, nil, err
+		}
+		result = append(result, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return columns, result, nil
+}
+
+func countColumns(ctx context.Context, db *sql.DB, table string) (int, error) {
+	var count int
+	err := db.QueryRowContext(ctx, `SELECT count(*) FROM pragma_table_info(?)`, table).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// restore builds a new in-memory database from the stored schema and rows.
+func (c *dirContents) restore(ctx context.Context) (*sql.DB, error) {
+	db, err := openInMemoryDB()
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.insertAll(ctx, db)
+	if err != nil {
+		if closeErr := db.Close(); closeErr != nil {
+			log.Printf("Error closing database during error handling: %v", closeErr)
+		}
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func (c *dirContents) insertAll(ctx context.Context, db *sql.DB) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, statement := range c.index.Schema {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			// NOTE: A build without the module of a virtual table can still load
+			// the vault, the derived index is recreated by a build with it
+			if isVirtualTable(statement) {
+				continue
+			}
+			return fmt.Errorf("%w: schema: %w", errDirCorrupted, err)
+		}
+	}
+
+	// NOTE: PRAGMA does not accept bound parameters
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version = %d`, c.index.SchemaVersion)); err != nil {
+		return err
+	}
+
+	for _, table := range c.index.Tables {
+		if err := insertRows(ctx, tx, table); err != nil {
+			return err
+		}
+	}
+
+	for secretID, secret := range c.secrets {
+		for _, table := range secret.Tables {
+			if err := checkOwner(secretID, table); err != nil {
+				return err
+			}
+			if err := insertRows(ctx, tx, table); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// checkOwner rejects rows stored in the file of another secret.
+func checkOwner(secretID string, table dirTable) error {
+	owner := ownerColumn(table.Name, table.Columns)
+	if owner == "" {
+		return fmt.Errorf("%w: global table %s in secret %s", errDirCorrupted, table.Name, secretID)
+	}
+
+	for i, column := range table.Columns {
+		if column != owner {
+			continue
+		}
+		for _, row := range table.Rows {
+			if row[i] != secretID {
+				return fmt.Errorf("%w: secret %s holds rows of another secret", errDirCorrupted, secretID)
+			}
+		}
+	}
+
+	return nil
+}
+
+func insertRows(ctx context.Context, tx *sql.Tx, table dirTable) error {
+	if len(table.Rows) == 0 {
+		return nil
+	}
+
+	columns := make([]string, len(table.Columns))
+	placeholders := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		columns[i] = quoteIdentifier(column)
+		placeholders[i] = "?"
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s)`,
+		quoteIdentifier(table.Name), strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("%w: table %s: %w", errDirCorrupted, table.Name, err)
+	}
+	defer func() {
+		if err := stmt.Close(); err != nil {
+			log.Printf("Error closing statement: %v", err)
+		}
+	}()
+
+	for _, row := range table.Rows {
+		if len(row) != len(table.Columns) {
+			return fmt.Errorf("%w: table %s has a malformed row", errDirCorrupted, table.Name)
+		}
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return fmt.Errorf("%w: table %s: %w", errDirCorrupted, table.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
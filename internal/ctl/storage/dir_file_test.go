@@ -0,0 +1,242 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirVaultFile(t *testing.T) {
+	ctx := context.Background()
+	cryptor := crypto.NewCryptor("password", "login")
+
+	t.Run("changes touch only the secret files involved", func(t *testing.T) {
+		cfg := Config{Path: filepath.Join(t.TempDir(), "vault"), Backend: BackendFiles}
+		createTestVault(t, cryptor, cfg, 3, 1024)
+
+		secretFile := func(id string) string {
+			return filepath.Join(cfg.Path, dirSecretsName, id+dirSecretExt)
+		}
+		read := func(path string) string {
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			return string(data)
+		}
+
+		index := read(filepath.Join(cfg.Path, dirIndexName))
+		untouched := read(secretFile("secret-0"))
+
+		storage, err := openSQLiteStorage(ctx, cryptor, cfg)
+		require.NoError(t, err)
+		_, ok := storage.file.(*dirVaultFile)
+		require.True(t, ok, "backend must be detected from the directory")
+
+		changed := newTestSecret(t, "secret-1", 2048)
+		require.NoError(t, storage.UpdateSecret(ctx, changed))
+		require.NoError(t, storage.Close())
+
+		assert.Equal(t, index, read(filepath.Join(cfg.Path, dirIndexName)), "edits leave the index as it is")
+		assert.Equal(t, untouched, read(secretFile("secret-0")))
+
+		storage, err = openSQLiteStorage(ctx, cryptor, cfg)
+		require.NoError(t, err)
+		require.NoError(t, storage.DeleteSecret(ctx, "secret-2"))
+		require.NoError(t, storage.Close())
+
+		assert.NotEqual(t, index, read(filepath.Join(cfg.Path, dirIndexName)), "the index lists the secrets")
+		assert.Equal(t, untouched, read(secretFile("secret-0")))
+		assert.NoFileExists(t, secretFile("secret-2"))
+		index = read(filepath.Join(cfg.Path, dirIndexName))
+
+		storage, err = openSQLiteStorage(ctx, cryptor, cfg)
+		require.NoError(t, err)
+
+		loaded, err := storage.GetSecret(ctx, "secret-1", true)
+		require.NoError(t, err)
+		assert.Equal(t, changed.Data, loaded.Data)
+		assert.True(t, changed.LastModified.Equal(loaded.LastModified))
+
+		manifest := types.NewSyncManifest()
+		manifest.Revision = 7
+		require.NoError(t, storage.SaveSyncManifest(ctx, "server", manifest))
+		require.NoError(t, storage.Close())
+
+		assert.NotEqual(t, index, read(filepath.Join(cfg.Path, dirIndexName)), "sync state lives in the index")
+
+		storage, err = openSQLiteStorage(ctx, cryptor, cfg)
+		require.NoError(t, err)
+		defer storage.Close()
+
+		stored, err := storage.GetSyncManifest(ctx, "server")
+		require.NoError(t, err)
+		assert.Equal(t, uint64(7), stored.Revision)
+
+		secrets, err := storage.ListSecrets(ctx)
+		require.NoError(t, err)
+		assert.Len(t, secrets, 2)
+	})
+
+	t.Run("secret file moved to another name is rejected", func(t *testing.T) {
+		cfg := Config{Path: filepath.Join(t.TempDir(), "vault"), Backend: BackendFiles}
+		createTestVault(t, cryptor, cfg, 2, 1024)
+
+		secretsDir := filepath.Join(cfg.Path, dirSecretsName)
+		require.NoError(t, os.Remove(filepath.Join(secretsDir, "secret-1"+dirSecretExt)))
+		require.NoError(t, os.Rename(
+			filepath.Join(secretsDir, "secret-0"+dirSecretExt),
+			filepath.Join(secretsDir, "secret-1"+dirSecretExt),
+		))
+
+		_, err := openSQLiteStorage(ctx, cryptor, cfg)
+		assert.ErrorIs(t, err, errDirCorrupted)
+	})
+
+	t.Run("missing secret file is rejected", func(t *testing.T) {
+		cfg := Config{Path: filepath.Join(t.TempDir(), "vault"), Backend: BackendFiles}
+		createTestVault(t, cryptor, cfg, 2, 1024)
+
+		require.NoError(t, os.Remove(filepath.Join(cfg.Path, dirSecretsName, "secret-1"+dirSecretExt)))
+
+		_, err := openSQLiteStorage(ctx, cryptor, cfg)
+		assert.ErrorIs(t, err, errDirCorrupted)
+		assert.ErrorContains(t, err, "secret-1 is missing")
+	})
+
+	t.Run("extra secret file is rejected", func(t *testing.T) {
+		cfg := Config{Path: filepath.Join(t.TempDir(), "vault"), Backend: BackendFiles}
+		createTestVault(t, cryptor, cfg, 2, 1024)
+
+		// NOTE: Files of other vaults do not open with this key, an extra
+		// file comes from an older state of the same vault
+		secretFile := filepath.Join(cfg.Path, dirSecretsName, "secret-0"+dirSecretExt)
+		deleted, err := os.ReadFile(secretFile)
+		require.NoError(t, err)
+
+		storage, err := openSQLiteStorage(ctx, cryptor, cfg)
+		require.NoError(t, err)
+		require.NoError(t, storage.DeleteSecret(ctx, "secret-0"))
+		require.NoError(t, storage.Close())
+
+		require.NoError(t, os.WriteFile(secretFile, deleted, 0600))
+
+		_, err = openSQLiteStorage(ctx, cryptor, cfg)
+		assert.ErrorIs(t, err, errDirCorrupted)
+		assert.ErrorContains(t, err, "unexpected secret file secret-0")
+	})
+
+	t.Run("stale secret file is rejected", func(t *testing.T) {
+		cfg := Config{Path: filepath.Join(t.TempDir(), "vault"), Backend: BackendFiles}
+		createTestVault(t, cryptor, cfg, 2, 1024)
+
+		secretFile := filepath.Join(cfg.Path, dirSecretsName, "secret-0"+dirSecretExt)
+		old, err := os.ReadFile(secretFile)
+		require.NoError(t, err)
+
+		storage, err := openSQLiteStorage(ctx, cryptor, cfg)
+		require.NoError(t, err)
+		require.NoError(t, storage.UpdateSecret(ctx, newTestSecret(t, "secret-0", 2048)))
+		require.NoError(t, storage.Close())
+
+		// NOTE: The index records the epoch of the file when it is next written
+		storage, err = openSQLiteStorage(ctx, cryptor, cfg)
+		require.NoError(t, err)
+		require.NoError(t, storage.SaveSyncManifest(ctx, "server", types.NewSyncManifest()))
+		require.NoError(t, storage.Close())
+
+		require.NoError(t, os.WriteFile(secretFile, old, 0600))
+
+		_, err = openSQLiteStorage(ctx, cryptor, cfg)
+		assert.ErrorIs(t, err, errDirCorrupted)
+		assert.ErrorContains(t, err, "secret-0 is older than the index")
+	})
+
+	t.Run("interrupted save still loads", func(t *testing.T) {
+		cfg := Config{Path: filepath.Join(t.TempDir(), "vault"), Backend: BackendFiles}
+		createTestVault(t, cryptor, cfg, 2, 1024)
+
+		storage, err := openSQLiteStorage(ctx, cryptor, cfg)
+		require.NoError(t, err)
+		_, err = storage.CreateSecret(ctx, newTestSecret(t, "secret-2", 1024))
+		require.NoError(t, err)
+		require.NoError(t, storage.DeleteSecret(ctx, "secret-0"))
+
+		// NOTE: A directory in place of the new file fails the save after
+		// the index is written, as a crash would
+		blocked := filepath.Join(cfg.Path, dirSecretsName, "secret-2"+dirSecretExt)
+		require.NoError(t, os.Mkdir(blocked, 0700))
+		require.Error(t, storage.Close())
+		require.NoError(t, os.Remove(blocked))
+
+		storage, err = openSQLiteStorage(ctx, cryptor, cfg)
+		require.NoError(t, err)
+		secrets, err := storage.ListSecrets(ctx)
+		require.NoError(t, err)
+		assert.Len(t, secrets, 2, "the save stopped before any secret file changed")
+
+		_, err = storage.CreateSecret(ctx, newTestSecret(t, "secret-3", 1024))
+		require.NoError(t, err)
+		require.NoError(t, storage.Close())
+
+		storage, err = openSQLiteStorage(ctx, cryptor, cfg)
+		require.NoError(t, err)
+		defer storage.Close()
+		secrets, err = storage.ListSecrets(ctx)
+		require.NoError(t, err)
+		assert.Len(t, secrets, 3)
+	})
+
+	t.Run("changes on disk are not overwritten", func(t *testing.T) {
+		cfg := Config{Path: filepath.Join(t.TempDir(), "vault"), Backend: BackendFiles}
+		createTestVault(t, cryptor, cfg, 2, 1024)
+
+		storage, err := openSQLiteStorage(ctx, cryptor, cfg)
+		require.NoError(t, err)
+
+		require.NoError(t, os.Remove(filepath.Join(cfg.Path, dirSecretsName, "secret-0"+dirSecretExt)))
+
+		require.NoError(t, storage.DeleteSecret(ctx, "secret-1"))
+		assert.ErrorIs(t, storage.Close(), ErrVaultChanged)
+	})
+}
+
+func TestConvertStorage(t *testing.T) {
+	ctx := context.Background()
+	cryptor := crypto.NewCryptor("password", "login")
+
+	cfg := Config{Path: filepath.Join(t.TempDir(), "vault.db"), Backend: BackendBlob}
+	createTestVault(t, cryptor, cfg, 3, 4096)
+
+	for _, backend := range []string{BackendFiles, BackendPaged, BackendBlob} {
+		backupPath, err := ConvertStorage(ctx, cryptor, cfg, backend)
+		require.NoError(t, err, backend)
+		require.NoError(t, os.RemoveAll(backupPath))
+
+		detected, err := detectBackend(cfg.Path)
+		require.NoError(t, err)
+		assert.Equal(t, backend, detected)
+
+		storage, err := openSQLiteStorage(ctx, cryptor, cfg)
+		require.NoError(t, err)
+
+		secrets, err := storage.ListSecrets(ctx)
+		require.NoError(t, err)
+		assert.Len(t, secrets, 3, backend)
+
+		version, err := storage.schemaVersion(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, latestSchemaVersion(), version)
+		require.NoError(t, storage.Close())
+	}
+
+	_, err := ConvertStorage(ctx, cryptor, cfg, BackendBlob)
+	assert.ErrorContains(t, err, "already uses")
+
+	_, err = ConvertStorage(ctx, cryptor, cfg, "tape")
+	assert.ErrorContains(t, err, "unknown storage backend")
+}
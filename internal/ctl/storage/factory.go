@@ -2,12 +2,49 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/etoneja/go-keeper/internal/ctl/crypto"
 )
 
-func InitializeStorage(ctx context.Context, cryptor crypto.Cryptor, path string) error {
-	err := initializeSQLiteStorage(ctx, cryptor, path)
+// Storage backends, selecting how the vault image is persisted.
+const (
+	// BackendBlob re-encrypts and rewrites the whole vault on every save.
+	BackendBlob = "blob"
+	// BackendPaged encrypts the vault page by page and rewrites only
+	// changed pages.
+	BackendPaged = "paged"
+	// BackendFiles keeps a directory with one encrypted file per secret.
+	BackendFiles = "files"
+)
+
+// Config describes where the vault lives and how it is persisted. Backend
+// applies to new vaults, existing ones are opened with the backend they were
+// written by. Backups is the number of previous encrypted generations kept by
+// the blob backend. A zero LockTimeout fails fast, a negative one waits forever.
+// Compress compresses secret data written from now on, data is read either way.
+// Secrets in the trash longer than TrashRetention are purged when the vault
+// is opened, a zero TrashRetention keeps them until they are purged by hand.
+type Config struct {
+	Path           string
+	Backend        string
+	Backups        int
+	LockTimeout    time.Duration
+	Compress       bool
+	History        HistoryRetention
+	TrashRetention time.Duration
+}
+
+// HistoryRetention limits the earlier versions kept of every secret: at most
+// Versions of them, none older than MaxAge unless it is zero. Zero Versions
+// keeps no history.
+type HistoryRetention struct {
+	Versions int
+	MaxAge   time.Duration
+}
+
+func InitializeStorage(ctx context.Context, cryptor crypto.Cryptor, cfg Config) error {
+	err := initializeSQLiteStorage(ctx, cryptor, cfg)
 	if err != nil {
 		return err
 	}
@@ -15,8 +52,8 @@ func InitializeStorage(ctx context.Context, cryptor crypto.Cryptor, path string)
 	return nil
 }
 
-func NewStorage(ctx context.Context, cryptor crypto.Cryptor, path string) (Storager, error) {
-	storage, err := openSQLiteStorage(ctx, cryptor, path)
+func NewStorage(ctx context.Context, cryptor crypto.Cryptor, cfg Config) (Storager, error) {
+	storage, err := openSQLiteStorage(ctx, cryptor, cfg)
 	if err != nil {
 		return nil, err
 	}
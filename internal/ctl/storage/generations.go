@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/fsutil"
+)
+
+// ListGenerations returns the encrypted vault generations kept on disk,
+// newest first.
+func ListGenerations(cfg Config) ([]fsutil.Generation, error) {
+	return fsutil.ListGenerations(cfg.Path, cfg.Backups)
+}
+
+// RestoreGeneration replaces the vault with the given generation. The vault
+// being replaced becomes generation 1, so a restore can itself be undone.
+func RestoreGeneration(ctx context.Context, cryptor crypto.Cryptor, cfg Config, generation int) error {
+	if generation < 1 {
+		return errs.Validationf("generation must be positive, got %d", generation)
+	}
+
+	lock, err := lockVault(cfg)
+	if err != nil {
+		return err
+	}
+
+	backend, err := detectBackend(cfg.Path)
+	if err != nil {
+		return unlockVault(lock, err)
+	}
+	if backend != BackendBlob {
+		return unlockVault(lock, errs.Validationf("vault generations are not kept by the %s backend", backend))
+	}
+
+	return unlockVault(lock, restoreGeneration(ctx, cryptor, cfg, generation))
+}
+
+func restoreGeneration(ctx context.Context, cryptor crypto.Cryptor, cfg Config, generation int) error {
+	genPath := fsutil.GenerationPath(cfg.Path, generation)
+	encryptedData, err := os.ReadFile(genPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return &errs.NotFoundError{Entity: "vault generation", UUID: strconv.Itoa(generation)}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read generation %d: %w", generation, err)
+	}
+
+	// NOTE: Check that the generation decrypts and loads before it replaces anything
+	decryptedData, err := cryptor.DecryptStorageData(encryptedData)
+	if err != nil {
+		return vaultDecryptError(fmt.Errorf("generation %d: %w", generation, err))
+	}
+
+	db, err := deserializeInMemoryDBFromBytes(ctx, decryptedData)
+	if err != nil {
+		return errs.NewCorruptError(fmt.Errorf("generation %d is not a valid vault: %w", generation, err))
+	}
+	if err := db.Close(); err != nil {
+		return err
+	}
+
+	_, err = writeVaultFile(cfg.Path, cfg.Backups, encryptedData)
+	return err
+}
@@ -0,0 +1,325 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/compress"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+// storedState is what the vault holds for a secret before it is overwritten.
+type storedState struct {
+	lastModified time.Time
+	hash         string
+	trashed      bool
+}
+
+// isVersionOf reports whether overwriting the stored secret with the given one
+// makes the stored secret an earlier version. Rewrites that keep the
+// modification time, such as rehashing, are not versions, neither are moves
+// to and from the trash that keep the content.
+func (st storedState) isVersionOf(secret *types.LocalSecret) bool {
+	if st.lastModified.IsZero() || st.lastModified.Equal(secret.LastModified) {
+		return false
+	}
+	return st.hash != secret.Hash || (!st.trashed && !secret.InTrash())
+}
+
+// secretState returns what the vault holds for the secret, a zero
+// modification time when it does not exist.
+// NOTE: The vault has one connection, read before a transaction is begun
+func (s *SQLiteStorage) secretState(ctx context.Context, secretID string) (storedState, error) {
+	var state storedState
+	var deletedAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, `SELECT last_modified, hash, deleted_at FROM secrets WHERE uuid = ?`, secretID).
+		Scan(&state.lastModified, &state.hash, &deletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storedState{}, nil
+	}
+	state.trashed = deletedAt.Valid
+	return state, err
+}
+
+// archiveSecret copies the stored secret into its history as the next
+// version, before it is overwritten or deleted, and prunes its history.
+func (s *SQLiteStorage) archiveSecret(ctx context.Context, tx *sql.Tx, secretID string) error {
+	if s.history.Versions <= 0 {
+		return nil
+	}
+
+	tags, err := txSecretTags(ctx, tx, secretID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO secret_versions (secret_uuid, version, type, name, last_modified, hash, metadata, data, fields, tags, archived_at)
+		SELECT uuid,
+			COALESCE((SELECT MAX(version) FROM secret_versions WHERE secret_uuid = ?), 0) + 1,
+			type, name, last_modified, hash, metadata, data, fields, ?, ?
+		FROM secrets
+		WHERE uuid = ?
+	`
+
+	_, err = tx.ExecContext(ctx, query, secretID, tags, time.Now().UTC(), secretID)
+	if err != nil {
+		return fmt.Errorf("failed to archive secret %s: %w", secretID, err)
+	}
+
+	_, err = s.pruneVersions(ctx, tx, secretID)
+	return err
+}
+
+func txSecretTags(ctx context.Context, tx *sql.Tx, secretID string) (string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT tag FROM secret_tags WHERE secret_uuid = ? ORDER BY tag`, secretID)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return "", err
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// pruneVersions deletes the versions of the secret, or of every secret when
+// secretID is empty, that fall outside the retention, see HistoryRetention.
+func (s *SQLiteStorage) pruneVersions(ctx context.Context, tx *sql.Tx, secretID string) (int64, error) {
+	query := `
+		DELETE FROM secret_versions
+		WHERE (? = '' OR secret_uuid = ?)
+			AND (
+				(SELECT COUNT(*) FROM secret_versions newer
+					WHERE newer.secret_uuid = secret_versions.secret_uuid
+						AND newer.version > secret_versions.version) >= ?
+				OR (? AND archived_at < ?)
+			)
+	`
+
+	maxAge := s.history.MaxAge > 0
+	cutoff := time.Now().UTC().Add(-s.history.MaxAge)
+
+	result, err := tx.ExecContext(ctx, query, secretID, secretID, max(s.history.Versions, 0), maxAge, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune secret history: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// pruneHistory applies the retention to the whole history, which may have
+// been kept under a looser one.
+func (s *SQLiteStorage) pruneHistory(ctx context.Context) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	pruned, err := s.pruneVersions(ctx, tx, "")
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if pruned > 0 {
+		s.markDirty()
+	}
+
+	return nil
+}
+
+// ListSecretVersions returns the earlier versions of the secret, newest
+// first, without their data. Versions are listed after the secret is deleted.
+func (s *SQLiteStorage) ListSecretVersions(ctx context.Context, secretID string) ([]*types.SecretVersion, error) {
+	query := `
+		SELECT version, archived_at, type, name, last_modified, hash, metadata, tags
+		FROM secret_versions
+		WHERE secret_uuid = ?
+		ORDER BY version DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, secretID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	var versions []*types.SecretVersion
+	for rows.Next() {
+		version, err := scanSecretVersion(rows, secretID, false)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
+// GetSecretVersion returns an earlier version of the secret with its data.
+func (s *SQLiteStorage) GetSecretVersion(ctx context.Context, secretID string, version int) (*types.SecretVersion, error) {
+	query := `
+		SELECT version, archived_at, type, name, last_modified, hash, metadata, tags, data, fields
+		FROM secret_versions
+		WHERE secret_uuid = ? AND version = ?
+	`
+
+	row := s.db.QueryRowContext(ctx, query, secretID, version)
+
+	secretVersion, err := scanSecretVersion(row, secretID, true)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &errs.NotFoundError{Entity: "version", UUID: secretID + " v" + strconv.Itoa(version)}
+		}
+		return nil, err
+	}
+
+	return secretVersion, nil
+}
+
+func scanSecretVersion(row interface{ Scan(...any) error }, secretID string, loadData bool) (*types.SecretVersion, error) {
+	secret := &types.LocalSecret{UUID: secretID}
+	version := &types.SecretVersion{Secret: secret}
+
+	var tags string
+	var data []byte
+	var fields sql.NullString
+	dest := []any{
+		&version.Version,
+		&version.ArchivedAt,
+		&secret.Type,
+		&secret.Name,
+		&secret.LastModified,
+		&secret.Hash,
+		&secret.Metadata,
+		&tags,
+	}
+	if loadData {
+		dest = append(dest, &data, &fields)
+	}
+
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(tags), &secret.Tags); err != nil {
+		return nil, fmt.Errorf("failed to parse tags of secret %s v%d: %w", secretID, version.Version, err)
+	}
+	if len(secret.Tags) == 0 {
+		secret.Tags = nil
+	}
+
+	if loadData {
+		var err error
+		secret.Data, err = compress.Decompress(data)
+		if err != nil {
+			return nil, fmt.Errorf("secret %s v%d: %w", secretID, version.Version, err)
+		}
+		secret.Fields, err = decodeFields(fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return version, nil
+}
+
+// PurgeSecret erases the secret with its tags, attachments and history and
+// remembers it was purged, see ListPurgedSecrets. The secret may be deleted
+// already, its history is erased then.
+func (s *SQLiteStorage) PurgeSecret(ctx context.Context, secretID string) error {
+	// NOTE: Freed pages would keep the data until reused, and the vault file is a copy of them
+	if _, err := s.db.ExecContext(ctx, `PRAGMA secure_delete = ON`); err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := s.db.ExecContext(ctx, `PRAGMA secure_delete = OFF`); err != nil {
+			log.Printf("Error turning secure delete off: %v", err)
+		}
+	}()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var affected int64
+	for _, query := range []string{
+		`DELETE FROM secrets WHERE uuid = ?`,
+		`DELETE FROM secret_attachments WHERE secret_uuid = ?`,
+		`DELETE FROM secret_versions WHERE secret_uuid = ?`,
+	} {
+		result, err := tx.ExecContext(ctx, query, secretID)
+		if err != nil {
+			return err
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		affected += rows
+	}
+	if affected == 0 {
+		return errs.NewSecretNotFoundError(secretID)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT OR REPLACE INTO purged_secrets (uuid, purged_at) VALUES (?, ?)`,
+		secretID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	if err := replaceSecretTags(ctx, tx, secretID, nil); err != nil {
+		return err
+	}
+
+	if err := s.unindexSecret(ctx, tx, secretID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	s.markDirty()
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteStorage_History(t *testing.T) {
+	ctx := context.Background()
+	cryptor := crypto.NewCryptor("password", "login")
+	cfg := Config{
+		Path:     filepath.Join(t.TempDir(), "vault"),
+		Compress: true,
+		History:  HistoryRetention{Versions: 2},
+	}
+	require.NoError(t, initializeSQLiteStorage(ctx, cryptor, cfg))
+
+	storage, err := openSQLiteStorage(ctx, cryptor, cfg)
+	require.NoError(t, err)
+
+	modified := time.Now().UTC().Add(-time.Hour).Truncate(time.Microsecond)
+	secret := &types.LocalSecret{
+		UUID:         "secret",
+		Type:         constants.SecretTypeText,
+		Name:         "notes",
+		LastModified: modified,
+		Hash:         "hash-1",
+		Data:         []byte(`{"content":"one"}`),
+		Tags:         []string{"work"},
+	}
+	_, err = storage.CreateSecret(ctx, secret)
+	require.NoError(t, err)
+
+	update := func(hash string, content string) {
+		modified = modified.Add(time.Minute)
+		secret.LastModified = modified
+		secret.Hash = hash
+		secret.Data = []byte(`{"content":"` + content + `"}`)
+		secret.Tags = nil
+		require.NoError(t, storage.UpdateSecret(ctx, secret))
+	}
+
+	update("hash-2", "two")
+
+	// NOTE: A rewrite keeping the modification time is not a version
+	secret.Hash = "rehashed-2"
+	require.NoError(t, storage.UpdateSecret(ctx, secret))
+
+	versions, err := storage.ListSecretVersions(ctx, secret.UUID)
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, 1, versions[0].Version)
+	assert.Equal(t, "hash-1", versions[0].Secret.Hash)
+	assert.Equal(t, []string{"work"}, versions[0].Secret.Tags)
+	assert.Nil(t, versions[0].Secret.Data)
+
+	version, err := storage.GetSecretVersion(ctx, secret.UUID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"content":"one"}`), version.Secret.Data)
+
+	update("hash-3", "three")
+	require.NoError(t, storage.DeleteSecret(ctx, secret.UUID))
+
+	// NOTE: Two versions are kept, the oldest is pruned
+	versions, err = storage.ListSecretVersions(ctx, secret.UUID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, 3, versions[0].Version)
+	assert.Equal(t, "hash-3", versions[0].Secret.Hash)
+	assert.Equal(t, 2, versions[1].Version)
+	assert.Equal(t, "rehashed-2", versions[1].Secret.Hash)
+
+	_, err = storage.GetSecretVersion(ctx, secret.UUID, 1)
+	assert.True(t, errs.IsNotFound(err))
+
+	require.NoError(t, storage.Close())
+
+	// NOTE: A tighter retention applies to the kept history on open
+	cfg.History = HistoryRetention{Versions: 2, MaxAge: time.Nanosecond}
+	storage, err = openSQLiteStorage(ctx, cryptor, cfg)
+	require.NoError(t, err)
+	defer storage.Close()
+
+	versions, err = storage.ListSecretVersions(ctx, secret.UUID)
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+}
+
+func TestSQLiteStorage_PurgeSecret(t *testing.T) {
+	ctx := context.Background()
+	cryptor := crypto.NewCryptor("password", "login")
+	cfg := Config{
+		Path:    filepath.Join(t.TempDir(), "vault"),
+		History: HistoryRetention{Versions: 5},
+	}
+	require.NoError(t, initializeSQLiteStorage(ctx, cryptor, cfg))
+
+	storage, err := openSQLiteStorage(ctx, cryptor, cfg)
+	require.NoError(t, err)
+	defer storage.Close()
+
+	secret := &types.LocalSecret{
+		UUID:         "secret",
+		Type:         constants.SecretTypeText,
+		Name:         "notes",
+		LastModified: time.Now().UTC().Add(-time.Minute),
+		Hash:         "hash-1",
+		Data:         []byte(`{"content":"one"}`),
+		Tags:         []string{"work"},
+	}
+	_, err = storage.CreateSecret(ctx, secret)
+	require.NoError(t, err)
+
+	secret.LastModified = time.Now().UTC()
+	secret.Hash = "hash-2"
+	require.NoError(t, storage.UpdateSecret(ctx, secret))
+
+	require.NoError(t, storage.CreateAttachment(ctx, &types.Attachment{
+		UUID:         "attachment",
+		SecretUUID:   secret.UUID,
+		Name:         "key.pem",
+		LastModified: time.Now().UTC(),
+		Content:      []byte("key"),
+	}))
+
+	require.NoError(t, storage.PurgeSecret(ctx, secret.UUID))
+
+	_, err = storage.GetSecret(ctx, secret.UUID, false)
+	assert.True(t, errs.IsNotFound(err))
+
+	versions, err := storage.ListSecretVersions(ctx, secret.UUID)
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+
+	attachments, err := storage.ListAttachments(ctx, secret.UUID)
+	require.NoError(t, err)
+	assert.Empty(t, attachments)
+
+	tags, err := storage.listTags(ctx, secret.UUID)
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+
+	err = storage.PurgeSecret(ctx, secret.UUID)
+	assert.True(t, errs.IsNotFound(err))
+}
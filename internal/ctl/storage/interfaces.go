@@ -9,8 +9,40 @@ type Storager interface {
 	CreateSecret(ctx context.Context, secret *types.LocalSecret) (*types.LocalSecret, error)
 	GetSecret(ctx context.Context, secretID string, loadData bool) (*types.LocalSecret, error)
 	UpdateSecret(ctx context.Context, secret *types.LocalSecret) error
+	// DeleteSecret removes the secret for good, keeping its history. Secrets
+	// are moved to the trash by setting DeletedAt, see ListTrash.
 	DeleteSecret(ctx context.Context, secretID string) error
+	// ListSecrets returns the secrets not in the trash.
 	ListSecrets(ctx context.Context) ([]*types.LocalSecret, error)
+	// ListTrash returns the secrets in the trash, most recently deleted first.
+	ListTrash(ctx context.Context) ([]*types.LocalSecret, error)
+	// SearchSecrets returns the secrets matching a full-text query, best first.
+	SearchSecrets(ctx context.Context, query string) ([]*types.LocalSecret, error)
+
+	// Attachments are kept when their secret is deleted, see DeleteAttachment.
+	CreateAttachment(ctx context.Context, attachment *types.Attachment) error
+	GetAttachment(ctx context.Context, attachmentID string, loadContent bool) (*types.Attachment, error)
+	DeleteAttachment(ctx context.Context, attachmentID string) error
+	// ListAttachments returns the attachments of the secret, or of every
+	// secret when secretID is empty, without their content.
+	ListAttachments(ctx context.Context, secretID string) ([]*types.Attachment, error)
+
+	// History holds earlier versions of secrets, archived when a secret is
+	// modified or deleted, see HistoryRetention.
+	ListSecretVersions(ctx context.Context, secretID string) ([]*types.SecretVersion, error)
+	GetSecretVersion(ctx context.Context, secretID string, version int) (*types.SecretVersion, error)
+	// PurgeSecret erases the secret with its attachments and history.
+	PurgeSecret(ctx context.Context, secretID string) error
+	// ListPurgedSecrets returns the secrets purged since they were last
+	// created, so that sync erases their copies in the trash elsewhere.
+	ListPurgedSecrets(ctx context.Context) ([]string, error)
+
+	// GetSyncManifest returns the manifest last seen on the sync target.
+	GetSyncManifest(ctx context.Context, target string) (*types.SyncManifest, error)
+	SaveSyncManifest(ctx context.Context, target string, manifest *types.SyncManifest) error
+
+	// DataStats sums up the stored data, see Config.Compress.
+	DataStats(ctx context.Context) (*DataStats, error)
 
 	Close() error
 }
@@ -0,0 +1,448 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/fsutil"
+	"github.com/zeebo/blake3"
+)
+
+// Paged vault layout:
+//
+//	prefix   magic "GKPV" | version | 3 reserved | salt | page size u32 | page count u32
+//	slots    page count slots of nonce | sealed page | tag, sealed with the page index
+//	trailer  nonce | sealed (epoch u64 | BLAKE3 of every slot) | tag, sealed with the prefix
+//
+// The storage key is derived once from the salt. A save seals only the pages
+// whose content changed and writes them in place through a redo journal, the
+// trailer ties every slot to the current state so pages cannot be swapped or
+// replayed from older files.
+const (
+	pagedMagic         = "GKPV"
+	pagedFormatVersion = 1
+	pagedPrefixSize    = 32
+	pagedPageADLabel   = "go-keeper/page"
+
+	pagedJournalMagic  = "GKPJ"
+	pagedJournalSuffix = ".journal"
+)
+
+var errPagedCorrupted = errs.NewCorruptError(errors.New("paged vault is corrupted"))
+
+type pagedVaultFile struct {
+	cryptor crypto.Cryptor
+	path    string
+
+	sealer      crypto.Sealer
+	salt        []byte
+	pageSize    int
+	epoch       uint64
+	pageDigests [][32]byte
+	slotDigests [][32]byte
+	fingerprint vaultFingerprint
+}
+
+type pagedSlot struct {
+	index int
+	data  []byte
+}
+
+// pagedJournal is the redo log of one save: the new prefix, the rewritten
+// slots and the new trailer.
+type pagedJournal struct {
+	prefix  []byte
+	slotLen int
+	slots   []pagedSlot
+	trailer []byte
+}
+
+func (f *pagedVaultFile) load(ctx context.Context) (*sql.DB, error) {
+	image, err := f.loadImage()
+	if err != nil {
+		return nil, err
+	}
+
+	return deserializeInMemoryDBFromBytes(ctx, image)
+}
+
+func (f *pagedVaultFile) save(ctx context.Context, db *sql.DB) error {
+	image, err := serializeInMemoryDBToBytes(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	return f.saveImage(image)
+}
+
+func (f *pagedVaultFile) snapshot(dst string) error {
+	return copyVaultFile(f.path, dst)
+}
+
+func (f *pagedVaultFile) loadImage() ([]byte, error) {
+	if err := replayPagedJournal(f.path); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read db file: %w", err)
+	}
+
+	if len(data) < pagedPrefixSize || string(data[:len(pagedMagic)]) != pagedMagic {
+		return nil, fmt.Errorf("%w: not a paged vault", errPagedCorrupted)
+	}
+	prefix := data[:pagedPrefixSize]
+	if prefix[4] != pagedFormatVersion {
+		return nil, fmt.Errorf("unsupported paged vault version %d", prefix[4])
+	}
+
+	salt := bytes.Clone(prefix[8 : 8+crypto.StorageSaltSize])
+	pageSize := int(binary.BigEndian.Uint32(prefix[24:28]))
+	pageCount := int(binary.BigEndian.Uint32(prefix[28:32]))
+
+	sealer, err := f.cryptor.StorageSealer(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	slotLen := pageSize + sealer.Overhead()
+	trailerOffset := pagedPrefixSize + pageCount*slotLen
+	if len(data) <= trailerOffset {
+		return nil, fmt.Errorf("%w: truncated", errPagedCorrupted)
+	}
+
+	trailer, err := sealer.Open(data[trailerOffset:], prefix)
+	if err != nil {
+		return nil, vaultDecryptError(err)
+	}
+	if len(trailer) != 8+32*pageCount {
+		return nil, fmt.Errorf("%w: bad trailer", errPagedCorrupted)
+	}
+
+	epoch := binary.BigEndian.Uint64(trailer[:8])
+	image := make([]byte, 0, pageCount*pageSize)
+	pageDigests := make([][32]byte, pageCount)
+	slotDigests := make([][32]byte, pageCount)
+	for i := range pageCount {
+		slot := data[pagedPrefixSize+i*slotLen : pagedPrefixSize+(i+1)*slotLen]
+
+		slotDigests[i] = blake3.Sum256(slot)
+		if !bytes.Equal(slotDigests[i][:], trailer[8+32*i:8+32*(i+1)]) {
+			return nil, fmt.Errorf("%w: page %d does not match the trailer", errPagedCorrupted, i)
+		}
+
+		page, err := sealer.Open(slot, pagedPageAD(i))
+		if err != nil {
+			return nil, fmt.Errorf("%w: page %d: %w", errPagedCorrupted, i, err)
+		}
+
+		pageDigests[i] = blake3.Sum256(page)
+		image = append(image, page...)
+	}
+
+	fingerprint, err := statVaultFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+
+	f.sealer = sealer
+	f.salt = salt
+	f.pageSize = pageSize
+	f.epoch = epoch
+	f.pageDigests = pageDigests
+	f.slotDigests = slotDigests
+	f.fingerprint = fingerprint
+
+	return image, nil
+}
+
+func (f *pagedVaultFile) saveImage(image []byte) error {
+	if err := checkVaultUnchanged(f.path, f.fingerprint); err != nil {
+		return err
+	}
+
+	pageSize, err := sqlitePageSize(image)
+	if err != nil {
+		return err
+	}
+
+	if f.sealer == nil {
+		salt, err := crypto.NewStorageSalt()
+		if err != nil {
+			return err
+		}
+		sealer, err := f.cryptor.StorageSealer(salt)
+		if err != nil {
+			return err
+		}
+		f.sealer = sealer
+		f.salt = salt
+	}
+
+	if pageSize != f.pageSize {
+		// NOTE: A new page size invalidates every page, the file is rewritten in full
+		f.pageDigests = nil
+		f.slotDigests = nil
+	}
+
+	pageCount := len(image) / pageSize
+	pageDigests := make([][32]byte, pageCount)
+	slotDigests := make([][32]byte, pageCount)
+	var changed []pagedSlot
+	for i := range pageCount {
+		page := image[i*pageSize : (i+1)*pageSize]
+		pageDigests[i] = blake3.Sum256(page)
+
+		if i < len(f.pageDigests) && pageDigests[i] == f.pageDigests[i] {
+			slotDigests[i] = f.slotDigests[i]
+			continue
+		}
+
+		slot, err := f.sealer.Seal(page, pagedPageAD(i))
+		if err != nil {
+			return fmt.Errorf("failed to encrypt page %d: %w", i, err)
+		}
+		slotDigests[i] = blake3.Sum256(slot)
+		changed = append(changed, pagedSlot{index: i, data: slot})
+	}
+
+	if len(changed) == 0 && pageCount == len(f.pageDigests) {
+		return nil
+	}
+
+	epoch := f.epoch + 1
+	prefix := pagedPrefix(f.salt, pageSize, pageCount)
+
+	trailerData := binary.BigEndian.AppendUint64(make([]byte, 0, 8+32*pageCount), epoch)
+	for _, digest := range slotDigests {
+		trailerData = append(trailerData, digest[:]...)
+	}
+	trailer, err := f.sealer.Seal(trailerData, prefix)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt trailer: %w", err)
+	}
+
+	journal := &pagedJournal{
+		prefix:  prefix,
+		slotLen: pageSize + f.sealer.Overhead(),
+		slots:   changed,
+		trailer: trailer,
+	}
+
+	if f.fingerprint.exists {
+		err = commitPagedJournal(f.path, journal)
+	} else {
+		err = fsutil.WriteFileAtomic(f.path, journal.fullFile(), 0600)
+	}
+	if err != nil {
+		return err
+	}
+
+	fingerprint, err := statVaultFile(f.path)
+	if err != nil {
+		return err
+	}
+
+	f.pageSize = pageSize
+	f.epoch = epoch
+	f.pageDigests = pageDigests
+	f.slotDigests = slotDigests
+	f.fingerprint = fingerprint
+
+	return nil
+}
+
+func pagedPrefix(salt []byte, pageSize, pageCount int) []byte {
+	prefix := make([]byte, pagedPrefixSize)
+	copy(prefix, pagedMagic)
+	prefix[4] = pagedFormatVersion
+	copy(prefix[8:], salt)
+	binary.BigEndian.PutUint32(prefix[24:28], uint32(pageSize))
+	binary.BigEndian.PutUint32(prefix[28:32], uint32(pageCount))
+	return prefix
+}
+
+func pagedPageAD(index int) []byte {
+	ad := make([]byte, 0, len(pagedPageADLabel)+4)
+	ad = append(ad, pagedPageADLabel...)
+	return binary.BigEndian.AppendUint32(ad, uint32(index))
+}
+
+// sqlitePageSize reads the page size from the header of a database image.
+func sqlitePageSize(image []byte) (int, error) {
+	if len(image) < 100 {
+		return 0, fmt.Errorf("database image too short")
+	}
+
+	pageSize := int(binary.BigEndian.Uint16(image[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || len(image)%pageSize != 0 {
+		return 0, fmt.Errorf("database image has invalid page size %d", pageSize)
+	}
+
+	return pageSize, nil
+}
+
+// fullFile assembles a complete vault file, valid only when every slot is
+// part of the journal.
+func (j *pagedJournal) fullFile() []byte {
+	data := make([]byte, 0, pagedPrefixSize+len(j.slots)*j.slotLen+len(j.trailer))
+	data = append(data, j.prefix...)
+	for _, slot := range j.slots {
+		data = append(data, slot.data...)
+	}
+	return append(data, j.trailer...)
+}
+
+func (j *pagedJournal) marshal() []byte {
+	data := make([]byte, 0, len(pagedJournalMagic)+pagedPrefixSize+8+len(j.slots)*(4+j.slotLen)+8+len(j.trailer))
+	data = append(data, pagedJournalMagic...)
+	data = append(data, j.prefix...)
+	data = binary.BigEndian.AppendUint32(data, uint32(j.slotLen))
+	data = binary.BigEndian.AppendUint32(data, uint32(len(j.slots)))
+	for _, slot := range j.slots {
+		data = binary.BigEndian.AppendUint32(data, uint32(slot.index))
+		data = append(data, slot.data...)
+	}
+	data = binary.BigEndian.AppendUint64(data, uint64(len(j.trailer)))
+	return append(data, j.trailer...)
+}
+
+func unmarshalPagedJournal(data []byte) (*pagedJournal, error) {
+	errBad := fmt.Errorf("%w: bad journal", errPagedCorrupted)
+
+	header := len(pagedJournalMagic) + pagedPrefixSize + 8
+	if len(data) < header || string(data[:len(pagedJournalMagic)]) != pagedJournalMagic {
+		return nil, errBad
+	}
+	data = data[len(pagedJournalMagic):]
+
+	j := &pagedJournal{prefix: data[:pagedPrefixSize]}
+	data = data[pagedPrefixSize:]
+	j.slotLen = int(binary.BigEndian.Uint32(data[:4]))
+	count := int(binary.BigEndian.Uint32(data[4:8]))
+	data = data[8:]
+
+	for range count {
+		if len(data) < 4+j.slotLen {
+			return nil, errBad
+		}
+		j.slots = append(j.slots, pagedSlot{
+			index: int(binary.BigEndian.Uint32(data[:4])),
+			data:  data[4 : 4+j.slotLen],
+		})
+		data = data[4+j.slotLen:]
+	}
+
+	if len(data) < 8 {
+		return nil, errBad
+	}
+	trailerLen := binary.BigEndian.Uint64(data[:8])
+	if uint64(len(data)-8) != trailerLen {
+		return nil, errBad
+	}
+	j.trailer = data[8:]
+
+	return j, nil
+}
+
+// commitPagedJournal makes the journal durable, applies it to the vault and
+// removes it. A crash at any point is recovered by replayPagedJournal.
+func commitPagedJournal(path string, journal *pagedJournal) error {
+	journalPath := path + pagedJournalSuffix
+	if err := fsutil.WriteFileAtomic(journalPath, journal.marshal(), 0600); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+
+	if err := applyPagedJournal(path, journal); err != nil {
+		return err
+	}
+
+	if err := os.Remove(journalPath); err != nil {
+		return fmt.Errorf("failed to remove journal: %w", err)
+	}
+
+	return fsutil.SyncDir(filepath.Dir(path))
+}
+
+// replayPagedJournal finishes a save interrupted after its journal was
+// written. Applying a journal twice is harmless.
+func replayPagedJournal(path string) error {
+	journalPath := path + pagedJournalSuffix
+	data, err := os.ReadFile(journalPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	journal, err := unmarshalPagedJournal(data)
+	if err != nil {
+		return err
+	}
+
+	if err := applyPagedJournal(path, journal); err != nil {
+		return err
+	}
+
+	if err := os.Remove(journalPath); err != nil {
+		return fmt.Errorf("failed to remove journal: %w", err)
+	}
+
+	return fsutil.SyncDir(filepath.Dir(path))
+}
+
+func applyPagedJournal(path string, journal *pagedJournal) error {
+	file, err := os.OpenFile(path, os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open db file: %w", err)
+	}
+
+	err = writePagedJournal(file, journal)
+	closeErr := file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write db file: %w", err)
+	}
+	return closeErr
+}
+
+func writePagedJournal(file *os.File, journal *pagedJournal) error {
+	pageCount := int(binary.BigEndian.Uint32(journal.prefix[28:32]))
+	trailerOffset := int64(pagedPrefixSize + pageCount*journal.slotLen)
+
+	if _, err := file.WriteAt(journal.prefix, 0); err != nil {
+		return err
+	}
+
+	for _, slot := range journal.slots {
+		if slot.index >= pageCount {
+			return fmt.Errorf("%w: journal page %d out of range", errPagedCorrupted, slot.index)
+		}
+		offset := int64(pagedPrefixSize + slot.index*journal.slotLen)
+		if _, err := file.WriteAt(slot.data, offset); err != nil {
+			return err
+		}
+	}
+
+	if _, err := file.WriteAt(journal.trailer, trailerOffset); err != nil {
+		return err
+	}
+
+	if err := file.Truncate(trailerOffset + int64(len(journal.trailer))); err != nil {
+		return err
+	}
+
+	return file.Sync()
+}
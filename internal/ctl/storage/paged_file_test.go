@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSecret(t testing.TB, id string, size int) *types.LocalSecret {
+	data := make([]byte, size)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+
+	return &types.LocalSecret{
+		UUID:         id,
+		Type:         "binary",
+		Name:         id,
+		LastModified: time.Now().UTC(),
+		Hash:         "hash-" + id,
+		Data:         data,
+	}
+}
+
+func createTestVault(t testing.TB, cryptor crypto.Cryptor, cfg Config, secrets int, size int) {
+	ctx := context.Background()
+	require.NoError(t, initializeSQLiteStorage(ctx, cryptor, cfg))
+
+	storage, err := openSQLiteStorage(ctx, cryptor, cfg)
+	require.NoError(t, err)
+	for i := range secrets {
+		_, err := storage.CreateSecret(ctx, newTestSecret(t, fmt.Sprintf("secret-%d", i), size))
+		require.NoError(t, err)
+	}
+	require.NoError(t, storage.Close())
+}
+
+// pagedSlotRange returns the byte range of slot index in a paged vault file.
+func pagedSlotRange(t *testing.T, data []byte, index int) (int, int) {
+	pageSize := int(binary.BigEndian.Uint32(data[24:28]))
+	slotLen := pageSize + 24 + 16
+	start := pagedPrefixSize + index*slotLen
+	require.LessOrEqual(t, start+slotLen, len(data))
+	return start, start + slotLen
+}
+
+func TestPagedVaultFile(t *testing.T) {
+	ctx := context.Background()
+	cryptor := crypto.NewCryptor("password", "login")
+
+	t.Run("round trip rewrites only changed pages", func(t *testing.T) {
+		cfg := Config{Path: filepath.Join(t.TempDir(), "vault.db"), Backend: BackendPaged}
+		createTestVault(t, cryptor, cfg, 20, 8*1024)
+
+		before, err := os.ReadFile(cfg.Path)
+		require.NoError(t, err)
+
+		storage, err := openSQLiteStorage(ctx, cryptor, cfg)
+		require.NoError(t, err)
+		_, ok := storage.file.(*pagedVaultFile)
+		require.True(t, ok, "backend must be detected from the file")
+
+		secret := newTestSecret(t, "secret-3", 8*1024)
+		require.NoError(t, storage.UpdateSecret(ctx, secret))
+		require.NoError(t, storage.Close())
+
+		after, err := os.ReadFile(cfg.Path)
+		require.NoError(t, err)
+
+		// NOTE: SQLite may write the new row before freeing the old one,
+		// growing the database by a few pages, so compare the common ones
+		pageCount := int(binary.BigEndian.Uint32(before[28:32]))
+		require.GreaterOrEqual(t, int(binary.BigEndian.Uint32(after[28:32])), pageCount)
+		changedSlots := 0
+		for i := range pageCount {
+			start, end := pagedSlotRange(t, after, i)
+			if string(before[start:end]) != string(after[start:end]) {
+				changedSlots++
+			}
+		}
+		assert.Positive(t, changedSlots)
+		assert.Less(t, changedSlots, pageCount/4, "most pages must be left untouched")
+
+		storage, err = openSQLiteStorage(ctx, cryptor, cfg)
+		require.NoError(t, err)
+		defer storage.Close()
+
+		loaded, err := storage.GetSecret(ctx, "secret-3", true)
+		require.NoError(t, err)
+		assert.Equal(t, secret.Data, loaded.Data)
+
+		secrets, err := storage.ListSecrets(ctx)
+		require.NoError(t, err)
+		assert.Len(t, secrets, 20)
+	})
+
+	t.Run("interrupted save is replayed from the journal", func(t *testing.T) {
+		cfg := Config{Path: filepath.Join(t.TempDir(), "vault.db"), Backend: BackendPaged}
+		createTestVault(t, cryptor, cfg, 3, 8*1024)
+
+		old, err := os.ReadFile(cfg.Path)
+		require.NoError(t, err)
+
+		storage, err := openSQLiteStorage(ctx, cryptor, cfg)
+		require.NoError(t, err)
+		_, err = storage.CreateSecret(ctx, newTestSecret(t, "late", 16*1024))
+		require.NoError(t, err)
+		require.NoError(t, storage.Close())
+
+		saved, err := os.ReadFile(cfg.Path)
+		require.NoError(t, err)
+
+		// NOTE: A journal holding every slot of the saved file, with the old file still on disk
+		pageSize := int(binary.BigEndian.Uint32(saved[24:28]))
+		pageCount := int(binary.BigEndian.Uint32(saved[28:32]))
+		journal := &pagedJournal{prefix: saved[:pagedPrefixSize], slotLen: pageSize + 24 + 16}
+		for i := range pageCount {
+			start, end := pagedSlotRange(t, saved, i)
+			journal.slots = append(journal.slots, pagedSlot{index: i, data: saved[start:end]})
+		}
+		journal.trailer = saved[pagedPrefixSize+pageCount*journal.slotLen:]
+
+		require.NoError(t, os.WriteFile(cfg.Path, old, 0600))
+		require.NoError(t, os.WriteFile(cfg.Path+pagedJournalSuffix, journal.marshal(), 0600))
+
+		storage, err = openSQLiteStorage(ctx, cryptor, cfg)
+		require.NoError(t, err)
+		defer storage.Close()
+
+		_, err = storage.GetSecret(ctx, "late", false)
+		require.NoError(t, err)
+		assert.NoFileExists(t, cfg.Path+pagedJournalSuffix)
+	})
+
+	t.Run("swapped pages are rejected", func(t *testing.T) {
+		cfg := Config{Path: filepath.Join(t.TempDir(), "vault.db"), Backend: BackendPaged}
+		createTestVault(t, cryptor, cfg, 3, 8*1024)
+
+		data, err := os.ReadFile(cfg.Path)
+		require.NoError(t, err)
+
+		start1, end1 := pagedSlotRange(t, data, 1)
+		start2, end2 := pagedSlotRange(t, data, 2)
+		slot1 := append([]byte(nil), data[start1:end1]...)
+		copy(data[start1:end1], data[start2:end2])
+		copy(data[start2:end2], slot1)
+		require.NoError(t, os.WriteFile(cfg.Path, data, 0600))
+
+		_, err = openSQLiteStorage(ctx, cryptor, cfg)
+		assert.ErrorIs(t, err, errPagedCorrupted)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		cfg := Config{Path: filepath.Join(t.TempDir(), "vault.db"), Backend: BackendPaged}
+		createTestVault(t, cryptor, cfg, 1, 1024)
+
+		_, err := openSQLiteStorage(ctx, crypto.NewCryptor("wrong", "login"), cfg)
+		assert.ErrorContains(t, err, "failed to decrypt db")
+	})
+}
+
+// BenchmarkVaultSave measures persisting a single changed secret in a vault
+// holding many large ones, for each backend.
+func BenchmarkVaultSave(b *testing.B) {
+	ctx := context.Background()
+	cryptor := crypto.NewCryptor("password", "login")
+
+	for _, backend := range []string{BackendBlob, BackendPaged} {
+		b.Run(backend, func(b *testing.B) {
+			cfg := Config{Path: filepath.Join(b.TempDir(), "vault.db"), Backend: backend}
+			createTestVault(b, cryptor, cfg, 100, 64*1024)
+
+			storage, err := openSQLiteStorage(ctx, cryptor, cfg)
+			require.NoError(b, err)
+			defer storage.Close()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				secret := newTestSecret(b, fmt.Sprintf("secret-%d", i%100), 64*1024)
+				require.NoError(b, storage.UpdateSecret(ctx, secret))
+				require.NoError(b, storage.dump())
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+// ErrSearchUnsupported is returned by SearchSecrets when SQLite was built
+// without FTS5.
+var ErrSearchUnsupported = errs.NewValidationError(errors.New("full-text search needs keeperctl built with -tags sqlite_fts5"))
+
+// The search index is an FTS5 table in the in-memory vault, so it is
+// encrypted with the rest of the dump. It is not a migration: builds without
+// FTS5 can still open the vault, they just leave the index stale. Passwords,
+// CVVs, card numbers and text or file contents are never indexed.
+//
+// uuid and hash identify the indexed version of the secret, see checkSearch.
+const createSearchTable = `
+	CREATE VIRTUAL TABLE IF NOT EXISTS secrets_search USING fts5(
+		uuid UNINDEXED,
+		hash UNINDEXED,
+		name,
+		tags,
+		username,
+		url,
+		file_name,
+		holder,
+		metadata,
+		tokenize = 'unicode61 remove_diacritics 2',
+		prefix = '2 3'
+	)
+`
+
+// searchRank weighs matches by column, in table order. Lower is better.
+const searchRank = `bm25(secrets_search, 0, 0, 10, 5, 3, 2, 2, 2, 1)`
+
+func searchSupported(ctx context.Context, db *sql.DB) (bool, error) {
+	var supported bool
+	err := db.QueryRowContext(ctx, `SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&supported)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for FTS5: %w", err)
+	}
+	return supported, nil
+}
+
+// openSearch creates the search index when SQLite supports it and rebuilds
+// it when it does not match the secrets, as after a change made by a build
+// without FTS5 or a load from the dir backend, which does not store it.
+// A rebuild does not mark the vault dirty, the index is saved with the
+// next change.
+func (s *SQLiteStorage) openSearch(ctx context.Context) error {
+	supported, err := searchSupported(ctx, s.db)
+	if err != nil || !supported {
+		return err
+	}
+
+	if _, err := s.db.ExecContext(ctx, createSearchTable); err != nil {
+		return fmt.Errorf("failed to create search index: %w", err)
+	}
+	s.searchable = true
+
+	current, err := s.checkSearch(ctx)
+	if err != nil || current {
+		return err
+	}
+
+	return s.rebuildSearch(ctx)
+}
+
+// checkSearch reports whether the index holds exactly the current version
+// of every secret not in the trash. The hash covers data, metadata and tags,
+// not the name.
+func (s *SQLiteStorage) checkSearch(ctx context.Context) (bool, error) {
+	query := `
+		SELECT
+			(SELECT count(*) FROM secrets WHERE deleted_at IS NULL),
+			(SELECT count(*) FROM secrets_search),
+			(SELECT count(*) FROM secrets s JOIN secrets_search f
+				ON f.uuid = s.uuid AND f.hash = s.hash AND f.name = s.name
+				WHERE s.deleted_at IS NULL)
+	`
+
+	var secrets, indexed, current int
+	if err := s.db.QueryRowContext(ctx, query).Scan(&secrets, &indexed, &current); err != nil {
+		return false, fmt.Errorf("failed to check search index: %w", err)
+	}
+
+	return secrets == indexed && secrets == current, nil
+}
+
+func (s *SQLiteStorage) rebuildSearch(ctx context.Context) error {
+	rows, err := s.db.QueryContext(ctx, `SELECT uuid FROM secrets WHERE deleted_at IS NULL`)
+	if err != nil {
+		return err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Close(); err != nil {
+		log.Printf("Error closing rows: %v", err)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// NOTE: The in-memory database has one connection, read before the transaction
+	secrets := make([]*types.LocalSecret, 0, len(ids))
+	for _, id := range ids {
+		secret, err := s.GetSecret(ctx, id, true)
+		if err != nil {
+			return err
+		}
+		secrets = append(secrets, secret)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM secrets_search`); err != nil {
+		return fmt.Errorf("failed to clear search index: %w", err)
+	}
+
+	for _, secret := range secrets {
+		if err := s.indexSecret(ctx, tx, secret); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// indexSecret replaces the index entry of the secret. Data must be loaded.
+// Secrets in the trash are not found by search.
+func (s *SQLiteStorage) indexSecret(ctx context.Context, tx *sql.Tx, secret *types.LocalSecret) error {
+	if err := s.unindexSecret(ctx, tx, secret.UUID); err != nil {
+		return err
+	}
+	if !s.searchable || secret.InTrash() {
+		return nil
+	}
+
+	var username, url, fileName, holder string
+	// NOTE: Data that does not parse is still found by name, metadata and tags
+	data, err := secret.ParseData()
+	if err == nil {
+		switch d := data.(type) {
+		case types.LoginData:
+			username, url = d.Username, d.URL
+		case types.FileData:
+			fileName = d.FileName
+		case types.CardData:
+			holder = d.Holder
+		}
+	}
+
+	query := `
+		INSERT INTO secrets_search (uuid, hash, name, tags, username, url, file_name, holder, metadata)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = tx.ExecContext(ctx, query,
+		secret.UUID,
+		secret.Hash,
+		secret.Name,
+		strings.Join(secret.Tags, " "),
+		username,
+		url,
+		fileName,
+		holder,
+		secret.Metadata,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to index secret: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLiteStorage) unindexSecret(ctx context.Context, tx *sql.Tx, secretID string) error {
+	if !s.searchable {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, `DELETE FROM secrets_search WHERE uuid = ?`, secretID)
+	if err != nil {
+		return fmt.Errorf("failed to unindex secret: %w", err)
+	}
+	return nil
+}
+
+// SearchSecrets returns the secrets matching query, best match first,
+// without their data. See SearchQuery for the syntax.
+func (s *SQLiteStorage) SearchSecrets(ctx context.Context, query string) ([]*types.LocalSecret, error) {
+	if !s.searchable {
+		return nil, ErrSearchUnsupported
+	}
+
+	match, err := SearchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT s.uuid, s.type, s.name, s.last_modified, s.hash, s.metadata
+		FROM secrets_search f
+		JOIN secrets s ON s.uuid = f.uuid
+		WHERE secrets_search MATCH ?
+		ORDER BY `+searchRank+`, s.name
+	`, match)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	var secrets []*types.LocalSecret
+	for rows.Next() {
+		secret := &types.LocalSecret{}
+		err := rows.Scan(
+			&secret.UUID,
+			&secret.Type,
+			&secret.Name,
+			&secret.LastModified,
+			&secret.Hash,
+			&secret.Metadata,
+		)
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, secret)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tags, err := s.listTags(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, secret := range secrets {
+		secret.Tags = tags[secret.UUID]
+	}
+
+	return secrets, nil
+}
+
+// SearchQuery translates a user query to an FTS5 expression. Words are
+// matched as whole tokens, all of them must match. A trailing * makes a
+// prefix query and "double quotes" make a phrase query.
+func SearchQuery(query string) (string, error) {
+	var terms []string
+	rest := strings.TrimSpace(query)
+
+	for rest != "" {
+		var term string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				return "", errs.Validationf("unterminated phrase in search query %q", query)
+			}
+			term, rest = rest[1:end+1], rest[end+2:]
+			if suffix, ok := strings.CutPrefix(rest, "*"); ok {
+				term, rest = term+"*", suffix
+			}
+		} else {
+			end := strings.IndexAny(rest, " \t")
+			if end < 0 {
+				end = len(rest)
+			}
+			term, rest = rest[:end], rest[end:]
+		}
+		rest = strings.TrimSpace(rest)
+
+		prefix := strings.HasSuffix(term, "*")
+		term = strings.TrimSpace(strings.TrimRight(term, "*"))
+		if term == "" {
+			continue
+		}
+
+		quoted := `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		if prefix {
+			quoted += "*"
+		}
+		terms = append(terms, quoted)
+	}
+
+	if len(terms) == 0 {
+		return "", errs.Validationf("search query is empty")
+	}
+
+	return strings.Join(terms, " AND "), nil
+}
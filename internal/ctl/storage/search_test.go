@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "github", want: `"github"`},
+		{query: "  git*  hub ", want: `"git"* AND "hub"`},
+		{query: `"alice smith" mail`, want: `"alice smith" AND "mail"`},
+		{query: `"alice sm"*`, want: `"alice sm"*`},
+		{query: `OR NOT) (x`, want: `"OR" AND "NOT)" AND "(x"`},
+		{query: `a"b`, want: `"a""b"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := SearchQuery(tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, query := range []string{"", "  ", "*", `"unterminated`} {
+		_, err := SearchQuery(query)
+		require.Error(t, err, query)
+		assert.True(t, errs.IsValidation(err))
+	}
+}
+
+func TestSQLiteStorage_SearchUnsupported(t *testing.T) {
+	ctx := context.Background()
+	cfg := Config{Path: filepath.Join(t.TempDir(), "vault.db")}
+	require.NoError(t, initializeSQLiteStorage(ctx, newPlainCryptor(t), cfg))
+
+	storage, err := openSQLiteStorage(ctx, newPlainCryptor(t), cfg)
+	require.NoError(t, err)
+	defer storage.Close()
+
+	storage.searchable = false
+	_, err = storage.SearchSecrets(ctx, "mail")
+	assert.ErrorIs(t, err, ErrSearchUnsupported)
+	assert.Equal(t, errs.ExitValidation, errs.ExitCode(err))
+}
+
+func newSearchTestStorage(t *testing.T) (*SQLiteStorage, Config) {
+	ctx := context.Background()
+	cfg := Config{Path: filepath.Join(t.TempDir(), "vault.db")}
+	require.NoError(t, initializeSQLiteStorage(ctx, newPlainCryptor(t), cfg))
+
+	storage, err := openSQLiteStorage(ctx, newPlainCryptor(t), cfg)
+	require.NoError(t, err)
+	if !storage.searchable {
+		_ = storage.Close()
+		t.Skip("SQLite is built without FTS5, run with -tags sqlite_fts5")
+	}
+
+	return storage, cfg
+}
+
+func newSearchTestSecret(uuid, secretType, name, metadata, data string) *types.LocalSecret {
+	return &types.LocalSecret{
+		UUID:         uuid,
+		Type:         secretType,
+		Name:         name,
+		LastModified: time.Now().UTC(),
+		Hash:         "hash-" + uuid,
+		Metadata:     metadata,
+		Data:         []byte(data),
+	}
+}
+
+func searchNames(t *testing.T, storage *SQLiteStorage, query string) []string {
+	secrets, err := storage.SearchSecrets(context.Background(), query)
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, secret := range secrets {
+		names = append(names, secret.Name)
+	}
+	return names
+}
+
+func TestSQLiteStorage_SearchSecrets(t *testing.T) {
+	ctx := context.Background()
+	storage, cfg := newSearchTestStorage(t)
+
+	secrets := []*types.LocalSecret{
+		newSearchTestSecret("id-1", "password", "work/github", "",
+			`{"username":"alice","password":"hunter2","url":"https://github.com/login"}`),
+		newSearchTestSecret("id-2", "card", "visa", "",
+			`{"number":"4111111111111111","holder":"Alice Smith","expiry":"12/30","cvv":"737"}`),
+		newSearchTestSecret("id-3", "binary", "keys", "deploy key for github",
+			`{"file_name":"id_ed25519.pub","file_size":3,"content":"c2VjcmV0"}`),
+		newSearchTestSecret("id-4", "text", "diary", "", `{"content":"gitlab password hunter2"}`),
+	}
+	secrets[3].Tags = []string{"personal"}
+	for _, secret := range secrets {
+		_, err := storage.CreateSecret(ctx, secret)
+		require.NoError(t, err)
+	}
+
+	t.Run("non-sensitive fields", func(t *testing.T) {
+		assert.Equal(t, []string{"work/github"}, searchNames(t, storage, "alice login"))
+		assert.Equal(t, []string{"visa"}, searchNames(t, storage, "smith"))
+		assert.Equal(t, []string{"keys"}, searchNames(t, storage, "id_ed25519"))
+		assert.Equal(t, []string{"diary"}, searchNames(t, storage, "personal"))
+	})
+
+	t.Run("sensitive fields are not indexed", func(t *testing.T) {
+		for _, query := range []string{"hunter2", "4111111111111111", "737", "gitlab", "c2VjcmV0"} {
+			assert.Empty(t, searchNames(t, storage, query), query)
+		}
+	})
+
+	t.Run("ranking, prefix and phrase", func(t *testing.T) {
+		assert.Equal(t, []string{"work/github", "keys"}, searchNames(t, storage, "github"))
+		assert.Equal(t, []string{"work/github", "keys"}, searchNames(t, storage, "git*"))
+		assert.Equal(t, []string{"visa"}, searchNames(t, storage, `"alice smith"`))
+		assert.Empty(t, searchNames(t, storage, `"smith alice"`))
+	})
+
+	t.Run("index follows updates and deletes", func(t *testing.T) {
+		secrets[0].Name = "work/gitea"
+		secrets[0].Hash = "hash-id-1-v2"
+		require.NoError(t, storage.UpdateSecret(ctx, secrets[0]))
+		require.NoError(t, storage.DeleteSecret(ctx, "id-3"))
+
+		assert.Equal(t, []string{"work/gitea"}, searchNames(t, storage, "gitea"))
+		assert.Equal(t, []string{"work/gitea"}, searchNames(t, storage, "github"), "matched by the URL only")
+		assert.Empty(t, searchNames(t, storage, "deploy"))
+	})
+
+	t.Run("stale index is rebuilt on open", func(t *testing.T) {
+		_, err := storage.db.ExecContext(ctx, `DELETE FROM secrets_search`)
+		require.NoError(t, err)
+		storage.markDirty()
+		require.NoError(t, storage.Close())
+
+		reopened, err := openSQLiteStorage(ctx, newPlainCryptor(t), cfg)
+		require.NoError(t, err)
+		defer reopened.Close()
+
+		assert.Equal(t, []string{"work/gitea"}, searchNames(t, reopened, "gitea"))
+		assert.False(t, reopened.isDirty)
+	})
+}
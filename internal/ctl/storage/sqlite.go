@@ -3,21 +3,33 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
-	"os"
+	"time"
 
+	"github.com/etoneja/go-keeper/internal/ctl/compress"
 	"github.com/etoneja/go-keeper/internal/ctl/crypto"
 	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/fsutil"
 	"github.com/etoneja/go-keeper/internal/ctl/types"
 )
 
 type SQLiteStorage struct {
 	db      *sql.DB
 	cryptor crypto.Cryptor
-	path    string
+	file    vaultFile
 	isDirty bool
+	// searchable is set when SQLite has FTS5, see openSearch
+	searchable bool
+	// compress is set when secret data is written compressed, see encodeData
+	compress bool
+	history  HistoryRetention
+	// trashRetention is how long secrets stay in the trash, see purgeTrash
+	trashRetention time.Duration
+
+	lock *fsutil.FileLock
 }
 
 func (s *SQLiteStorage) markClean() {
@@ -33,21 +45,10 @@ func (s *SQLiteStorage) dump() error {
 		return nil
 	}
 
-	decryptedBytes, err := serializeInMemoryDBToBytes(context.Background(), s.db)
-	if err != nil {
+	if err := s.file.save(context.Background(), s.db); err != nil {
 		return err
 	}
 
-	encryptedData, err := s.cryptor.EncryptStorageData(decryptedBytes)
-	if err != nil {
-		return fmt.Errorf("failed to encrypt db: %w", err)
-	}
-
-	// TODO: Write to temp file and only then replace original
-	if err := os.WriteFile(s.path, encryptedData, 0600); err != nil {
-		return fmt.Errorf("failed to write db file: %w", err)
-	}
-
 	s.markClean()
 
 	return nil
@@ -56,53 +57,65 @@ func (s *SQLiteStorage) dump() error {
 func (s *SQLiteStorage) Close() error {
 	err := s.dump()
 	if err != nil {
-		return err
+		_ = s.db.Close()
+		return unlockVault(s.lock, err)
 	}
 
-	return s.db.Close()
+	return unlockVault(s.lock, s.db.Close())
 }
 
-func (s *SQLiteStorage) createSchema(ctx context.Context) error {
+func (s *SQLiteStorage) CreateSecret(ctx context.Context, secret *types.LocalSecret) (*types.LocalSecret, error) {
 	query := `
-	CREATE TABLE secrets (
-		uuid TEXT PRIMARY KEY,
-		type TEXT NOT NULL,
-		name TEXT NOT NULL,
-		last_modified DATETIME NOT NULL,
-		hash TEXT NOT NULL,
-		metadata TEXT,
-		data BLOB NOT NULL
-	);
+		INSERT INTO secrets (uuid, type, name, last_modified, hash, metadata, data, fields, deleted_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	_, err := s.db.ExecContext(ctx, query)
+
+	fields, err := encodeFields(secret.Fields)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	s.markDirty()
-
-	return nil
-}
-
-func (s *SQLiteStorage) CreateSecret(ctx context.Context, secret *types.LocalSecret) (*types.LocalSecret, error) {
-	query := `
-		INSERT INTO secrets (uuid, type, name, last_modified, hash, metadata, data)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
 
-	_, err := s.db.ExecContext(ctx, query,
+	_, err = tx.ExecContext(ctx, query,
 		secret.UUID,
 		secret.Type,
 		secret.Name,
 		secret.LastModified,
 		secret.Hash,
 		secret.Metadata,
-		secret.Data,
+		s.encodeData(secret),
+		fields,
+		deletedAt(secret),
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	// NOTE: A purged secret that comes back, say from a backup, is no longer purged
+	_, err = tx.ExecContext(ctx, `DELETE FROM purged_secrets WHERE uuid = ?`, secret.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := replaceSecretTags(ctx, tx, secret.UUID, secret.Tags); err != nil {
+		return nil, err
+	}
+
+	if err := s.indexSecret(ctx, tx, secret); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
 	s.markDirty()
 
 	return secret, nil
@@ -110,17 +123,20 @@ func (s *SQLiteStorage) CreateSecret(ctx context.Context, secret *types.LocalSec
 
 func (s *SQLiteStorage) GetSecret(ctx context.Context, uuid string, loadData bool) (*types.LocalSecret, error) {
 	query := `
-		SELECT uuid, type, name, last_modified, hash, metadata,
-			case when ? then data else null end as data
+		SELECT uuid, type, name, last_modified, hash, metadata, deleted_at,
+			case when ? then data else null end as data,
+			case when ? then fields else null end as fields
 		FROM secrets
 		WHERE uuid = ?
 	`
 
-	row := s.db.QueryRowContext(ctx, query, loadData, uuid)
+	row := s.db.QueryRowContext(ctx, query, loadData, loadData, uuid)
 
 	secret := &types.LocalSecret{}
 
+	var deleted sql.NullTime
 	var data []byte
+	var fields sql.NullString
 	err := row.Scan(
 		&secret.UUID,
 		&secret.Type,
@@ -128,7 +144,9 @@ func (s *SQLiteStorage) GetSecret(ctx context.Context, uuid string, loadData boo
 		&secret.LastModified,
 		&secret.Hash,
 		&secret.Metadata,
+		&deleted,
 		&data,
+		&fields,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -136,55 +154,137 @@ func (s *SQLiteStorage) GetSecret(ctx context.Context, uuid string, loadData boo
 		}
 		return nil, err
 	}
+	secret.DeletedAt = deleted.Time
 	if loadData {
-		secret.Data = data
+		secret.Data, err = compress.Decompress(data)
+		if err != nil {
+			return nil, fmt.Errorf("secret %s: %w", uuid, err)
+		}
+		secret.Fields, err = decodeFields(fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tags, err := s.listTags(ctx, uuid)
+	if err != nil {
+		return nil, err
 	}
+	secret.Tags = tags[uuid]
+
 	return secret, nil
 }
 
 func (s *SQLiteStorage) UpdateSecret(ctx context.Context, secret *types.LocalSecret) error {
 	query := `
 		UPDATE secrets 
-		SET type = ?, name = ?, last_modified = ?, hash = ?, metadata = ?, data = ?
+		SET type = ?, name = ?, last_modified = ?, hash = ?, metadata = ?, data = ?, fields = ?, deleted_at = ?
 		WHERE uuid = ?
 	`
 
-	_, err := s.db.ExecContext(ctx, query,
+	fields, err := encodeFields(secret.Fields)
+	if err != nil {
+		return err
+	}
+
+	stored, err := s.secretState(ctx, secret.UUID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if stored.isVersionOf(secret) {
+		if err := s.archiveSecret(ctx, tx, secret.UUID); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, query,
 		secret.Type,
 		secret.Name,
 		secret.LastModified,
 		secret.Hash,
 		secret.Metadata,
-		secret.Data,
+		s.encodeData(secret),
+		fields,
+		deletedAt(secret),
 		secret.UUID,
 	)
 	if err != nil {
 		return err
 	}
 
+	if err := replaceSecretTags(ctx, tx, secret.UUID, secret.Tags); err != nil {
+		return err
+	}
+
+	if err := s.indexSecret(ctx, tx, secret); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
 	s.markDirty()
 
 	return nil
 }
 
 func (s *SQLiteStorage) DeleteSecret(ctx context.Context, uuid string) error {
-	query := `DELETE FROM secrets WHERE uuid = ?`
-	_, err := s.db.ExecContext(ctx, query, uuid)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := s.archiveSecret(ctx, tx, uuid); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM secrets WHERE uuid = ?`, uuid)
 	if err != nil {
 		return err
 	}
 
+	if err := replaceSecretTags(ctx, tx, uuid, nil); err != nil {
+		return err
+	}
+
+	if err := s.unindexSecret(ctx, tx, uuid); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
 	s.markDirty()
 
 	return nil
 }
 
+// ListSecrets returns the secrets not in the trash, see ListTrash.
 func (s *SQLiteStorage) ListSecrets(ctx context.Context) ([]*types.LocalSecret, error) {
+	return s.listSecrets(ctx, `deleted_at IS NULL`, `last_modified DESC`)
+}
+
+// listSecrets returns the secrets matching where, without their data.
+func (s *SQLiteStorage) listSecrets(ctx context.Context, where string, orderBy string) ([]*types.LocalSecret, error) {
 	query := `
-		SELECT uuid, type, name, last_modified, hash, metadata
+		SELECT uuid, type, name, last_modified, hash, metadata, deleted_at
 		FROM secrets
-		ORDER BY last_modified DESC
-	`
+		WHERE ` + where + `
+		ORDER BY ` + orderBy
 
 	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
@@ -200,6 +300,7 @@ func (s *SQLiteStorage) ListSecrets(ctx context.Context) ([]*types.LocalSecret,
 	for rows.Next() {
 		secret := &types.LocalSecret{}
 
+		var deleted sql.NullTime
 		err := rows.Scan(
 			&secret.UUID,
 			&secret.Type,
@@ -209,13 +310,158 @@ func (s *SQLiteStorage) ListSecrets(ctx context.Context) ([]*types.LocalSecret,
 			// NOTE: Do not fetch data for listing
 			// &secret.Data,
 			&secret.Metadata,
+			&deleted,
 		)
 		if err != nil {
 			return nil, err
 		}
+		secret.DeletedAt = deleted.Time
 
 		secrets = append(secrets, secret)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tags, err := s.listTags(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, secret := range secrets {
+		secret.Tags = tags[secret.UUID]
+	}
 
 	return secrets, nil
 }
+
+// encodeData returns the data of the secret as the data column stores it.
+func (s *SQLiteStorage) encodeData(secret *types.LocalSecret) []byte {
+	if !s.compress {
+		return secret.Data
+	}
+	return secret.CompressedData()
+}
+
+// deletedAt returns when the secret was trashed as the deleted_at column
+// stores it, NULL when it is not in the trash.
+func deletedAt(secret *types.LocalSecret) any {
+	if !secret.InTrash() {
+		return nil
+	}
+	return secret.DeletedAt
+}
+
+// encodeFields stores custom fields as JSON, NULL when there are none.
+func encodeFields(fields []types.CustomField) (any, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal custom fields: %w", err)
+	}
+	return string(data), nil
+}
+
+func decodeFields(data sql.NullString) ([]types.CustomField, error) {
+	if !data.Valid {
+		return nil, nil
+	}
+
+	var fields []types.CustomField
+	if err := json.Unmarshal([]byte(data.String), &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse custom fields: %w", err)
+	}
+	return fields, nil
+}
+
+// listTags returns the sorted tags of the secret, or of all secrets when
+// secretID is empty, keyed by secret UUID.
+func (s *SQLiteStorage) listTags(ctx context.Context, secretID string) (map[string][]string, error) {
+	query := `
+		SELECT secret_uuid, tag
+		FROM secret_tags
+		WHERE ? = '' OR secret_uuid = ?
+		ORDER BY secret_uuid, tag
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, secretID, secretID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	tags := make(map[string][]string)
+	for rows.Next() {
+		var uuid, tag string
+		if err := rows.Scan(&uuid, &tag); err != nil {
+			return nil, err
+		}
+		tags[uuid] = append(tags[uuid], tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// replaceSecretTags sets the tags of the secret to exactly tags.
+func replaceSecretTags(ctx context.Context, tx *sql.Tx, secretID string, tags []string) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM secret_tags WHERE secret_uuid = ?`, secretID)
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		_, err := tx.ExecContext(ctx, `INSERT INTO secret_tags (secret_uuid, tag) VALUES (?, ?)`, secretID, tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *SQLiteStorage) GetSyncManifest(ctx context.Context, target string) (*types.SyncManifest, error) {
+	query := `SELECT manifest FROM sync_targets WHERE target = ?`
+
+	var data []byte
+	err := s.db.QueryRowContext(ctx, query, target).Scan(&data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.NewSyncManifest(), nil
+		}
+		return nil, err
+	}
+
+	manifest := types.NewSyncManifest()
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse sync manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+func (s *SQLiteStorage) SaveSyncManifest(ctx context.Context, target string, manifest *types.SyncManifest) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sync manifest: %w", err)
+	}
+
+	query := `
+		INSERT INTO sync_targets (target, manifest) VALUES (?, ?)
+		ON CONFLICT (target) DO UPDATE SET manifest = excluded.manifest
+	`
+
+	_, err = s.db.ExecContext(ctx, query, target, data)
+	if err != nil {
+		return err
+	}
+
+	s.markDirty()
+
+	return nil
+}
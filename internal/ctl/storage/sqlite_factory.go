@@ -7,11 +7,13 @@ import (
 	"path/filepath"
 
 	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
 )
 
-func initializeSQLiteStorage(ctx context.Context, cryptor crypto.Cryptor, dbPath string) error {
+func initializeSQLiteStorage(ctx context.Context, cryptor crypto.Cryptor, cfg Config) error {
+	dbPath := cfg.Path
 	if _, err := os.Stat(dbPath); err == nil {
-		return fmt.Errorf("vault already exists at %s", dbPath)
+		return errs.NewConflictError(fmt.Errorf("vault already exists at %s", dbPath))
 	}
 
 	dbDir := filepath.Dir(dbPath)
@@ -19,21 +21,44 @@ func initializeSQLiteStorage(ctx context.Context, cryptor crypto.Cryptor, dbPath
 		return fmt.Errorf("failed to create database directory: %w", err)
 	}
 
-	db, err := openInMemoryDB()
+	lock, err := lockVault(cfg)
 	if err != nil {
 		return err
 	}
 
+	// NOTE: Another process may have initialized the vault while we waited for the lock
+	if _, err := os.Stat(dbPath); err == nil {
+		return unlockVault(lock, errs.NewConflictError(fmt.Errorf("vault already exists at %s", dbPath)))
+	}
+
+	file, err := newVaultFile(cryptor, cfg)
+	if err != nil {
+		return unlockVault(lock, err)
+	}
+
+	db, err := openInMemoryDB()
+	if err != nil {
+		return unlockVault(lock, err)
+	}
+
 	storage := &SQLiteStorage{
 		db:      db,
 		cryptor: cryptor,
-		path:    dbPath,
+		file:    file,
+		lock:    lock,
 		isDirty: false,
 	}
 
-	err = storage.createSchema(ctx)
+	err = storage.migrate(ctx)
 	if err != nil {
-		return err
+		_ = db.Close()
+		return unlockVault(lock, err)
+	}
+
+	err = storage.openSearch(ctx)
+	if err != nil {
+		_ = db.Close()
+		return unlockVault(lock, err)
 	}
 
 	err = storage.Close()
@@ -44,35 +69,84 @@ func initializeSQLiteStorage(ctx context.Context, cryptor crypto.Cryptor, dbPath
 	return nil
 }
 
-func openSQLiteStorage(ctx context.Context, cryptor crypto.Cryptor, dbPath string) (*SQLiteStorage, error) {
+func openSQLiteStorage(ctx context.Context, cryptor crypto.Cryptor, cfg Config) (*SQLiteStorage, error) {
+	dbPath := cfg.Path
 	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("storage is not initialized: %s not found", dbPath)
+		return nil, fmt.Errorf("storage is not initialized, run keeperctl init: %w", &errs.NotFoundError{Entity: "vault", UUID: dbPath})
 	}
 
-	encryptedData, err := os.ReadFile(dbPath)
+	lock, err := lockVault(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read db file: %w", err)
+		return nil, err
 	}
 
-	decryptedData, err := cryptor.DecryptStorageData(encryptedData)
+	storage, err := loadSQLiteStorage(ctx, cryptor, cfg)
+	if err != nil {
+		return nil, unlockVault(lock, err)
+	}
+	storage.lock = lock
+
+	return storage, nil
+}
+
+func loadSQLiteStorage(ctx context.Context, cryptor crypto.Cryptor, cfg Config) (*SQLiteStorage, error) {
+	file, err := openVaultFile(cryptor, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt db: %w", err)
+		return nil, err
 	}
 
-	db, err := deserializeInMemoryDBFromBytes(ctx, decryptedData)
+	db, err := file.load(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	if err := db.Ping(); err != nil {
+		_ = db.Close()
 		return nil, err
 	}
 
 	storage := &SQLiteStorage{
-		db:      db,
-		cryptor: cryptor,
-		path:    dbPath,
-		isDirty: false,
+		db:       db,
+		cryptor:  cryptor,
+		file:     file,
+		isDirty:  false,
+		compress: cfg.Compress,
+		history:  cfg.History,
+
+		trashRetention: cfg.TrashRetention,
+	}
+
+	version, err := storage.schemaVersion(ctx)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	if version < latestSchemaVersion() {
+		if err := backupBeforeMigration(file, cfg.Path, version); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+
+	if err := storage.migrate(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	if err := storage.openSearch(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	if err := storage.pruneHistory(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	if err := storage.purgeTrash(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
 	}
 
 	return storage, nil
@@ -11,26 +11,22 @@ import (
 
 const sqliteMainDB = "main"
 
+// openInMemoryDB opens an empty in-memory database. Every connection to
+// ":memory:" is a separate database, so the pool is limited to one.
 func openInMemoryDB() (*sql.DB, error) {
 	db, err := sql.Open("sqlite3", ":memory:")
 	if err != nil {
 		return nil, err
 	}
+	db.SetMaxOpenConns(1)
 	return db, err
 }
 
-func deserializeInMemoryDBFromBytes(ctx context.Context, data []byte) (*sql.DB, error) {
-	db, err := openInMemoryDB()
-	if err != nil {
-		return nil, err
-	}
-
+// withSQLiteConn runs fn on the single connection of an in-memory database.
+func withSQLiteConn(ctx context.Context, db *sql.DB, fn func(conn *sqlite3.SQLiteConn) error) error {
 	conn, err := db.Conn(ctx)
 	if err != nil {
-		if closeErr := db.Close(); closeErr != nil {
-			log.Printf("Error closing database during error handling: %v", closeErr)
-		}
-		return nil, err
+		return err
 	}
 	defer func() {
 		if err := conn.Close(); err != nil {
@@ -38,16 +34,53 @@ func deserializeInMemoryDBFromBytes(ctx context.Context, data []byte) (*sql.DB,
 		}
 	}()
 
-	err = conn.Raw(func(driverConn any) error {
+	return conn.Raw(func(driverConn any) error {
 		sqliteConn, ok := driverConn.(*sqlite3.SQLiteConn)
 		if !ok {
 			return fmt.Errorf("driver connection is not SQLiteConn")
 		}
+		return fn(sqliteConn)
+	})
+}
+
+// deserializeInMemoryDBFromBytes loads data into a new in-memory database.
+// NOTE: The driver deserializes without SQLITE_DESERIALIZE_RESIZEABLE, so such
+// a database cannot grow. The image is loaded into a scratch database and
+// copied page by page into a regular, growable one.
+func deserializeInMemoryDBFromBytes(ctx context.Context, data []byte) (*sql.DB, error) {
+	scratch, err := openInMemoryDB()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := scratch.Close(); err != nil {
+			log.Printf("Error closing scratch database: %v", err)
+		}
+	}()
+
+	db, err := openInMemoryDB()
+	if err != nil {
+		return nil, err
+	}
 
-		if err := sqliteConn.Deserialize(data, sqliteMainDB); err != nil {
+	err = withSQLiteConn(ctx, scratch, func(scratchConn *sqlite3.SQLiteConn) error {
+		if err := scratchConn.Deserialize(data, sqliteMainDB); err != nil {
 			return err
 		}
-		return nil
+
+		return withSQLiteConn(ctx, db, func(dbConn *sqlite3.SQLiteConn) error {
+			backup, err := dbConn.Backup(sqliteMainDB, scratchConn, sqliteMainDB)
+			if err != nil {
+				return err
+			}
+
+			if _, err := backup.Step(-1); err != nil {
+				_ = backup.Finish()
+				return err
+			}
+
+			return backup.Finish()
+		})
 	})
 	if err != nil {
 		if closeErr := db.Close(); closeErr != nil {
@@ -62,23 +95,8 @@ func deserializeInMemoryDBFromBytes(ctx context.Context, data []byte) (*sql.DB,
 func serializeInMemoryDBToBytes(ctx context.Context, db *sql.DB) ([]byte, error) {
 	var bytes []byte
 
-	conn, err := db.Conn(ctx)
-	if err != nil {
-		return nil, err
-	}
-	defer func() {
-		if err := conn.Close(); err != nil {
-			log.Printf("Error closing connection: %v", err)
-		}
-	}()
-
-	err = conn.Raw(func(driverConn any) error {
-		sqliteConn, ok := driverConn.(*sqlite3.SQLiteConn)
-		if !ok {
-			return fmt.Errorf("driver connection is not SQLiteConn")
-		}
-
-		data, err := sqliteConn.Serialize(sqliteMainDB)
+	err := withSQLiteConn(ctx, db, func(conn *sqlite3.SQLiteConn) error {
+		data, err := conn.Serialize(sqliteMainDB)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+)
+
+// ErrSchemaTooNew is returned for vaults written by a newer keeperctl.
+var ErrSchemaTooNew = errs.NewCorruptError(errors.New("vault schema is newer than this keeperctl supports"))
+
+// migration upgrades the vault schema to version. Statements must tolerate
+// vaults created before versioning, which already have some of the tables.
+type migration struct {
+	version     int
+	description string
+	statements  []string
+}
+
+// migrations are applied in order, each in its own transaction together
+// with the PRAGMA user_version bump. Never edit or reorder released entries.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "create secrets table",
+		statements: []string{`
+		CREATE TABLE IF NOT EXISTS secrets (
+			uuid TEXT PRIMARY KEY,
+			type TEXT NOT NULL,
+			name TEXT NOT NULL,
+			last_modified DATETIME NOT NULL,
+			hash TEXT NOT NULL,
+			metadata TEXT,
+			data BLOB NOT NULL
+		);
+		`},
+	},
+	{
+		version:     2,
+		description: "create sync state table",
+		statements: []string{`
+		CREATE TABLE IF NOT EXISTS sync_state (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			manifest BLOB NOT NULL
+		);
+		`},
+	},
+	{
+		version:     3,
+		description: "key sync state by target",
+		// NOTE: State kept before sync targets existed belongs to the server
+		statements: []string{`
+		CREATE TABLE IF NOT EXISTS sync_targets (
+			target TEXT PRIMARY KEY,
+			manifest BLOB NOT NULL
+		);
+		`, `
+		INSERT INTO sync_targets (target, manifest)
+		SELECT 'server', manifest FROM sync_state;
+		`, `
+		DROP TABLE sync_state;
+		`},
+	},
+	{
+		version:     4,
+		description: "create secret tags table",
+		statements: []string{`
+		CREATE TABLE IF NOT EXISTS secret_tags (
+			secret_uuid TEXT NOT NULL,
+			tag TEXT NOT NULL,
+			PRIMARY KEY (secret_uuid, tag)
+		);
+		`, `
+		CREATE INDEX IF NOT EXISTS secret_tags_tag ON secret_tags (tag);
+		`},
+	},
+	{
+		version:     5,
+		description: "add custom fields to secrets",
+		// NOTE: Fields are JSON, loaded with data only
+		statements: []string{`
+		ALTER TABLE secrets ADD COLUMN fields TEXT;
+		`},
+	},
+	{
+		version:     6,
+		description: "create secret attachments table",
+		// NOTE: Content is only loaded on request, listing reads the other columns
+		statements: []string{`
+		CREATE TABLE IF NOT EXISTS secret_attachments (
+			uuid TEXT PRIMARY KEY,
+			secret_uuid TEXT NOT NULL,
+			name TEXT NOT NULL,
+			mime_type TEXT NOT NULL,
+			size INTEGER NOT NULL,
+			last_modified DATETIME NOT NULL,
+			hash TEXT NOT NULL,
+			content BLOB NOT NULL
+		);
+		`, `
+		CREATE INDEX IF NOT EXISTS secret_attachments_secret ON secret_attachments (secret_uuid);
+		`},
+	},
+	{
+		version:     7,
+		description: "create secret versions table",
+		// NOTE: Versions outlive their secret until purged, tags are a JSON array
+		statements: []string{`
+		CREATE TABLE IF NOT EXISTS secret_versions (
+			secret_uuid TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			type TEXT NOT NULL,
+			name TEXT NOT NULL,
+			last_modified DATETIME NOT NULL,
+			hash TEXT NOT NULL,
+			metadata TEXT,
+			data BLOB NOT NULL,
+			fields TEXT,
+			tags TEXT NOT NULL,
+			archived_at DATETIME NOT NULL,
+			PRIMARY KEY (secret_uuid, version)
+		);
+		`},
+	},
+	{
+		version:     8,
+		description: "add trash to secrets",
+		// NOTE: Purged secrets are remembered so that sync erases their other copies
+		statements: []string{`
+		ALTER TABLE secrets ADD COLUMN deleted_at DATETIME;
+		`, `
+		CREATE TABLE IF NOT EXISTS purged_secrets (
+			uuid TEXT PRIMARY KEY,
+			purged_at DATETIME NOT NULL
+		);
+		`},
+	},
+}
+
+func latestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+func (s *SQLiteStorage) schemaVersion(ctx context.Context) (int, error) {
+	var version int
+	err := s.db.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// migrate brings the schema to the latest version and marks the vault dirty
+// when anything was applied.
+func (s *SQLiteStorage) migrate(ctx context.Context) error {
+	version, err := s.schemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if version > latestSchemaVersion() {
+		return fmt.Errorf("%w: version %d, supported %d", ErrSchemaTooNew, version, latestSchemaVersion())
+	}
+
+	for _, m := range migrations {
+		if m.version <= version {
+			continue
+		}
+
+		if err := s.applyMigration(ctx, m); err != nil {
+			return fmt.Errorf("failed to migrate vault to version %d (%s): %w", m.version, m.description, err)
+		}
+		s.markDirty()
+	}
+
+	return nil
+}
+
+func (s *SQLiteStorage) applyMigration(ctx context.Context, m migration) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, statement := range m.statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	// NOTE: PRAGMA does not accept bound parameters
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version = %d`, m.version)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// backupBeforeMigration keeps a copy of the encrypted vault as it was before
+// an upgrade from version, next to the vault.
+func backupBeforeMigration(file vaultFile, path string, version int) error {
+	backupPath := fmt.Sprintf("%s.pre-migration-v%d", path, version)
+	if err := file.snapshot(backupPath); err != nil {
+		return fmt.Errorf("failed to write pre-migration backup: %w", err)
+	}
+
+	return nil
+}
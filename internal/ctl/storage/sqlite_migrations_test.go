@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPlainCryptor(t *testing.T) crypto.Cryptor {
+	ctrl := gomock.NewController(t)
+	cryptor := crypto.NewMockCryptor(ctrl)
+	identity := func(data []byte) ([]byte, error) { return data, nil }
+	cryptor.EXPECT().EncryptStorageData(gomock.Any()).DoAndReturn(identity).AnyTimes()
+	cryptor.EXPECT().DecryptStorageData(gomock.Any()).DoAndReturn(identity).AnyTimes()
+	return cryptor
+}
+
+func writeRawVault(t *testing.T, path string, statements ...string) {
+	ctx := context.Background()
+
+	db, err := openInMemoryDB()
+	require.NoError(t, err)
+	defer db.Close()
+
+	for _, statement := range statements {
+		_, err := db.ExecContext(ctx, statement)
+		require.NoError(t, err)
+	}
+
+	data, err := serializeInMemoryDBToBytes(ctx, db)
+	require.NoError(t, err)
+
+	_, err = writeVaultFile(path, 0, data)
+	require.NoError(t, err)
+}
+
+func TestSQLiteStorage_Migrate(t *testing.T) {
+	ctx := context.Background()
+	cryptor := newPlainCryptor(t)
+
+	t.Run("new vault is at latest version", func(t *testing.T) {
+		cfg := Config{Path: filepath.Join(t.TempDir(), "vault.db")}
+		require.NoError(t, initializeSQLiteStorage(ctx, cryptor, cfg))
+
+		storage, err := openSQLiteStorage(ctx, cryptor, cfg)
+		require.NoError(t, err)
+		defer storage.Close()
+
+		version, err := storage.schemaVersion(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, latestSchemaVersion(), version)
+		assert.False(t, storage.isDirty)
+		assert.NoFileExists(t, cfg.Path+".pre-migration-v0")
+	})
+
+	t.Run("unversioned vault is upgraded", func(t *testing.T) {
+		cfg := Config{Path: filepath.Join(t.TempDir(), "vault.db")}
+		writeRawVault(t, cfg.Path,
+			`CREATE TABLE secrets (uuid TEXT PRIMARY KEY, type TEXT NOT NULL, name TEXT NOT NULL,
+				last_modified DATETIME NOT NULL, hash TEXT NOT NULL, metadata TEXT, data BLOB NOT NULL)`,
+			`INSERT INTO secrets VALUES ('id-1', 'text', 'note', '2025-01-01 00:00:00', 'h', '', x'00')`,
+		)
+
+		storage, err := openSQLiteStorage(ctx, cryptor, cfg)
+		require.NoError(t, err)
+
+		version, err := storage.schemaVersion(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, latestSchemaVersion(), version)
+		assert.True(t, storage.isDirty, "migrated vault must be persisted")
+		assert.FileExists(t, cfg.Path+".pre-migration-v0")
+
+		secret, err := storage.GetSecret(ctx, "id-1", false)
+		require.NoError(t, err)
+		assert.Equal(t, "note", secret.Name)
+
+		manifest, err := storage.GetSyncManifest(ctx, "server")
+		require.NoError(t, err)
+		assert.Zero(t, manifest.Revision)
+
+		require.NoError(t, storage.Close())
+
+		storage, err = openSQLiteStorage(ctx, cryptor, cfg)
+		require.NoError(t, err)
+		assert.False(t, storage.isDirty)
+		require.NoError(t, storage.Close())
+	})
+
+	t.Run("sync state is moved to the server target", func(t *testing.T) {
+		cfg := Config{Path: filepath.Join(t.TempDir(), "vault.db")}
+		writeRawVault(t, cfg.Path,
+			`CREATE TABLE secrets (uuid TEXT PRIMARY KEY, type TEXT NOT NULL, name TEXT NOT NULL,
+				last_modified DATETIME NOT NULL, hash TEXT NOT NULL, metadata TEXT, data BLOB NOT NULL)`,
+			`CREATE TABLE sync_state (id INTEGER PRIMARY KEY CHECK (id = 1), manifest BLOB NOT NULL)`,
+			`INSERT INTO sync_state VALUES (1, '{"revision": 4, "secrets": {}}')`,
+			`PRAGMA user_version = 2`,
+		)
+
+		storage, err := openSQLiteStorage(ctx, cryptor, cfg)
+		require.NoError(t, err)
+		defer storage.Close()
+
+		manifest, err := storage.GetSyncManifest(ctx, "server")
+		require.NoError(t, err)
+		assert.Equal(t, uint64(4), manifest.Revision)
+
+		manifest, err = storage.GetSyncManifest(ctx, "bundle:other")
+		require.NoError(t, err)
+		assert.Zero(t, manifest.Revision)
+	})
+
+	t.Run("newer vault is refused", func(t *testing.T) {
+		cfg := Config{Path: filepath.Join(t.TempDir(), "vault.db")}
+		writeRawVault(t, cfg.Path, `PRAGMA user_version = 1000`)
+
+		_, err := openSQLiteStorage(ctx, cryptor, cfg)
+		assert.ErrorIs(t, err, ErrSchemaTooNew)
+		assert.Equal(t, errs.ExitCorrupt, errs.ExitCode(err))
+	})
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/etoneja/go-keeper/internal/ctl/compress"
+)
+
+// DataStats sums up what the vault stores. PlainSize is the size of the
+// secret data uncompressed, StoredSize as the vault holds it.
+type DataStats struct {
+	Secrets        int
+	Compressed     int
+	PlainSize      int64
+	StoredSize     int64
+	Attachments    int
+	AttachmentSize int64
+}
+
+func (s *SQLiteStorage) DataStats(ctx context.Context) (*DataStats, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT uuid, data FROM secrets`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	stats := &DataStats{}
+	for rows.Next() {
+		var uuid string
+		var data []byte
+		if err := rows.Scan(&uuid, &data); err != nil {
+			return nil, err
+		}
+
+		stats.Secrets++
+		stats.StoredSize += int64(len(data))
+		if !compress.IsCompressed(data) {
+			stats.PlainSize += int64(len(data))
+			continue
+		}
+
+		plain, err := compress.Decompress(data)
+		if err != nil {
+			return nil, fmt.Errorf("secret %s: %w", uuid, err)
+		}
+		stats.Compressed++
+		stats.PlainSize += int64(len(plain))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = s.db.QueryRowContext(ctx, `SELECT COUNT(*), COALESCE(SUM(size), 0) FROM secret_attachments`).
+		Scan(&stats.Attachments, &stats.AttachmentSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteStorage_Compression(t *testing.T) {
+	ctx := context.Background()
+	cryptor := crypto.NewCryptor("password", "login")
+	cfg := Config{Path: filepath.Join(t.TempDir(), "vault"), Compress: true}
+	require.NoError(t, initializeSQLiteStorage(ctx, cryptor, cfg))
+
+	textSecret := func(id string) *types.LocalSecret {
+		return &types.LocalSecret{
+			UUID:         id,
+			Type:         constants.SecretTypeText,
+			Name:         id,
+			LastModified: time.Now().UTC(),
+			Hash:         "hash-" + id,
+			Data:         []byte(`{"content":"` + strings.Repeat("key = value\\n", 200) + `"}`),
+		}
+	}
+
+	storage, err := openSQLiteStorage(ctx, cryptor, cfg)
+	require.NoError(t, err)
+	compressed := textSecret("compressed")
+	_, err = storage.CreateSecret(ctx, compressed)
+	require.NoError(t, err)
+	require.NoError(t, storage.Close())
+
+	// NOTE: Turning compression off keeps reading what was written compressed
+	cfg.Compress = false
+	storage, err = openSQLiteStorage(ctx, cryptor, cfg)
+	require.NoError(t, err)
+	defer storage.Close()
+
+	plain := textSecret("plain")
+	_, err = storage.CreateSecret(ctx, plain)
+	require.NoError(t, err)
+
+	loaded, err := storage.GetSecret(ctx, compressed.UUID, true)
+	require.NoError(t, err)
+	assert.Equal(t, compressed.Data, loaded.Data)
+
+	stats, err := storage.DataStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Secrets)
+	assert.Equal(t, 1, stats.Compressed)
+	assert.Equal(t, int64(len(compressed.Data)+len(plain.Data)), stats.PlainSize)
+	assert.Less(t, stats.StoredSize, int64(len(plain.Data))+int64(len(compressed.Data))/4)
+}
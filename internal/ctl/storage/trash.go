@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+// ListTrash returns the secrets in the trash, most recently deleted first,
+// without their data.
+func (s *SQLiteStorage) ListTrash(ctx context.Context) ([]*types.LocalSecret, error) {
+	return s.listSecrets(ctx, `deleted_at IS NOT NULL`, `deleted_at DESC`)
+}
+
+// ListPurgedSecrets returns the UUIDs of the secrets purged from the vault
+// and not created again since.
+func (s *SQLiteStorage) ListPurgedSecrets(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT uuid FROM purged_secrets ORDER BY uuid`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// purgeTrash purges the secrets that have been in the trash longer than the
+// retention, see Config.TrashRetention.
+func (s *SQLiteStorage) purgeTrash(ctx context.Context) error {
+	if s.trashRetention <= 0 {
+		return nil
+	}
+
+	cutoff := time.Now().UTC().Add(-s.trashRetention)
+	expired, err := s.listSecrets(ctx, `deleted_at IS NOT NULL`, `deleted_at`)
+	if err != nil {
+		return err
+	}
+
+	for _, secret := range expired {
+		if !secret.DeletedAt.Before(cutoff) {
+			break
+		}
+		if err := s.PurgeSecret(ctx, secret.UUID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteStorage_Trash(t *testing.T) {
+	ctx := context.Background()
+	cryptor := crypto.NewCryptor("password", "login")
+	cfg := Config{
+		Path:    filepath.Join(t.TempDir(), "vault"),
+		History: HistoryRetention{Versions: 5},
+	}
+	require.NoError(t, initializeSQLiteStorage(ctx, cryptor, cfg))
+
+	storage, err := openSQLiteStorage(ctx, cryptor, cfg)
+	require.NoError(t, err)
+
+	newSecret := func(id string) *types.LocalSecret {
+		secret := &types.LocalSecret{
+			UUID:         id,
+			Type:         constants.SecretTypeText,
+			Name:         id,
+			LastModified: time.Now().UTC().Add(-time.Hour).Truncate(time.Microsecond),
+			Hash:         "hash-" + id,
+			Data:         []byte(`{"content":"` + id + `"}`),
+		}
+		_, err := storage.CreateSecret(ctx, secret)
+		require.NoError(t, err)
+		return secret
+	}
+
+	kept := newSecret("kept")
+	trashed := newSecret("trashed")
+
+	trashed.DeletedAt = time.Now().UTC().Truncate(time.Microsecond)
+	trashed.LastModified = trashed.DeletedAt
+	require.NoError(t, storage.UpdateSecret(ctx, trashed))
+
+	secrets, err := storage.ListSecrets(ctx)
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+	assert.Equal(t, kept.UUID, secrets[0].UUID)
+
+	trash, err := storage.ListTrash(ctx)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	assert.Equal(t, trashed.UUID, trash[0].UUID)
+	assert.True(t, trash[0].DeletedAt.Equal(trashed.DeletedAt))
+
+	loaded, err := storage.GetSecret(ctx, trashed.UUID, true)
+	require.NoError(t, err)
+	assert.True(t, loaded.InTrash())
+	assert.Equal(t, trashed.Data, loaded.Data)
+
+	// NOTE: Moving to the trash does not change the content, it is not a version
+	versions, err := storage.ListSecretVersions(ctx, trashed.UUID)
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+
+	require.NoError(t, storage.PurgeSecret(ctx, trashed.UUID))
+
+	purged, err := storage.ListPurgedSecrets(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{trashed.UUID}, purged)
+
+	// NOTE: A secret created again is no longer purged
+	trashed = newSecret("trashed")
+	purged, err = storage.ListPurgedSecrets(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, purged)
+
+	trashed.DeletedAt = time.Now().UTC().Add(-48 * time.Hour)
+	require.NoError(t, storage.UpdateSecret(ctx, trashed))
+	require.NoError(t, storage.Close())
+
+	// NOTE: Secrets in the trash longer than the retention are purged on open
+	cfg.TrashRetention = 24 * time.Hour
+	storage, err = openSQLiteStorage(ctx, cryptor, cfg)
+	require.NoError(t, err)
+	defer storage.Close()
+
+	_, err = storage.GetSecret(ctx, trashed.UUID, false)
+	assert.True(t, errs.IsNotFound(err))
+
+	_, err = storage.GetSecret(ctx, kept.UUID, false)
+	require.NoError(t, err)
+
+	purged, err = storage.ListPurgedSecrets(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{trashed.UUID}, purged)
+}
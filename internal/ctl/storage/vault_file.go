@@ -0,0 +1,281 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/etoneja/go-keeper/internal/ctl/fsutil"
+)
+
+// ErrVaultChanged is returned when the vault file was replaced by someone
+// else since it was loaded, so writing it back would lose their changes.
+var ErrVaultChanged = errs.NewConflictError(errors.New("vault file changed on disk since it was loaded"))
+
+// vaultFile persists the in-memory SQLite database of a vault.
+type vaultFile interface {
+	// load returns a new in-memory database with the stored vault.
+	load(ctx context.Context) (*sql.DB, error)
+	// save stores db. It fails with ErrVaultChanged when the vault changed
+	// on disk since it was loaded or last saved.
+	save(ctx context.Context, db *sql.DB) error
+	// snapshot copies the stored vault, as is, to dst.
+	snapshot(dst string) error
+}
+
+// newVaultFile returns the file for a vault of the configured backend.
+func newVaultFile(cryptor crypto.Cryptor, cfg Config) (vaultFile, error) {
+	switch cfg.Backend {
+	case "", BackendBlob:
+		return &blobVaultFile{cryptor: cryptor, path: cfg.Path, backups: cfg.Backups}, nil
+	case BackendPaged:
+		return &pagedVaultFile{cryptor: cryptor, path: cfg.Path}, nil
+	case BackendFiles:
+		return &dirVaultFile{cryptor: cryptor, path: cfg.Path}, nil
+	default:
+		return nil, errs.Validationf("unknown storage backend %q", cfg.Backend)
+	}
+}
+
+// detectBackend returns the backend an existing vault file was written by.
+func detectBackend(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read db file: %w", err)
+	}
+	if info.IsDir() {
+		return BackendFiles, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read db file: %w", err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			log.Printf("Error closing db file: %v", err)
+		}
+	}()
+
+	magic := make([]byte, len(pagedMagic))
+	_, err = io.ReadFull(file, magic)
+	if err == nil && string(magic) == pagedMagic {
+		return BackendPaged, nil
+	}
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", fmt.Errorf("failed to read db file: %w", err)
+	}
+
+	return BackendBlob, nil
+}
+
+// openVaultFile returns the file for an existing vault, whatever backend
+// is configured for new ones.
+func openVaultFile(cryptor crypto.Cryptor, cfg Config) (vaultFile, error) {
+	backend, err := detectBackend(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Backend = backend
+	return newVaultFile(cryptor, cfg)
+}
+
+// blobVaultFile keeps the whole image as one encrypted file, rewritten and
+// re-encrypted with a fresh salt on every save.
+type blobVaultFile struct {
+	cryptor     crypto.Cryptor
+	path        string
+	backups     int
+	fingerprint vaultFingerprint
+}
+
+func (f *blobVaultFile) load(ctx context.Context) (*sql.DB, error) {
+	image, fingerprint, err := readVaultFile(f.cryptor, f.path)
+	if err != nil {
+		return nil, err
+	}
+	f.fingerprint = fingerprint
+
+	return deserializeInMemoryDBFromBytes(ctx, image)
+}
+
+func (f *blobVaultFile) save(ctx context.Context, db *sql.DB) error {
+	image, err := serializeInMemoryDBToBytes(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	encryptedData, err := f.cryptor.EncryptStorageData(image)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt db: %w", err)
+	}
+
+	if err := checkVaultUnchanged(f.path, f.fingerprint); err != nil {
+		return err
+	}
+
+	fingerprint, err := writeVaultFile(f.path, f.backups, encryptedData)
+	if err != nil {
+		return err
+	}
+	f.fingerprint = fingerprint
+
+	return nil
+}
+
+func (f *blobVaultFile) snapshot(dst string) error {
+	return copyVaultFile(f.path, dst)
+}
+
+func copyVaultFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to read db file: %w", err)
+	}
+
+	return fsutil.WriteFileAtomic(dst, data, 0600)
+}
+
+// vaultFingerprint identifies the vault file content a handle was loaded
+// from. Without a digest only size and mtime are compared.
+type vaultFingerprint struct {
+	exists    bool
+	size      int64
+	modTime   time.Time
+	hasDigest bool
+	digest    [sha256.Size]byte
+}
+
+func lockVault(cfg Config) (*fsutil.FileLock, error) {
+	lock, err := fsutil.LockFile(cfg.Path+".lock", cfg.LockTimeout)
+	if errors.Is(err, fsutil.ErrLocked) {
+		return nil, fmt.Errorf("vault is in use by another keeperctl process: %w", err)
+	}
+	return lock, err
+}
+
+func unlockVault(lock *fsutil.FileLock, err error) error {
+	if unlockErr := lock.Unlock(); unlockErr != nil && err == nil {
+		return fmt.Errorf("failed to unlock vault: %w", unlockErr)
+	}
+	return err
+}
+
+func fingerprintVaultFile(path string, encryptedData []byte) (vaultFingerprint, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return vaultFingerprint{}, nil
+	}
+	if err != nil {
+		return vaultFingerprint{}, err
+	}
+
+	return vaultFingerprint{
+		exists:    true,
+		size:      info.Size(),
+		modTime:   info.ModTime(),
+		hasDigest: true,
+		digest:    sha256.Sum256(encryptedData),
+	}, nil
+}
+
+func statVaultFile(path string) (vaultFingerprint, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return vaultFingerprint{}, nil
+	}
+	if err != nil {
+		return vaultFingerprint{}, err
+	}
+
+	return vaultFingerprint{exists: true, size: info.Size(), modTime: info.ModTime()}, nil
+}
+
+// checkVaultUnchanged fails with ErrVaultChanged when path no longer holds
+// the content described by fingerprint. Size and mtime are compared first,
+// the content only when they differ and a digest is known.
+func checkVaultUnchanged(path string, fingerprint vaultFingerprint) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		if fingerprint.exists {
+			return fmt.Errorf("%w: %s was removed", ErrVaultChanged, path)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !fingerprint.exists {
+		return fmt.Errorf("%w: %s was created", ErrVaultChanged, path)
+	}
+
+	if info.Size() == fingerprint.size && info.ModTime().Equal(fingerprint.modTime) {
+		return nil
+	}
+
+	if !fingerprint.hasDigest {
+		return fmt.Errorf("%w: %s", ErrVaultChanged, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256(data)
+	if !bytes.Equal(digest[:], fingerprint.digest[:]) {
+		return fmt.Errorf("%w: %s", ErrVaultChanged, path)
+	}
+
+	return nil
+}
+
+// vaultDecryptError reports a vault that does not open with the configured
+// password, which is far more often a wrong password than a damaged file.
+func vaultDecryptError(err error) error {
+	return errs.NewAuthError(fmt.Errorf("failed to decrypt db, wrong password or corrupted vault: %w", err))
+}
+
+func readVaultFile(cryptor crypto.Cryptor, path string) ([]byte, vaultFingerprint, error) {
+	encryptedData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, vaultFingerprint{}, fmt.Errorf("failed to read db file: %w", err)
+	}
+
+	fingerprint, err := fingerprintVaultFile(path, encryptedData)
+	if err != nil {
+		return nil, vaultFingerprint{}, err
+	}
+
+	decryptedData, err := cryptor.DecryptStorageData(encryptedData)
+	if err != nil {
+		return nil, vaultFingerprint{}, vaultDecryptError(err)
+	}
+
+	return decryptedData, fingerprint, nil
+}
+
+// writeVaultFile keeps the current vault as the newest generation and
+// atomically replaces it with encryptedData.
+func writeVaultFile(path string, backups int, encryptedData []byte) (vaultFingerprint, error) {
+	if err := fsutil.RotateGenerations(path, backups); err != nil {
+		return vaultFingerprint{}, fmt.Errorf("failed to rotate vault generations: %w", err)
+	}
+
+	if err := fsutil.WriteFileAtomic(path, encryptedData, 0600); err != nil {
+		return vaultFingerprint{}, fmt.Errorf("failed to write db file: %w", err)
+	}
+
+	return fingerprintVaultFile(path, encryptedData)
+}
@@ -0,0 +1,86 @@
+package ctl
+
+import (
+	"github.com/etoneja/go-keeper/internal/ctl/config"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+)
+
+// The choose*Action methods ask the user unless the profile sets a sync
+// strategy, see config.SyncStrategies. Strategies never delete and never take
+// the remote copy of a secret flagged as a possible rollback. Trash state is
+// carried over without asking: a secret moved to the trash on one side goes
+// to the trash on the other, and a purged one is purged there.
+
+func (s *VaultService) syncStrategy() string {
+	if s.cfg.SyncStrategy == "" {
+		return config.SyncPrompt
+	}
+	return s.cfg.SyncStrategy
+}
+
+func (s *VaultService) chooseLocalOnlyAction(localSecret *types.LocalSecret, rollbackReason string, purged bool) (ActionType, error) {
+	switch {
+	case purged:
+		return ActionPurgeLocal, nil
+	case localSecret.InTrash() && rollbackReason == "":
+		return ActionCreateRemote, nil
+	case s.syncStrategy() != config.SyncPrompt:
+		return ActionCreateRemote, nil
+	case rollbackReason != "":
+		return PromptForRollbackLocalOnlyAction(localSecret, rollbackReason)
+	default:
+		return PromptForLocalOnlyAction(localSecret)
+	}
+}
+
+func (s *VaultService) chooseRemoteOnlyAction(remoteSecret *types.RemoteSecret, rollbackReason string, purged bool) (ActionType, error) {
+	switch {
+	case purged:
+		return ActionPurgeRemote, nil
+	case remoteSecret.InTrash() && rollbackReason == "":
+		return ActionCreateLocal, nil
+	case s.syncStrategy() != config.SyncPrompt && rollbackReason != "":
+		return ActionSkip, nil
+	case s.syncStrategy() != config.SyncPrompt:
+		return ActionCreateLocal, nil
+	case rollbackReason != "":
+		return PromptForRollbackRemoteOnlyAction(remoteSecret, rollbackReason)
+	default:
+		return PromptForRemoteOnlyAction(remoteSecret)
+	}
+}
+
+func (s *VaultService) chooseCheckPairAction(checkPair *types.SecretCheckPair, rollbackReason string) (ActionType, error) {
+	// NOTE: The newer move in or out of the trash wins, the trash on a tie
+	if checkPair.TrashOnly() && rollbackReason == "" {
+		local, remote := checkPair.Local.LastModified, checkPair.Remote.LastModified
+		if remote.After(local) || (remote.Equal(local) && checkPair.Remote.InTrash()) {
+			return ActionReplaceLocal, nil
+		}
+		return ActionReplaceRemote, nil
+	}
+
+	switch s.syncStrategy() {
+	case config.SyncLocal:
+		return ActionReplaceRemote, nil
+	case config.SyncRemote:
+		return takeRemoteUnlessRollback(rollbackReason), nil
+	case config.SyncNewest:
+		if checkPair.Remote.LastModified.After(checkPair.Local.LastModified) {
+			return takeRemoteUnlessRollback(rollbackReason), nil
+		}
+		return ActionReplaceRemote, nil
+	}
+
+	if rollbackReason != "" {
+		return PromptForRollbackCheckPairAction(checkPair, rollbackReason)
+	}
+	return PromptForConflictCheckPairAction(checkPair)
+}
+
+func takeRemoteUnlessRollback(rollbackReason string) ActionType {
+	if rollbackReason != "" {
+		return ActionSkip
+	}
+	return ActionReplaceLocal
+}
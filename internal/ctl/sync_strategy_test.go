@@ -0,0 +1,85 @@
+package ctl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/config"
+	"github.com/etoneja/go-keeper/internal/ctl/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChooseCheckPairAction(t *testing.T) {
+	now := time.Now()
+	newerRemote := &types.SecretCheckPair{
+		Local:  &types.LocalSecret{UUID: "a", LastModified: now},
+		Remote: &types.RemoteSecret{UUID: "a", LastModified: now.Add(time.Minute)},
+	}
+
+	tests := []struct {
+		strategy string
+		rollback string
+		want     ActionType
+	}{
+		{strategy: config.SyncLocal, want: ActionReplaceRemote},
+		{strategy: config.SyncRemote, want: ActionReplaceLocal},
+		{strategy: config.SyncNewest, want: ActionReplaceLocal},
+		{strategy: config.SyncNewest, rollback: "is older than the server manifest", want: ActionSkip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy+tt.rollback, func(t *testing.T) {
+			service := NewVaultService(&config.Config{SyncStrategy: tt.strategy})
+
+			action, err := service.chooseCheckPairAction(newerRemote, tt.rollback)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, action)
+		})
+	}
+
+	t.Run("remote only rollback is skipped", func(t *testing.T) {
+		service := NewVaultService(&config.Config{SyncStrategy: config.SyncLocal})
+
+		action, err := service.chooseRemoteOnlyAction(newerRemote.Remote, "was deleted locally", false)
+		require.NoError(t, err)
+		assert.Equal(t, ActionSkip, action)
+	})
+	t.Run("newer trash state wins", func(t *testing.T) {
+		service := NewVaultService(&config.Config{SyncStrategy: config.SyncLocal})
+		trashedRemote := &types.SecretCheckPair{
+			Local:  &types.LocalSecret{UUID: "a", Hash: "h", LastModified: now},
+			Remote: &types.RemoteSecret{UUID: "a", Hash: "h", LastModified: now.Add(time.Minute), DeletedAt: now.Add(time.Minute)},
+		}
+
+		action, err := service.chooseCheckPairAction(trashedRemote, "")
+		require.NoError(t, err)
+		assert.Equal(t, ActionReplaceLocal, action)
+
+		trashedRemote.Remote.LastModified = now
+		action, err = service.chooseCheckPairAction(trashedRemote, "")
+		require.NoError(t, err)
+		assert.Equal(t, ActionReplaceLocal, action)
+
+		trashedRemote.Local.LastModified = now.Add(time.Hour)
+		action, err = service.chooseCheckPairAction(trashedRemote, "")
+		require.NoError(t, err)
+		assert.Equal(t, ActionReplaceRemote, action)
+	})
+
+	t.Run("trash is carried over", func(t *testing.T) {
+		service := NewVaultService(&config.Config{})
+
+		action, err := service.chooseRemoteOnlyAction(&types.RemoteSecret{UUID: "a", DeletedAt: now}, "", false)
+		require.NoError(t, err)
+		assert.Equal(t, ActionCreateLocal, action)
+
+		action, err = service.chooseRemoteOnlyAction(&types.RemoteSecret{UUID: "a", DeletedAt: now}, "", true)
+		require.NoError(t, err)
+		assert.Equal(t, ActionPurgeRemote, action)
+
+		action, err = service.chooseLocalOnlyAction(&types.LocalSecret{UUID: "a", DeletedAt: now}, "", true)
+		require.NoError(t, err)
+		assert.Equal(t, ActionPurgeLocal, action)
+	})
+}
@@ -0,0 +1,306 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/google/uuid"
+)
+
+// Attachment is a file attached to a secret. Attachments are never changed,
+// so they sync apart from their secret: editing the secret does not upload
+// them again, and replacing a file is deleting one attachment and adding another.
+type Attachment struct {
+	UUID         string
+	SecretUUID   string
+	Name         string
+	MIMEType     string
+	Size         int64
+	LastModified time.Time
+	Hash         string
+	// Content is loaded on request only
+	Content []byte
+}
+
+// RemoteAttachment is an attachment as the sync target holds it. The secret
+// UUID is stored in the clear so that the target can list attachments by
+// secret, it is repeated inside the sealed data and checked on download.
+type RemoteAttachment struct {
+	UUID         string
+	SecretUUID   string
+	LastModified time.Time
+	Hash         string
+	// Data is the sealed name, MIME type and size
+	Data []byte
+	// Header opens the sealed content, Chunks chunks of Size bytes in total
+	Header []byte
+	Chunks int
+	Size   int64
+}
+
+// ChunkSource returns the sealed chunks of attachment content by index.
+type ChunkSource interface {
+	Chunk(index int) ([]byte, error)
+}
+
+// ChunkReader reads the sealed chunks of attachment content in order,
+// returning io.EOF after the last one.
+type ChunkReader interface {
+	ReadChunk() ([]byte, error)
+	Close() error
+}
+
+// sliceChunkReader reads chunks held in memory.
+type sliceChunkReader struct {
+	chunks [][]byte
+}
+
+// NewChunkReader returns a reader of chunks held in memory.
+func NewChunkReader(chunks [][]byte) ChunkReader {
+	return &sliceChunkReader{chunks: chunks}
+}
+
+func (r *sliceChunkReader) ReadChunk() ([]byte, error) {
+	if len(r.chunks) == 0 {
+		return nil, io.EOF
+	}
+	chunk := r.chunks[0]
+	r.chunks = r.chunks[1:]
+	return chunk, nil
+}
+
+func (r *sliceChunkReader) Close() error {
+	return nil
+}
+
+type attachmentContainer struct {
+	SecretUUID string `json:"secret_uuid"`
+	Name       string `json:"name"`
+	MIMEType   string `json:"mime_type"`
+	Size       int64  `json:"size"`
+	// Content is only set by attachments synced before content was chunked
+	Content []byte `json:"content,omitempty"`
+}
+
+// attachmentChunks seals attachment content chunk by chunk on request.
+type attachmentChunks struct {
+	sealer  crypto.StreamSealer
+	content []byte
+	chunks  int
+}
+
+func (c *attachmentChunks) Chunk(index int) ([]byte, error) {
+	if index < 0 || index >= c.chunks {
+		return nil, fmt.Errorf("chunk %d out of range", index)
+	}
+
+	start := index * constants.AttachmentChunkSize
+	end := min(start+constants.AttachmentChunkSize, len(c.content))
+
+	return c.sealer.Seal(index, c.content[start:end], index == c.chunks-1)
+}
+
+// attachmentChunkCount is the number of chunks content of size is sealed in,
+// empty content takes one.
+func attachmentChunkCount(size int64) int {
+	return max(1, int((size+constants.AttachmentChunkSize-1)/constants.AttachmentChunkSize))
+}
+
+// NewAttachment returns a new attachment of the secret.
+func NewAttachment(cryptor crypto.Cryptor, secretID, name, mimeType string, content []byte) (*Attachment, error) {
+	name = strings.TrimSpace(name)
+	if err := validateAttachment(name, int64(len(content))); err != nil {
+		return nil, errs.NewValidationError(err)
+	}
+
+	attachment := &Attachment{
+		UUID:         uuid.New().String(),
+		SecretUUID:   secretID,
+		Name:         name,
+		MIMEType:     mimeType,
+		Size:         int64(len(content)),
+		LastModified: time.Now().UTC().Truncate(time.Microsecond),
+		Content:      content,
+	}
+	attachment.RefreshHash(cryptor)
+
+	return attachment, nil
+}
+
+func validateAttachment(name string, size int64) error {
+	if name == "" {
+		return fmt.Errorf("attachment name must not be empty")
+	}
+	if len(name) > constants.MaxAttachmentNameLength {
+		return fmt.Errorf("attachment name is longer than %d characters", constants.MaxAttachmentNameLength)
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("attachment name %q must not contain slashes", name)
+	}
+	if size > constants.MaxAttachmentSize {
+		return fmt.Errorf("attachment too large: %d bytes (max: %d bytes)", size, constants.MaxAttachmentSize)
+	}
+	return nil
+}
+
+// RefreshHash computes the hash of the attachment. Content must be loaded.
+func (a *Attachment) RefreshHash(cryptor crypto.Cryptor) {
+	a.Hash = cryptor.CalculateDataHash(a.hashInput())
+}
+
+func (a *Attachment) hashInput() []byte {
+	input := a.Name + "\x00" + a.MIMEType + "\x00"
+	return append([]byte(input), a.Content...)
+}
+
+// ConvertAttachmentToRemoteAttachment seals the attachment, whose content
+// must be loaded, bound to its UUID. The content is sealed chunk by chunk as
+// the returned source is read.
+func ConvertAttachmentToRemoteAttachment(cryptor crypto.Cryptor, attachment *Attachment) (*RemoteAttachment, ChunkSource, error) {
+	container := &attachmentContainer{
+		SecretUUID: attachment.SecretUUID,
+		Name:       attachment.Name,
+		MIMEType:   attachment.MIMEType,
+		Size:       int64(len(attachment.Content)),
+	}
+
+	data, err := json.Marshal(container)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	binding := crypto.SecretBinding{
+		SecretID:     attachment.UUID,
+		LastModified: attachment.LastModified,
+	}
+
+	encryptedData, err := cryptor.EncryptSecretData(data, binding)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// NOTE: The hash covers the content, so a resumed upload seals the same chunks
+	sealer, err := cryptor.NewStreamSealer(binding, attachment.Hash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chunks := &attachmentChunks{
+		sealer:  sealer,
+		content: attachment.Content,
+		chunks:  attachmentChunkCount(container.Size),
+	}
+
+	remoteAttachment := &RemoteAttachment{
+		UUID:         attachment.UUID,
+		SecretUUID:   attachment.SecretUUID,
+		LastModified: attachment.LastModified,
+		Hash:         attachment.Hash,
+		Data:         encryptedData,
+		Header:       sealer.Header(),
+		Chunks:       chunks.chunks,
+		Size:         container.Size + int64(chunks.chunks*sealer.Overhead()),
+	}
+
+	return remoteAttachment, chunks, nil
+}
+
+// ConvertRemoteAttachmentToAttachment opens the attachment, reading its
+// sealed content from content. Content is not read for attachments synced
+// before content was chunked.
+func ConvertRemoteAttachmentToAttachment(cryptor crypto.Cryptor, remoteAttachment *RemoteAttachment, content ChunkReader) (*Attachment, error) {
+	binding := crypto.SecretBinding{
+		SecretID:     remoteAttachment.UUID,
+		LastModified: remoteAttachment.LastModified,
+	}
+
+	data, err := cryptor.DecryptSecretData(remoteAttachment.Data, binding)
+	if err != nil {
+		if errors.Is(err, crypto.ErrSecretBindingMismatch) {
+			return nil, errs.NewAttachmentTamperedError(remoteAttachment.UUID, err)
+		}
+		return nil, err
+	}
+
+	var container attachmentContainer
+	if err := json.Unmarshal(data, &container); err != nil {
+		return nil, fmt.Errorf("failed to parse attachment: %w", err)
+	}
+
+	// NOTE: The target could move an attachment to another secret otherwise
+	if container.SecretUUID != remoteAttachment.SecretUUID {
+		return nil, errs.NewAttachmentTamperedError(remoteAttachment.UUID,
+			fmt.Errorf("attachment secret does not match its record"))
+	}
+
+	if len(remoteAttachment.Header) > 0 {
+		container.Content, err = openAttachmentContent(cryptor, binding, remoteAttachment, container.Size, content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	attachment := &Attachment{
+		UUID:         remoteAttachment.UUID,
+		SecretUUID:   container.SecretUUID,
+		Name:         container.Name,
+		MIMEType:     container.MIMEType,
+		Size:         int64(len(container.Content)),
+		LastModified: remoteAttachment.LastModified,
+		Hash:         remoteAttachment.Hash,
+		Content:      container.Content,
+	}
+
+	return attachment, nil
+}
+
+func openAttachmentContent(cryptor crypto.Cryptor, binding crypto.SecretBinding, remoteAttachment *RemoteAttachment, size int64, content ChunkReader) ([]byte, error) {
+	if size < 0 || size > constants.MaxAttachmentSize {
+		return nil, errs.Validationf("attachment %s too large: %d bytes (max: %d bytes)",
+			remoteAttachment.UUID, size, constants.MaxAttachmentSize)
+	}
+
+	// NOTE: The sealed size is authenticated, the chunk count the target reports is not
+	chunks := attachmentChunkCount(size)
+	if remoteAttachment.Chunks != chunks {
+		return nil, errs.NewAttachmentTamperedError(remoteAttachment.UUID,
+			fmt.Errorf("attachment has %d chunks, expected %d", remoteAttachment.Chunks, chunks))
+	}
+
+	opener, err := cryptor.NewStreamOpener(binding, remoteAttachment.Header)
+	if err != nil {
+		return nil, errs.NewAttachmentTamperedError(remoteAttachment.UUID, err)
+	}
+
+	plainContent := make([]byte, 0, size)
+	for index := range chunks {
+		sealedChunk, err := content.ReadChunk()
+		if errors.Is(err, io.EOF) {
+			return nil, errs.NewAttachmentTamperedError(remoteAttachment.UUID,
+				fmt.Errorf("attachment ended after %d of %d chunks", index, chunks))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		plainChunk, err := opener.Open(index, sealedChunk, index == chunks-1)
+		if err != nil {
+			return nil, errs.NewAttachmentTamperedError(remoteAttachment.UUID, err)
+		}
+		plainContent = append(plainContent, plainChunk...)
+	}
+
+	if int64(len(plainContent)) != size {
+		return nil, errs.NewAttachmentTamperedError(remoteAttachment.UUID,
+			fmt.Errorf("attachment has %d bytes, expected %d", len(plainContent), size))
+	}
+
+	return plainContent, nil
+}
@@ -0,0 +1,160 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAttachment(t *testing.T) {
+	cryptor := crypto.NewCryptor("masterpass", "testuser")
+
+	attachment, err := NewAttachment(cryptor, "secret-1", " key.pem ", "application/x-pem-file", []byte("content"))
+	require.NoError(t, err)
+	assert.Equal(t, "key.pem", attachment.Name)
+	assert.Equal(t, int64(7), attachment.Size)
+	assert.NotEmpty(t, attachment.Hash)
+
+	for _, name := range []string{"", "dir/key.pem", strings.Repeat("a", constants.MaxAttachmentNameLength+1)} {
+		_, err := NewAttachment(cryptor, "secret-1", name, "", []byte("content"))
+		assert.True(t, errs.IsValidation(err), name)
+	}
+
+	assert.Error(t, validateAttachment("big", constants.MaxAttachmentSize+1))
+}
+
+// sealChunks converts the attachment and seals all of its chunks.
+func sealChunks(t *testing.T, cryptor crypto.Cryptor, attachment *Attachment) (*RemoteAttachment, [][]byte) {
+	remote, source, err := ConvertAttachmentToRemoteAttachment(cryptor, attachment)
+	require.NoError(t, err)
+
+	chunks := make([][]byte, remote.Chunks)
+	var size int64
+	for i := range chunks {
+		chunks[i], err = source.Chunk(i)
+		require.NoError(t, err)
+		size += int64(len(chunks[i]))
+	}
+	assert.Equal(t, remote.Size, size)
+
+	return remote, chunks
+}
+
+func TestConvertAttachments(t *testing.T) {
+	cryptor := crypto.NewCryptor("masterpass", "testuser")
+
+	newAttachment := func(t *testing.T, name string) *Attachment {
+		attachment, err := NewAttachment(cryptor, "secret-1", name, "text/plain", []byte(name+" content"))
+		require.NoError(t, err)
+		return attachment
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		attachment := newAttachment(t, "notes.txt")
+
+		remote, chunks := sealChunks(t, cryptor, attachment)
+		assert.Equal(t, "secret-1", remote.SecretUUID)
+		assert.Equal(t, 1, remote.Chunks)
+
+		converted, err := ConvertRemoteAttachmentToAttachment(cryptor, remote, NewChunkReader(chunks))
+		require.NoError(t, err)
+		assert.Equal(t, attachment, converted)
+	})
+
+	t.Run("content in several chunks", func(t *testing.T) {
+		content := bytes.Repeat([]byte("0123456789abcdef"), constants.AttachmentChunkSize/8)
+		attachment, err := NewAttachment(cryptor, "secret-1", "large.bin", "", content)
+		require.NoError(t, err)
+
+		remote, chunks := sealChunks(t, cryptor, attachment)
+		assert.Equal(t, 2, remote.Chunks)
+
+		converted, err := ConvertRemoteAttachmentToAttachment(cryptor, remote, NewChunkReader(chunks))
+		require.NoError(t, err)
+		assert.Equal(t, content, converted.Content)
+
+		_, err = ConvertRemoteAttachmentToAttachment(cryptor, remote, NewChunkReader(chunks[:1]))
+		assert.True(t, errs.IsTampered(err), "cut off")
+
+		_, err = ConvertRemoteAttachmentToAttachment(cryptor, remote, NewChunkReader([][]byte{chunks[1], chunks[0]}))
+		assert.True(t, errs.IsTampered(err), "reordered")
+
+		remote.Chunks = 1
+		_, err = ConvertRemoteAttachmentToAttachment(cryptor, remote, NewChunkReader(chunks[:1]))
+		assert.True(t, errs.IsTampered(err), "chunk count lowered")
+	})
+
+	t.Run("empty content", func(t *testing.T) {
+		attachment, err := NewAttachment(cryptor, "secret-1", "empty", "", nil)
+		require.NoError(t, err)
+
+		remote, chunks := sealChunks(t, cryptor, attachment)
+		assert.Equal(t, 1, remote.Chunks)
+
+		converted, err := ConvertRemoteAttachmentToAttachment(cryptor, remote, NewChunkReader(chunks))
+		require.NoError(t, err)
+		assert.Empty(t, converted.Content)
+	})
+
+	t.Run("sealed again gives the same chunks", func(t *testing.T) {
+		attachment := newAttachment(t, "notes.txt")
+
+		_, first := sealChunks(t, cryptor, attachment)
+		_, again := sealChunks(t, cryptor, attachment)
+		assert.Equal(t, first, again)
+	})
+
+	t.Run("moved attachment is rejected", func(t *testing.T) {
+		remote, chunks := sealChunks(t, cryptor, newAttachment(t, "notes.txt"))
+
+		remote.SecretUUID = "secret-2"
+
+		_, err := ConvertRemoteAttachmentToAttachment(cryptor, remote, NewChunkReader(chunks))
+		assert.True(t, errs.IsTampered(err))
+	})
+
+	t.Run("swapped data is rejected", func(t *testing.T) {
+		first, firstChunks := sealChunks(t, cryptor, newAttachment(t, "first"))
+		second, secondChunks := sealChunks(t, cryptor, newAttachment(t, "second"))
+
+		first.Data, second.Data = second.Data, first.Data
+
+		_, err := ConvertRemoteAttachmentToAttachment(cryptor, first, NewChunkReader(firstChunks))
+		assert.True(t, errs.IsTampered(err))
+
+		first.Data, second.Data = second.Data, first.Data
+
+		_, err = ConvertRemoteAttachmentToAttachment(cryptor, first, NewChunkReader(secondChunks))
+		assert.True(t, errs.IsTampered(err), "swapped content")
+	})
+
+	t.Run("attachment with inline content", func(t *testing.T) {
+		attachment := newAttachment(t, "notes.txt")
+		remote, _ := sealChunks(t, cryptor, attachment)
+
+		data, err := json.Marshal(&attachmentContainer{
+			SecretUUID: attachment.SecretUUID,
+			Name:       attachment.Name,
+			MIMEType:   attachment.MIMEType,
+			Size:       attachment.Size,
+			Content:    attachment.Content,
+		})
+		require.NoError(t, err)
+
+		binding := crypto.SecretBinding{SecretID: attachment.UUID, LastModified: attachment.LastModified}
+		remote.Data, err = cryptor.EncryptSecretData(data, binding)
+		require.NoError(t, err)
+		remote.Header, remote.Chunks, remote.Size = nil, 0, 0
+
+		converted, err := ConvertRemoteAttachmentToAttachment(cryptor, remote, nil)
+		require.NoError(t, err)
+		assert.Equal(t, attachment, converted)
+	})
+}
@@ -1,11 +1,17 @@
 package types
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 type SecretDataContainer struct {
-	Type       string     `json:"type"`
-	Name       string     `json:"name"`
-	SecretData SecretData `json:"-"`
+	Type       string        `json:"type"`
+	Name       string        `json:"name"`
+	Tags       []string      `json:"tags,omitempty"`
+	Fields     []CustomField `json:"fields,omitempty"`
+	DeletedAt  *time.Time    `json:"deleted_at,omitempty"`
+	SecretData SecretData    `json:"-"`
 }
 
 func (c *SecretDataContainer) MarshalJSON() ([]byte, error) {
@@ -2,11 +2,17 @@ package types
 
 import (
 	"encoding/json"
+	"errors"
 
+	"github.com/etoneja/go-keeper/internal/ctl/compress"
 	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
 )
 
-func ConvertLocalSecretToRemoteSecret(cryptor crypto.Cryptor, localSecret *LocalSecret) (*RemoteSecret, error) {
+// ConvertLocalSecretToRemoteSecret seals the secret bound to its UUID. With
+// compressData the container is compressed first, unless it holds a file
+// that is compressed already.
+func ConvertLocalSecretToRemoteSecret(cryptor crypto.Cryptor, localSecret *LocalSecret, compressData bool) (*RemoteSecret, error) {
 	secretData, err := localSecret.ParseData()
 	if err != nil {
 		return nil, err
@@ -15,15 +21,28 @@ func ConvertLocalSecretToRemoteSecret(cryptor crypto.Cryptor, localSecret *Local
 	secretDataContainer := &SecretDataContainer{
 		Type:       localSecret.Type,
 		Name:       localSecret.Name,
+		Tags:       localSecret.Tags,
+		Fields:     localSecret.Fields,
 		SecretData: secretData,
 	}
+	if localSecret.InTrash() {
+		secretDataContainer.DeletedAt = &localSecret.DeletedAt
+	}
 
 	remoteData, err := json.Marshal(secretDataContainer)
 	if err != nil {
 		return nil, err
 	}
+	if compressData && !holdsCompressedFile(secretData) {
+		remoteData = compress.Compress(remoteData)
+	}
 
-	encryptedRemoteData, err := cryptor.EncryptSecretData(remoteData)
+	binding := crypto.SecretBinding{
+		SecretID:     localSecret.UUID,
+		LastModified: localSecret.LastModified,
+	}
+
+	encryptedRemoteData, err := cryptor.EncryptSecretData(remoteData, binding)
 	if err != nil {
 		return nil, err
 	}
@@ -33,13 +52,27 @@ func ConvertLocalSecretToRemoteSecret(cryptor crypto.Cryptor, localSecret *Local
 		LastModified: localSecret.LastModified,
 		Hash:         localSecret.Hash,
 		Data:         encryptedRemoteData,
+		DeletedAt:    localSecret.DeletedAt,
 	}
 
 	return remoteSecret, nil
 }
 
 func ConvertRemoteSecretToLocalSecret(cryptor crypto.Cryptor, remoteSecret *RemoteSecret) (*LocalSecret, error) {
-	remoteDecryptedData, err := cryptor.DecryptSecretData(remoteSecret.Data)
+	binding := crypto.SecretBinding{
+		SecretID:     remoteSecret.UUID,
+		LastModified: remoteSecret.LastModified,
+	}
+
+	remoteDecryptedData, err := cryptor.DecryptSecretData(remoteSecret.Data, binding)
+	if err != nil {
+		if errors.Is(err, crypto.ErrSecretBindingMismatch) {
+			return nil, errs.NewSecretTamperedError(remoteSecret.UUID, err)
+		}
+		return nil, err
+	}
+
+	remoteDecryptedData, err = compress.Decompress(remoteDecryptedData)
 	if err != nil {
 		return nil, err
 	}
@@ -53,8 +86,15 @@ func ConvertRemoteSecretToLocalSecret(cryptor crypto.Cryptor, remoteSecret *Remo
 		UUID:         remoteSecret.UUID,
 		Type:         secretDataContainer.Type,
 		Name:         secretDataContainer.Name,
+		Tags:         secretDataContainer.Tags,
+		Fields:       secretDataContainer.Fields,
 		LastModified: remoteSecret.LastModified,
 		Hash:         remoteSecret.Hash,
+		DeletedAt:    remoteSecret.DeletedAt,
+	}
+	// NOTE: The sealed trash state wins, the target only adds one when it trashed the secret itself
+	if secretDataContainer.DeletedAt != nil {
+		localSecret.DeletedAt = secretDataContainer.DeletedAt.UTC()
 	}
 
 	err = localSecret.SetData(cryptor, secretDataContainer.SecretData)
@@ -64,3 +104,28 @@ func ConvertRemoteSecretToLocalSecret(cryptor crypto.Cryptor, remoteSecret *Remo
 
 	return localSecret, nil
 }
+
+// ResealRemoteSecret seals a remote secret uploaded before ciphertexts were
+// bound to their record again, bound to the record it is served under. The
+// record itself is kept, so the secret still compares equal to its copies.
+func ResealRemoteSecret(cryptor crypto.Cryptor, remoteSecret *RemoteSecret) (*RemoteSecret, error) {
+	plainData, err := cryptor.DecryptLegacySecretData(remoteSecret.Data)
+	if err != nil {
+		return nil, errs.NewSecretTamperedError(remoteSecret.UUID, err)
+	}
+
+	binding := crypto.SecretBinding{
+		SecretID:     remoteSecret.UUID,
+		LastModified: remoteSecret.LastModified,
+	}
+
+	sealedData, err := cryptor.EncryptSecretData(plainData, binding)
+	if err != nil {
+		return nil, err
+	}
+
+	resealed := *remoteSecret
+	resealed.Data = sealedData
+
+	return &resealed, nil
+}
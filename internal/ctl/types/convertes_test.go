@@ -0,0 +1,160 @@
+package types
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertSecrets(t *testing.T) {
+	cryptor := crypto.NewCryptor("masterpass", "testuser")
+
+	newSecret := func(t *testing.T, name string) *LocalSecret {
+		base := BaseSecret{Type: constants.SecretTypeText, Name: name}
+		secret, err := NewSecretModel(base, TextData{Content: name + " content"}, cryptor)
+		require.NoError(t, err)
+		return secret
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		localSecret := newSecret(t, "first")
+
+		remoteSecret, err := ConvertLocalSecretToRemoteSecret(cryptor, localSecret, false)
+		require.NoError(t, err)
+
+		converted, err := ConvertRemoteSecretToLocalSecret(cryptor, remoteSecret)
+		require.NoError(t, err)
+
+		assert.Equal(t, localSecret.UUID, converted.UUID)
+		assert.Equal(t, localSecret.Name, converted.Name)
+		assert.Equal(t, localSecret.Data, converted.Data)
+	})
+
+	t.Run("tags survive", func(t *testing.T) {
+		base := BaseSecret{Type: constants.SecretTypeText, Name: "tagged", Tags: []string{"work", "mail"}}
+		localSecret, err := NewSecretModel(base, TextData{Content: "content"}, cryptor)
+		require.NoError(t, err)
+
+		remoteSecret, err := ConvertLocalSecretToRemoteSecret(cryptor, localSecret, false)
+		require.NoError(t, err)
+
+		converted, err := ConvertRemoteSecretToLocalSecret(cryptor, remoteSecret)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"mail", "work"}, converted.Tags)
+		assert.Equal(t, localSecret.Hash, converted.Hash)
+	})
+
+	t.Run("custom fields survive", func(t *testing.T) {
+		base := BaseSecret{
+			Type:   constants.SecretTypeText,
+			Name:   "bank",
+			Fields: []CustomField{{Type: constants.FieldTypeHidden, Label: "PIN", Value: "1234"}},
+		}
+		localSecret, err := NewSecretModel(base, TextData{Content: "content"}, cryptor)
+		require.NoError(t, err)
+
+		remoteSecret, err := ConvertLocalSecretToRemoteSecret(cryptor, localSecret, false)
+		require.NoError(t, err)
+
+		converted, err := ConvertRemoteSecretToLocalSecret(cryptor, remoteSecret)
+		require.NoError(t, err)
+
+		assert.Equal(t, localSecret.Fields, converted.Fields)
+		assert.Equal(t, localSecret.Hash, converted.Hash)
+	})
+
+	t.Run("trash state survives", func(t *testing.T) {
+		localSecret := newSecret(t, "trashed")
+		localSecret.DeletedAt = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+		remoteSecret, err := ConvertLocalSecretToRemoteSecret(cryptor, localSecret, false)
+		require.NoError(t, err)
+		assert.Equal(t, localSecret.DeletedAt, remoteSecret.DeletedAt)
+
+		// NOTE: The sealed copy wins over what the target claims in the clear
+		remoteSecret.DeletedAt = time.Time{}
+		converted, err := ConvertRemoteSecretToLocalSecret(cryptor, remoteSecret)
+		require.NoError(t, err)
+		assert.True(t, converted.DeletedAt.Equal(localSecret.DeletedAt))
+		assert.Equal(t, localSecret.Hash, converted.Hash)
+	})
+
+	t.Run("trashed by target", func(t *testing.T) {
+		localSecret := newSecret(t, "live")
+
+		remoteSecret, err := ConvertLocalSecretToRemoteSecret(cryptor, localSecret, false)
+		require.NoError(t, err)
+		remoteSecret.DeletedAt = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+		converted, err := ConvertRemoteSecretToLocalSecret(cryptor, remoteSecret)
+		require.NoError(t, err)
+		assert.True(t, converted.InTrash())
+	})
+
+	t.Run("compressed round trip", func(t *testing.T) {
+		base := BaseSecret{Type: constants.SecretTypeText, Name: "config"}
+		localSecret, err := NewSecretModel(base, TextData{Content: strings.Repeat("key = value\n", 500)}, cryptor)
+		require.NoError(t, err)
+
+		plain, err := ConvertLocalSecretToRemoteSecret(cryptor, localSecret, false)
+		require.NoError(t, err)
+		compressed, err := ConvertLocalSecretToRemoteSecret(cryptor, localSecret, true)
+		require.NoError(t, err)
+		assert.Less(t, len(compressed.Data), len(plain.Data)/4)
+
+		converted, err := ConvertRemoteSecretToLocalSecret(cryptor, compressed)
+		require.NoError(t, err)
+		assert.Equal(t, localSecret.Data, converted.Data)
+		assert.Equal(t, localSecret.Hash, converted.Hash)
+	})
+
+	t.Run("compressed file is not compressed again", func(t *testing.T) {
+		content := append([]byte{0x1f, 0x8b, 0x08, 0x00}, []byte(strings.Repeat("a", 4096))...)
+		base := BaseSecret{Type: constants.SecretTypeBinary, Name: "archive"}
+		localSecret, err := NewSecretModel(base, FileData{
+			FileName: "archive.gz",
+			FileSize: int64(len(content)),
+			Content:  base64.StdEncoding.EncodeToString(content),
+		}, cryptor)
+		require.NoError(t, err)
+
+		plain, err := ConvertLocalSecretToRemoteSecret(cryptor, localSecret, false)
+		require.NoError(t, err)
+		compressed, err := ConvertLocalSecretToRemoteSecret(cryptor, localSecret, true)
+		require.NoError(t, err)
+		assert.Len(t, compressed.Data, len(plain.Data))
+		assert.Equal(t, localSecret.Data, localSecret.CompressedData())
+	})
+
+	t.Run("swapped data is rejected", func(t *testing.T) {
+		first, err := ConvertLocalSecretToRemoteSecret(cryptor, newSecret(t, "first"), false)
+		require.NoError(t, err)
+		second, err := ConvertLocalSecretToRemoteSecret(cryptor, newSecret(t, "second"), false)
+		require.NoError(t, err)
+
+		first.Data, second.Data = second.Data, first.Data
+
+		_, err = ConvertRemoteSecretToLocalSecret(cryptor, first)
+		require.Error(t, err)
+		assert.True(t, errs.IsTampered(err))
+	})
+
+	t.Run("replayed under new timestamp is rejected", func(t *testing.T) {
+		remoteSecret, err := ConvertLocalSecretToRemoteSecret(cryptor, newSecret(t, "first"), false)
+		require.NoError(t, err)
+
+		remoteSecret.LastModified = remoteSecret.LastModified.Add(time.Hour)
+
+		_, err = ConvertRemoteSecretToLocalSecret(cryptor, remoteSecret)
+		require.Error(t, err)
+		assert.True(t, errs.IsTampered(err))
+	})
+}
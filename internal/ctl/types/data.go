@@ -63,12 +63,25 @@ type FileData struct {
 	FileName string `json:"file_name"`
 	FileSize int64  `json:"file_size"`
 	Content  string `json:"content"`
+	// Attachment is the UUID of the attachment holding files larger than
+	// MaxFileSize, Content is empty then
+	Attachment string `json:"attachment,omitempty"`
 }
 
 func (d FileData) Validate() error {
 	if strings.TrimSpace(d.FileName) == "" {
 		return fmt.Errorf("file name is required")
 	}
+	if d.Attachment != "" {
+		if d.Content != "" {
+			return fmt.Errorf("content must be empty for a file held in an attachment")
+		}
+		if d.FileSize > constants.MaxAttachmentSize {
+			return fmt.Errorf("file size %d bytes exceeds maximum %d bytes",
+				d.FileSize, constants.MaxAttachmentSize)
+		}
+		return nil
+	}
 	if strings.TrimSpace(d.Content) == "" {
 		return fmt.Errorf("content is required")
 	}
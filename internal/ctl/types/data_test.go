@@ -3,6 +3,8 @@ package types
 import (
 	"testing"
 
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -91,6 +93,19 @@ func TestFileData_Validate(t *testing.T) {
 		data := FileData{FileName: "file.txt", Content: "   "}
 		assert.Error(t, data.Validate())
 	})
+
+	t.Run("held in attachment", func(t *testing.T) {
+		data := FileData{FileName: "disk.img", FileSize: constants.MaxFileSize + 1, Attachment: "attachment-uuid"}
+		assert.NoError(t, data.Validate())
+
+		data.Content = "content"
+		assert.Error(t, data.Validate())
+	})
+
+	t.Run("inline file too large", func(t *testing.T) {
+		data := FileData{FileName: "disk.img", FileSize: constants.MaxFileSize + 1, Content: "content"}
+		assert.Error(t, data.Validate())
+	})
 }
 
 func TestCardData_Validate(t *testing.T) {
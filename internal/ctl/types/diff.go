@@ -9,9 +9,31 @@ type SecretsDiff struct {
 	LocalOnly  []*LocalSecret
 	RemoteOnly []*RemoteSecret
 	Both       []*SecretCheckPair
+
+	// Rollbacks maps secret UUIDs to the reason their remote state looks
+	// older than what was already seen.
+	Rollbacks map[string]string
+
+	// Purged holds the UUIDs of secrets purged on one side while the other
+	// side still has them in the trash.
+	Purged map[string]bool
 }
 
 func (p *SecretCheckPair) IsIdentical() bool {
 	return p.Local.Hash == p.Remote.Hash &&
-		p.Local.LastModified.Equal(p.Remote.LastModified)
+		p.Local.LastModified.Equal(p.Remote.LastModified) &&
+		p.Local.InTrash() == p.Remote.InTrash()
+}
+
+// TrashOnly reports whether the copies hold the same content and differ only
+// in whether and when they were moved to the trash.
+func (p *SecretCheckPair) TrashOnly() bool {
+	return p.Local.Hash == p.Remote.Hash && !p.IsIdentical() &&
+		(p.Local.InTrash() || p.Remote.InTrash())
+}
+
+// RollbackReason reports whether the secret was flagged as a possible rollback.
+func (d *SecretsDiff) RollbackReason(secretID string) (string, bool) {
+	reason, exists := d.Rollbacks[secretID]
+	return reason, exists
 }
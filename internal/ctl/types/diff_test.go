@@ -65,6 +65,50 @@ func TestSecretCheckPair_IsIdentical(t *testing.T) {
 		}
 		assert.False(t, pair.IsIdentical())
 	})
+
+	t.Run("different trash state", func(t *testing.T) {
+		pair := &SecretCheckPair{
+			Local: &LocalSecret{
+				Hash:         "abc123",
+				LastModified: now,
+			},
+			Remote: &RemoteSecret{
+				Hash:         "abc123",
+				LastModified: now,
+				DeletedAt:    now,
+			},
+		}
+		assert.False(t, pair.IsIdentical())
+		assert.True(t, pair.TrashOnly())
+	})
+}
+
+func TestSecretCheckPair_TrashOnly(t *testing.T) {
+	now := time.Now()
+
+	t.Run("trashed with same content", func(t *testing.T) {
+		pair := &SecretCheckPair{
+			Local:  &LocalSecret{Hash: "abc123", LastModified: now.Add(time.Hour), DeletedAt: now.Add(time.Hour)},
+			Remote: &RemoteSecret{Hash: "abc123", LastModified: now},
+		}
+		assert.True(t, pair.TrashOnly())
+	})
+
+	t.Run("trashed with other content", func(t *testing.T) {
+		pair := &SecretCheckPair{
+			Local:  &LocalSecret{Hash: "abc123", LastModified: now.Add(time.Hour), DeletedAt: now.Add(time.Hour)},
+			Remote: &RemoteSecret{Hash: "def456", LastModified: now},
+		}
+		assert.False(t, pair.TrashOnly())
+	})
+
+	t.Run("neither trashed", func(t *testing.T) {
+		pair := &SecretCheckPair{
+			Local:  &LocalSecret{Hash: "abc123", LastModified: now.Add(time.Hour)},
+			Remote: &RemoteSecret{Hash: "abc123", LastModified: now},
+		}
+		assert.False(t, pair.TrashOnly())
+	})
 }
 
 func TestSecretsDiff_Empty(t *testing.T) {
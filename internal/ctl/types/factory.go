@@ -7,6 +7,7 @@ import (
 
 	"github.com/etoneja/go-keeper/internal/ctl/constants"
 	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
 	"github.com/google/uuid"
 )
 
@@ -19,19 +20,35 @@ var typeMap = map[string]any{
 
 func NewSecretModel(base BaseSecret, data SecretData, cryptor crypto.Cryptor) (*LocalSecret, error) {
 	if err := validateBaseSecret(base, data); err != nil {
-		return nil, err
+		return nil, errs.NewValidationError(err)
 	}
 
 	if err := data.Validate(); err != nil {
-		return nil, fmt.Errorf("data validation failed: %w", err)
+		return nil, errs.Validationf("data validation failed: %w", err)
+	}
+
+	name, err := NormalizePath(base.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := NormalizeTags(base.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ValidateCustomFields(base.Fields); err != nil {
+		return nil, err
 	}
 
 	secret := &LocalSecret{
 		UUID:         uuid.New().String(),
 		Type:         base.Type,
-		Name:         base.Name,
+		Name:         name,
 		LastModified: time.Now().UTC().Truncate(time.Microsecond),
 		Metadata:     base.Metadata,
+		Tags:         tags,
+		Fields:       base.Fields,
 	}
 
 	if err := secret.SetData(cryptor, data); err != nil {
@@ -114,3 +114,34 @@ func TestLocalSecret_SetData(t *testing.T) {
 		assert.Equal(t, "test content", parsedData.(TextData).Content)
 	})
 }
+
+func TestLocalSecret_Hashes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockCryptor := crypto.NewMockCryptor(ctrl)
+
+	secret := &LocalSecret{
+		Hash:     "legacy",
+		Data:     []byte(`{"content":"test"}`),
+		Metadata: "meta",
+	}
+	hashInput := []byte(`{"content":"test"}meta`)
+
+	t.Run("legacy hash", func(t *testing.T) {
+		mockCryptor.EXPECT().
+			CalculateLegacyDataHash(hashInput).
+			Return("legacy")
+
+		assert.True(t, secret.HasLegacyHash())
+		assert.Equal(t, "legacy", secret.LegacyHash(mockCryptor))
+	})
+
+	t.Run("refresh hash", func(t *testing.T) {
+		mockCryptor.EXPECT().
+			CalculateDataHash(hashInput).
+			Return("v2:keyed")
+
+		secret.RefreshHash(mockCryptor)
+		assert.Equal(t, "v2:keyed", secret.Hash)
+		assert.False(t, secret.HasLegacyHash())
+	})
+}
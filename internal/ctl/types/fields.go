@@ -0,0 +1,85 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+)
+
+// FieldTypes are the custom field types, hidden values are masked on display.
+var FieldTypes = []string{constants.FieldTypeText, constants.FieldTypeHidden, constants.FieldTypeURL}
+
+// CustomField is a labeled value added to a secret of any type.
+type CustomField struct {
+	Type  string `json:"type"`
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// IsHidden reports whether the value is masked unless asked for.
+func (f CustomField) IsHidden() bool {
+	return f.Type == constants.FieldTypeHidden
+}
+
+// ParseCustomField parses a "label=value" flag into a field of fieldType.
+func ParseCustomField(spec string, fieldType string) (CustomField, error) {
+	label, value, found := strings.Cut(spec, "=")
+	if !found {
+		return CustomField{}, errs.Validationf("field %q must be label=value", spec)
+	}
+	return CustomField{Type: fieldType, Label: strings.TrimSpace(label), Value: value}, nil
+}
+
+// ValidateCustomFields checks field types, lengths and that labels are
+// unique, ignoring case, so a label finds at most one field.
+func ValidateCustomFields(fields []CustomField) error {
+	if len(fields) > constants.MaxCustomFields {
+		return errs.Validationf("too many custom fields: %d (max: %d)", len(fields), constants.MaxCustomFields)
+	}
+
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if err := validateCustomField(field); err != nil {
+			return errs.NewValidationError(err)
+		}
+
+		key := strings.ToLower(field.Label)
+		if seen[key] {
+			return errs.Validationf("custom field %q is given more than once", field.Label)
+		}
+		seen[key] = true
+	}
+
+	return nil
+}
+
+func validateCustomField(field CustomField) error {
+	if !slices.Contains(FieldTypes, field.Type) {
+		return fmt.Errorf("custom field %q has unknown type %q, expected one of %s",
+			field.Label, field.Type, strings.Join(FieldTypes, ", "))
+	}
+	if strings.TrimSpace(field.Label) == "" {
+		return fmt.Errorf("custom field label is required")
+	}
+	if len(field.Label) > constants.MaxFieldLabelLength {
+		return fmt.Errorf("custom field label %q is longer than %d characters", field.Label, constants.MaxFieldLabelLength)
+	}
+	if len(field.Value) > constants.MaxFieldValueLength {
+		return fmt.Errorf("custom field %q value too long: %d characters (max: %d)",
+			field.Label, len(field.Value), constants.MaxFieldValueLength)
+	}
+	return nil
+}
+
+// Field returns the custom field with label, comparing labels case-insensitively.
+func (s *LocalSecret) Field(label string) (CustomField, bool) {
+	for _, field := range s.Fields {
+		if strings.EqualFold(field.Label, label) {
+			return field, true
+		}
+	}
+	return CustomField{}, false
+}
@@ -0,0 +1,83 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCustomField(t *testing.T) {
+	field, err := ParseCustomField(" Bank = Example=Bank", constants.FieldTypeText)
+	require.NoError(t, err)
+	assert.Equal(t, CustomField{Type: "text", Label: "Bank", Value: " Example=Bank"}, field)
+
+	_, err = ParseCustomField("no separator", constants.FieldTypeText)
+	require.Error(t, err)
+	assert.True(t, errs.IsValidation(err))
+}
+
+func TestValidateCustomFields(t *testing.T) {
+	valid := []CustomField{
+		{Type: constants.FieldTypeText, Label: "Bank", Value: "Example"},
+		{Type: constants.FieldTypeHidden, Label: "PIN", Value: "1234"},
+		{Type: constants.FieldTypeURL, Label: "Portal", Value: "https://bank.example.com"},
+	}
+	require.NoError(t, ValidateCustomFields(valid))
+
+	tests := map[string][]CustomField{
+		"unknown type":    {{Type: "secret", Label: "PIN", Value: "1"}},
+		"empty label":     {{Type: constants.FieldTypeText, Label: " ", Value: "1"}},
+		"duplicate label": {valid[0], {Type: constants.FieldTypeHidden, Label: "bank", Value: "2"}},
+	}
+	for name, fields := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateCustomFields(fields)
+			require.Error(t, err)
+			assert.True(t, errs.IsValidation(err))
+		})
+	}
+}
+
+func TestLocalSecret_FieldsInHash(t *testing.T) {
+	cryptor := crypto.NewCryptor("masterpass", "testuser")
+
+	secret, err := NewSecretModel(BaseSecret{Type: "text", Name: "note"}, TextData{Content: "x"}, cryptor)
+	require.NoError(t, err)
+	plain := secret.Hash
+
+	secret.Fields = []CustomField{{Type: constants.FieldTypeHidden, Label: "PIN", Value: "1234"}}
+	secret.RefreshHash(cryptor)
+	assert.NotEqual(t, plain, secret.Hash)
+	withPIN := secret.Hash
+
+	secret.Fields[0].Value = "4321"
+	secret.RefreshHash(cryptor)
+	assert.NotEqual(t, withPIN, secret.Hash)
+
+	field, exists := secret.Field("pin")
+	assert.True(t, exists)
+	assert.Equal(t, "4321", field.Value)
+
+	// NOTE: Both values are invalid UTF-8, which JSON would encode alike
+	secret.Fields[0].Value = "\xff"
+	secret.RefreshHash(cryptor)
+	invalid := secret.Hash
+	secret.Fields[0].Value = "\xfe"
+	secret.RefreshHash(cryptor)
+	assert.NotEqual(t, invalid, secret.Hash)
+
+	secret.Fields = []CustomField{{Type: constants.FieldTypeText, Label: "a", Value: "bc"}}
+	secret.RefreshHash(cryptor)
+	split := secret.Hash
+	secret.Fields = []CustomField{{Type: constants.FieldTypeText, Label: "ab", Value: "c"}}
+	secret.RefreshHash(cryptor)
+	assert.NotEqual(t, split, secret.Hash)
+
+	secret.Fields = nil
+	secret.RefreshHash(cryptor)
+	assert.Equal(t, plain, secret.Hash)
+}
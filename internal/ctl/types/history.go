@@ -0,0 +1,11 @@
+package types
+
+import "time"
+
+// SecretVersion is an earlier state of a secret, archived in its history
+// when the secret was modified or deleted. Versions count up from 1.
+type SecretVersion struct {
+	Version    int
+	ArchivedAt time.Time
+	Secret     *LocalSecret
+}
@@ -1,10 +1,15 @@
 package types
 
 import (
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/etoneja/go-keeper/internal/ctl/compress"
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
 	"github.com/etoneja/go-keeper/internal/ctl/crypto"
 )
 
@@ -12,6 +17,8 @@ type BaseSecret struct {
 	Type     string
 	Name     string
 	Metadata string
+	Tags     []string
+	Fields   []CustomField
 }
 
 type LocalSecret struct {
@@ -22,6 +29,17 @@ type LocalSecret struct {
 	Hash         string
 	Data         []byte
 	Metadata     string
+	// Tags are normalized, see NormalizeTags
+	Tags []string
+	// Fields are loaded with Data, in the order they were given
+	Fields []CustomField
+	// DeletedAt is when the secret was moved to the trash, zero when it is not there
+	DeletedAt time.Time
+}
+
+// InTrash reports whether the secret was deleted and can still be restored.
+func (s *LocalSecret) InTrash() bool {
+	return !s.DeletedAt.IsZero()
 }
 
 func (s *LocalSecret) ParseData() (SecretData, error) {
@@ -34,11 +52,68 @@ func (s *LocalSecret) SetData(cryptor crypto.Cryptor, data SecretData) error {
 		return fmt.Errorf("failed to marshal data: %w", err)
 	}
 
-	// TODO: can be more efficient
-	hashData := fmt.Sprintf("%s%s", string(jsonData), s.Metadata)
-	hash := cryptor.CalculateDataHash([]byte(hashData))
-
 	s.Data = jsonData
-	s.Hash = hash
+	s.Hash = cryptor.CalculateDataHash(s.hashInput())
 	return nil
 }
+
+// CompressedData returns Data compressed for storage, see compress.Compress.
+// Files that are compressed already are returned as is.
+func (s *LocalSecret) CompressedData() []byte {
+	if s.Type == constants.SecretTypeBinary {
+		data, err := s.ParseData()
+		if err == nil && holdsCompressedFile(data) {
+			return s.Data
+		}
+	}
+	return compress.Compress(s.Data)
+}
+
+// holdsCompressedFile reports whether data is a file of a compressed format.
+func holdsCompressedFile(data SecretData) bool {
+	file, ok := data.(FileData)
+	if !ok {
+		return false
+	}
+
+	// NOTE: 24 base64 characters decode to the 18 bytes the magic numbers need
+	prefix, err := base64.StdEncoding.DecodeString(file.Content[:min(len(file.Content), 24)])
+	return err == nil && compress.LooksCompressed(prefix)
+}
+
+// HasLegacyHash reports whether the hash predates keyed content hashes.
+func (s *LocalSecret) HasLegacyHash() bool {
+	return crypto.IsLegacyDataHash(s.Hash)
+}
+
+// LegacyHash computes the unkeyed hash older clients stored for the same
+// content. Data must be loaded.
+func (s *LocalSecret) LegacyHash(cryptor crypto.Cryptor) string {
+	return cryptor.CalculateLegacyDataHash(s.hashInput())
+}
+
+// RefreshHash recomputes the hash in the current format. Data must be loaded.
+func (s *LocalSecret) RefreshHash(cryptor crypto.Cryptor) {
+	s.Hash = cryptor.CalculateDataHash(s.hashInput())
+}
+
+func (s *LocalSecret) hashInput() []byte {
+	// TODO: can be more efficient
+	input := []byte(fmt.Sprintf("%s%s", string(s.Data), s.Metadata))
+	// NOTE: Untagged secrets keep the hash they had before tags existed
+	if len(s.Tags) > 0 {
+		input = append(input, "\x00tags:"+strings.Join(s.Tags, ",")...)
+	}
+	// NOTE: Likewise for secrets without custom fields. Every string is
+	// prefixed with its length, so no two field lists give the same input
+	if len(s.Fields) > 0 {
+		input = append(input, "\x00fields:"...)
+		for _, field := range s.Fields {
+			for _, part := range []string{field.Type, field.Label, field.Value} {
+				input = binary.AppendUvarint(input, uint64(len(part)))
+				input = append(input, part...)
+			}
+		}
+	}
+	return input
+}
@@ -0,0 +1,141 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+)
+
+// SyncManifest is the authenticated list of secrets a client has seen on the
+// server, with the newest version observed for each of them.
+type SyncManifest struct {
+	Revision  uint64                   `json:"revision"`
+	UpdatedAt time.Time                `json:"updated_at"`
+	Secrets   map[string]ManifestEntry `json:"secrets"`
+	// Attachments tell an attachment deleted on one side from a new one on the other
+	Attachments map[string]ManifestEntry `json:"attachments,omitempty"`
+	// Bound is set once every secret on the target was sealed bound to its
+	// record, from then on legacy blobs are refused instead of re-sealed
+	Bound bool `json:"bound,omitempty"`
+}
+
+type ManifestEntry struct {
+	LastModified time.Time `json:"last_modified"`
+	Hash         string    `json:"hash"`
+}
+
+func NewSyncManifest() *SyncManifest {
+	return &SyncManifest{
+		Secrets:     make(map[string]ManifestEntry),
+		Attachments: make(map[string]ManifestEntry),
+	}
+}
+
+// HighWaterMark returns the newest version of the secret recorded in the manifest.
+func (m *SyncManifest) HighWaterMark(secretID string) (time.Time, bool) {
+	entry, exists := m.Secrets[secretID]
+	if !exists {
+		return time.Time{}, false
+	}
+	return entry.LastModified, true
+}
+
+// Observe records a version of the secret, keeping the newest one seen.
+func (m *SyncManifest) Observe(secretID string, lastModified time.Time, hash string) {
+	entry, exists := m.Secrets[secretID]
+	if exists && entry.LastModified.After(lastModified) {
+		return
+	}
+	m.Secrets[secretID] = ManifestEntry{LastModified: lastModified, Hash: hash}
+}
+
+// ObserveAttachment records an attachment. Attachments never change.
+func (m *SyncManifest) ObserveAttachment(attachmentID string, lastModified time.Time, hash string) {
+	m.Attachments[attachmentID] = ManifestEntry{LastModified: lastModified, Hash: hash}
+}
+
+// HasAttachment reports whether the attachment was seen on the target.
+func (m *SyncManifest) HasAttachment(attachmentID string) bool {
+	_, exists := m.Attachments[attachmentID]
+	return exists
+}
+
+// Equal reports whether both manifests list the same secret and attachment
+// versions and agree on whether the target is bound.
+func (m *SyncManifest) Equal(other *SyncManifest) bool {
+	return other != nil &&
+		m.Bound == other.Bound &&
+		equalManifestEntries(m.Secrets, other.Secrets) &&
+		equalManifestEntries(m.Attachments, other.Attachments)
+}
+
+func equalManifestEntries(entries, other map[string]ManifestEntry) bool {
+	if len(entries) != len(other) {
+		return false
+	}
+	for id, entry := range entries {
+		otherEntry, exists := other[id]
+		if !exists || otherEntry.Hash != entry.Hash || !otherEntry.LastModified.Equal(entry.LastModified) {
+			return false
+		}
+	}
+	return true
+}
+
+func ConvertManifestToRemoteSecret(cryptor crypto.Cryptor, manifest *SyncManifest) (*RemoteSecret, error) {
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	binding := crypto.SecretBinding{
+		SecretID:     constants.ManifestSecretID,
+		LastModified: manifest.UpdatedAt,
+	}
+
+	encryptedData, err := cryptor.EncryptSecretData(manifestData, binding)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteSecret := &RemoteSecret{
+		UUID:         constants.ManifestSecretID,
+		LastModified: manifest.UpdatedAt,
+		Hash:         cryptor.CalculateDataHash(manifestData),
+		Data:         encryptedData,
+	}
+
+	return remoteSecret, nil
+}
+
+func ConvertRemoteSecretToManifest(cryptor crypto.Cryptor, remoteSecret *RemoteSecret) (*SyncManifest, error) {
+	binding := crypto.SecretBinding{
+		SecretID:     remoteSecret.UUID,
+		LastModified: remoteSecret.LastModified,
+	}
+
+	manifestData, err := cryptor.DecryptSecretData(remoteSecret.Data, binding)
+	if err != nil {
+		if errors.Is(err, crypto.ErrSecretBindingMismatch) {
+			return nil, errs.NewSecretTamperedError(remoteSecret.UUID, err)
+		}
+		return nil, err
+	}
+
+	manifest := NewSyncManifest()
+	if err := json.Unmarshal(manifestData, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse sync manifest: %w", err)
+	}
+
+	if !manifest.UpdatedAt.Equal(remoteSecret.LastModified) {
+		return nil, errs.NewSecretTamperedError(remoteSecret.UUID,
+			fmt.Errorf("manifest timestamp does not match its record"))
+	}
+
+	return manifest, nil
+}
@@ -0,0 +1,80 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncManifest_Observe(t *testing.T) {
+	now := time.Now().UTC()
+	manifest := NewSyncManifest()
+
+	manifest.Observe("1", now, "new")
+	manifest.Observe("1", now.Add(-time.Hour), "old")
+
+	mark, exists := manifest.HighWaterMark("1")
+	require.True(t, exists)
+	assert.True(t, mark.Equal(now))
+	assert.Equal(t, "new", manifest.Secrets["1"].Hash)
+
+	_, exists = manifest.HighWaterMark("2")
+	assert.False(t, exists)
+}
+
+func TestSyncManifest_Equal(t *testing.T) {
+	now := time.Now().UTC()
+
+	first := NewSyncManifest()
+	first.Observe("1", now, "hash")
+
+	second := NewSyncManifest()
+	second.Revision = 5
+	second.Observe("1", now, "hash")
+
+	assert.True(t, first.Equal(second))
+
+	first.ObserveAttachment("a", now, "hash")
+	assert.False(t, first.Equal(second))
+	second.ObserveAttachment("a", now, "hash")
+	assert.True(t, first.Equal(second))
+	assert.True(t, second.HasAttachment("a"))
+
+	second.Observe("2", now, "hash")
+	assert.False(t, first.Equal(second))
+	assert.False(t, first.Equal(nil))
+}
+
+func TestConvertManifest(t *testing.T) {
+	cryptor := crypto.NewCryptor("masterpass", "testuser")
+
+	manifest := NewSyncManifest()
+	manifest.Revision = 3
+	manifest.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	manifest.Observe("1", manifest.UpdatedAt, "hash")
+
+	t.Run("round trip", func(t *testing.T) {
+		record, err := ConvertManifestToRemoteSecret(cryptor, manifest)
+		require.NoError(t, err)
+
+		converted, err := ConvertRemoteSecretToManifest(cryptor, record)
+		require.NoError(t, err)
+		assert.Equal(t, manifest.Revision, converted.Revision)
+		assert.True(t, manifest.Equal(converted))
+	})
+
+	t.Run("replayed record is rejected", func(t *testing.T) {
+		record, err := ConvertManifestToRemoteSecret(cryptor, manifest)
+		require.NoError(t, err)
+
+		record.LastModified = record.LastModified.Add(time.Hour)
+
+		_, err = ConvertRemoteSecretToManifest(cryptor, record)
+		require.Error(t, err)
+		assert.True(t, errs.IsTampered(err))
+	})
+}
@@ -0,0 +1,74 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+)
+
+// PathSeparator splits secret names into folders, as in "work/aws/root".
+const PathSeparator = "/"
+
+// NormalizePath trims the segments of a secret path and drops empty ones, so
+// " work//aws/ " becomes "work/aws". The result must not be empty and must
+// not hold "." or ".." segments.
+func NormalizePath(name string) (string, error) {
+	normalized, err := NormalizeFolder(name)
+	if err != nil {
+		return "", err
+	}
+	if normalized == "" {
+		return "", errs.Validationf("name is required")
+	}
+	return normalized, nil
+}
+
+// NormalizeFolder is NormalizePath for folders, where "" and "/" mean the root.
+func NormalizeFolder(folder string) (string, error) {
+	var segments []string
+	for _, segment := range strings.Split(folder, PathSeparator) {
+		segment = strings.TrimSpace(segment)
+		switch segment {
+		case "":
+			continue
+		case ".", "..":
+			return "", errs.Validationf("path %q must not contain %q", folder, segment)
+		}
+		segments = append(segments, segment)
+	}
+	return strings.Join(segments, PathSeparator), nil
+}
+
+// SplitPath returns the folder and the base name of a normalized path.
+func SplitPath(name string) (string, string) {
+	index := strings.LastIndex(name, PathSeparator)
+	if index < 0 {
+		return "", name
+	}
+	return name[:index], name[index+1:]
+}
+
+// JoinPath joins a normalized folder and a name.
+func JoinPath(folder string, name string) string {
+	if folder == "" {
+		return name
+	}
+	return fmt.Sprintf("%s%s%s", folder, PathSeparator, name)
+}
+
+// InFolder reports whether the normalized path lies in folder or below it.
+// Every path lies in the root folder "".
+func InFolder(name string, folder string) bool {
+	return folder == "" || strings.HasPrefix(name, folder+PathSeparator)
+}
+
+// Path returns the normalized name of the secret, or the name as is when it
+// predates paths and does not normalize.
+func (s *LocalSecret) Path() string {
+	normalized, err := NormalizePath(s.Name)
+	if err != nil {
+		return s.Name
+	}
+	return normalized
+}
@@ -0,0 +1,46 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "mail", want: "mail"},
+		{name: " work//aws/ prod /", want: "work/aws/prod"},
+		{name: "/root", want: "root"},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizePath(tt.name)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+
+	for _, invalid := range []string{"", " / ", "work/../root", "./mail"} {
+		_, err := NormalizePath(invalid)
+		assert.Error(t, err, invalid)
+	}
+
+	root, err := NormalizeFolder("/")
+	require.NoError(t, err)
+	assert.Empty(t, root)
+}
+
+func TestPathHelpers(t *testing.T) {
+	folder, name := SplitPath("work/aws/root")
+	assert.Equal(t, "work/aws", folder)
+	assert.Equal(t, "root", name)
+	assert.Equal(t, "work/aws/root", JoinPath(folder, name))
+
+	assert.True(t, InFolder("work/aws/root", "work"))
+	assert.True(t, InFolder("mail", ""))
+	assert.False(t, InFolder("workshop/key", "work"))
+	assert.False(t, InFolder("work", "work"))
+}
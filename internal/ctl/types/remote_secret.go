@@ -9,4 +9,12 @@ type RemoteSecret struct {
 	LastModified time.Time
 	Hash         string
 	Data         []byte
+	// DeletedAt is set while the secret is in the trash. The target keeps it
+	// in the clear to purge its trash, the sealed data holds it too.
+	DeletedAt time.Time
+}
+
+// InTrash reports whether the secret was deleted and can still be restored.
+func (s *RemoteSecret) InTrash() bool {
+	return !s.DeletedAt.IsZero()
 }
@@ -0,0 +1,45 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+)
+
+// NormalizeTags lowercases and trims tags and returns them sorted without
+// duplicates. Tags must be non-empty words without whitespace or commas.
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if err := validateTag(tag); err != nil {
+			return nil, errs.NewValidationError(err)
+		}
+		normalized = append(normalized, tag)
+	}
+
+	slices.Sort(normalized)
+	return slices.Compact(normalized), nil
+}
+
+func validateTag(tag string) error {
+	if tag == "" {
+		return fmt.Errorf("tag must not be empty")
+	}
+	if len(tag) > constants.MaxTagLength {
+		return fmt.Errorf("tag %q is longer than %d characters", tag, constants.MaxTagLength)
+	}
+	if strings.ContainsFunc(tag, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		return fmt.Errorf("tag %q must not contain commas or whitespace", tag)
+	}
+	return nil
+}
+
+// HasTag reports whether the secret is tagged with tag, which must be normalized.
+func (s *LocalSecret) HasTag(tag string) bool {
+	return slices.Contains(s.Tags, tag)
+}
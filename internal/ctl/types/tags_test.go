@@ -0,0 +1,38 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/etoneja/go-keeper/internal/ctl/crypto"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := NormalizeTags([]string{" Work", "mail", "work"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mail", "work"}, tags)
+
+	for _, invalid := range []string{"", "two words", "a,b"} {
+		_, err := NormalizeTags([]string{invalid})
+		require.Error(t, err, invalid)
+		assert.True(t, errs.IsValidation(err))
+	}
+}
+
+func TestLocalSecret_TagsInHash(t *testing.T) {
+	cryptor := crypto.NewCryptor("masterpass", "testuser")
+
+	secret, err := NewSecretModel(BaseSecret{Type: "text", Name: "note"}, TextData{Content: "x"}, cryptor)
+	require.NoError(t, err)
+	untagged := secret.Hash
+
+	secret.Tags = []string{"work"}
+	secret.RefreshHash(cryptor)
+	assert.NotEqual(t, untagged, secret.Hash)
+
+	secret.Tags = nil
+	secret.RefreshHash(cryptor)
+	assert.Equal(t, untagged, secret.Hash)
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/etoneja/go-keeper/internal/ctl/constants"
+	"github.com/etoneja/go-keeper/internal/ctl/errs"
 )
 
 type FileChecker struct {
@@ -23,9 +24,9 @@ func (f *FileChecker) CheckFileSize(filePath string) error {
 		return fmt.Errorf("failed to get file info: %w", err)
 	}
 
-	if info.Size() > constants.MaxFileSize {
-		return fmt.Errorf("file too large: %d bytes (max: %d bytes)",
-			info.Size(), constants.MaxFileSize)
+	if info.Size() > constants.MaxAttachmentSize {
+		return errs.Validationf("file too large: %d bytes (max: %d bytes)",
+			info.Size(), constants.MaxAttachmentSize)
 	}
 
 	return nil
@@ -29,7 +29,7 @@ func TestFileChecker_CheckFileSize(t *testing.T) {
 		checker := &FileChecker{
 			statFunc: func(name string) (fs.FileInfo, error) {
 				return mockFileInfo{
-					size: constants.MaxFileSize - 100,
+					size: constants.MaxAttachmentSize - 100,
 					name: "testfile.txt",
 				}, nil
 			},
@@ -43,7 +43,7 @@ func TestFileChecker_CheckFileSize(t *testing.T) {
 		checker := &FileChecker{
 			statFunc: func(name string) (fs.FileInfo, error) {
 				return mockFileInfo{
-					size: constants.MaxFileSize + 100,
+					size: constants.MaxAttachmentSize + 100,
 					name: "largefile.bin",
 				}, nil
 			},
@@ -72,7 +72,7 @@ func TestFileChecker_CheckFileSize(t *testing.T) {
 		checker := &FileChecker{
 			statFunc: func(name string) (fs.FileInfo, error) {
 				return mockFileInfo{
-					size: constants.MaxFileSize,
+					size: constants.MaxAttachmentSize,
 					name: "maxfile.dat",
 				}, nil
 			},
@@ -438,6 +438,7 @@ type Secret struct {
 	xxx_hidden_Data         []byte                 `protobuf:"bytes,2,opt,name=data"`
 	xxx_hidden_Hash         *string                `protobuf:"bytes,3,opt,name=hash"`
 	xxx_hidden_LastModified *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_modified,json=lastModified"`
+	xxx_hidden_DeletedAt    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=deleted_at,json=deletedAt"`
 	XXX_raceDetectHookData  protoimpl.RaceDetectHookData
 	XXX_presence            [1]uint32
 	unknownFields           protoimpl.UnknownFields
@@ -503,9 +504,16 @@ func (x *Secret) GetLastModified() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *Secret) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_DeletedAt
+	}
+	return nil
+}
+
 func (x *Secret) SetId(v string) {
 	x.xxx_hidden_Id = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 4)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 5)
 }
 
 func (x *Secret) SetData(v []byte) {
@@ -513,18 +521,22 @@ func (x *Secret) SetData(v []byte) {
 		v = []byte{}
 	}
 	x.xxx_hidden_Data = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 4)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 5)
 }
 
 func (x *Secret) SetHash(v string) {
 	x.xxx_hidden_Hash = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 4)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 5)
 }
 
 func (x *Secret) SetLastModified(v *timestamppb.Timestamp) {
 	x.xxx_hidden_LastModified = v
 }
 
+func (x *Secret) SetDeletedAt(v *timestamppb.Timestamp) {
+	x.xxx_hidden_DeletedAt = v
+}
+
 func (x *Secret) HasId() bool {
 	if x == nil {
 		return false
@@ -553,6 +565,13 @@ func (x *Secret) HasLastModified() bool {
 	return x.xxx_hidden_LastModified != nil
 }
 
+func (x *Secret) HasDeletedAt() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_DeletedAt != nil
+}
+
 func (x *Secret) ClearId() {
 	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
 	x.xxx_hidden_Id = nil
@@ -572,6 +591,10 @@ func (x *Secret) ClearLastModified() {
 	x.xxx_hidden_LastModified = nil
 }
 
+func (x *Secret) ClearDeletedAt() {
+	x.xxx_hidden_DeletedAt = nil
+}
+
 type Secret_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
@@ -579,6 +602,7 @@ type Secret_builder struct {
 	Data         []byte
 	Hash         *string
 	LastModified *timestamppb.Timestamp
+	DeletedAt    *timestamppb.Timestamp
 }
 
 func (b0 Secret_builder) Build() *Secret {
@@ -586,18 +610,19 @@ func (b0 Secret_builder) Build() *Secret {
 	b, x := &b0, m0
 	_, _ = b, x
 	if b.Id != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 4)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 5)
 		x.xxx_hidden_Id = b.Id
 	}
 	if b.Data != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 4)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 5)
 		x.xxx_hidden_Data = b.Data
 	}
 	if b.Hash != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 4)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 5)
 		x.xxx_hidden_Hash = b.Hash
 	}
 	x.xxx_hidden_LastModified = b.LastModified
+	x.xxx_hidden_DeletedAt = b.DeletedAt
 	return m0
 }
 
@@ -893,6 +918,7 @@ func (b0 GetSecretResponse_builder) Build() *GetSecretResponse {
 type DeleteSecretRequest struct {
 	state                  protoimpl.MessageState `protogen:"opaque.v1"`
 	xxx_hidden_SecretId    *string                `protobuf:"bytes,1,opt,name=secret_id,json=secretId"`
+	xxx_hidden_Purge       bool                   `protobuf:"varint,2,opt,name=purge"`
 	XXX_raceDetectHookData protoimpl.RaceDetectHookData
 	XXX_presence           [1]uint32
 	unknownFields          protoimpl.UnknownFields
@@ -934,9 +960,21 @@ func (x *DeleteSecretRequest) GetSecretId() string {
 	return ""
 }
 
+func (x *DeleteSecretRequest) GetPurge() bool {
+	if x != nil {
+		return x.xxx_hidden_Purge
+	}
+	return false
+}
+
 func (x *DeleteSecretRequest) SetSecretId(v string) {
 	x.xxx_hidden_SecretId = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 2)
+}
+
+func (x *DeleteSecretRequest) SetPurge(v bool) {
+	x.xxx_hidden_Purge = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
 }
 
 func (x *DeleteSecretRequest) HasSecretId() bool {
@@ -946,15 +984,28 @@ func (x *DeleteSecretRequest) HasSecretId() bool {
 	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
 }
 
+func (x *DeleteSecretRequest) HasPurge() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
 func (x *DeleteSecretRequest) ClearSecretId() {
 	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
 	x.xxx_hidden_SecretId = nil
 }
 
+func (x *DeleteSecretRequest) ClearPurge() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Purge = false
+}
+
 type DeleteSecretRequest_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
 	SecretId *string
+	Purge    *bool
 }
 
 func (b0 DeleteSecretRequest_builder) Build() *DeleteSecretRequest {
@@ -962,9 +1013,13 @@ func (b0 DeleteSecretRequest_builder) Build() *DeleteSecretRequest {
 	b, x := &b0, m0
 	_, _ = b, x
 	if b.SecretId != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 2)
 		x.xxx_hidden_SecretId = b.SecretId
 	}
+	if b.Purge != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_Purge = *b.Purge
+	}
 	return m0
 }
 
@@ -1145,96 +1200,1622 @@ func (b0 ListSecretsResponse_builder) Build() *ListSecretsResponse {
 	return m0
 }
 
-var File_internal_proto_api_proto protoreflect.FileDescriptor
+type Attachment struct {
+	state                   protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Id           *string                `protobuf:"bytes,1,opt,name=id"`
+	xxx_hidden_SecretId     *string                `protobuf:"bytes,2,opt,name=secret_id,json=secretId"`
+	xxx_hidden_Data         []byte                 `protobuf:"bytes,3,opt,name=data"`
+	xxx_hidden_Hash         *string                `protobuf:"bytes,4,opt,name=hash"`
+	xxx_hidden_LastModified *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_modified,json=lastModified"`
+	xxx_hidden_Header       []byte                 `protobuf:"bytes,6,opt,name=header"`
+	xxx_hidden_Chunks       int32                  `protobuf:"varint,7,opt,name=chunks"`
+	xxx_hidden_Size         int64                  `protobuf:"varint,8,opt,name=size"`
+	XXX_raceDetectHookData  protoimpl.RaceDetectHookData
+	XXX_presence            [1]uint32
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
 
-const file_internal_proto_api_proto_rawDesc = "" +
-	"\n" +
-	"\x18internal/proto/api.proto\x12\bgokeeper\x1a\x1fgoogle/protobuf/timestamp.proto\"C\n" +
-	"\x0fRegisterRequest\x12\x14\n" +
-	"\x05login\x18\x01 \x01(\tR\x05login\x12\x1a\n" +
-	"\bpassword\x18\x02 \x01(\tR\bpassword\"+\n" +
-	"\x10RegisterResponse\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\"@\n" +
-	"\fLoginRequest\x12\x14\n" +
-	"\x05login\x18\x01 \x01(\tR\x05login\x12\x1a\n" +
-	"\bpassword\x18\x02 \x01(\tR\bpassword\">\n" +
-	"\rLoginResponse\x12\x14\n" +
-	"\x05token\x18\x01 \x01(\tR\x05token\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\tR\x06userId\"\x81\x01\n" +
-	"\x06Secret\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
-	"\x04data\x18\x02 \x01(\fR\x04data\x12\x12\n" +
-	"\x04hash\x18\x03 \x01(\tR\x04hash\x12?\n" +
-	"\rlast_modified\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\flastModified\"<\n" +
-	"\x10SetSecretRequest\x12(\n" +
-	"\x06secret\x18\x01 \x01(\v2\x10.gokeeper.SecretR\x06secret\"-\n" +
-	"\x11SetSecretResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"/\n" +
-	"\x10GetSecretRequest\x12\x1b\n" +
-	"\tsecret_id\x18\x01 \x01(\tR\bsecretId\"=\n" +
-	"\x11GetSecretResponse\x12(\n" +
-	"\x06secret\x18\x01 \x01(\v2\x10.gokeeper.SecretR\x06secret\"2\n" +
-	"\x13DeleteSecretRequest\x12\x1b\n" +
-	"\tsecret_id\x18\x01 \x01(\tR\bsecretId\"0\n" +
-	"\x14DeleteSecretResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x14\n" +
-	"\x12ListSecretsRequest\"A\n" +
-	"\x13ListSecretsResponse\x12*\n" +
-	"\asecrets\x18\x01 \x03(\v2\x10.gokeeper.SecretR\asecrets2\x8a\x01\n" +
-	"\vAuthService\x12A\n" +
-	"\bRegister\x12\x19.gokeeper.RegisterRequest\x1a\x1a.gokeeper.RegisterResponse\x128\n" +
-	"\x05Login\x12\x16.gokeeper.LoginRequest\x1a\x17.gokeeper.LoginResponse2\xb6\x02\n" +
-	"\rSecretService\x12D\n" +
-	"\tSetSecret\x12\x1a.gokeeper.SetSecretRequest\x1a\x1b.gokeeper.SetSecretResponse\x12D\n" +
-	"\tGetSecret\x12\x1a.gokeeper.GetSecretRequest\x1a\x1b.gokeeper.GetSecretResponse\x12M\n" +
-	"\fDeleteSecret\x12\x1d.gokeeper.DeleteSecretRequest\x1a\x1e.gokeeper.DeleteSecretResponse\x12J\n" +
-	"\vListSecrets\x12\x1c.gokeeper.ListSecretsRequest\x1a\x1d.gokeeper.ListSecretsResponseB\x12Z\x10./internal/protob\beditionsp\xe8\a"
+func (x *Attachment) Reset() {
+	*x = Attachment{}
+	mi := &file_internal_proto_api_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
 
-var file_internal_proto_api_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
-var file_internal_proto_api_proto_goTypes = []any{
-	(*RegisterRequest)(nil),       // 0: gokeeper.RegisterRequest
-	(*RegisterResponse)(nil),      // 1: gokeeper.RegisterResponse
-	(*LoginRequest)(nil),          // 2: gokeeper.LoginRequest
-	(*LoginResponse)(nil),         // 3: gokeeper.LoginResponse
-	(*Secret)(nil),                // 4: gokeeper.Secret
-	(*SetSecretRequest)(nil),      // 5: gokeeper.SetSecretRequest
-	(*SetSecretResponse)(nil),     // 6: gokeeper.SetSecretResponse
-	(*GetSecretRequest)(nil),      // 7: gokeeper.GetSecretRequest
-	(*GetSecretResponse)(nil),     // 8: gokeeper.GetSecretResponse
-	(*DeleteSecretRequest)(nil),   // 9: gokeeper.DeleteSecretRequest
-	(*DeleteSecretResponse)(nil),  // 10: gokeeper.DeleteSecretResponse
-	(*ListSecretsRequest)(nil),    // 11: gokeeper.ListSecretsRequest
-	(*ListSecretsResponse)(nil),   // 12: gokeeper.ListSecretsResponse
-	(*timestamppb.Timestamp)(nil), // 13: google.protobuf.Timestamp
+func (x *Attachment) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
-var file_internal_proto_api_proto_depIdxs = []int32{
-	13, // 0: gokeeper.Secret.last_modified:type_name -> google.protobuf.Timestamp
-	4,  // 1: gokeeper.SetSecretRequest.secret:type_name -> gokeeper.Secret
-	4,  // 2: gokeeper.GetSecretResponse.secret:type_name -> gokeeper.Secret
-	4,  // 3: gokeeper.ListSecretsResponse.secrets:type_name -> gokeeper.Secret
-	0,  // 4: gokeeper.AuthService.Register:input_type -> gokeeper.RegisterRequest
-	2,  // 5: gokeeper.AuthService.Login:input_type -> gokeeper.LoginRequest
-	5,  // 6: gokeeper.SecretService.SetSecret:input_type -> gokeeper.SetSecretRequest
-	7,  // 7: gokeeper.SecretService.GetSecret:input_type -> gokeeper.GetSecretRequest
-	9,  // 8: gokeeper.SecretService.DeleteSecret:input_type -> gokeeper.DeleteSecretRequest
-	11, // 9: gokeeper.SecretService.ListSecrets:input_type -> gokeeper.ListSecretsRequest
-	1,  // 10: gokeeper.AuthService.Register:output_type -> gokeeper.RegisterResponse
-	3,  // 11: gokeeper.AuthService.Login:output_type -> gokeeper.LoginResponse
-	6,  // 12: gokeeper.SecretService.SetSecret:output_type -> gokeeper.SetSecretResponse
-	8,  // 13: gokeeper.SecretService.GetSecret:output_type -> gokeeper.GetSecretResponse
-	10, // 14: gokeeper.SecretService.DeleteSecret:output_type -> gokeeper.DeleteSecretResponse
-	12, // 15: gokeeper.SecretService.ListSecrets:output_type -> gokeeper.ListSecretsResponse
-	10, // [10:16] is the sub-list for method output_type
-	4,  // [4:10] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
+
+func (*Attachment) ProtoMessage() {}
+
+func (x *Attachment) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-func init() { file_internal_proto_api_proto_init() }
-func file_internal_proto_api_proto_init() {
-	if File_internal_proto_api_proto != nil {
-		return
+func (x *Attachment) GetId() string {
+	if x != nil {
+		if x.xxx_hidden_Id != nil {
+			return *x.xxx_hidden_Id
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *Attachment) GetSecretId() string {
+	if x != nil {
+		if x.xxx_hidden_SecretId != nil {
+			return *x.xxx_hidden_SecretId
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *Attachment) GetData() []byte {
+	if x != nil {
+		return x.xxx_hidden_Data
+	}
+	return nil
+}
+
+func (x *Attachment) GetHash() string {
+	if x != nil {
+		if x.xxx_hidden_Hash != nil {
+			return *x.xxx_hidden_Hash
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *Attachment) GetLastModified() *timestamppb.Timestamp {
+	if x != nil {
+		return x.xxx_hidden_LastModified
+	}
+	return nil
+}
+
+func (x *Attachment) GetHeader() []byte {
+	if x != nil {
+		return x.xxx_hidden_Header
+	}
+	return nil
+}
+
+func (x *Attachment) GetChunks() int32 {
+	if x != nil {
+		return x.xxx_hidden_Chunks
+	}
+	return 0
+}
+
+func (x *Attachment) GetSize() int64 {
+	if x != nil {
+		return x.xxx_hidden_Size
+	}
+	return 0
+}
+
+func (x *Attachment) SetId(v string) {
+	x.xxx_hidden_Id = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 8)
+}
+
+func (x *Attachment) SetSecretId(v string) {
+	x.xxx_hidden_SecretId = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 8)
+}
+
+func (x *Attachment) SetData(v []byte) {
+	if v == nil {
+		v = []byte{}
+	}
+	x.xxx_hidden_Data = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 8)
+}
+
+func (x *Attachment) SetHash(v string) {
+	x.xxx_hidden_Hash = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 8)
+}
+
+func (x *Attachment) SetLastModified(v *timestamppb.Timestamp) {
+	x.xxx_hidden_LastModified = v
+}
+
+func (x *Attachment) SetHeader(v []byte) {
+	if v == nil {
+		v = []byte{}
+	}
+	x.xxx_hidden_Header = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 5, 8)
+}
+
+func (x *Attachment) SetChunks(v int32) {
+	x.xxx_hidden_Chunks = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 6, 8)
+}
+
+func (x *Attachment) SetSize(v int64) {
+	x.xxx_hidden_Size = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 7, 8)
+}
+
+func (x *Attachment) HasId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *Attachment) HasSecretId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *Attachment) HasData() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *Attachment) HasHash() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
+func (x *Attachment) HasLastModified() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_LastModified != nil
+}
+
+func (x *Attachment) HasHeader() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 5)
+}
+
+func (x *Attachment) HasChunks() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 6)
+}
+
+func (x *Attachment) HasSize() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 7)
+}
+
+func (x *Attachment) ClearId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Id = nil
+}
+
+func (x *Attachment) ClearSecretId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_SecretId = nil
+}
+
+func (x *Attachment) ClearData() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Data = nil
+}
+
+func (x *Attachment) ClearHash() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_Hash = nil
+}
+
+func (x *Attachment) ClearLastModified() {
+	x.xxx_hidden_LastModified = nil
+}
+
+func (x *Attachment) ClearHeader() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 5)
+	x.xxx_hidden_Header = nil
+}
+
+func (x *Attachment) ClearChunks() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 6)
+	x.xxx_hidden_Chunks = 0
+}
+
+func (x *Attachment) ClearSize() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 7)
+	x.xxx_hidden_Size = 0
+}
+
+type Attachment_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Id           *string
+	SecretId     *string
+	Data         []byte
+	Hash         *string
+	LastModified *timestamppb.Timestamp
+	Header       []byte
+	Chunks       *int32
+	Size         *int64
+}
+
+func (b0 Attachment_builder) Build() *Attachment {
+	m0 := &Attachment{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Id != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 8)
+		x.xxx_hidden_Id = b.Id
+	}
+	if b.SecretId != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 8)
+		x.xxx_hidden_SecretId = b.SecretId
+	}
+	if b.Data != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 8)
+		x.xxx_hidden_Data = b.Data
+	}
+	if b.Hash != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 8)
+		x.xxx_hidden_Hash = b.Hash
+	}
+	x.xxx_hidden_LastModified = b.LastModified
+	if b.Header != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 5, 8)
+		x.xxx_hidden_Header = b.Header
+	}
+	if b.Chunks != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 6, 8)
+		x.xxx_hidden_Chunks = *b.Chunks
+	}
+	if b.Size != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 7, 8)
+		x.xxx_hidden_Size = *b.Size
+	}
+	return m0
+}
+
+type AttachmentChunk struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Index       int32                  `protobuf:"varint,1,opt,name=index"`
+	xxx_hidden_Data        []byte                 `protobuf:"bytes,2,opt,name=data"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *AttachmentChunk) Reset() {
+	*x = AttachmentChunk{}
+	mi := &file_internal_proto_api_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachmentChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachmentChunk) ProtoMessage() {}
+
+func (x *AttachmentChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *AttachmentChunk) GetIndex() int32 {
+	if x != nil {
+		return x.xxx_hidden_Index
+	}
+	return 0
+}
+
+func (x *AttachmentChunk) GetData() []byte {
+	if x != nil {
+		return x.xxx_hidden_Data
+	}
+	return nil
+}
+
+func (x *AttachmentChunk) SetIndex(v int32) {
+	x.xxx_hidden_Index = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 2)
+}
+
+func (x *AttachmentChunk) SetData(v []byte) {
+	if v == nil {
+		v = []byte{}
+	}
+	x.xxx_hidden_Data = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+}
+
+func (x *AttachmentChunk) HasIndex() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *AttachmentChunk) HasData() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *AttachmentChunk) ClearIndex() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Index = 0
+}
+
+func (x *AttachmentChunk) ClearData() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Data = nil
+}
+
+type AttachmentChunk_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Index *int32
+	Data  []byte
+}
+
+func (b0 AttachmentChunk_builder) Build() *AttachmentChunk {
+	m0 := &AttachmentChunk{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Index != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 2)
+		x.xxx_hidden_Index = *b.Index
+	}
+	if b.Data != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_Data = b.Data
+	}
+	return m0
+}
+
+type AttachmentUpload struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Attachment  *Attachment            `protobuf:"bytes,1,opt,name=attachment"`
+	xxx_hidden_FirstChunk  int32                  `protobuf:"varint,2,opt,name=first_chunk,json=firstChunk"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *AttachmentUpload) Reset() {
+	*x = AttachmentUpload{}
+	mi := &file_internal_proto_api_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachmentUpload) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachmentUpload) ProtoMessage() {}
+
+func (x *AttachmentUpload) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *AttachmentUpload) GetAttachment() *Attachment {
+	if x != nil {
+		return x.xxx_hidden_Attachment
+	}
+	return nil
+}
+
+func (x *AttachmentUpload) GetFirstChunk() int32 {
+	if x != nil {
+		return x.xxx_hidden_FirstChunk
+	}
+	return 0
+}
+
+func (x *AttachmentUpload) SetAttachment(v *Attachment) {
+	x.xxx_hidden_Attachment = v
+}
+
+func (x *AttachmentUpload) SetFirstChunk(v int32) {
+	x.xxx_hidden_FirstChunk = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+}
+
+func (x *AttachmentUpload) HasAttachment() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Attachment != nil
+}
+
+func (x *AttachmentUpload) HasFirstChunk() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *AttachmentUpload) ClearAttachment() {
+	x.xxx_hidden_Attachment = nil
+}
+
+func (x *AttachmentUpload) ClearFirstChunk() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_FirstChunk = 0
+}
+
+type AttachmentUpload_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Attachment *Attachment
+	FirstChunk *int32
+}
+
+func (b0 AttachmentUpload_builder) Build() *AttachmentUpload {
+	m0 := &AttachmentUpload{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Attachment = b.Attachment
+	if b.FirstChunk != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_FirstChunk = *b.FirstChunk
+	}
+	return m0
+}
+
+type UploadAttachmentRequest struct {
+	state              protoimpl.MessageState            `protogen:"opaque.v1"`
+	xxx_hidden_Payload isUploadAttachmentRequest_Payload `protobuf_oneof:"payload"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *UploadAttachmentRequest) Reset() {
+	*x = UploadAttachmentRequest{}
+	mi := &file_internal_proto_api_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadAttachmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadAttachmentRequest) ProtoMessage() {}
+
+func (x *UploadAttachmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *UploadAttachmentRequest) GetUpload() *AttachmentUpload {
+	if x != nil {
+		if x, ok := x.xxx_hidden_Payload.(*uploadAttachmentRequest_Upload); ok {
+			return x.Upload
+		}
+	}
+	return nil
+}
+
+func (x *UploadAttachmentRequest) GetChunk() *AttachmentChunk {
+	if x != nil {
+		if x, ok := x.xxx_hidden_Payload.(*uploadAttachmentRequest_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+func (x *UploadAttachmentRequest) SetUpload(v *AttachmentUpload) {
+	if v == nil {
+		x.xxx_hidden_Payload = nil
+		return
+	}
+	x.xxx_hidden_Payload = &uploadAttachmentRequest_Upload{v}
+}
+
+func (x *UploadAttachmentRequest) SetChunk(v *AttachmentChunk) {
+	if v == nil {
+		x.xxx_hidden_Payload = nil
+		return
+	}
+	x.xxx_hidden_Payload = &uploadAttachmentRequest_Chunk{v}
+}
+
+func (x *UploadAttachmentRequest) HasPayload() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Payload != nil
+}
+
+func (x *UploadAttachmentRequest) HasUpload() bool {
+	if x == nil {
+		return false
+	}
+	_, ok := x.xxx_hidden_Payload.(*uploadAttachmentRequest_Upload)
+	return ok
+}
+
+func (x *UploadAttachmentRequest) HasChunk() bool {
+	if x == nil {
+		return false
+	}
+	_, ok := x.xxx_hidden_Payload.(*uploadAttachmentRequest_Chunk)
+	return ok
+}
+
+func (x *UploadAttachmentRequest) ClearPayload() {
+	x.xxx_hidden_Payload = nil
+}
+
+func (x *UploadAttachmentRequest) ClearUpload() {
+	if _, ok := x.xxx_hidden_Payload.(*uploadAttachmentRequest_Upload); ok {
+		x.xxx_hidden_Payload = nil
+	}
+}
+
+func (x *UploadAttachmentRequest) ClearChunk() {
+	if _, ok := x.xxx_hidden_Payload.(*uploadAttachmentRequest_Chunk); ok {
+		x.xxx_hidden_Payload = nil
+	}
+}
+
+const UploadAttachmentRequest_Payload_not_set_case case_UploadAttachmentRequest_Payload = 0
+const UploadAttachmentRequest_Upload_case case_UploadAttachmentRequest_Payload = 1
+const UploadAttachmentRequest_Chunk_case case_UploadAttachmentRequest_Payload = 2
+
+func (x *UploadAttachmentRequest) WhichPayload() case_UploadAttachmentRequest_Payload {
+	if x == nil {
+		return UploadAttachmentRequest_Payload_not_set_case
+	}
+	switch x.xxx_hidden_Payload.(type) {
+	case *uploadAttachmentRequest_Upload:
+		return UploadAttachmentRequest_Upload_case
+	case *uploadAttachmentRequest_Chunk:
+		return UploadAttachmentRequest_Chunk_case
+	default:
+		return UploadAttachmentRequest_Payload_not_set_case
+	}
+}
+
+type UploadAttachmentRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Fields of oneof xxx_hidden_Payload:
+	Upload *AttachmentUpload
+	Chunk  *AttachmentChunk
+	// -- end of xxx_hidden_Payload
+}
+
+func (b0 UploadAttachmentRequest_builder) Build() *UploadAttachmentRequest {
+	m0 := &UploadAttachmentRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Upload != nil {
+		x.xxx_hidden_Payload = &uploadAttachmentRequest_Upload{b.Upload}
+	}
+	if b.Chunk != nil {
+		x.xxx_hidden_Payload = &uploadAttachmentRequest_Chunk{b.Chunk}
+	}
+	return m0
+}
+
+type case_UploadAttachmentRequest_Payload protoreflect.FieldNumber
+
+func (x case_UploadAttachmentRequest_Payload) String() string {
+	md := file_internal_proto_api_proto_msgTypes[16].Descriptor()
+	if x == 0 {
+		return "not set"
+	}
+	return protoimpl.X.MessageFieldStringOf(md, protoreflect.FieldNumber(x))
+}
+
+type isUploadAttachmentRequest_Payload interface {
+	isUploadAttachmentRequest_Payload()
+}
+
+type uploadAttachmentRequest_Upload struct {
+	Upload *AttachmentUpload `protobuf:"bytes,1,opt,name=upload,oneof"`
+}
+
+type uploadAttachmentRequest_Chunk struct {
+	Chunk *AttachmentChunk `protobuf:"bytes,2,opt,name=chunk,oneof"`
+}
+
+func (*uploadAttachmentRequest_Upload) isUploadAttachmentRequest_Payload() {}
+
+func (*uploadAttachmentRequest_Chunk) isUploadAttachmentRequest_Payload() {}
+
+type UploadAttachmentResponse struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Success     bool                   `protobuf:"varint,1,opt,name=success"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *UploadAttachmentResponse) Reset() {
+	*x = UploadAttachmentResponse{}
+	mi := &file_internal_proto_api_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadAttachmentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadAttachmentResponse) ProtoMessage() {}
+
+func (x *UploadAttachmentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *UploadAttachmentResponse) GetSuccess() bool {
+	if x != nil {
+		return x.xxx_hidden_Success
+	}
+	return false
+}
+
+func (x *UploadAttachmentResponse) SetSuccess(v bool) {
+	x.xxx_hidden_Success = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *UploadAttachmentResponse) HasSuccess() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *UploadAttachmentResponse) ClearSuccess() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Success = false
+}
+
+type UploadAttachmentResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Success *bool
+}
+
+func (b0 UploadAttachmentResponse_builder) Build() *UploadAttachmentResponse {
+	m0 := &UploadAttachmentResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Success != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_Success = *b.Success
+	}
+	return m0
+}
+
+type DownloadAttachmentRequest struct {
+	state                   protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_AttachmentId *string                `protobuf:"bytes,1,opt,name=attachment_id,json=attachmentId"`
+	XXX_raceDetectHookData  protoimpl.RaceDetectHookData
+	XXX_presence            [1]uint32
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *DownloadAttachmentRequest) Reset() {
+	*x = DownloadAttachmentRequest{}
+	mi := &file_internal_proto_api_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadAttachmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadAttachmentRequest) ProtoMessage() {}
+
+func (x *DownloadAttachmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *DownloadAttachmentRequest) GetAttachmentId() string {
+	if x != nil {
+		if x.xxx_hidden_AttachmentId != nil {
+			return *x.xxx_hidden_AttachmentId
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *DownloadAttachmentRequest) SetAttachmentId(v string) {
+	x.xxx_hidden_AttachmentId = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *DownloadAttachmentRequest) HasAttachmentId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *DownloadAttachmentRequest) ClearAttachmentId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_AttachmentId = nil
+}
+
+type DownloadAttachmentRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	AttachmentId *string
+}
+
+func (b0 DownloadAttachmentRequest_builder) Build() *DownloadAttachmentRequest {
+	m0 := &DownloadAttachmentRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.AttachmentId != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_AttachmentId = b.AttachmentId
+	}
+	return m0
+}
+
+type DownloadAttachmentResponse struct {
+	state              protoimpl.MessageState               `protogen:"opaque.v1"`
+	xxx_hidden_Payload isDownloadAttachmentResponse_Payload `protobuf_oneof:"payload"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *DownloadAttachmentResponse) Reset() {
+	*x = DownloadAttachmentResponse{}
+	mi := &file_internal_proto_api_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadAttachmentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadAttachmentResponse) ProtoMessage() {}
+
+func (x *DownloadAttachmentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *DownloadAttachmentResponse) GetAttachment() *Attachment {
+	if x != nil {
+		if x, ok := x.xxx_hidden_Payload.(*downloadAttachmentResponse_Attachment); ok {
+			return x.Attachment
+		}
+	}
+	return nil
+}
+
+func (x *DownloadAttachmentResponse) GetChunk() *AttachmentChunk {
+	if x != nil {
+		if x, ok := x.xxx_hidden_Payload.(*downloadAttachmentResponse_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+func (x *DownloadAttachmentResponse) SetAttachment(v *Attachment) {
+	if v == nil {
+		x.xxx_hidden_Payload = nil
+		return
+	}
+	x.xxx_hidden_Payload = &downloadAttachmentResponse_Attachment{v}
+}
+
+func (x *DownloadAttachmentResponse) SetChunk(v *AttachmentChunk) {
+	if v == nil {
+		x.xxx_hidden_Payload = nil
+		return
+	}
+	x.xxx_hidden_Payload = &downloadAttachmentResponse_Chunk{v}
+}
+
+func (x *DownloadAttachmentResponse) HasPayload() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Payload != nil
+}
+
+func (x *DownloadAttachmentResponse) HasAttachment() bool {
+	if x == nil {
+		return false
+	}
+	_, ok := x.xxx_hidden_Payload.(*downloadAttachmentResponse_Attachment)
+	return ok
+}
+
+func (x *DownloadAttachmentResponse) HasChunk() bool {
+	if x == nil {
+		return false
+	}
+	_, ok := x.xxx_hidden_Payload.(*downloadAttachmentResponse_Chunk)
+	return ok
+}
+
+func (x *DownloadAttachmentResponse) ClearPayload() {
+	x.xxx_hidden_Payload = nil
+}
+
+func (x *DownloadAttachmentResponse) ClearAttachment() {
+	if _, ok := x.xxx_hidden_Payload.(*downloadAttachmentResponse_Attachment); ok {
+		x.xxx_hidden_Payload = nil
+	}
+}
+
+func (x *DownloadAttachmentResponse) ClearChunk() {
+	if _, ok := x.xxx_hidden_Payload.(*downloadAttachmentResponse_Chunk); ok {
+		x.xxx_hidden_Payload = nil
+	}
+}
+
+const DownloadAttachmentResponse_Payload_not_set_case case_DownloadAttachmentResponse_Payload = 0
+const DownloadAttachmentResponse_Attachment_case case_DownloadAttachmentResponse_Payload = 1
+const DownloadAttachmentResponse_Chunk_case case_DownloadAttachmentResponse_Payload = 2
+
+func (x *DownloadAttachmentResponse) WhichPayload() case_DownloadAttachmentResponse_Payload {
+	if x == nil {
+		return DownloadAttachmentResponse_Payload_not_set_case
+	}
+	switch x.xxx_hidden_Payload.(type) {
+	case *downloadAttachmentResponse_Attachment:
+		return DownloadAttachmentResponse_Attachment_case
+	case *downloadAttachmentResponse_Chunk:
+		return DownloadAttachmentResponse_Chunk_case
+	default:
+		return DownloadAttachmentResponse_Payload_not_set_case
+	}
+}
+
+type DownloadAttachmentResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Fields of oneof xxx_hidden_Payload:
+	Attachment *Attachment
+	Chunk      *AttachmentChunk
+	// -- end of xxx_hidden_Payload
+}
+
+func (b0 DownloadAttachmentResponse_builder) Build() *DownloadAttachmentResponse {
+	m0 := &DownloadAttachmentResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Attachment != nil {
+		x.xxx_hidden_Payload = &downloadAttachmentResponse_Attachment{b.Attachment}
+	}
+	if b.Chunk != nil {
+		x.xxx_hidden_Payload = &downloadAttachmentResponse_Chunk{b.Chunk}
+	}
+	return m0
+}
+
+type case_DownloadAttachmentResponse_Payload protoreflect.FieldNumber
+
+func (x case_DownloadAttachmentResponse_Payload) String() string {
+	md := file_internal_proto_api_proto_msgTypes[19].Descriptor()
+	if x == 0 {
+		return "not set"
+	}
+	return protoimpl.X.MessageFieldStringOf(md, protoreflect.FieldNumber(x))
+}
+
+type isDownloadAttachmentResponse_Payload interface {
+	isDownloadAttachmentResponse_Payload()
+}
+
+type downloadAttachmentResponse_Attachment struct {
+	Attachment *Attachment `protobuf:"bytes,1,opt,name=attachment,oneof"`
+}
+
+type downloadAttachmentResponse_Chunk struct {
+	Chunk *AttachmentChunk `protobuf:"bytes,2,opt,name=chunk,oneof"`
+}
+
+func (*downloadAttachmentResponse_Attachment) isDownloadAttachmentResponse_Payload() {}
+
+func (*downloadAttachmentResponse_Chunk) isDownloadAttachmentResponse_Payload() {}
+
+type GetAttachmentUploadRequest struct {
+	state                   protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_AttachmentId *string                `protobuf:"bytes,1,opt,name=attachment_id,json=attachmentId"`
+	XXX_raceDetectHookData  protoimpl.RaceDetectHookData
+	XXX_presence            [1]uint32
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *GetAttachmentUploadRequest) Reset() {
+	*x = GetAttachmentUploadRequest{}
+	mi := &file_internal_proto_api_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAttachmentUploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAttachmentUploadRequest) ProtoMessage() {}
+
+func (x *GetAttachmentUploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *GetAttachmentUploadRequest) GetAttachmentId() string {
+	if x != nil {
+		if x.xxx_hidden_AttachmentId != nil {
+			return *x.xxx_hidden_AttachmentId
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *GetAttachmentUploadRequest) SetAttachmentId(v string) {
+	x.xxx_hidden_AttachmentId = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *GetAttachmentUploadRequest) HasAttachmentId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *GetAttachmentUploadRequest) ClearAttachmentId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_AttachmentId = nil
+}
+
+type GetAttachmentUploadRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	AttachmentId *string
+}
+
+func (b0 GetAttachmentUploadRequest_builder) Build() *GetAttachmentUploadRequest {
+	m0 := &GetAttachmentUploadRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.AttachmentId != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_AttachmentId = b.AttachmentId
+	}
+	return m0
+}
+
+type GetAttachmentUploadResponse struct {
+	state                     protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Attachment     *Attachment            `protobuf:"bytes,1,opt,name=attachment"`
+	xxx_hidden_ReceivedChunks int32                  `protobuf:"varint,2,opt,name=received_chunks,json=receivedChunks"`
+	XXX_raceDetectHookData    protoimpl.RaceDetectHookData
+	XXX_presence              [1]uint32
+	unknownFields             protoimpl.UnknownFields
+	sizeCache                 protoimpl.SizeCache
+}
+
+func (x *GetAttachmentUploadResponse) Reset() {
+	*x = GetAttachmentUploadResponse{}
+	mi := &file_internal_proto_api_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAttachmentUploadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAttachmentUploadResponse) ProtoMessage() {}
+
+func (x *GetAttachmentUploadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *GetAttachmentUploadResponse) GetAttachment() *Attachment {
+	if x != nil {
+		return x.xxx_hidden_Attachment
+	}
+	return nil
+}
+
+func (x *GetAttachmentUploadResponse) GetReceivedChunks() int32 {
+	if x != nil {
+		return x.xxx_hidden_ReceivedChunks
+	}
+	return 0
+}
+
+func (x *GetAttachmentUploadResponse) SetAttachment(v *Attachment) {
+	x.xxx_hidden_Attachment = v
+}
+
+func (x *GetAttachmentUploadResponse) SetReceivedChunks(v int32) {
+	x.xxx_hidden_ReceivedChunks = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+}
+
+func (x *GetAttachmentUploadResponse) HasAttachment() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Attachment != nil
+}
+
+func (x *GetAttachmentUploadResponse) HasReceivedChunks() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *GetAttachmentUploadResponse) ClearAttachment() {
+	x.xxx_hidden_Attachment = nil
+}
+
+func (x *GetAttachmentUploadResponse) ClearReceivedChunks() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_ReceivedChunks = 0
+}
+
+type GetAttachmentUploadResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Attachment     *Attachment
+	ReceivedChunks *int32
+}
+
+func (b0 GetAttachmentUploadResponse_builder) Build() *GetAttachmentUploadResponse {
+	m0 := &GetAttachmentUploadResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Attachment = b.Attachment
+	if b.ReceivedChunks != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_ReceivedChunks = *b.ReceivedChunks
+	}
+	return m0
+}
+
+type DeleteAttachmentRequest struct {
+	state                   protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_AttachmentId *string                `protobuf:"bytes,1,opt,name=attachment_id,json=attachmentId"`
+	XXX_raceDetectHookData  protoimpl.RaceDetectHookData
+	XXX_presence            [1]uint32
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *DeleteAttachmentRequest) Reset() {
+	*x = DeleteAttachmentRequest{}
+	mi := &file_internal_proto_api_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteAttachmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteAttachmentRequest) ProtoMessage() {}
+
+func (x *DeleteAttachmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *DeleteAttachmentRequest) GetAttachmentId() string {
+	if x != nil {
+		if x.xxx_hidden_AttachmentId != nil {
+			return *x.xxx_hidden_AttachmentId
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *DeleteAttachmentRequest) SetAttachmentId(v string) {
+	x.xxx_hidden_AttachmentId = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *DeleteAttachmentRequest) HasAttachmentId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *DeleteAttachmentRequest) ClearAttachmentId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_AttachmentId = nil
+}
+
+type DeleteAttachmentRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	AttachmentId *string
+}
+
+func (b0 DeleteAttachmentRequest_builder) Build() *DeleteAttachmentRequest {
+	m0 := &DeleteAttachmentRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.AttachmentId != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_AttachmentId = b.AttachmentId
+	}
+	return m0
+}
+
+type DeleteAttachmentResponse struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Success     bool                   `protobuf:"varint,1,opt,name=success"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *DeleteAttachmentResponse) Reset() {
+	*x = DeleteAttachmentResponse{}
+	mi := &file_internal_proto_api_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteAttachmentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteAttachmentResponse) ProtoMessage() {}
+
+func (x *DeleteAttachmentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *DeleteAttachmentResponse) GetSuccess() bool {
+	if x != nil {
+		return x.xxx_hidden_Success
+	}
+	return false
+}
+
+func (x *DeleteAttachmentResponse) SetSuccess(v bool) {
+	x.xxx_hidden_Success = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *DeleteAttachmentResponse) HasSuccess() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *DeleteAttachmentResponse) ClearSuccess() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Success = false
+}
+
+type DeleteAttachmentResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Success *bool
+}
+
+func (b0 DeleteAttachmentResponse_builder) Build() *DeleteAttachmentResponse {
+	m0 := &DeleteAttachmentResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Success != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_Success = *b.Success
+	}
+	return m0
+}
+
+type ListAttachmentsRequest struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAttachmentsRequest) Reset() {
+	*x = ListAttachmentsRequest{}
+	mi := &file_internal_proto_api_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAttachmentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAttachmentsRequest) ProtoMessage() {}
+
+func (x *ListAttachmentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type ListAttachmentsRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 ListAttachmentsRequest_builder) Build() *ListAttachmentsRequest {
+	m0 := &ListAttachmentsRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+type ListAttachmentsResponse struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Attachments *[]*Attachment         `protobuf:"bytes,1,rep,name=attachments"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *ListAttachmentsResponse) Reset() {
+	*x = ListAttachmentsResponse{}
+	mi := &file_internal_proto_api_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAttachmentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAttachmentsResponse) ProtoMessage() {}
+
+func (x *ListAttachmentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *ListAttachmentsResponse) GetAttachments() []*Attachment {
+	if x != nil {
+		if x.xxx_hidden_Attachments != nil {
+			return *x.xxx_hidden_Attachments
+		}
+	}
+	return nil
+}
+
+func (x *ListAttachmentsResponse) SetAttachments(v []*Attachment) {
+	x.xxx_hidden_Attachments = &v
+}
+
+type ListAttachmentsResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Attachments []*Attachment
+}
+
+func (b0 ListAttachmentsResponse_builder) Build() *ListAttachmentsResponse {
+	m0 := &ListAttachmentsResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Attachments = &b.Attachments
+	return m0
+}
+
+var File_internal_proto_api_proto protoreflect.FileDescriptor
+
+const file_internal_proto_api_proto_rawDesc = "" +
+	"\n" +
+	"\x18internal/proto/api.proto\x12\bgokeeper\x1a\x1fgoogle/protobuf/timestamp.proto\"C\n" +
+	"\x0fRegisterRequest\x12\x14\n" +
+	"\x05login\x18\x01 \x01(\tR\x05login\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"+\n" +
+	"\x10RegisterResponse\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"@\n" +
+	"\fLoginRequest\x12\x14\n" +
+	"\x05login\x18\x01 \x01(\tR\x05login\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\">\n" +
+	"\rLoginResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"\xbc\x01\n" +
+	"\x06Secret\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x12\x12\n" +
+	"\x04hash\x18\x03 \x01(\tR\x04hash\x12?\n" +
+	"\rlast_modified\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\flastModified\x129\n" +
+	"\n" +
+	"deleted_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAt\"<\n" +
+	"\x10SetSecretRequest\x12(\n" +
+	"\x06secret\x18\x01 \x01(\v2\x10.gokeeper.SecretR\x06secret\"-\n" +
+	"\x11SetSecretResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"/\n" +
+	"\x10GetSecretRequest\x12\x1b\n" +
+	"\tsecret_id\x18\x01 \x01(\tR\bsecretId\"=\n" +
+	"\x11GetSecretResponse\x12(\n" +
+	"\x06secret\x18\x01 \x01(\v2\x10.gokeeper.SecretR\x06secret\"H\n" +
+	"\x13DeleteSecretRequest\x12\x1b\n" +
+	"\tsecret_id\x18\x01 \x01(\tR\bsecretId\x12\x14\n" +
+	"\x05purge\x18\x02 \x01(\bR\x05purge\"0\n" +
+	"\x14DeleteSecretResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x14\n" +
+	"\x12ListSecretsRequest\"A\n" +
+	"\x13ListSecretsResponse\x12*\n" +
+	"\asecrets\x18\x01 \x03(\v2\x10.gokeeper.SecretR\asecrets\"\xe6\x01\n" +
+	"\n" +
+	"Attachment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tsecret_id\x18\x02 \x01(\tR\bsecretId\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\x12\x12\n" +
+	"\x04hash\x18\x04 \x01(\tR\x04hash\x12?\n" +
+	"\rlast_modified\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\flastModified\x12\x16\n" +
+	"\x06header\x18\x06 \x01(\fR\x06header\x12\x16\n" +
+	"\x06chunks\x18\a \x01(\x05R\x06chunks\x12\x12\n" +
+	"\x04size\x18\b \x01(\x03R\x04size\";\n" +
+	"\x0fAttachmentChunk\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\"i\n" +
+	"\x10AttachmentUpload\x124\n" +
+	"\n" +
+	"attachment\x18\x01 \x01(\v2\x14.gokeeper.AttachmentR\n" +
+	"attachment\x12\x1f\n" +
+	"\vfirst_chunk\x18\x02 \x01(\x05R\n" +
+	"firstChunk\"\x8d\x01\n" +
+	"\x17UploadAttachmentRequest\x124\n" +
+	"\x06upload\x18\x01 \x01(\v2\x1a.gokeeper.AttachmentUploadH\x00R\x06upload\x121\n" +
+	"\x05chunk\x18\x02 \x01(\v2\x19.gokeeper.AttachmentChunkH\x00R\x05chunkB\t\n" +
+	"\apayload\"4\n" +
+	"\x18UploadAttachmentResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"@\n" +
+	"\x19DownloadAttachmentRequest\x12#\n" +
+	"\rattachment_id\x18\x01 \x01(\tR\fattachmentId\"\x92\x01\n" +
+	"\x1aDownloadAttachmentResponse\x126\n" +
+	"\n" +
+	"attachment\x18\x01 \x01(\v2\x14.gokeeper.AttachmentH\x00R\n" +
+	"attachment\x121\n" +
+	"\x05chunk\x18\x02 \x01(\v2\x19.gokeeper.AttachmentChunkH\x00R\x05chunkB\t\n" +
+	"\apayload\"A\n" +
+	"\x1aGetAttachmentUploadRequest\x12#\n" +
+	"\rattachment_id\x18\x01 \x01(\tR\fattachmentId\"|\n" +
+	"\x1bGetAttachmentUploadResponse\x124\n" +
+	"\n" +
+	"attachment\x18\x01 \x01(\v2\x14.gokeeper.AttachmentR\n" +
+	"attachment\x12'\n" +
+	"\x0freceived_chunks\x18\x02 \x01(\x05R\x0ereceivedChunks\">\n" +
+	"\x17DeleteAttachmentRequest\x12#\n" +
+	"\rattachment_id\x18\x01 \x01(\tR\fattachmentId\"4\n" +
+	"\x18DeleteAttachmentResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x18\n" +
+	"\x16ListAttachmentsRequest\"Q\n" +
+	"\x17ListAttachmentsResponse\x126\n" +
+	"\vattachments\x18\x01 \x03(\v2\x14.gokeeper.AttachmentR\vattachments2\x8a\x01\n" +
+	"\vAuthService\x12A\n" +
+	"\bRegister\x12\x19.gokeeper.RegisterRequest\x1a\x1a.gokeeper.RegisterResponse\x128\n" +
+	"\x05Login\x12\x16.gokeeper.LoginRequest\x1a\x17.gokeeper.LoginResponse2\x8d\x06\n" +
+	"\rSecretService\x12D\n" +
+	"\tSetSecret\x12\x1a.gokeeper.SetSecretRequest\x1a\x1b.gokeeper.SetSecretResponse\x12D\n" +
+	"\tGetSecret\x12\x1a.gokeeper.GetSecretRequest\x1a\x1b.gokeeper.GetSecretResponse\x12M\n" +
+	"\fDeleteSecret\x12\x1d.gokeeper.DeleteSecretRequest\x1a\x1e.gokeeper.DeleteSecretResponse\x12J\n" +
+	"\vListSecrets\x12\x1c.gokeeper.ListSecretsRequest\x1a\x1d.gokeeper.ListSecretsResponse\x12[\n" +
+	"\x10UploadAttachment\x12!.gokeeper.UploadAttachmentRequest\x1a\".gokeeper.UploadAttachmentResponse(\x01\x12a\n" +
+	"\x12DownloadAttachment\x12#.gokeeper.DownloadAttachmentRequest\x1a$.gokeeper.DownloadAttachmentResponse0\x01\x12b\n" +
+	"\x13GetAttachmentUpload\x12$.gokeeper.GetAttachmentUploadRequest\x1a%.gokeeper.GetAttachmentUploadResponse\x12Y\n" +
+	"\x10DeleteAttachment\x12!.gokeeper.DeleteAttachmentRequest\x1a\".gokeeper.DeleteAttachmentResponse\x12V\n" +
+	"\x0fListAttachments\x12 .gokeeper.ListAttachmentsRequest\x1a!.gokeeper.ListAttachmentsResponseB\x12Z\x10./internal/protob\beditionsp\xe8\a"
+
+var file_internal_proto_api_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
+var file_internal_proto_api_proto_goTypes = []any{
+	(*RegisterRequest)(nil),             // 0: gokeeper.RegisterRequest
+	(*RegisterResponse)(nil),            // 1: gokeeper.RegisterResponse
+	(*LoginRequest)(nil),                // 2: gokeeper.LoginRequest
+	(*LoginResponse)(nil),               // 3: gokeeper.LoginResponse
+	(*Secret)(nil),                      // 4: gokeeper.Secret
+	(*SetSecretRequest)(nil),            // 5: gokeeper.SetSecretRequest
+	(*SetSecretResponse)(nil),           // 6: gokeeper.SetSecretResponse
+	(*GetSecretRequest)(nil),            // 7: gokeeper.GetSecretRequest
+	(*GetSecretResponse)(nil),           // 8: gokeeper.GetSecretResponse
+	(*DeleteSecretRequest)(nil),         // 9: gokeeper.DeleteSecretRequest
+	(*DeleteSecretResponse)(nil),        // 10: gokeeper.DeleteSecretResponse
+	(*ListSecretsRequest)(nil),          // 11: gokeeper.ListSecretsRequest
+	(*ListSecretsResponse)(nil),         // 12: gokeeper.ListSecretsResponse
+	(*Attachment)(nil),                  // 13: gokeeper.Attachment
+	(*AttachmentChunk)(nil),             // 14: gokeeper.AttachmentChunk
+	(*AttachmentUpload)(nil),            // 15: gokeeper.AttachmentUpload
+	(*UploadAttachmentRequest)(nil),     // 16: gokeeper.UploadAttachmentRequest
+	(*UploadAttachmentResponse)(nil),    // 17: gokeeper.UploadAttachmentResponse
+	(*DownloadAttachmentRequest)(nil),   // 18: gokeeper.DownloadAttachmentRequest
+	(*DownloadAttachmentResponse)(nil),  // 19: gokeeper.DownloadAttachmentResponse
+	(*GetAttachmentUploadRequest)(nil),  // 20: gokeeper.GetAttachmentUploadRequest
+	(*GetAttachmentUploadResponse)(nil), // 21: gokeeper.GetAttachmentUploadResponse
+	(*DeleteAttachmentRequest)(nil),     // 22: gokeeper.DeleteAttachmentRequest
+	(*DeleteAttachmentResponse)(nil),    // 23: gokeeper.DeleteAttachmentResponse
+	(*ListAttachmentsRequest)(nil),      // 24: gokeeper.ListAttachmentsRequest
+	(*ListAttachmentsResponse)(nil),     // 25: gokeeper.ListAttachmentsResponse
+	(*timestamppb.Timestamp)(nil),       // 26: google.protobuf.Timestamp
+}
+var file_internal_proto_api_proto_depIdxs = []int32{
+	26, // 0: gokeeper.Secret.last_modified:type_name -> google.protobuf.Timestamp
+	26, // 1: gokeeper.Secret.deleted_at:type_name -> google.protobuf.Timestamp
+	4,  // 2: gokeeper.SetSecretRequest.secret:type_name -> gokeeper.Secret
+	4,  // 3: gokeeper.GetSecretResponse.secret:type_name -> gokeeper.Secret
+	4,  // 4: gokeeper.ListSecretsResponse.secrets:type_name -> gokeeper.Secret
+	26, // 5: gokeeper.Attachment.last_modified:type_name -> google.protobuf.Timestamp
+	13, // 6: gokeeper.AttachmentUpload.attachment:type_name -> gokeeper.Attachment
+	15, // 7: gokeeper.UploadAttachmentRequest.upload:type_name -> gokeeper.AttachmentUpload
+	14, // 8: gokeeper.UploadAttachmentRequest.chunk:type_name -> gokeeper.AttachmentChunk
+	13, // 9: gokeeper.DownloadAttachmentResponse.attachment:type_name -> gokeeper.Attachment
+	14, // 10: gokeeper.DownloadAttachmentResponse.chunk:type_name -> gokeeper.AttachmentChunk
+	13, // 11: gokeeper.GetAttachmentUploadResponse.attachment:type_name -> gokeeper.Attachment
+	13, // 12: gokeeper.ListAttachmentsResponse.attachments:type_name -> gokeeper.Attachment
+	0,  // 13: gokeeper.AuthService.Register:input_type -> gokeeper.RegisterRequest
+	2,  // 14: gokeeper.AuthService.Login:input_type -> gokeeper.LoginRequest
+	5,  // 15: gokeeper.SecretService.SetSecret:input_type -> gokeeper.SetSecretRequest
+	7,  // 16: gokeeper.SecretService.GetSecret:input_type -> gokeeper.GetSecretRequest
+	9,  // 17: gokeeper.SecretService.DeleteSecret:input_type -> gokeeper.DeleteSecretRequest
+	11, // 18: gokeeper.SecretService.ListSecrets:input_type -> gokeeper.ListSecretsRequest
+	16, // 19: gokeeper.SecretService.UploadAttachment:input_type -> gokeeper.UploadAttachmentRequest
+	18, // 20: gokeeper.SecretService.DownloadAttachment:input_type -> gokeeper.DownloadAttachmentRequest
+	20, // 21: gokeeper.SecretService.GetAttachmentUpload:input_type -> gokeeper.GetAttachmentUploadRequest
+	22, // 22: gokeeper.SecretService.DeleteAttachment:input_type -> gokeeper.DeleteAttachmentRequest
+	24, // 23: gokeeper.SecretService.ListAttachments:input_type -> gokeeper.ListAttachmentsRequest
+	1,  // 24: gokeeper.AuthService.Register:output_type -> gokeeper.RegisterResponse
+	3,  // 25: gokeeper.AuthService.Login:output_type -> gokeeper.LoginResponse
+	6,  // 26: gokeeper.SecretService.SetSecret:output_type -> gokeeper.SetSecretResponse
+	8,  // 27: gokeeper.SecretService.GetSecret:output_type -> gokeeper.GetSecretResponse
+	10, // 28: gokeeper.SecretService.DeleteSecret:output_type -> gokeeper.DeleteSecretResponse
+	12, // 29: gokeeper.SecretService.ListSecrets:output_type -> gokeeper.ListSecretsResponse
+	17, // 30: gokeeper.SecretService.UploadAttachment:output_type -> gokeeper.UploadAttachmentResponse
+	19, // 31: gokeeper.SecretService.DownloadAttachment:output_type -> gokeeper.DownloadAttachmentResponse
+	21, // 32: gokeeper.SecretService.GetAttachmentUpload:output_type -> gokeeper.GetAttachmentUploadResponse
+	23, // 33: gokeeper.SecretService.DeleteAttachment:output_type -> gokeeper.DeleteAttachmentResponse
+	25, // 34: gokeeper.SecretService.ListAttachments:output_type -> gokeeper.ListAttachmentsResponse
+	24, // [24:35] is the sub-list for method output_type
+	13, // [13:24] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_internal_proto_api_proto_init() }
+func file_internal_proto_api_proto_init() {
+	if File_internal_proto_api_proto != nil {
+		return
+	}
+	file_internal_proto_api_proto_msgTypes[16].OneofWrappers = []any{
+		(*uploadAttachmentRequest_Upload)(nil),
+		(*uploadAttachmentRequest_Chunk)(nil),
+	}
+	file_internal_proto_api_proto_msgTypes[19].OneofWrappers = []any{
+		(*downloadAttachmentResponse_Attachment)(nil),
+		(*downloadAttachmentResponse_Chunk)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -1242,7 +2823,7 @@ func file_internal_proto_api_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_proto_api_proto_rawDesc), len(file_internal_proto_api_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   13,
+			NumMessages:   26,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
@@ -31,13 +31,17 @@ func NewApp() (*App, error) {
 
 	repos := repository.NewRepositories()
 	jwtManager := token.NewJWTManager(cfg.JWTSecret, time.Hour)
-	svc := NewService(db, jwtManager, nil, repos)
+	svc := NewService(db, jwtManager, nil, repos, cfg.UserQuota, cfg.TrashRetention)
 
 	grpcServer := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(
 			LoggingInterceptor(),
 			AuthInterceptor(svc),
 		),
+		grpc.ChainStreamInterceptor(
+			LoggingStreamInterceptor(),
+			AuthStreamInterceptor(svc),
+		),
 	)
 
 	proto.RegisterAuthServiceServer(grpcServer, NewAuthHandler(svc))
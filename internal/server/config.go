@@ -3,11 +3,18 @@ package server
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	DBURL     string
 	JWTSecret string
+	// UserQuota caps the bytes stored per user, 0 disables it
+	UserQuota int64
+	// TrashRetention is how long deleted secrets are kept, 0 keeps them
+	TrashRetention time.Duration
 }
 
 func LoadCfg() (*Config, error) {
@@ -24,9 +31,58 @@ func LoadCfg() (*Config, error) {
 		return nil, fmt.Errorf("GOKEEPER_JWT_SECRET environment variable is required")
 	}
 
+	userQuota := int64(defaultUserQuota)
+	if value := os.Getenv("GOKEEPER_USER_QUOTA"); value != "" {
+		quota, err := parseSize(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GOKEEPER_USER_QUOTA: %w", err)
+		}
+		userQuota = quota
+	}
+
+	trashDays := defaultTrashDays
+	if value := os.Getenv("GOKEEPER_TRASH_DAYS"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			return nil, fmt.Errorf("invalid GOKEEPER_TRASH_DAYS: %q is not a number of days", value)
+		}
+		trashDays = days
+	}
+
 	cfg := &Config{
-		DBURL:     dbURL,
-		JWTSecret: jwtSecret,
+		DBURL:          dbURL,
+		JWTSecret:      jwtSecret,
+		UserQuota:      userQuota,
+		TrashRetention: time.Duration(trashDays) * 24 * time.Hour,
 	}
 	return cfg, nil
 }
+
+// parseSize parses a byte count with an optional KB, MB or GB suffix, in
+// powers of 1024.
+func parseSize(value string) (int64, error) {
+	units := []struct {
+		suffix string
+		size   int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	}
+
+	number, unit := strings.ToUpper(strings.TrimSpace(value)), int64(1)
+	for _, u := range units {
+		if trimmed, found := strings.CutSuffix(number, u.suffix); found {
+			number, unit = strings.TrimSpace(trimmed), u.size
+			break
+		}
+	}
+
+	size, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("%q is not a size", value)
+	}
+
+	return size * unit, nil
+}
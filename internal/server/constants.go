@@ -1,3 +1,8 @@
 package server
 
-const maxSecretSize = 5 * 1024 * 1024 // 5MB
+const (
+	maxSecretSize          = 5 * 1024 * 1024 // 5MB
+	maxAttachmentChunkSize = 1 * 1024 * 1024 // 1MB
+	defaultUserQuota       = 512 * 1024 * 1024
+	defaultTrashDays       = 30
+)
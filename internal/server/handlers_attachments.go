@@ -0,0 +1,214 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/etoneja/go-keeper/internal/proto"
+	"github.com/etoneja/go-keeper/internal/server/repository"
+	"github.com/etoneja/go-keeper/internal/server/stypes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *SecretHandler) UploadAttachment(stream grpc.ClientStreamingServer[proto.UploadAttachmentRequest, proto.UploadAttachmentResponse]) error {
+	ctx := stream.Context()
+
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if !req.HasUpload() {
+		return status.Error(codes.InvalidArgument, "upload must start with the attachment")
+	}
+
+	reqAttachment := req.GetUpload().GetAttachment()
+
+	attachment := &stypes.Attachment{
+		ID:           reqAttachment.GetId(),
+		UserID:       userID,
+		SecretID:     reqAttachment.GetSecretId(),
+		Data:         reqAttachment.GetData(),
+		Hash:         reqAttachment.GetHash(),
+		LastModified: reqAttachment.GetLastModified().AsTime(),
+		Header:       reqAttachment.GetHeader(),
+		Chunks:       int(reqAttachment.GetChunks()),
+		Size:         reqAttachment.GetSize(),
+	}
+
+	recv := func() (*stypes.AttachmentChunk, error) {
+		req, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		if !req.HasChunk() {
+			return nil, fmt.Errorf("%w: expected a chunk", ErrInvalidUpload)
+		}
+
+		chunk := &stypes.AttachmentChunk{
+			Index: int(req.GetChunk().GetIndex()),
+			Data:  req.GetChunk().GetData(),
+		}
+		return chunk, nil
+	}
+
+	err = h.service.UploadAttachment(ctx, attachment, int(req.GetUpload().GetFirstChunk()), recv)
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrInvalidUpload), errors.Is(err, ErrSecretTooLarge):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrUploadMismatch):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, ErrQuotaExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case status.Code(err) != codes.Unknown:
+		// NOTE: The client went away, the chunks received so far are kept
+		return err
+	default:
+		log.Printf("UploadAttachment failed: %v", err)
+		return status.Error(codes.Internal, "failed to upload attachment")
+	}
+
+	resp := &proto.UploadAttachmentResponse{}
+	resp.SetSuccess(true)
+
+	return stream.SendAndClose(resp)
+}
+
+func (h *SecretHandler) GetAttachmentUpload(ctx context.Context, req *proto.GetAttachmentUploadRequest) (*proto.GetAttachmentUploadResponse, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	upload, err := h.service.GetAttachmentUpload(ctx, userID, req.GetAttachmentId())
+	if errors.Is(err, repository.ErrAttachmentNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		log.Printf("GetAttachmentUpload failed: %v", err)
+		return nil, status.Error(codes.Internal, "failed to get attachment upload")
+	}
+
+	resp := &proto.GetAttachmentUploadResponse{}
+	resp.SetAttachment(newFullProtoAttachment(upload.Attachment))
+	resp.SetReceivedChunks(int32(upload.ReceivedChunks))
+
+	return resp, nil
+}
+
+func (h *SecretHandler) DownloadAttachment(req *proto.DownloadAttachmentRequest, stream grpc.ServerStreamingServer[proto.DownloadAttachmentResponse]) error {
+	ctx := stream.Context()
+
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	attachment, err := h.service.GetAttachment(ctx, userID, req.GetAttachmentId())
+	if errors.Is(err, repository.ErrAttachmentNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		log.Printf("GetAttachment failed: %v", err)
+		return status.Error(codes.Internal, "failed to get attachment")
+	}
+
+	resp := &proto.DownloadAttachmentResponse{}
+	resp.SetAttachment(newFullProtoAttachment(attachment))
+	if err := stream.Send(resp); err != nil {
+		return err
+	}
+
+	err = h.service.ReadAttachmentChunks(ctx, userID, attachment.ID, func(chunk *stypes.AttachmentChunk) error {
+		respChunk := &proto.AttachmentChunk{}
+		respChunk.SetIndex(int32(chunk.Index))
+		respChunk.SetData(chunk.Data)
+
+		resp := &proto.DownloadAttachmentResponse{}
+		resp.SetChunk(respChunk)
+
+		return stream.Send(resp)
+	})
+	if err != nil && status.Code(err) == codes.Unknown {
+		log.Printf("DownloadAttachment failed: %v", err)
+		return status.Error(codes.Internal, "failed to read attachment")
+	}
+
+	return err
+}
+
+func (h *SecretHandler) DeleteAttachment(ctx context.Context, req *proto.DeleteAttachmentRequest) (*proto.DeleteAttachmentResponse, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	err = h.service.DeleteAttachment(ctx, userID, req.GetAttachmentId())
+	if errors.Is(err, repository.ErrAttachmentNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		log.Printf("DeleteAttachment failed: %v", err)
+		return nil, status.Error(codes.Internal, "failed to delete attachment")
+	}
+
+	resp := &proto.DeleteAttachmentResponse{}
+	resp.SetSuccess(true)
+
+	return resp, nil
+}
+
+func (h *SecretHandler) ListAttachments(ctx context.Context, req *proto.ListAttachmentsRequest) (*proto.ListAttachmentsResponse, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	attachments, err := h.service.ListAttachments(ctx, userID)
+	if err != nil {
+		log.Printf("ListAttachments failed: %v", err)
+		return nil, status.Error(codes.Internal, "failed to retrieve attachments")
+	}
+
+	respAttachments := make([]*proto.Attachment, len(attachments))
+	for i, attachment := range attachments {
+		respAttachments[i] = newProtoAttachment(attachment)
+	}
+
+	resp := &proto.ListAttachmentsResponse{}
+	resp.SetAttachments(respAttachments)
+
+	return resp, nil
+}
+
+// newProtoAttachment converts the attachment without its data and header.
+func newProtoAttachment(attachment *stypes.Attachment) *proto.Attachment {
+	respAttachment := &proto.Attachment{}
+
+	respAttachment.SetId(attachment.ID)
+	respAttachment.SetSecretId(attachment.SecretID)
+	respAttachment.SetHash(attachment.Hash)
+	respAttachment.SetLastModified(timestamppb.New(attachment.LastModified))
+	respAttachment.SetChunks(int32(attachment.Chunks))
+	respAttachment.SetSize(attachment.Size)
+
+	return respAttachment
+}
+
+func newFullProtoAttachment(attachment *stypes.Attachment) *proto.Attachment {
+	respAttachment := newProtoAttachment(attachment)
+	respAttachment.SetData(attachment.Data)
+	respAttachment.SetHeader(attachment.Header)
+
+	return respAttachment
+}
@@ -0,0 +1,278 @@
+package server
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/etoneja/go-keeper/internal/proto"
+	"github.com/etoneja/go-keeper/internal/server/repository"
+	"github.com/etoneja/go-keeper/internal/server/stypes"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// uploadStream feeds requests to UploadAttachment.
+type uploadStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	requests []*proto.UploadAttachmentRequest
+	resp     *proto.UploadAttachmentResponse
+}
+
+func (s *uploadStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *uploadStream) Recv() (*proto.UploadAttachmentRequest, error) {
+	if len(s.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+	return req, nil
+}
+
+func (s *uploadStream) SendAndClose(resp *proto.UploadAttachmentResponse) error {
+	s.resp = resp
+	return nil
+}
+
+// downloadStream collects the responses of DownloadAttachment.
+type downloadStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses []*proto.DownloadAttachmentResponse
+}
+
+func (s *downloadStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *downloadStream) Send(resp *proto.DownloadAttachmentResponse) error {
+	s.responses = append(s.responses, resp)
+	return nil
+}
+
+func TestSecretHandler_UploadAttachment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := NewMockServicer(ctrl)
+	handler := NewSecretHandler(mockService)
+
+	newStream := func(ctx context.Context) *uploadStream {
+		reqAttachment := &proto.Attachment{}
+		reqAttachment.SetId("attachment1")
+		reqAttachment.SetSecretId("secret1")
+		reqAttachment.SetData([]byte("description"))
+		reqAttachment.SetHash("hash123")
+		reqAttachment.SetLastModified(timestamppb.New(time.Now()))
+		reqAttachment.SetHeader([]byte("header"))
+		reqAttachment.SetChunks(2)
+		reqAttachment.SetSize(6)
+
+		upload := &proto.AttachmentUpload{}
+		upload.SetAttachment(reqAttachment)
+		upload.SetFirstChunk(1)
+
+		first := &proto.UploadAttachmentRequest{}
+		first.SetUpload(upload)
+
+		chunk := &proto.AttachmentChunk{}
+		chunk.SetIndex(1)
+		chunk.SetData([]byte("chunk1"))
+
+		second := &proto.UploadAttachmentRequest{}
+		second.SetChunk(chunk)
+
+		return &uploadStream{ctx: ctx, requests: []*proto.UploadAttachmentRequest{first, second}}
+	}
+
+	ctx := context.WithValue(context.Background(), userIDKey, "user123")
+
+	t.Run("success", func(t *testing.T) {
+		stream := newStream(ctx)
+
+		mockService.EXPECT().UploadAttachment(gomock.Any(), gomock.Any(), 1, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, attachment *stypes.Attachment, firstChunk int, recv ChunkReceiver) error {
+				assert.Equal(t, "user123", attachment.UserID)
+				assert.Equal(t, "secret1", attachment.SecretID)
+				assert.Equal(t, []byte("header"), attachment.Header)
+				assert.Equal(t, 2, attachment.Chunks)
+
+				chunk, err := recv()
+				require.NoError(t, err)
+				assert.Equal(t, 1, chunk.Index)
+				assert.Equal(t, []byte("chunk1"), chunk.Data)
+
+				_, err = recv()
+				assert.ErrorIs(t, err, io.EOF)
+				return nil
+			})
+
+		require.NoError(t, handler.UploadAttachment(stream))
+		assert.True(t, stream.resp.GetSuccess())
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		err := handler.UploadAttachment(newStream(context.Background()))
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("chunk first", func(t *testing.T) {
+		stream := newStream(ctx)
+		stream.requests = stream.requests[1:]
+
+		err := handler.UploadAttachment(stream)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	errorCodes := map[error]codes.Code{
+		ErrInvalidUpload:  codes.InvalidArgument,
+		ErrUploadMismatch: codes.FailedPrecondition,
+		ErrQuotaExceeded:  codes.ResourceExhausted,
+	}
+	for serviceErr, code := range errorCodes {
+		t.Run(serviceErr.Error(), func(t *testing.T) {
+			mockService.EXPECT().UploadAttachment(gomock.Any(), gomock.Any(), 1, gomock.Any()).Return(serviceErr)
+
+			err := handler.UploadAttachment(newStream(ctx))
+			assert.Equal(t, code, status.Code(err))
+		})
+	}
+}
+
+func TestSecretHandler_GetAttachmentUpload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := NewMockServicer(ctrl)
+	handler := NewSecretHandler(mockService)
+
+	ctx := context.WithValue(context.Background(), userIDKey, "user123")
+	req := &proto.GetAttachmentUploadRequest{}
+	req.SetAttachmentId("attachment1")
+
+	t.Run("success", func(t *testing.T) {
+		upload := &stypes.AttachmentUpload{
+			Attachment:     &stypes.Attachment{ID: "attachment1", Header: []byte("header"), Chunks: 3},
+			ReceivedChunks: 2,
+		}
+		mockService.EXPECT().GetAttachmentUpload(gomock.Any(), "user123", "attachment1").Return(upload, nil)
+
+		resp, err := handler.GetAttachmentUpload(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("header"), resp.GetAttachment().GetHeader())
+		assert.Equal(t, int32(3), resp.GetAttachment().GetChunks())
+		assert.Equal(t, int32(2), resp.GetReceivedChunks())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockService.EXPECT().GetAttachmentUpload(gomock.Any(), "user123", "attachment1").Return(nil, repository.ErrAttachmentNotFound)
+
+		_, err := handler.GetAttachmentUpload(ctx, req)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestSecretHandler_DownloadAttachment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := NewMockServicer(ctrl)
+	handler := NewSecretHandler(mockService)
+
+	ctx := context.WithValue(context.Background(), userIDKey, "user123")
+	req := &proto.DownloadAttachmentRequest{}
+	req.SetAttachmentId("attachment1")
+
+	t.Run("success", func(t *testing.T) {
+		attachment := &stypes.Attachment{
+			ID:           "attachment1",
+			UserID:       "user123",
+			SecretID:     "secret1",
+			Data:         []byte("description"),
+			Hash:         "hash123",
+			LastModified: time.Now(),
+			Header:       []byte("header"),
+			Chunks:       2,
+			Size:         12,
+		}
+		mockService.EXPECT().GetAttachment(gomock.Any(), "user123", "attachment1").Return(attachment, nil)
+		mockService.EXPECT().ReadAttachmentChunks(gomock.Any(), "user123", "attachment1", gomock.Any()).DoAndReturn(
+			func(ctx context.Context, userID, attachmentID string, fn func(*stypes.AttachmentChunk) error) error {
+				for i, data := range []string{"chunk0", "chunk1"} {
+					if err := fn(&stypes.AttachmentChunk{Index: i, Data: []byte(data)}); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+
+		stream := &downloadStream{ctx: ctx}
+		require.NoError(t, handler.DownloadAttachment(req, stream))
+
+		require.Len(t, stream.responses, 3)
+		assert.Equal(t, "secret1", stream.responses[0].GetAttachment().GetSecretId())
+		assert.Equal(t, []byte("description"), stream.responses[0].GetAttachment().GetData())
+		assert.Equal(t, []byte("header"), stream.responses[0].GetAttachment().GetHeader())
+		assert.Equal(t, int32(1), stream.responses[2].GetChunk().GetIndex())
+		assert.Equal(t, []byte("chunk1"), stream.responses[2].GetChunk().GetData())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockService.EXPECT().GetAttachment(gomock.Any(), "user123", "attachment1").Return(nil, repository.ErrAttachmentNotFound)
+
+		stream := &downloadStream{ctx: ctx}
+		err := handler.DownloadAttachment(req, stream)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Empty(t, stream.responses)
+	})
+}
+
+func TestSecretHandler_DeleteAttachment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := NewMockServicer(ctrl)
+	handler := NewSecretHandler(mockService)
+
+	ctx := context.WithValue(context.Background(), userIDKey, "user123")
+	req := &proto.DeleteAttachmentRequest{}
+	req.SetAttachmentId("attachment1")
+
+	mockService.EXPECT().DeleteAttachment(gomock.Any(), "user123", "attachment1").Return(nil)
+
+	resp, err := handler.DeleteAttachment(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, resp.GetSuccess())
+}
+
+func TestSecretHandler_ListAttachments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := NewMockServicer(ctrl)
+	handler := NewSecretHandler(mockService)
+
+	ctx := context.WithValue(context.Background(), userIDKey, "user123")
+
+	attachments := []*stypes.Attachment{
+		{ID: "attachment1", SecretID: "secret1", Hash: "hash1", Data: []byte("not listed"), LastModified: time.Now()},
+		{ID: "attachment2", SecretID: "secret1", Hash: "hash2", LastModified: time.Now()},
+	}
+	mockService.EXPECT().ListAttachments(gomock.Any(), "user123").Return(attachments, nil)
+
+	resp, err := handler.ListAttachments(ctx, &proto.ListAttachmentsRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.GetAttachments(), 2)
+	assert.Equal(t, "attachment1", resp.GetAttachments()[0].GetId())
+	assert.Empty(t, resp.GetAttachments()[0].GetData())
+}
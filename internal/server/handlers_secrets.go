@@ -35,6 +35,10 @@ func (h *SecretHandler) SetSecret(ctx context.Context, req *proto.SetSecretReque
 		Hash:         reqSecret.GetHash(),
 		LastModified: reqSecret.GetLastModified().AsTime(),
 	}
+	if reqSecret.HasDeletedAt() {
+		deletedAt := reqSecret.GetDeletedAt().AsTime()
+		secret.DeletedAt = &deletedAt
+	}
 
 	err = h.service.SetSecret(ctx, secret)
 	if err != nil {
@@ -61,11 +65,7 @@ func (h *SecretHandler) GetSecret(ctx context.Context, req *proto.GetSecretReque
 		return nil, status.Error(codes.Internal, "failed to get secret")
 	}
 
-	respSecret := &proto.Secret{}
-
-	respSecret.SetId(secret.ID)
-	respSecret.SetHash(secret.Hash)
-	respSecret.SetLastModified(timestamppb.New(secret.LastModified))
+	respSecret := newProtoSecret(secret)
 	respSecret.SetData(secret.Data)
 
 	resp := &proto.GetSecretResponse{}
@@ -80,7 +80,11 @@ func (h *SecretHandler) DeleteSecret(ctx context.Context, req *proto.DeleteSecre
 		return nil, status.Error(codes.Unauthenticated, "authentication required")
 	}
 
-	err = h.service.DeleteSecret(ctx, userID, req.GetSecretId())
+	if req.GetPurge() {
+		err = h.service.PurgeSecret(ctx, userID, req.GetSecretId())
+	} else {
+		err = h.service.DeleteSecret(ctx, userID, req.GetSecretId())
+	}
 	if err != nil {
 		// TODO: return NotFound if not found
 		log.Printf("DeleteSecret failed: %v", err)
@@ -107,13 +111,7 @@ func (h *SecretHandler) ListSecrets(ctx context.Context, req *proto.ListSecretsR
 
 	respSecrets := make([]*proto.Secret, len(secrets))
 	for i, secret := range secrets {
-		respSecret := &proto.Secret{}
-
-		respSecret.SetId(secret.ID)
-		respSecret.SetHash(secret.Hash)
-		respSecret.SetLastModified(timestamppb.New(secret.LastModified))
-
-		respSecrets[i] = respSecret
+		respSecrets[i] = newProtoSecret(secret)
 	}
 
 	resp := &proto.ListSecretsResponse{}
@@ -121,3 +119,17 @@ func (h *SecretHandler) ListSecrets(ctx context.Context, req *proto.ListSecretsR
 
 	return resp, nil
 }
+
+// newProtoSecret converts the secret without its data.
+func newProtoSecret(secret *stypes.Secret) *proto.Secret {
+	respSecret := &proto.Secret{}
+
+	respSecret.SetId(secret.ID)
+	respSecret.SetHash(secret.Hash)
+	respSecret.SetLastModified(timestamppb.New(secret.LastModified))
+	if secret.DeletedAt != nil {
+		respSecret.SetDeletedAt(timestamppb.New(*secret.DeletedAt))
+	}
+
+	return respSecret
+}
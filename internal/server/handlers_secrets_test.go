@@ -151,6 +151,20 @@ func TestSecretHandler_DeleteSecret(t *testing.T) {
 		assert.True(t, resp.GetSuccess())
 	})
 
+	t.Run("purge", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), userIDKey, "user123")
+
+		req := &proto.DeleteSecretRequest{}
+		req.SetSecretId("secret1")
+		req.SetPurge(true)
+
+		mockService.EXPECT().PurgeSecret(gomock.Any(), "user123", "secret1").Return(nil)
+
+		resp, err := handler.DeleteSecret(ctx, req)
+		require.NoError(t, err)
+		assert.True(t, resp.GetSuccess())
+	})
+
 	t.Run("unauthorized", func(t *testing.T) {
 		req := &proto.DeleteSecretRequest{}
 		req.SetSecretId("secret1")
@@ -199,6 +213,7 @@ func TestSecretHandler_ListSecrets(t *testing.T) {
 				ID:           "secret2",
 				Hash:         "hash2",
 				LastModified: now.Add(-time.Hour),
+				DeletedAt:    &now,
 			},
 		}
 
@@ -213,10 +228,12 @@ func TestSecretHandler_ListSecrets(t *testing.T) {
 		assert.Equal(t, "secret1", respSecrets[0].GetId())
 		assert.Equal(t, "hash1", respSecrets[0].GetHash())
 		assert.True(t, respSecrets[0].GetLastModified().AsTime().Equal(now))
+		assert.False(t, respSecrets[0].HasDeletedAt())
 
 		assert.Equal(t, "secret2", respSecrets[1].GetId())
 		assert.Equal(t, "hash2", respSecrets[1].GetHash())
 		assert.True(t, respSecrets[1].GetLastModified().AsTime().Equal(now.Add(-time.Hour)))
+		assert.True(t, respSecrets[1].GetDeletedAt().AsTime().Equal(now))
 	})
 
 	t.Run("empty list", func(t *testing.T) {
@@ -19,26 +19,58 @@ func AuthInterceptor(service *Service) grpc.UnaryServerInterceptor {
 			return handler(ctx, req)
 		}
 
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			return nil, status.Error(codes.Unauthenticated, "missing metadata")
-		}
-		tokens := md["authorization"]
-		if len(tokens) == 0 {
-			return nil, status.Error(codes.Unauthenticated, "missing authorization token")
+		ctx, err := authenticate(ctx, service)
+		if err != nil {
+			return nil, err
 		}
 
-		token := tokens[0]
-		userID, err := service.tokenManager.ValidateToken(token)
+		return handler(ctx, req)
+	}
+}
+
+// AuthStreamInterceptor authenticates streaming calls, none of which is open
+// to anonymous users.
+func AuthStreamInterceptor(service *Service) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context(), service)
 		if err != nil {
-			return nil, status.Error(codes.Unauthenticated, "invalid token")
+			return err
 		}
 
-		ctx = context.WithValue(ctx, userIDKey, userID)
-		return handler(ctx, req)
+		return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
 	}
 }
 
+// authenticatedStream carries the user ID in its context.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticate returns ctx with the ID of the user its token belongs to.
+func authenticate(ctx context.Context, service *Service) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing metadata")
+	}
+	tokens := md["authorization"]
+	if len(tokens) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing authorization token")
+	}
+
+	token := tokens[0]
+	userID, err := service.tokenManager.ValidateToken(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	return context.WithValue(ctx, userIDKey, userID), nil
+}
+
 func getUserIDFromContext(ctx context.Context) (string, error) {
 	userID, ok := ctx.Value(userIDKey).(string)
 	if !ok {
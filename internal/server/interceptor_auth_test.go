@@ -122,6 +122,50 @@ func TestAuthInterceptor(t *testing.T) {
 	})
 }
 
+// contextStream is a server stream with a context only.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestAuthStreamInterceptor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTokenManager := token.NewMockTokenManager(ctrl)
+	interceptor := AuthStreamInterceptor(&Service{tokenManager: mockTokenManager})
+	info := &grpc.StreamServerInfo{FullMethod: "/gokeeper.SecretService/UploadAttachment"}
+
+	t.Run("valid token", func(t *testing.T) {
+		md := metadata.MD{"authorization": []string{"valid-token"}}
+		stream := &contextStream{ctx: metadata.NewIncomingContext(context.Background(), md)}
+
+		mockTokenManager.EXPECT().ValidateToken("valid-token").Return("user123", nil)
+
+		err := interceptor(nil, stream, info, func(srv any, stream grpc.ServerStream) error {
+			userID, err := getUserIDFromContext(stream.Context())
+			assert.NoError(t, err)
+			assert.Equal(t, "user123", userID)
+			return nil
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		stream := &contextStream{ctx: metadata.NewIncomingContext(context.Background(), metadata.MD{})}
+
+		err := interceptor(nil, stream, info, func(srv any, stream grpc.ServerStream) error {
+			t.Fatal("handler must not be called")
+			return nil
+		})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
 func TestGetUserIDFromContext(t *testing.T) {
 	t.Run("userID exists", func(t *testing.T) {
 		ctx := context.WithValue(context.Background(), userIDKey, "user123")
@@ -29,3 +29,24 @@ func LoggingInterceptor() grpc.UnaryServerInterceptor {
 		return resp, err
 	}
 }
+
+func LoggingStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+
+		err := handler(srv, stream)
+
+		duration := time.Since(start)
+		statusCode := status.Code(err)
+
+		if err != nil {
+			log.Printf("gRPC stream failed: %s, duration: %s, status: %s, error: %v",
+				info.FullMethod, duration, statusCode, err)
+		} else {
+			log.Printf("gRPC stream completed: %s, duration: %s, status: %s",
+				info.FullMethod, duration, statusCode)
+		}
+
+		return err
+	}
+}
@@ -55,3 +55,18 @@ func TestLoggingInterceptor(t *testing.T) {
 		assert.Equal(t, "delayed", resp)
 	})
 }
+
+func TestLoggingStreamInterceptor(t *testing.T) {
+	interceptor := LoggingStreamInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/service.Stream"}
+
+	err := interceptor(nil, nil, info, func(srv any, stream grpc.ServerStream) error {
+		return nil
+	})
+	assert.NoError(t, err)
+
+	err = interceptor(nil, nil, info, func(srv any, stream grpc.ServerStream) error {
+		return status.Error(codes.Canceled, "canceled")
+	})
+	assert.Equal(t, codes.Canceled, status.Code(err))
+}
@@ -12,5 +12,12 @@ type Servicer interface {
 	SetSecret(ctx context.Context, secret *stypes.Secret) error
 	GetSecret(ctx context.Context, userID, secretID string) (*stypes.Secret, error)
 	DeleteSecret(ctx context.Context, userID, secretID string) error
+	PurgeSecret(ctx context.Context, userID, secretID string) error
 	ListSecrets(ctx context.Context, userID string) ([]*stypes.Secret, error)
+	UploadAttachment(ctx context.Context, attachment *stypes.Attachment, firstChunk int, recv ChunkReceiver) error
+	GetAttachmentUpload(ctx context.Context, userID, attachmentID string) (*stypes.AttachmentUpload, error)
+	GetAttachment(ctx context.Context, userID, attachmentID string) (*stypes.Attachment, error)
+	ReadAttachmentChunks(ctx context.Context, userID, attachmentID string, fn func(*stypes.AttachmentChunk) error) error
+	DeleteAttachment(ctx context.Context, userID, attachmentID string) error
+	ListAttachments(ctx context.Context, userID string) ([]*stypes.Attachment, error)
 }
@@ -35,6 +35,20 @@ func (m *MockServicer) EXPECT() *MockServicerMockRecorder {
 	return m.recorder
 }
 
+// DeleteAttachment mocks base method.
+func (m *MockServicer) DeleteAttachment(ctx context.Context, userID, attachmentID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAttachment", ctx, userID, attachmentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAttachment indicates an expected call of DeleteAttachment.
+func (mr *MockServicerMockRecorder) DeleteAttachment(ctx, userID, attachmentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAttachment", reflect.TypeOf((*MockServicer)(nil).DeleteAttachment), ctx, userID, attachmentID)
+}
+
 // DeleteSecret mocks base method.
 func (m *MockServicer) DeleteSecret(ctx context.Context, userID, secretID string) error {
 	m.ctrl.T.Helper()
@@ -49,6 +63,36 @@ func (mr *MockServicerMockRecorder) DeleteSecret(ctx, userID, secretID interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecret", reflect.TypeOf((*MockServicer)(nil).DeleteSecret), ctx, userID, secretID)
 }
 
+// GetAttachment mocks base method.
+func (m *MockServicer) GetAttachment(ctx context.Context, userID, attachmentID string) (*stypes.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttachment", ctx, userID, attachmentID)
+	ret0, _ := ret[0].(*stypes.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAttachment indicates an expected call of GetAttachment.
+func (mr *MockServicerMockRecorder) GetAttachment(ctx, userID, attachmentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttachment", reflect.TypeOf((*MockServicer)(nil).GetAttachment), ctx, userID, attachmentID)
+}
+
+// GetAttachmentUpload mocks base method.
+func (m *MockServicer) GetAttachmentUpload(ctx context.Context, userID, attachmentID string) (*stypes.AttachmentUpload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttachmentUpload", ctx, userID, attachmentID)
+	ret0, _ := ret[0].(*stypes.AttachmentUpload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAttachmentUpload indicates an expected call of GetAttachmentUpload.
+func (mr *MockServicerMockRecorder) GetAttachmentUpload(ctx, userID, attachmentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttachmentUpload", reflect.TypeOf((*MockServicer)(nil).GetAttachmentUpload), ctx, userID, attachmentID)
+}
+
 // GetSecret mocks base method.
 func (m *MockServicer) GetSecret(ctx context.Context, userID, secretID string) (*stypes.Secret, error) {
 	m.ctrl.T.Helper()
@@ -64,6 +108,21 @@ func (mr *MockServicerMockRecorder) GetSecret(ctx, userID, secretID interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecret", reflect.TypeOf((*MockServicer)(nil).GetSecret), ctx, userID, secretID)
 }
 
+// ListAttachments mocks base method.
+func (m *MockServicer) ListAttachments(ctx context.Context, userID string) ([]*stypes.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAttachments", ctx, userID)
+	ret0, _ := ret[0].([]*stypes.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAttachments indicates an expected call of ListAttachments.
+func (mr *MockServicerMockRecorder) ListAttachments(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAttachments", reflect.TypeOf((*MockServicer)(nil).ListAttachments), ctx, userID)
+}
+
 // ListSecrets mocks base method.
 func (m *MockServicer) ListSecrets(ctx context.Context, userID string) ([]*stypes.Secret, error) {
 	m.ctrl.T.Helper()
@@ -95,6 +154,34 @@ func (mr *MockServicerMockRecorder) Login(ctx, login, password interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockServicer)(nil).Login), ctx, login, password)
 }
 
+// PurgeSecret mocks base method.
+func (m *MockServicer) PurgeSecret(ctx context.Context, userID, secretID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeSecret", ctx, userID, secretID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeSecret indicates an expected call of PurgeSecret.
+func (mr *MockServicerMockRecorder) PurgeSecret(ctx, userID, secretID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeSecret", reflect.TypeOf((*MockServicer)(nil).PurgeSecret), ctx, userID, secretID)
+}
+
+// ReadAttachmentChunks mocks base method.
+func (m *MockServicer) ReadAttachmentChunks(ctx context.Context, userID, attachmentID string, fn func(*stypes.AttachmentChunk) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAttachmentChunks", ctx, userID, attachmentID, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReadAttachmentChunks indicates an expected call of ReadAttachmentChunks.
+func (mr *MockServicerMockRecorder) ReadAttachmentChunks(ctx, userID, attachmentID, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAttachmentChunks", reflect.TypeOf((*MockServicer)(nil).ReadAttachmentChunks), ctx, userID, attachmentID, fn)
+}
+
 // Register mocks base method.
 func (m *MockServicer) Register(ctx context.Context, login, password string) (*stypes.User, error) {
 	m.ctrl.T.Helper()
@@ -123,3 +210,17 @@ func (mr *MockServicerMockRecorder) SetSecret(ctx, secret interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSecret", reflect.TypeOf((*MockServicer)(nil).SetSecret), ctx, secret)
 }
+
+// UploadAttachment mocks base method.
+func (m *MockServicer) UploadAttachment(ctx context.Context, attachment *stypes.Attachment, firstChunk int, recv ChunkReceiver) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadAttachment", ctx, attachment, firstChunk, recv)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UploadAttachment indicates an expected call of UploadAttachment.
+func (mr *MockServicerMockRecorder) UploadAttachment(ctx, attachment, firstChunk, recv interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadAttachment", reflect.TypeOf((*MockServicer)(nil).UploadAttachment), ctx, attachment, firstChunk, recv)
+}
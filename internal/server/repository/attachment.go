@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/etoneja/go-keeper/internal/server/stypes"
+)
+
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+)
+
+type AttachmentRepository struct{}
+
+func NewAttachmentRepository() *AttachmentRepository {
+	return &AttachmentRepository{}
+}
+
+// CreateAttachmentUpload starts receiving the attachment, replacing the
+// attachment or an earlier upload of it. Run it in a transaction.
+func (r *AttachmentRepository) CreateAttachmentUpload(ctx context.Context, q Querier, attachment *stypes.Attachment) error {
+	query := `
+		INSERT INTO attachments (id, user_id, secret_id, data, hash, last_modified, header, chunks, size, complete)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, FALSE)
+		ON CONFLICT (id, user_id) DO UPDATE SET
+			secret_id = $3,
+			data = $4,
+			hash = $5,
+			last_modified = $6,
+			header = $7,
+			chunks = $8,
+			size = $9,
+			complete = FALSE
+	`
+
+	_, err := q.Exec(ctx, query,
+		attachment.ID,
+		attachment.UserID,
+		attachment.SecretID,
+		attachment.Data,
+		attachment.Hash,
+		attachment.LastModified,
+		attachment.Header,
+		attachment.Chunks,
+		attachment.Size,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(ctx, `DELETE FROM attachment_chunks WHERE user_id = $1 AND attachment_id = $2`,
+		attachment.UserID, attachment.ID)
+
+	return err
+}
+
+// GetAttachmentUpload returns an unfinished upload with the chunks received so far.
+func (r *AttachmentRepository) GetAttachmentUpload(ctx context.Context, q Querier, userID, attachmentID string) (*stypes.AttachmentUpload, error) {
+	query := `
+		SELECT a.id, a.user_id, a.secret_id, a.data, a.hash, a.last_modified, a.header, a.chunks, a.size,
+			COUNT(c.chunk_index), COALESCE(SUM(LENGTH(c.data)), 0)
+		FROM attachments a
+		LEFT JOIN attachment_chunks c ON c.attachment_id = a.id AND c.user_id = a.user_id
+		WHERE a.user_id = $1 AND a.id = $2 AND NOT a.complete
+		GROUP BY a.id, a.user_id
+	`
+
+	var attachment stypes.Attachment
+	upload := stypes.AttachmentUpload{Attachment: &attachment}
+	err := q.QueryRow(ctx, query, userID, attachmentID).Scan(
+		&attachment.ID,
+		&attachment.UserID,
+		&attachment.SecretID,
+		&attachment.Data,
+		&attachment.Hash,
+		&attachment.LastModified,
+		&attachment.Header,
+		&attachment.Chunks,
+		&attachment.Size,
+		&upload.ReceivedChunks,
+		&upload.ReceivedSize,
+	)
+
+	if err != nil {
+		return nil, ErrAttachmentNotFound
+	}
+
+	return &upload, nil
+}
+
+func (r *AttachmentRepository) AddAttachmentChunk(ctx context.Context, q Querier, userID, attachmentID string, chunk *stypes.AttachmentChunk) error {
+	query := `
+		INSERT INTO attachment_chunks (attachment_id, user_id, chunk_index, data)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := q.Exec(ctx, query, attachmentID, userID, chunk.Index, chunk.Data)
+	return err
+}
+
+// CompleteAttachmentUpload makes a fully received attachment visible.
+func (r *AttachmentRepository) CompleteAttachmentUpload(ctx context.Context, q Querier, userID, attachmentID string) error {
+	query := `
+		UPDATE attachments SET complete = TRUE
+		WHERE user_id = $1 AND id = $2 AND NOT complete
+	`
+
+	result, err := q.Exec(ctx, query, userID, attachmentID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrAttachmentNotFound
+	}
+
+	return nil
+}
+
+// GetAttachment returns a complete attachment without its chunks.
+func (r *AttachmentRepository) GetAttachment(ctx context.Context, q Querier, userID, attachmentID string) (*stypes.Attachment, error) {
+	query := `
+		SELECT id, user_id, secret_id, data, hash, last_modified, header, chunks, size
+		FROM attachments
+		WHERE user_id = $1 AND id = $2 AND complete
+	`
+
+	var attachment stypes.Attachment
+	err := q.QueryRow(ctx, query, userID, attachmentID).Scan(
+		&attachment.ID,
+		&attachment.UserID,
+		&attachment.SecretID,
+		&attachment.Data,
+		&attachment.Hash,
+		&attachment.LastModified,
+		&attachment.Header,
+		&attachment.Chunks,
+		&attachment.Size,
+	)
+
+	if err != nil {
+		return nil, ErrAttachmentNotFound
+	}
+
+	return &attachment, nil
+}
+
+// ReadAttachmentChunks calls fn with each chunk of the attachment in order,
+// holding one chunk in memory at a time.
+func (r *AttachmentRepository) ReadAttachmentChunks(ctx context.Context, q Querier, userID, attachmentID string, fn func(*stypes.AttachmentChunk) error) error {
+	query := `
+		SELECT chunk_index, data
+		FROM attachment_chunks
+		WHERE user_id = $1 AND attachment_id = $2
+		ORDER BY chunk_index
+	`
+
+	rows, err := q.Query(ctx, query, userID, attachmentID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var chunk stypes.AttachmentChunk
+		if err := rows.Scan(&chunk.Index, &chunk.Data); err != nil {
+			return err
+		}
+		if err := fn(&chunk); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// DeleteAttachment deletes the attachment with its chunks, or an unfinished upload of it.
+func (r *AttachmentRepository) DeleteAttachment(ctx context.Context, q Querier, userID, attachmentID string) error {
+	query := `
+		DELETE FROM attachments
+		WHERE user_id = $1 AND id = $2
+	`
+
+	result, err := q.Exec(ctx, query, userID, attachmentID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrAttachmentNotFound
+	}
+
+	return nil
+}
+
+// DeleteSecretAttachments deletes the attachments of the secret with their
+// chunks, unfinished uploads included.
+func (r *AttachmentRepository) DeleteSecretAttachments(ctx context.Context, q Querier, userID, secretID string) error {
+	query := `
+		DELETE FROM attachments
+		WHERE user_id = $1 AND secret_id = $2
+	`
+
+	_, err := q.Exec(ctx, query, userID, secretID)
+	return err
+}
+
+// ListAttachments returns the complete attachments of the user without their data.
+func (r *AttachmentRepository) ListAttachments(ctx context.Context, q Querier, userID string) ([]*stypes.Attachment, error) {
+	query := `
+		SELECT id, user_id, secret_id, hash, last_modified, chunks, size
+		FROM attachments
+		WHERE user_id = $1 AND complete
+		ORDER BY secret_id, last_modified
+	`
+
+	rows, err := q.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []*stypes.Attachment
+	for rows.Next() {
+		var attachment stypes.Attachment
+		err := rows.Scan(
+			&attachment.ID,
+			&attachment.UserID,
+			&attachment.SecretID,
+			&attachment.Hash,
+			&attachment.LastModified,
+			&attachment.Chunks,
+			&attachment.Size,
+		)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, &attachment)
+	}
+	return attachments, rows.Err()
+}
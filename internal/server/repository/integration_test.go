@@ -130,6 +130,39 @@ func TestSecretRepository_Integration(t *testing.T) {
 		assert.Equal(t, secret.Hash, retrieved.Hash)
 	})
 
+	t.Run("SetSecret same id for two users", func(t *testing.T) {
+		ctx := context.Background()
+
+		// NOTE: Clients choose ids, the sync manifest has the same id for every user
+		secretID := generateTestID("manifest")
+		users := []*testUser{
+			createTestUser(t, userRepo, generateTestID("user"), "password"),
+			createTestUser(t, userRepo, generateTestID("user"), "password"),
+		}
+
+		for _, user := range users {
+			secret := &stypes.Secret{
+				ID:           secretID,
+				UserID:       user.ID,
+				Data:         []byte("data of " + user.ID),
+				Hash:         generateTestID("hash"),
+				LastModified: time.Now(),
+			}
+			require.NoError(t, secretRepo.SetSecret(ctx, db, secret))
+			require.NoError(t, secretRepo.SetSecret(ctx, db, secret), "updates match the conflict target")
+		}
+
+		for _, user := range users {
+			retrieved, err := secretRepo.GetSecret(ctx, db, user.ID, secretID)
+			require.NoError(t, err)
+			assert.Equal(t, []byte("data of "+user.ID), retrieved.Data)
+		}
+
+		require.NoError(t, secretRepo.DeleteSecret(ctx, db, users[0].ID, secretID))
+		_, err := secretRepo.GetSecret(ctx, db, users[1].ID, secretID)
+		require.NoError(t, err)
+	})
+
 	t.Run("GetSecret not found", func(t *testing.T) {
 		ctx := context.Background()
 
@@ -199,6 +232,47 @@ func TestSecretRepository_Integration(t *testing.T) {
 		assert.ErrorIs(t, err, ErrSecretNotFound)
 	})
 
+	t.Run("TrashSecret and PurgeTrash", func(t *testing.T) {
+		ctx := context.Background()
+
+		user := createTestUser(t, userRepo, generateTestID("user"), "password")
+		secretID := generateTestID("secret")
+
+		secret := &stypes.Secret{
+			ID:           secretID,
+			UserID:       user.ID,
+			Data:         []byte("data"),
+			Hash:         generateTestID("hash"),
+			LastModified: time.Now(),
+		}
+
+		err := secretRepo.SetSecret(ctx, db, secret)
+		require.NoError(t, err)
+
+		trashedAt := time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Microsecond)
+		err = secretRepo.TrashSecret(ctx, db, user.ID, secretID, trashedAt)
+		require.NoError(t, err)
+
+		// NOTE: Trashing again keeps the first time
+		err = secretRepo.TrashSecret(ctx, db, user.ID, secretID, time.Now())
+		require.NoError(t, err)
+
+		retrieved, err := secretRepo.GetSecret(ctx, db, user.ID, secretID)
+		require.NoError(t, err)
+		require.NotNil(t, retrieved.DeletedAt)
+		assert.True(t, retrieved.DeletedAt.Equal(trashedAt))
+
+		err = secretRepo.PurgeTrash(ctx, db, user.ID, trashedAt.Add(-time.Hour))
+		require.NoError(t, err)
+		_, err = secretRepo.GetSecret(ctx, db, user.ID, secretID)
+		require.NoError(t, err)
+
+		err = secretRepo.PurgeTrash(ctx, db, user.ID, time.Now())
+		require.NoError(t, err)
+		_, err = secretRepo.GetSecret(ctx, db, user.ID, secretID)
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+	})
+
 	t.Run("DeleteSecret not found", func(t *testing.T) {
 		ctx := context.Background()
 
@@ -298,3 +372,120 @@ func TestSecretRepository_Integration(t *testing.T) {
 		assert.Equal(t, "hash1", secrets[2].Hash)
 	})
 }
+
+func TestAttachmentRepository_Integration(t *testing.T) {
+	db := getTestDB(t)
+	userRepo := NewUserRepository()
+	attachmentRepo := NewAttachmentRepository()
+
+	t.Run("upload, chunks, ListAttachments and DeleteAttachment", func(t *testing.T) {
+		ctx := context.Background()
+
+		user := createTestUser(t, userRepo, generateTestID("user"), "password")
+
+		attachment := &stypes.Attachment{
+			ID:           generateTestID("attachment"),
+			UserID:       user.ID,
+			SecretID:     generateTestID("secret"),
+			Data:         []byte("attachment data"),
+			Hash:         generateTestID("hash"),
+			LastModified: time.Now(),
+			Header:       []byte("header"),
+			Chunks:       2,
+			Size:         12,
+		}
+
+		err := attachmentRepo.CreateAttachmentUpload(ctx, db, attachment)
+		require.NoError(t, err)
+
+		err = attachmentRepo.AddAttachmentChunk(ctx, db, user.ID, attachment.ID, &stypes.AttachmentChunk{Index: 0, Data: []byte("chunk0")})
+		require.NoError(t, err)
+
+		_, err = attachmentRepo.GetAttachment(ctx, db, user.ID, attachment.ID)
+		assert.ErrorIs(t, err, ErrAttachmentNotFound, "unfinished uploads are not visible")
+
+		upload, err := attachmentRepo.GetAttachmentUpload(ctx, db, user.ID, attachment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, upload.ReceivedChunks)
+		assert.Equal(t, int64(6), upload.ReceivedSize)
+		assert.Equal(t, attachment.Header, upload.Attachment.Header)
+
+		usage, err := userRepo.GetStorageUsage(ctx, db, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(attachment.Data)+len(attachment.Header))+attachment.Size, usage)
+		require.NoError(t, userRepo.LockUser(ctx, db, user.ID))
+
+		err = attachmentRepo.AddAttachmentChunk(ctx, db, user.ID, attachment.ID, &stypes.AttachmentChunk{Index: 1, Data: []byte("chunk1")})
+		require.NoError(t, err)
+		require.NoError(t, attachmentRepo.CompleteAttachmentUpload(ctx, db, user.ID, attachment.ID))
+
+		retrieved, err := attachmentRepo.GetAttachment(ctx, db, user.ID, attachment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, attachment.SecretID, retrieved.SecretID)
+		assert.Equal(t, attachment.Data, retrieved.Data)
+		assert.Equal(t, 2, retrieved.Chunks)
+
+		var chunks []string
+		err = attachmentRepo.ReadAttachmentChunks(ctx, db, user.ID, attachment.ID, func(chunk *stypes.AttachmentChunk) error {
+			chunks = append(chunks, string(chunk.Data))
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"chunk0", "chunk1"}, chunks)
+
+		attachments, err := attachmentRepo.ListAttachments(ctx, db, user.ID)
+		require.NoError(t, err)
+		require.Len(t, attachments, 1)
+		assert.Equal(t, attachment.Hash, attachments[0].Hash)
+		assert.Nil(t, attachments[0].Data)
+
+		err = attachmentRepo.DeleteAttachment(ctx, db, user.ID, attachment.ID)
+		require.NoError(t, err)
+
+		err = attachmentRepo.DeleteAttachment(ctx, db, user.ID, attachment.ID)
+		assert.ErrorIs(t, err, ErrAttachmentNotFound)
+	})
+
+	t.Run("same attachment id for two users", func(t *testing.T) {
+		ctx := context.Background()
+
+		attachmentID := generateTestID("attachment")
+		users := []*testUser{
+			createTestUser(t, userRepo, generateTestID("user"), "password"),
+			createTestUser(t, userRepo, generateTestID("user"), "password"),
+		}
+
+		for _, user := range users {
+			attachment := &stypes.Attachment{
+				ID:           attachmentID,
+				UserID:       user.ID,
+				SecretID:     generateTestID("secret"),
+				Data:         []byte("attachment data"),
+				Hash:         generateTestID("hash"),
+				LastModified: time.Now(),
+				Header:       []byte("header"),
+				Chunks:       1,
+				Size:         int64(len(user.ID)),
+			}
+			require.NoError(t, attachmentRepo.CreateAttachmentUpload(ctx, db, attachment))
+			chunk := &stypes.AttachmentChunk{Index: 0, Data: []byte(user.ID)}
+			require.NoError(t, attachmentRepo.AddAttachmentChunk(ctx, db, user.ID, attachmentID, chunk))
+
+			upload, err := attachmentRepo.GetAttachmentUpload(ctx, db, user.ID, attachmentID)
+			require.NoError(t, err)
+			assert.Equal(t, 1, upload.ReceivedChunks)
+
+			require.NoError(t, attachmentRepo.CompleteAttachmentUpload(ctx, db, user.ID, attachmentID))
+		}
+
+		require.NoError(t, attachmentRepo.DeleteAttachment(ctx, db, users[0].ID, attachmentID))
+
+		var chunks []string
+		err := attachmentRepo.ReadAttachmentChunks(ctx, db, users[1].ID, attachmentID, func(chunk *stypes.AttachmentChunk) error {
+			chunks = append(chunks, string(chunk.Data))
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{users[1].ID}, chunks)
+	})
+}
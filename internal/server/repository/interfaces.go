@@ -2,6 +2,7 @@ package repository
 
 import (
 	context "context"
+	"time"
 
 	"github.com/etoneja/go-keeper/internal/server/stypes"
 	"github.com/jackc/pgx/v5"
@@ -22,11 +23,27 @@ type UserRepositorier interface {
 	CreateUser(ctx context.Context, q Querier, login, passwordHash string) (*stypes.User, error)
 	GetUserByLogin(ctx context.Context, q Querier, login string) (*stypes.User, error)
 	GetUserByID(ctx context.Context, q Querier, userID string) (*stypes.User, error)
+	LockUser(ctx context.Context, q Querier, userID string) error
+	GetStorageUsage(ctx context.Context, q Querier, userID string) (int64, error)
 }
 
 type SecretRepositorier interface {
 	SetSecret(ctx context.Context, q Querier, secret *stypes.Secret) error
 	GetSecret(ctx context.Context, q Querier, userID, secretID string) (*stypes.Secret, error)
+	TrashSecret(ctx context.Context, q Querier, userID, secretID string, at time.Time) error
 	DeleteSecret(ctx context.Context, q Querier, userID, secretID string) error
 	ListSecrets(ctx context.Context, q Querier, userID string) ([]*stypes.Secret, error)
+	PurgeTrash(ctx context.Context, q Querier, userID string, before time.Time) error
+}
+
+type AttachmentRepositorier interface {
+	CreateAttachmentUpload(ctx context.Context, q Querier, attachment *stypes.Attachment) error
+	GetAttachmentUpload(ctx context.Context, q Querier, userID, attachmentID string) (*stypes.AttachmentUpload, error)
+	AddAttachmentChunk(ctx context.Context, q Querier, userID, attachmentID string, chunk *stypes.AttachmentChunk) error
+	CompleteAttachmentUpload(ctx context.Context, q Querier, userID, attachmentID string) error
+	GetAttachment(ctx context.Context, q Querier, userID, attachmentID string) (*stypes.Attachment, error)
+	ReadAttachmentChunks(ctx context.Context, q Querier, userID, attachmentID string, fn func(*stypes.AttachmentChunk) error) error
+	DeleteAttachment(ctx context.Context, q Querier, userID, attachmentID string) error
+	DeleteSecretAttachments(ctx context.Context, q Querier, userID, secretID string) error
+	ListAttachments(ctx context.Context, q Querier, userID string) ([]*stypes.Attachment, error)
 }
@@ -7,6 +7,7 @@ package repository
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	stypes "github.com/etoneja/go-keeper/internal/server/stypes"
 	gomock "github.com/golang/mock/gomock"
@@ -171,6 +172,21 @@ func (mr *MockUserRepositorierMockRecorder) CreateUser(ctx, q, login, passwordHa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserRepositorier)(nil).CreateUser), ctx, q, login, passwordHash)
 }
 
+// GetStorageUsage mocks base method.
+func (m *MockUserRepositorier) GetStorageUsage(ctx context.Context, q Querier, userID string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStorageUsage", ctx, q, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStorageUsage indicates an expected call of GetStorageUsage.
+func (mr *MockUserRepositorierMockRecorder) GetStorageUsage(ctx, q, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStorageUsage", reflect.TypeOf((*MockUserRepositorier)(nil).GetStorageUsage), ctx, q, userID)
+}
+
 // GetUserByID mocks base method.
 func (m *MockUserRepositorier) GetUserByID(ctx context.Context, q Querier, userID string) (*stypes.User, error) {
 	m.ctrl.T.Helper()
@@ -201,6 +217,20 @@ func (mr *MockUserRepositorierMockRecorder) GetUserByLogin(ctx, q, login interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByLogin", reflect.TypeOf((*MockUserRepositorier)(nil).GetUserByLogin), ctx, q, login)
 }
 
+// LockUser mocks base method.
+func (m *MockUserRepositorier) LockUser(ctx context.Context, q Querier, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockUser", ctx, q, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockUser indicates an expected call of LockUser.
+func (mr *MockUserRepositorierMockRecorder) LockUser(ctx, q, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockUser", reflect.TypeOf((*MockUserRepositorier)(nil).LockUser), ctx, q, userID)
+}
+
 // MockSecretRepositorier is a mock of SecretRepositorier interface.
 type MockSecretRepositorier struct {
 	ctrl     *gomock.Controller
@@ -268,6 +298,20 @@ func (mr *MockSecretRepositorierMockRecorder) ListSecrets(ctx, q, userID interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecrets", reflect.TypeOf((*MockSecretRepositorier)(nil).ListSecrets), ctx, q, userID)
 }
 
+// PurgeTrash mocks base method.
+func (m *MockSecretRepositorier) PurgeTrash(ctx context.Context, q Querier, userID string, before time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTrash", ctx, q, userID, before)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeTrash indicates an expected call of PurgeTrash.
+func (mr *MockSecretRepositorierMockRecorder) PurgeTrash(ctx, q, userID, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTrash", reflect.TypeOf((*MockSecretRepositorier)(nil).PurgeTrash), ctx, q, userID, before)
+}
+
 // SetSecret mocks base method.
 func (m *MockSecretRepositorier) SetSecret(ctx context.Context, q Querier, secret *stypes.Secret) error {
 	m.ctrl.T.Helper()
@@ -281,3 +325,169 @@ func (mr *MockSecretRepositorierMockRecorder) SetSecret(ctx, q, secret interface
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSecret", reflect.TypeOf((*MockSecretRepositorier)(nil).SetSecret), ctx, q, secret)
 }
+
+// TrashSecret mocks base method.
+func (m *MockSecretRepositorier) TrashSecret(ctx context.Context, q Querier, userID, secretID string, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrashSecret", ctx, q, userID, secretID, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TrashSecret indicates an expected call of TrashSecret.
+func (mr *MockSecretRepositorierMockRecorder) TrashSecret(ctx, q, userID, secretID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrashSecret", reflect.TypeOf((*MockSecretRepositorier)(nil).TrashSecret), ctx, q, userID, secretID, at)
+}
+
+// MockAttachmentRepositorier is a mock of AttachmentRepositorier interface.
+type MockAttachmentRepositorier struct {
+	ctrl     *gomock.Controller
+	recorder *MockAttachmentRepositorierMockRecorder
+}
+
+// MockAttachmentRepositorierMockRecorder is the mock recorder for MockAttachmentRepositorier.
+type MockAttachmentRepositorierMockRecorder struct {
+	mock *MockAttachmentRepositorier
+}
+
+// NewMockAttachmentRepositorier creates a new mock instance.
+func NewMockAttachmentRepositorier(ctrl *gomock.Controller) *MockAttachmentRepositorier {
+	mock := &MockAttachmentRepositorier{ctrl: ctrl}
+	mock.recorder = &MockAttachmentRepositorierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAttachmentRepositorier) EXPECT() *MockAttachmentRepositorierMockRecorder {
+	return m.recorder
+}
+
+// AddAttachmentChunk mocks base method.
+func (m *MockAttachmentRepositorier) AddAttachmentChunk(ctx context.Context, q Querier, userID, attachmentID string, chunk *stypes.AttachmentChunk) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAttachmentChunk", ctx, q, userID, attachmentID, chunk)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAttachmentChunk indicates an expected call of AddAttachmentChunk.
+func (mr *MockAttachmentRepositorierMockRecorder) AddAttachmentChunk(ctx, q, userID, attachmentID, chunk interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAttachmentChunk", reflect.TypeOf((*MockAttachmentRepositorier)(nil).AddAttachmentChunk), ctx, q, userID, attachmentID, chunk)
+}
+
+// CompleteAttachmentUpload mocks base method.
+func (m *MockAttachmentRepositorier) CompleteAttachmentUpload(ctx context.Context, q Querier, userID, attachmentID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteAttachmentUpload", ctx, q, userID, attachmentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteAttachmentUpload indicates an expected call of CompleteAttachmentUpload.
+func (mr *MockAttachmentRepositorierMockRecorder) CompleteAttachmentUpload(ctx, q, userID, attachmentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteAttachmentUpload", reflect.TypeOf((*MockAttachmentRepositorier)(nil).CompleteAttachmentUpload), ctx, q, userID, attachmentID)
+}
+
+// CreateAttachmentUpload mocks base method.
+func (m *MockAttachmentRepositorier) CreateAttachmentUpload(ctx context.Context, q Querier, attachment *stypes.Attachment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAttachmentUpload", ctx, q, attachment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAttachmentUpload indicates an expected call of CreateAttachmentUpload.
+func (mr *MockAttachmentRepositorierMockRecorder) CreateAttachmentUpload(ctx, q, attachment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAttachmentUpload", reflect.TypeOf((*MockAttachmentRepositorier)(nil).CreateAttachmentUpload), ctx, q, attachment)
+}
+
+// DeleteAttachment mocks base method.
+func (m *MockAttachmentRepositorier) DeleteAttachment(ctx context.Context, q Querier, userID, attachmentID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAttachment", ctx, q, userID, attachmentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAttachment indicates an expected call of DeleteAttachment.
+func (mr *MockAttachmentRepositorierMockRecorder) DeleteAttachment(ctx, q, userID, attachmentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAttachment", reflect.TypeOf((*MockAttachmentRepositorier)(nil).DeleteAttachment), ctx, q, userID, attachmentID)
+}
+
+// DeleteSecretAttachments mocks base method.
+func (m *MockAttachmentRepositorier) DeleteSecretAttachments(ctx context.Context, q Querier, userID, secretID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSecretAttachments", ctx, q, userID, secretID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSecretAttachments indicates an expected call of DeleteSecretAttachments.
+func (mr *MockAttachmentRepositorierMockRecorder) DeleteSecretAttachments(ctx, q, userID, secretID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecretAttachments", reflect.TypeOf((*MockAttachmentRepositorier)(nil).DeleteSecretAttachments), ctx, q, userID, secretID)
+}
+
+// GetAttachment mocks base method.
+func (m *MockAttachmentRepositorier) GetAttachment(ctx context.Context, q Querier, userID, attachmentID string) (*stypes.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttachment", ctx, q, userID, attachmentID)
+	ret0, _ := ret[0].(*stypes.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAttachment indicates an expected call of GetAttachment.
+func (mr *MockAttachmentRepositorierMockRecorder) GetAttachment(ctx, q, userID, attachmentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttachment", reflect.TypeOf((*MockAttachmentRepositorier)(nil).GetAttachment), ctx, q, userID, attachmentID)
+}
+
+// GetAttachmentUpload mocks base method.
+func (m *MockAttachmentRepositorier) GetAttachmentUpload(ctx context.Context, q Querier, userID, attachmentID string) (*stypes.AttachmentUpload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttachmentUpload", ctx, q, userID, attachmentID)
+	ret0, _ := ret[0].(*stypes.AttachmentUpload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAttachmentUpload indicates an expected call of GetAttachmentUpload.
+func (mr *MockAttachmentRepositorierMockRecorder) GetAttachmentUpload(ctx, q, userID, attachmentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttachmentUpload", reflect.TypeOf((*MockAttachmentRepositorier)(nil).GetAttachmentUpload), ctx, q, userID, attachmentID)
+}
+
+// ListAttachments mocks base method.
+func (m *MockAttachmentRepositorier) ListAttachments(ctx context.Context, q Querier, userID string) ([]*stypes.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAttachments", ctx, q, userID)
+	ret0, _ := ret[0].([]*stypes.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAttachments indicates an expected call of ListAttachments.
+func (mr *MockAttachmentRepositorierMockRecorder) ListAttachments(ctx, q, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAttachments", reflect.TypeOf((*MockAttachmentRepositorier)(nil).ListAttachments), ctx, q, userID)
+}
+
+// ReadAttachmentChunks mocks base method.
+func (m *MockAttachmentRepositorier) ReadAttachmentChunks(ctx context.Context, q Querier, userID, attachmentID string, fn func(*stypes.AttachmentChunk) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAttachmentChunks", ctx, q, userID, attachmentID, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReadAttachmentChunks indicates an expected call of ReadAttachmentChunks.
+func (mr *MockAttachmentRepositorierMockRecorder) ReadAttachmentChunks(ctx, q, userID, attachmentID, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAttachmentChunks", reflect.TypeOf((*MockAttachmentRepositorier)(nil).ReadAttachmentChunks), ctx, q, userID, attachmentID, fn)
+}
@@ -10,16 +10,19 @@ import (
 )
 
 type Repositories struct {
-	UserRepo   UserRepositorier
-	SecretRepo SecretRepositorier
+	UserRepo       UserRepositorier
+	SecretRepo     SecretRepositorier
+	AttachmentRepo AttachmentRepositorier
 }
 
 func NewRepositories() *Repositories {
 	userRepo := NewUserRepository()
 	secretRepo := NewSecretRepository()
+	attachmentRepo := NewAttachmentRepository()
 	return &Repositories{
-		UserRepo:   userRepo,
-		SecretRepo: secretRepo,
+		UserRepo:       userRepo,
+		SecretRepo:     secretRepo,
+		AttachmentRepo: attachmentRepo,
 	}
 }
 
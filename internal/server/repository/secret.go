@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/etoneja/go-keeper/internal/server/stypes"
 )
@@ -19,12 +20,13 @@ func NewSecretRepository() *SecretRepository {
 
 func (r *SecretRepository) SetSecret(ctx context.Context, q Querier, secret *stypes.Secret) error {
 	query := `
-		INSERT INTO secrets (id, user_id, data, hash, last_modified)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO secrets (id, user_id, data, hash, last_modified, deleted_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		ON CONFLICT (id, user_id) DO UPDATE SET
 			data = $3,
 			hash = $4, 
-			last_modified = $5
+			last_modified = $5,
+			deleted_at = $6
 	`
 
 	_, err := q.Exec(ctx, query,
@@ -33,6 +35,7 @@ func (r *SecretRepository) SetSecret(ctx context.Context, q Querier, secret *sty
 		secret.Data,
 		secret.Hash,
 		secret.LastModified,
+		secret.DeletedAt,
 	)
 
 	return err
@@ -40,7 +43,7 @@ func (r *SecretRepository) SetSecret(ctx context.Context, q Querier, secret *sty
 
 func (r *SecretRepository) GetSecret(ctx context.Context, q Querier, userID, secretID string) (*stypes.Secret, error) {
 	query := `
-		SELECT id, user_id, data, hash, last_modified 
+		SELECT id, user_id, data, hash, last_modified, deleted_at
 		FROM secrets 
 		WHERE user_id = $1 AND id = $2
 	`
@@ -52,6 +55,7 @@ func (r *SecretRepository) GetSecret(ctx context.Context, q Querier, userID, sec
 		&secret.Data,
 		&secret.Hash,
 		&secret.LastModified,
+		&secret.DeletedAt,
 	)
 
 	if err != nil {
@@ -61,6 +65,28 @@ func (r *SecretRepository) GetSecret(ctx context.Context, q Querier, userID, sec
 	return &secret, nil
 }
 
+// TrashSecret moves the secret to the trash at the given time. A secret
+// already in the trash keeps the time it was moved there.
+func (r *SecretRepository) TrashSecret(ctx context.Context, q Querier, userID, secretID string, at time.Time) error {
+	query := `
+		UPDATE secrets
+		SET deleted_at = COALESCE(deleted_at, $3)
+		WHERE user_id = $1 AND id = $2
+	`
+
+	result, err := q.Exec(ctx, query, userID, secretID, at)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrSecretNotFound
+	}
+
+	return nil
+}
+
+// DeleteSecret erases the secret, in the trash or not.
 func (r *SecretRepository) DeleteSecret(ctx context.Context, q Querier, userID, secretID string) error {
 	query := `
 		DELETE FROM secrets
@@ -81,7 +107,7 @@ func (r *SecretRepository) DeleteSecret(ctx context.Context, q Querier, userID,
 
 func (r *SecretRepository) ListSecrets(ctx context.Context, q Querier, userID string) ([]*stypes.Secret, error) {
 	query := `
-		SELECT id, user_id, hash, last_modified
+		SELECT id, user_id, hash, last_modified, deleted_at
 		FROM secrets 
 		WHERE user_id = $1
 		ORDER BY last_modified DESC
@@ -101,6 +127,7 @@ func (r *SecretRepository) ListSecrets(ctx context.Context, q Querier, userID st
 			&secret.UserID,
 			&secret.Hash,
 			&secret.LastModified,
+			&secret.DeletedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -109,3 +136,20 @@ func (r *SecretRepository) ListSecrets(ctx context.Context, q Querier, userID st
 	}
 	return secrets, nil
 }
+
+// PurgeTrash erases the secrets of the user moved to the trash before the
+// given time, with their attachments.
+func (r *SecretRepository) PurgeTrash(ctx context.Context, q Querier, userID string, before time.Time) error {
+	query := `
+		WITH purged AS (
+			DELETE FROM secrets
+			WHERE user_id = $1 AND deleted_at < $2
+			RETURNING id
+		)
+		DELETE FROM attachments
+		WHERE user_id = $1 AND secret_id IN (SELECT id FROM purged)
+	`
+
+	_, err := q.Exec(ctx, query, userID, before)
+	return err
+}
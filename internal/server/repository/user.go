@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"github.com/etoneja/go-keeper/internal/server/stypes"
+	"github.com/jackc/pgx/v5"
 )
 
 var (
@@ -83,3 +84,41 @@ func (r *UserRepository) GetUserByID(ctx context.Context, q Querier, userID stri
 
 	return &user, nil
 }
+
+// LockUser locks the user row until the transaction of q ends, so that
+// concurrent writes of the same user are serialized.
+func (r *UserRepository) LockUser(ctx context.Context, q Querier, userID string) error {
+	query := `
+		SELECT id
+		FROM users
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	var id string
+	if err := q.QueryRow(ctx, query, userID).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetStorageUsage returns the bytes stored for the user. Unfinished uploads
+// count at their full size.
+func (r *UserRepository) GetStorageUsage(ctx context.Context, q Querier, userID string) (int64, error) {
+	query := `
+		SELECT
+			COALESCE((SELECT SUM(LENGTH(data)) FROM secrets WHERE user_id = $1), 0) +
+			COALESCE((SELECT SUM(LENGTH(data) + LENGTH(header) + size) FROM attachments WHERE user_id = $1), 0)
+	`
+
+	var usage int64
+	if err := q.QueryRow(ctx, query, userID).Scan(&usage); err != nil {
+		return 0, err
+	}
+
+	return usage, nil
+}
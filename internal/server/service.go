@@ -1,8 +1,12 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"time"
 
 	"github.com/etoneja/go-keeper/internal/server/repository"
 	"github.com/etoneja/go-keeper/internal/server/stypes"
@@ -16,16 +20,26 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUserAlreadyExists  = errors.New("user already exists")
 	ErrSecretTooLarge     = errors.New("secret data too large")
+	ErrQuotaExceeded      = errors.New("storage quota exceeded")
+	ErrInvalidUpload      = errors.New("invalid attachment upload")
+	ErrUploadMismatch     = errors.New("no matching upload to resume")
 )
 
+// ChunkReceiver returns the next chunk of an upload, io.EOF after the last one.
+type ChunkReceiver func() (*stypes.AttachmentChunk, error)
+
 type Service struct {
 	db           *pgxpool.Pool
 	tokenManager token.TokenManager
 	repos        *repository.Repositories
 	txManager    repository.TxManager
+	// userQuota caps the bytes stored per user, zero or less disables it
+	userQuota int64
+	// trashRetention is how long secrets stay in the trash, zero or less keeps them
+	trashRetention time.Duration
 }
 
-func NewService(db *pgxpool.Pool, tokenManager token.TokenManager, txManager repository.TxManager, repos *repository.Repositories) *Service {
+func NewService(db *pgxpool.Pool, tokenManager token.TokenManager, txManager repository.TxManager, repos *repository.Repositories, userQuota int64, trashRetention time.Duration) *Service {
 	if txManager == nil {
 		txManager = &repository.DefaultTxManager{Repos: repos, Db: db}
 	}
@@ -34,6 +48,9 @@ func NewService(db *pgxpool.Pool, tokenManager token.TokenManager, txManager rep
 		tokenManager: tokenManager,
 		repos:        repos,
 		txManager:    txManager,
+		userQuota:    userQuota,
+
+		trashRetention: trashRetention,
 	}
 }
 
@@ -91,7 +108,29 @@ func (s *Service) SetSecret(ctx context.Context, secret *stypes.Secret) error {
 		return ErrSecretTooLarge
 	}
 
-	return s.repos.SecretRepo.SetSecret(ctx, s.db, secret)
+	return s.txManager.WithTx(ctx, func(q repository.Querier) error {
+		if err := s.lockQuota(ctx, q, secret.UserID); err != nil {
+			return err
+		}
+
+		if err := s.purgeTrash(ctx, q, secret.UserID); err != nil {
+			return err
+		}
+
+		added := int64(len(secret.Data))
+		existing, err := s.repos.SecretRepo.GetSecret(ctx, q, secret.UserID, secret.ID)
+		if err == nil {
+			added -= int64(len(existing.Data))
+		} else if !errors.Is(err, repository.ErrSecretNotFound) {
+			return err
+		}
+
+		if err := s.checkQuota(ctx, q, secret.UserID, added); err != nil {
+			return err
+		}
+
+		return s.repos.SecretRepo.SetSecret(ctx, q, secret)
+	})
 }
 
 func (s *Service) GetSecret(ctx context.Context, userID, secretID string) (*stypes.Secret, error) {
@@ -99,11 +138,188 @@ func (s *Service) GetSecret(ctx context.Context, userID, secretID string) (*styp
 	return s.repos.SecretRepo.GetSecret(ctx, s.db, userID, secretID)
 }
 
+// DeleteSecret moves the secret to the trash, where it stays listed until
+// purged, see PurgeSecret and purgeTrash.
 func (s *Service) DeleteSecret(ctx context.Context, userID, secretID string) error {
 	// TODO: check ownership in service
-	return s.repos.SecretRepo.DeleteSecret(ctx, s.db, userID, secretID)
+	return s.txManager.WithTx(ctx, func(q repository.Querier) error {
+		if err := s.purgeTrash(ctx, q, userID); err != nil {
+			return err
+		}
+
+		return s.repos.SecretRepo.TrashSecret(ctx, q, userID, secretID, time.Now().UTC())
+	})
+}
+
+// PurgeSecret erases the secret with its attachments.
+func (s *Service) PurgeSecret(ctx context.Context, userID, secretID string) error {
+	return s.txManager.WithTx(ctx, func(q repository.Querier) error {
+		if err := s.repos.SecretRepo.DeleteSecret(ctx, q, userID, secretID); err != nil {
+			return err
+		}
+
+		return s.repos.AttachmentRepo.DeleteSecretAttachments(ctx, q, userID, secretID)
+	})
 }
 
+// ListSecrets returns the secrets of the user, those in the trash too.
 func (s *Service) ListSecrets(ctx context.Context, userID string) ([]*stypes.Secret, error) {
 	return s.repos.SecretRepo.ListSecrets(ctx, s.db, userID)
 }
+
+// purgeTrash erases the secrets of the user in the trash longer than the
+// retention. It runs when the user writes, so listing never deletes.
+func (s *Service) purgeTrash(ctx context.Context, q repository.Querier, userID string) error {
+	if s.trashRetention <= 0 {
+		return nil
+	}
+
+	before := time.Now().UTC().Add(-s.trashRetention)
+	return s.repos.SecretRepo.PurgeTrash(ctx, q, userID, before)
+}
+
+// UploadAttachment receives the attachment chunk by chunk. Chunks are stored
+// as they arrive, so an upload cut off midway is resumed by uploading the same
+// attachment again from firstChunk. The attachment is listed once complete.
+func (s *Service) UploadAttachment(ctx context.Context, attachment *stypes.Attachment, firstChunk int, recv ChunkReceiver) error {
+	if attachment.Chunks < 1 || attachment.Size < 0 || firstChunk < 0 || firstChunk > attachment.Chunks {
+		return fmt.Errorf("%w: %d chunks starting at %d", ErrInvalidUpload, attachment.Chunks, firstChunk)
+	}
+	if len(attachment.Data) > maxSecretSize {
+		return ErrSecretTooLarge
+	}
+
+	var receivedSize int64
+	if firstChunk == 0 {
+		if err := s.startAttachmentUpload(ctx, attachment); err != nil {
+			return err
+		}
+	} else {
+		upload, err := s.repos.AttachmentRepo.GetAttachmentUpload(ctx, s.db, attachment.UserID, attachment.ID)
+		if errors.Is(err, repository.ErrAttachmentNotFound) {
+			return ErrUploadMismatch
+		}
+		if err != nil {
+			return err
+		}
+		if !sameAttachment(upload.Attachment, attachment) || upload.ReceivedChunks != firstChunk {
+			return ErrUploadMismatch
+		}
+		receivedSize = upload.ReceivedSize
+	}
+
+	next := firstChunk
+	for {
+		chunk, err := recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if chunk.Index != next || next >= attachment.Chunks {
+			return fmt.Errorf("%w: got chunk %d, expected %d", ErrInvalidUpload, chunk.Index, next)
+		}
+		if len(chunk.Data) > maxAttachmentChunkSize {
+			return fmt.Errorf("%w: chunk %d is larger than %d bytes", ErrInvalidUpload, chunk.Index, maxAttachmentChunkSize)
+		}
+		receivedSize += int64(len(chunk.Data))
+		if receivedSize > attachment.Size {
+			return fmt.Errorf("%w: more than %d bytes sent", ErrInvalidUpload, attachment.Size)
+		}
+
+		if err := s.repos.AttachmentRepo.AddAttachmentChunk(ctx, s.db, attachment.UserID, attachment.ID, chunk); err != nil {
+			return err
+		}
+		next++
+	}
+
+	if next != attachment.Chunks || receivedSize != attachment.Size {
+		return fmt.Errorf("%w: ended after %d of %d chunks", ErrInvalidUpload, next, attachment.Chunks)
+	}
+
+	return s.repos.AttachmentRepo.CompleteAttachmentUpload(ctx, s.db, attachment.UserID, attachment.ID)
+}
+
+// startAttachmentUpload replaces the attachment with an empty upload of it,
+// which counts against the quota at its full size.
+func (s *Service) startAttachmentUpload(ctx context.Context, attachment *stypes.Attachment) error {
+	return s.txManager.WithTx(ctx, func(q repository.Querier) error {
+		if err := s.lockQuota(ctx, q, attachment.UserID); err != nil {
+			return err
+		}
+
+		err := s.repos.AttachmentRepo.DeleteAttachment(ctx, q, attachment.UserID, attachment.ID)
+		if err != nil && !errors.Is(err, repository.ErrAttachmentNotFound) {
+			return err
+		}
+
+		added := int64(len(attachment.Data)+len(attachment.Header)) + attachment.Size
+		if err := s.checkQuota(ctx, q, attachment.UserID, added); err != nil {
+			return err
+		}
+
+		return s.repos.AttachmentRepo.CreateAttachmentUpload(ctx, q, attachment)
+	})
+}
+
+func sameAttachment(a, b *stypes.Attachment) bool {
+	return a.SecretID == b.SecretID &&
+		a.Hash == b.Hash &&
+		a.LastModified.Equal(b.LastModified) &&
+		a.Chunks == b.Chunks &&
+		a.Size == b.Size &&
+		bytes.Equal(a.Header, b.Header) &&
+		bytes.Equal(a.Data, b.Data)
+}
+
+// GetAttachmentUpload returns an unfinished upload of the attachment.
+func (s *Service) GetAttachmentUpload(ctx context.Context, userID, attachmentID string) (*stypes.AttachmentUpload, error) {
+	return s.repos.AttachmentRepo.GetAttachmentUpload(ctx, s.db, userID, attachmentID)
+}
+
+func (s *Service) GetAttachment(ctx context.Context, userID, attachmentID string) (*stypes.Attachment, error) {
+	return s.repos.AttachmentRepo.GetAttachment(ctx, s.db, userID, attachmentID)
+}
+
+// ReadAttachmentChunks calls fn with each chunk of the attachment in order.
+func (s *Service) ReadAttachmentChunks(ctx context.Context, userID, attachmentID string, fn func(*stypes.AttachmentChunk) error) error {
+	return s.repos.AttachmentRepo.ReadAttachmentChunks(ctx, s.db, userID, attachmentID, fn)
+}
+
+func (s *Service) DeleteAttachment(ctx context.Context, userID, attachmentID string) error {
+	return s.repos.AttachmentRepo.DeleteAttachment(ctx, s.db, userID, attachmentID)
+}
+
+func (s *Service) ListAttachments(ctx context.Context, userID string) ([]*stypes.Attachment, error) {
+	return s.repos.AttachmentRepo.ListAttachments(ctx, s.db, userID)
+}
+
+// lockQuota locks the user for the rest of the transaction of q, so that
+// concurrent writes cannot all pass checkQuota on the same usage.
+func (s *Service) lockQuota(ctx context.Context, q repository.Querier, userID string) error {
+	if s.userQuota <= 0 {
+		return nil
+	}
+	return s.repos.UserRepo.LockUser(ctx, q, userID)
+}
+
+// checkQuota fails when storing added more bytes would take the user over
+// the quota. Shrinking is always allowed. Writes must hold lockQuota.
+func (s *Service) checkQuota(ctx context.Context, q repository.Querier, userID string, added int64) error {
+	if s.userQuota <= 0 || added <= 0 {
+		return nil
+	}
+
+	usage, err := s.repos.UserRepo.GetStorageUsage(ctx, q, userID)
+	if err != nil {
+		return err
+	}
+
+	if usage+added > s.userQuota {
+		return fmt.Errorf("%w: %d of %d bytes used, %d more needed", ErrQuotaExceeded, usage, s.userQuota, added)
+	}
+
+	return nil
+}
@@ -2,7 +2,9 @@ package server
 
 import (
 	"context"
+	"io"
 	"testing"
+	"time"
 
 	"github.com/etoneja/go-keeper/internal/server/repository"
 	"github.com/etoneja/go-keeper/internal/server/stypes"
@@ -36,7 +38,7 @@ func TestService_Register(t *testing.T) {
 	}
 	txManager := &MockTxManager{querier: mockQuerier}
 
-	service := NewService(nil, mockTokenManager, txManager, repos)
+	service := NewService(nil, mockTokenManager, txManager, repos, defaultUserQuota, 0)
 
 	t.Run("success", func(t *testing.T) {
 		mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), mockQuerier, "testuser").
@@ -72,7 +74,7 @@ func TestService_Login(t *testing.T) {
 		UserRepo:   mockUserRepo,
 		SecretRepo: mockSecretRepo,
 	}
-	service := NewService(nil, mockTokenManager, nil, repos)
+	service := NewService(nil, mockTokenManager, nil, repos, defaultUserQuota, 0)
 
 	passwordHash, _ := bcrypt.GenerateFromPassword([]byte("pass"), bcrypt.DefaultCost)
 	testUser := &stypes.User{ID: "123", PasswordHash: string(passwordHash)}
@@ -114,17 +116,52 @@ func TestService_Secrets(t *testing.T) {
 	mockTokenManager := token.NewMockTokenManager(ctrl)
 	mockUserRepo := repository.NewMockUserRepositorier(ctrl)
 	mockSecretRepo := repository.NewMockSecretRepositorier(ctrl)
+	mockAttachmentRepo := repository.NewMockAttachmentRepositorier(ctrl)
 
 	repos := &repository.Repositories{
-		UserRepo:   mockUserRepo,
-		SecretRepo: mockSecretRepo,
+		UserRepo:       mockUserRepo,
+		SecretRepo:     mockSecretRepo,
+		AttachmentRepo: mockAttachmentRepo,
 	}
-	service := NewService(nil, mockTokenManager, nil, repos)
+	trashRetention := defaultTrashDays * 24 * time.Hour
+	service := NewService(nil, mockTokenManager, &MockTxManager{}, repos, defaultUserQuota, trashRetention)
 
 	secret := &stypes.Secret{ID: "s1", UserID: "u1", Data: []byte("data")}
 	secrets := []*stypes.Secret{secret}
 
+	expectPurgeTrash := func() {
+		before := time.Now().UTC().Add(-trashRetention)
+		mockSecretRepo.EXPECT().PurgeTrash(gomock.Any(), gomock.Any(), "u1", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ repository.Querier, _ string, cutoff time.Time) error {
+				assert.WithinDuration(t, before, cutoff, time.Minute)
+				return nil
+			})
+	}
+
 	t.Run("SetSecret success", func(t *testing.T) {
+		mockUserRepo.EXPECT().LockUser(gomock.Any(), gomock.Any(), "u1").Return(nil)
+		expectPurgeTrash()
+		mockSecretRepo.EXPECT().GetSecret(gomock.Any(), gomock.Any(), "u1", "s1").Return(nil, repository.ErrSecretNotFound)
+		mockUserRepo.EXPECT().GetStorageUsage(gomock.Any(), gomock.Any(), "u1").Return(int64(100), nil)
+		mockSecretRepo.EXPECT().SetSecret(gomock.Any(), gomock.Any(), secret).Return(nil)
+		err := service.SetSecret(context.Background(), secret)
+		require.NoError(t, err)
+	})
+
+	t.Run("SetSecret over quota", func(t *testing.T) {
+		mockUserRepo.EXPECT().LockUser(gomock.Any(), gomock.Any(), "u1").Return(nil)
+		expectPurgeTrash()
+		mockSecretRepo.EXPECT().GetSecret(gomock.Any(), gomock.Any(), "u1", "s1").Return(nil, repository.ErrSecretNotFound)
+		mockUserRepo.EXPECT().GetStorageUsage(gomock.Any(), gomock.Any(), "u1").Return(int64(defaultUserQuota-2), nil)
+		err := service.SetSecret(context.Background(), secret)
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+	})
+
+	t.Run("SetSecret shrinking over quota", func(t *testing.T) {
+		existing := &stypes.Secret{ID: "s1", UserID: "u1", Data: []byte("longer data")}
+		mockUserRepo.EXPECT().LockUser(gomock.Any(), gomock.Any(), "u1").Return(nil)
+		expectPurgeTrash()
+		mockSecretRepo.EXPECT().GetSecret(gomock.Any(), gomock.Any(), "u1", "s1").Return(existing, nil)
 		mockSecretRepo.EXPECT().SetSecret(gomock.Any(), gomock.Any(), secret).Return(nil)
 		err := service.SetSecret(context.Background(), secret)
 		require.NoError(t, err)
@@ -145,11 +182,27 @@ func TestService_Secrets(t *testing.T) {
 	})
 
 	t.Run("DeleteSecret", func(t *testing.T) {
-		mockSecretRepo.EXPECT().DeleteSecret(gomock.Any(), gomock.Any(), "u1", "s1").Return(nil)
+		expectPurgeTrash()
+		mockSecretRepo.EXPECT().TrashSecret(gomock.Any(), gomock.Any(), "u1", "s1", gomock.Any()).Return(nil)
 		err := service.DeleteSecret(context.Background(), "u1", "s1")
 		require.NoError(t, err)
 	})
 
+	t.Run("PurgeSecret", func(t *testing.T) {
+		gomock.InOrder(
+			mockSecretRepo.EXPECT().DeleteSecret(gomock.Any(), gomock.Any(), "u1", "s1").Return(nil),
+			mockAttachmentRepo.EXPECT().DeleteSecretAttachments(gomock.Any(), gomock.Any(), "u1", "s1").Return(nil),
+		)
+		err := service.PurgeSecret(context.Background(), "u1", "s1")
+		require.NoError(t, err)
+	})
+
+	t.Run("PurgeSecret not found", func(t *testing.T) {
+		mockSecretRepo.EXPECT().DeleteSecret(gomock.Any(), gomock.Any(), "u1", "s2").Return(repository.ErrSecretNotFound)
+		err := service.PurgeSecret(context.Background(), "u1", "s2")
+		assert.ErrorIs(t, err, repository.ErrSecretNotFound)
+	})
+
 	t.Run("ListSecrets", func(t *testing.T) {
 		mockSecretRepo.EXPECT().ListSecrets(gomock.Any(), gomock.Any(), "u1").Return(secrets, nil)
 		result, err := service.ListSecrets(context.Background(), "u1")
@@ -157,3 +210,126 @@ func TestService_Secrets(t *testing.T) {
 		assert.Equal(t, secrets, result)
 	})
 }
+
+// chunkReceiver returns the chunks, then io.EOF.
+func chunkReceiver(chunks ...*stypes.AttachmentChunk) ChunkReceiver {
+	return func() (*stypes.AttachmentChunk, error) {
+		if len(chunks) == 0 {
+			return nil, io.EOF
+		}
+		chunk := chunks[0]
+		chunks = chunks[1:]
+		return chunk, nil
+	}
+}
+
+func TestService_Attachments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := repository.NewMockUserRepositorier(ctrl)
+	mockAttachmentRepo := repository.NewMockAttachmentRepositorier(ctrl)
+
+	repos := &repository.Repositories{
+		UserRepo:       mockUserRepo,
+		AttachmentRepo: mockAttachmentRepo,
+	}
+	service := NewService(nil, nil, &MockTxManager{}, repos, 1000, 0)
+
+	attachment := &stypes.Attachment{
+		ID: "a1", UserID: "u1", SecretID: "s1", Data: []byte("data"),
+		Header: []byte("header"), Chunks: 2, Size: 10,
+	}
+	attachments := []*stypes.Attachment{attachment}
+	chunks := []*stypes.AttachmentChunk{
+		{Index: 0, Data: []byte("chunk")},
+		{Index: 1, Data: []byte("chunk")},
+	}
+
+	t.Run("UploadAttachment success", func(t *testing.T) {
+		gomock.InOrder(
+			mockUserRepo.EXPECT().LockUser(gomock.Any(), gomock.Any(), "u1").Return(nil),
+			mockAttachmentRepo.EXPECT().DeleteAttachment(gomock.Any(), gomock.Any(), "u1", "a1").Return(repository.ErrAttachmentNotFound),
+			mockUserRepo.EXPECT().GetStorageUsage(gomock.Any(), gomock.Any(), "u1").Return(int64(500), nil),
+			mockAttachmentRepo.EXPECT().CreateAttachmentUpload(gomock.Any(), gomock.Any(), attachment).Return(nil),
+			mockAttachmentRepo.EXPECT().AddAttachmentChunk(gomock.Any(), gomock.Any(), "u1", "a1", chunks[0]).Return(nil),
+			mockAttachmentRepo.EXPECT().AddAttachmentChunk(gomock.Any(), gomock.Any(), "u1", "a1", chunks[1]).Return(nil),
+			mockAttachmentRepo.EXPECT().CompleteAttachmentUpload(gomock.Any(), gomock.Any(), "u1", "a1").Return(nil),
+		)
+
+		err := service.UploadAttachment(context.Background(), attachment, 0, chunkReceiver(chunks...))
+		require.NoError(t, err)
+	})
+
+	t.Run("UploadAttachment over quota", func(t *testing.T) {
+		mockUserRepo.EXPECT().LockUser(gomock.Any(), gomock.Any(), "u1").Return(nil)
+		mockAttachmentRepo.EXPECT().DeleteAttachment(gomock.Any(), gomock.Any(), "u1", "a1").Return(nil)
+		mockUserRepo.EXPECT().GetStorageUsage(gomock.Any(), gomock.Any(), "u1").Return(int64(990), nil)
+
+		err := service.UploadAttachment(context.Background(), attachment, 0, chunkReceiver(chunks...))
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+	})
+
+	t.Run("UploadAttachment resumed", func(t *testing.T) {
+		upload := &stypes.AttachmentUpload{Attachment: attachment, ReceivedChunks: 1, ReceivedSize: 5}
+		gomock.InOrder(
+			mockAttachmentRepo.EXPECT().GetAttachmentUpload(gomock.Any(), gomock.Any(), "u1", "a1").Return(upload, nil),
+			mockAttachmentRepo.EXPECT().AddAttachmentChunk(gomock.Any(), gomock.Any(), "u1", "a1", chunks[1]).Return(nil),
+			mockAttachmentRepo.EXPECT().CompleteAttachmentUpload(gomock.Any(), gomock.Any(), "u1", "a1").Return(nil),
+		)
+
+		err := service.UploadAttachment(context.Background(), attachment, 1, chunkReceiver(chunks[1]))
+		require.NoError(t, err)
+	})
+
+	t.Run("UploadAttachment resumed with other content", func(t *testing.T) {
+		other := *attachment
+		other.Hash = "other"
+		upload := &stypes.AttachmentUpload{Attachment: &other, ReceivedChunks: 1, ReceivedSize: 5}
+		mockAttachmentRepo.EXPECT().GetAttachmentUpload(gomock.Any(), gomock.Any(), "u1", "a1").Return(upload, nil)
+
+		err := service.UploadAttachment(context.Background(), attachment, 1, chunkReceiver(chunks[1]))
+		assert.ErrorIs(t, err, ErrUploadMismatch)
+	})
+
+	t.Run("UploadAttachment out of order", func(t *testing.T) {
+		mockUserRepo.EXPECT().LockUser(gomock.Any(), gomock.Any(), "u1").Return(nil)
+		mockAttachmentRepo.EXPECT().DeleteAttachment(gomock.Any(), gomock.Any(), "u1", "a1").Return(nil)
+		mockUserRepo.EXPECT().GetStorageUsage(gomock.Any(), gomock.Any(), "u1").Return(int64(0), nil)
+		mockAttachmentRepo.EXPECT().CreateAttachmentUpload(gomock.Any(), gomock.Any(), attachment).Return(nil)
+
+		err := service.UploadAttachment(context.Background(), attachment, 0, chunkReceiver(chunks[1]))
+		assert.ErrorIs(t, err, ErrInvalidUpload)
+	})
+
+	t.Run("UploadAttachment cut short", func(t *testing.T) {
+		mockUserRepo.EXPECT().LockUser(gomock.Any(), gomock.Any(), "u1").Return(nil)
+		mockAttachmentRepo.EXPECT().DeleteAttachment(gomock.Any(), gomock.Any(), "u1", "a1").Return(nil)
+		mockUserRepo.EXPECT().GetStorageUsage(gomock.Any(), gomock.Any(), "u1").Return(int64(0), nil)
+		mockAttachmentRepo.EXPECT().CreateAttachmentUpload(gomock.Any(), gomock.Any(), attachment).Return(nil)
+		mockAttachmentRepo.EXPECT().AddAttachmentChunk(gomock.Any(), gomock.Any(), "u1", "a1", chunks[0]).Return(nil)
+
+		err := service.UploadAttachment(context.Background(), attachment, 0, chunkReceiver(chunks[0]))
+		assert.ErrorIs(t, err, ErrInvalidUpload)
+	})
+
+	t.Run("GetAttachment", func(t *testing.T) {
+		mockAttachmentRepo.EXPECT().GetAttachment(gomock.Any(), gomock.Any(), "u1", "a1").Return(attachment, nil)
+		result, err := service.GetAttachment(context.Background(), "u1", "a1")
+		require.NoError(t, err)
+		assert.Equal(t, attachment, result)
+	})
+
+	t.Run("DeleteAttachment", func(t *testing.T) {
+		mockAttachmentRepo.EXPECT().DeleteAttachment(gomock.Any(), gomock.Any(), "u1", "a1").Return(nil)
+		err := service.DeleteAttachment(context.Background(), "u1", "a1")
+		require.NoError(t, err)
+	})
+
+	t.Run("ListAttachments", func(t *testing.T) {
+		mockAttachmentRepo.EXPECT().ListAttachments(gomock.Any(), gomock.Any(), "u1").Return(attachments, nil)
+		result, err := service.ListAttachments(context.Background(), "u1")
+		require.NoError(t, err)
+		assert.Equal(t, attachments, result)
+	})
+}
@@ -8,6 +8,35 @@ type Secret struct {
 	LastModified time.Time
 	Hash         string
 	Data         []byte
+	// DeletedAt is set while the secret is in the trash
+	DeletedAt *time.Time
+}
+
+// Attachment is a file attached to a secret. Like secrets, Data is encrypted
+// by the client and opaque to the server. The content is stored apart in
+// Chunks chunks of Size bytes in total, Header opens it on the client.
+type Attachment struct {
+	ID           string
+	UserID       string
+	SecretID     string
+	LastModified time.Time
+	Hash         string
+	Data         []byte
+	Header       []byte
+	Chunks       int
+	Size         int64
+}
+
+type AttachmentChunk struct {
+	Index int
+	Data  []byte
+}
+
+// AttachmentUpload is an attachment whose chunks are still being received.
+type AttachmentUpload struct {
+	Attachment     *Attachment
+	ReceivedChunks int
+	ReceivedSize   int64
 }
 
 type User struct {
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS attachments (
+    id VARCHAR(255) PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    secret_id VARCHAR(255) NOT NULL,
+    last_modified TIMESTAMP NOT NULL,
+    hash VARCHAR(255) NOT NULL,
+    data BYTEA NOT NULL
+);
+
+ALTER TABLE attachments ADD CONSTRAINT attachments_id_user_id_key UNIQUE (id, user_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_user_id ON attachments(user_id);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS attachment_chunks;
+
+ALTER TABLE attachments
+    DROP COLUMN IF EXISTS header,
+    DROP COLUMN IF EXISTS chunks,
+    DROP COLUMN IF EXISTS size,
+    DROP COLUMN IF EXISTS complete;
@@ -0,0 +1,15 @@
+ALTER TABLE attachments
+    ADD COLUMN IF NOT EXISTS header BYTEA NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS chunks INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS size BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS complete BOOLEAN NOT NULL DEFAULT TRUE;
+
+CREATE TABLE IF NOT EXISTS attachment_chunks (
+    attachment_id VARCHAR(255) NOT NULL REFERENCES attachments(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chunk_index INTEGER NOT NULL,
+    data BYTEA NOT NULL,
+    PRIMARY KEY (attachment_id, chunk_index)
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachment_chunks_user_id ON attachment_chunks(user_id);
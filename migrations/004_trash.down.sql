@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_secrets_deleted_at;
+
+ALTER TABLE secrets DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,14 @@
+ALTER TABLE attachment_chunks DROP CONSTRAINT IF EXISTS attachment_chunks_attachment_id_fkey;
+ALTER TABLE attachment_chunks DROP CONSTRAINT IF EXISTS attachment_chunks_pkey;
+
+ALTER TABLE secrets DROP CONSTRAINT IF EXISTS secrets_pkey;
+ALTER TABLE secrets DROP CONSTRAINT IF EXISTS secrets_id_user_id_key;
+ALTER TABLE secrets ADD CONSTRAINT secrets_pkey PRIMARY KEY (id, user_id);
+
+ALTER TABLE attachments DROP CONSTRAINT IF EXISTS attachments_pkey;
+ALTER TABLE attachments DROP CONSTRAINT IF EXISTS attachments_id_user_id_key;
+ALTER TABLE attachments ADD CONSTRAINT attachments_pkey PRIMARY KEY (id, user_id);
+
+ALTER TABLE attachment_chunks ADD CONSTRAINT attachment_chunks_pkey PRIMARY KEY (attachment_id, user_id, chunk_index);
+ALTER TABLE attachment_chunks ADD CONSTRAINT attachment_chunks_attachment_id_fkey
+    FOREIGN KEY (attachment_id, user_id) REFERENCES attachments(id, user_id) ON DELETE CASCADE;